@@ -0,0 +1,81 @@
+// bot/api/auth.go
+package api
+
+import (
+	"bot/database"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type scopesContextKey struct{}
+
+// hashAPIKey - Keys werden nur als SHA-256 Hash in der Tabelle api_keys gespeichert.
+// Neuer Key: echo -n "<key>" | sha256sum, dann
+// INSERT INTO api_keys (name, key_hash, scopes) VALUES ('webapp', '<hash>', 'events');
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// requireAPIKey - Middleware, die einen aktiven API Key mit passendem Scope verlangt.
+// Der Key wird per Header "X-API-Key" oder, für EventSource-Clients, per Query "api_key" übergeben.
+func requireAPIKey(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if key == "" {
+			key = r.URL.Query().Get("api_key")
+		}
+		if key == "" {
+			http.Error(w, "API Key fehlt", http.StatusUnauthorized)
+			return
+		}
+
+		var id int
+		var scopes string
+		err := database.DB.QueryRow(
+			`SELECT id, scopes FROM api_keys WHERE key_hash = ? AND is_active = true`,
+			hashAPIKey(key),
+		).Scan(&id, &scopes)
+		if err == sql.ErrNoRows {
+			http.Error(w, "Ungültiger API Key", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			log.Printf("Fehler beim Prüfen des API Keys: %v", err)
+			http.Error(w, "Fehler beim Prüfen des API Keys", http.StatusInternalServerError)
+			return
+		}
+		if !hasScope(scopes, scope) {
+			http.Error(w, "API Key hat keine Berechtigung für diesen Endpunkt", http.StatusForbidden)
+			return
+		}
+
+		if _, err := database.DB.Exec(`UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP WHERE id = ?`, id); err != nil {
+			log.Printf("Fehler beim Aktualisieren von last_used_at für API Key %d: %v", id, err)
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), scopesContextKey{}, scopes)))
+	}
+}
+
+// requestHasScope - prüft im Handler, ob der API Key der Anfrage einen weiteren Scope hat (z.B. "tickets_staff")
+func requestHasScope(r *http.Request, scope string) bool {
+	scopes, _ := r.Context().Value(scopesContextKey{}).(string)
+	return hasScope(scopes, scope)
+}
+
+// hasScope - scopes ist eine kommagetrennte Liste, "*" erlaubt alles
+func hasScope(scopes, scope string) bool {
+	for _, s := range strings.Split(scopes, ",") {
+		s = strings.TrimSpace(s)
+		if s == "*" || s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestHasScope(t *testing.T) {
+	tests := []struct {
+		scopes string
+		want   bool
+	}{
+		{scopes: "", want: false},
+		{scopes: "tickets", want: false},
+		{scopes: "tickets,events", want: false},
+		{scopes: "tickets_staff_read", want: false},
+		{scopes: "tickets_staff", want: true},
+		{scopes: "tickets, tickets_staff", want: true},
+		{scopes: "*", want: true},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/api/tickets/1/transcript", nil)
+		r = r.WithContext(context.WithValue(r.Context(), scopesContextKey{}, tt.scopes))
+		if got := requestHasScope(r, "tickets_staff"); got != tt.want {
+			t.Errorf("requestHasScope(%q) = %v, want %v", tt.scopes, got, tt.want)
+		}
+	}
+
+	// Ohne requireAPIKey gibt es keine Scopes im Context
+	if requestHasScope(httptest.NewRequest("GET", "/", nil), "tickets_staff") {
+		t.Errorf("requestHasScope ohne Scopes im Context = true")
+	}
+}
@@ -6,23 +6,31 @@ import (
 	"net/http"
 	"os"
 	"log"
+	quizService "bot/services/quiz"
 	statsService "bot/services/stats"
+	ticketService "bot/services/tickets"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/gorilla/mux"
 )
 
 type APIServer struct {
-	statsService *statsService.StatsService
-	bot          *discordgo.Session  // Bot-Session direkt hinzufügen
-	guildID      string
+	statsService    *statsService.StatsService
+	questionService *quizService.QuestionService
+	areaService     *ticketService.AreaService
+	ticketService   *ticketService.TicketService
+	bot             *discordgo.Session  // Bot-Session direkt hinzufügen
+	guildID         string
 }
 
 func NewAPIServer(bot *discordgo.Session, guildID string) *APIServer {
 	return &APIServer{
-		statsService: statsService.NewStatsService(bot),
-		bot:          bot,  // Bot-Session speichern
-		guildID:      guildID,
+		statsService:    statsService.NewStatsService(bot),
+		questionService: quizService.NewQuestionService(bot),
+		areaService:     ticketService.NewAreaService(bot),
+		ticketService:   ticketService.NewTicketService(bot),
+		bot:             bot,  // Bot-Session speichern
+		guildID:         guildID,
 	}
 }
 
@@ -41,6 +49,41 @@ func (api *APIServer) StartAPI() {
 	r.HandleFunc("/api/teams/member/delete/{user_id}", api.handleDeleteTeamMember).Methods("DELETE")
 	r.HandleFunc("/api/teams/name/change/{team_id}", api.handleChangeTeamName).Methods("POST")
 	r.HandleFunc("/api/teams/delete/{category_id}", api.handleDeleteTeam).Methods("DELETE")
+
+	// Live Events (SSE), nur mit API Key
+	r.HandleFunc("/api/events/stream", requireAPIKey("events", api.handleEventStream)).Methods("GET")
+
+	// Quiz-Fragen Verwaltung, nur mit API Key
+	r.HandleFunc("/api/quiz/questions", requireAPIKey("quiz", api.handleListQuizQuestions)).Methods("GET")
+	r.HandleFunc("/api/quiz/questions", requireAPIKey("quiz", api.handleCreateQuizQuestion)).Methods("POST")
+	r.HandleFunc("/api/quiz/questions/import", requireAPIKey("quiz", api.handleImportQuizQuestions)).Methods("POST")
+	r.HandleFunc("/api/quiz/questions/{id:[0-9]+}", requireAPIKey("quiz", api.handleGetQuizQuestion)).Methods("GET")
+	r.HandleFunc("/api/quiz/questions/{id:[0-9]+}", requireAPIKey("quiz", api.handleUpdateQuizQuestion)).Methods("PUT")
+	r.HandleFunc("/api/quiz/questions/{id:[0-9]+}", requireAPIKey("quiz", api.handleDeleteQuizQuestion)).Methods("DELETE")
+	r.HandleFunc("/api/quiz/schedule", requireAPIKey("quiz", api.handleQuizSchedule)).Methods("GET")
+
+	// Ticket-Bereiche, Formulare und Panels, nur mit API Key
+	r.HandleFunc("/api/tickets/areas", requireAPIKey("tickets", api.handleListTicketAreas)).Methods("GET")
+	r.HandleFunc("/api/tickets/areas", requireAPIKey("tickets", api.handleCreateTicketArea)).Methods("POST")
+	r.HandleFunc("/api/tickets/areas/{key}", requireAPIKey("tickets", api.handleGetTicketArea)).Methods("GET")
+	r.HandleFunc("/api/tickets/areas/{key}", requireAPIKey("tickets", api.handleUpdateTicketArea)).Methods("PUT")
+	r.HandleFunc("/api/tickets/areas/{key}", requireAPIKey("tickets", api.handleDeleteTicketArea)).Methods("DELETE")
+	r.HandleFunc("/api/tickets/panels", requireAPIKey("tickets", api.handleListTicketPanels)).Methods("GET")
+	r.HandleFunc("/api/tickets/panels", requireAPIKey("tickets", api.handleCreateTicketPanel)).Methods("POST")
+	r.HandleFunc("/api/tickets/panels/{id:[0-9]+}", requireAPIKey("tickets", api.handleUpdateTicketPanel)).Methods("PUT")
+	r.HandleFunc("/api/tickets/panels/{id:[0-9]+}", requireAPIKey("tickets", api.handleDeleteTicketPanel)).Methods("DELETE")
+	r.HandleFunc("/api/tickets/sla", requireAPIKey("tickets", api.handleGetTicketSLAReport)).Methods("GET")
+	r.HandleFunc("/api/tickets/funnel", requireAPIKey("tickets", api.handleGetTicketFunnel)).Methods("GET")
+	r.HandleFunc("/api/tickets/stats", requireAPIKey("tickets", api.handleGetTicketStats)).Methods("GET")
+	r.HandleFunc("/api/tickets/csat", requireAPIKey("tickets", api.handleGetTicketCSAT)).Methods("GET")
+	r.HandleFunc("/api/tickets/search", requireAPIKey("tickets", api.handleSearchTickets)).Methods("GET")
+	r.HandleFunc("/api/tickets/{id:[0-9]+}/decision", requireAPIKey("tickets", api.handleGetTicketDecision)).Methods("GET")
+	r.HandleFunc("/api/tickets/{id:[0-9]+}/feedback", requireAPIKey("tickets", api.handleGetTicketFeedback)).Methods("GET")
+	r.HandleFunc("/api/tickets/{id:[0-9]+}/events", requireAPIKey("tickets", api.handleGetTicketEvents)).Methods("GET")
+	r.HandleFunc("/api/tickets/{id:[0-9]+}/participants", requireAPIKey("tickets", api.handleGetTicketParticipants)).Methods("GET")
+	r.HandleFunc("/api/tickets/{id:[0-9]+}/assignments", requireAPIKey("tickets", api.handleGetTicketAssignments)).Methods("GET")
+	r.HandleFunc("/api/tickets/{id:[0-9]+}/notes", requireAPIKey("tickets_staff", api.handleGetTicketNotes)).Methods("GET")
+	r.HandleFunc("/api/tickets/{id:[0-9]+}/transcript", requireAPIKey("tickets", api.handleGetTicketTranscript)).Methods("GET")
 	
 	port := os.Getenv("API_PORT")
 	if port == "" {
@@ -83,8 +126,8 @@ func (api *APIServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-API-Key, Last-Event-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
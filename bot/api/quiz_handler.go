@@ -0,0 +1,183 @@
+// bot/api/quiz_handler.go
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	quizService "bot/services/quiz"
+	"bot/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// Maximale Größe einer Import-Datei (2 MB)
+const maxQuizImportSize = 2 << 20
+
+// handleListQuizQuestions - GET /api/quiz/questions?limit=&offset=
+func (api *APIServer) handleListQuizQuestions(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	questions, err := api.questionService.List(limit, offset)
+	if err != nil {
+		utils.LogAndNotifyAdmins(api.bot, "medium", "Error", "quiz_handler.go", true, err, "Fehler beim Laden der Quiz-Fragen")
+		http.Error(w, "Fehler beim Laden der Quiz-Fragen", http.StatusInternalServerError)
+		return
+	}
+	if questions == nil {
+		questions = []quizService.Question{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(questions)
+}
+
+// handleGetQuizQuestion - GET /api/quiz/questions/{id}
+func (api *APIServer) handleGetQuizQuestion(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Ungültige ID", http.StatusBadRequest)
+		return
+	}
+
+	q, err := api.questionService.Get(id)
+	if err != nil {
+		api.writeQuizError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(q)
+}
+
+// handleCreateQuizQuestion - POST /api/quiz/questions
+func (api *APIServer) handleCreateQuizQuestion(w http.ResponseWriter, r *http.Request) {
+	var q quizService.Question
+	if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+		http.Error(w, "Ungültiges JSON", http.StatusBadRequest)
+		return
+	}
+	q.ID = 0
+
+	if _, err := api.questionService.Create(&q); err != nil {
+		api.writeQuizError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(q)
+}
+
+// handleUpdateQuizQuestion - PUT /api/quiz/questions/{id}
+func (api *APIServer) handleUpdateQuizQuestion(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Ungültige ID", http.StatusBadRequest)
+		return
+	}
+
+	var q quizService.Question
+	if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+		http.Error(w, "Ungültiges JSON", http.StatusBadRequest)
+		return
+	}
+	q.ID = id
+
+	if err := api.questionService.Update(&q); err != nil {
+		api.writeQuizError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(q)
+}
+
+// handleDeleteQuizQuestion - DELETE /api/quiz/questions/{id}
+func (api *APIServer) handleDeleteQuizQuestion(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Ungültige ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.questionService.Delete(id); err != nil {
+		api.writeQuizError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Frage gelöscht",
+	})
+}
+
+// handleImportQuizQuestions - POST /api/quiz/questions/import
+// Body ist CSV (Content-Type text/csv oder ?format=csv) oder ein JSON-Array
+func (api *APIServer) handleImportQuizQuestions(w http.ResponseWriter, r *http.Request) {
+	body := io.LimitReader(r.Body, maxQuizImportSize)
+
+	var questions []quizService.Question
+	var err error
+	firstLine := 1
+	if r.URL.Query().Get("format") == "csv" || strings.HasPrefix(r.Header.Get("Content-Type"), "text/csv") {
+		questions, err = quizService.ParseCSV(body)
+		firstLine = 2
+	} else {
+		questions, err = quizService.ParseJSON(body)
+	}
+	if err != nil {
+		http.Error(w, "Datei konnte nicht gelesen werden: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := api.questionService.Import(questions, firstLine)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleQuizSchedule - GET /api/quiz/schedule?days=14
+func (api *APIServer) handleQuizSchedule(w http.ResponseWriter, r *http.Request) {
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days <= 0 || days > 90 {
+		days = 14
+	}
+
+	preview, err := api.questionService.Preview(days)
+	if err != nil {
+		utils.LogAndNotifyAdmins(api.bot, "medium", "Error", "quiz_handler.go", true, err, "Fehler beim Erstellen der Quiz-Vorschau")
+		http.Error(w, "Fehler beim Erstellen der Quiz-Vorschau", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+// writeQuizError übersetzt Service-Fehler in HTTP-Statuscodes
+func (api *APIServer) writeQuizError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, quizService.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, quizService.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, quizService.ErrDuplicate), errors.Is(err, quizService.ErrDateTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		utils.LogAndNotifyAdmins(api.bot, "medium", "Error", "quiz_handler.go", true, err, "Fehler bei der Verarbeitung einer Quiz-Frage")
+		http.Error(w, "Interner Fehler", http.StatusInternalServerError)
+	}
+}
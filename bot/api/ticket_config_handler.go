@@ -0,0 +1,182 @@
+// bot/api/ticket_config_handler.go
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// handleListTicketAreas - GET /api/tickets/areas
+func (api *APIServer) handleListTicketAreas(w http.ResponseWriter, r *http.Request) {
+	areas, err := api.areaService.ListAreas()
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+	if areas == nil {
+		areas = []ticketService.Area{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(areas)
+}
+
+// handleGetTicketArea - GET /api/tickets/areas/{key}
+func (api *APIServer) handleGetTicketArea(w http.ResponseWriter, r *http.Request) {
+	area, err := api.areaService.GetArea(mux.Vars(r)["key"])
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(area)
+}
+
+// handleCreateTicketArea - POST /api/tickets/areas
+func (api *APIServer) handleCreateTicketArea(w http.ResponseWriter, r *http.Request) {
+	// Neue Bereiche sind standardmäßig aktiv
+	area := ticketService.Area{Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(&area); err != nil {
+		http.Error(w, "Ungültiges JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.areaService.CreateArea(&area); err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(area)
+}
+
+// handleUpdateTicketArea - PUT /api/tickets/areas/{key}
+// Ohne "fields" im Body bleiben die Formularfelder unverändert
+func (api *APIServer) handleUpdateTicketArea(w http.ResponseWriter, r *http.Request) {
+	var area ticketService.Area
+	if err := json.NewDecoder(r.Body).Decode(&area); err != nil {
+		http.Error(w, "Ungültiges JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.areaService.UpdateArea(mux.Vars(r)["key"], &area); err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(area)
+}
+
+// handleDeleteTicketArea - DELETE /api/tickets/areas/{key}
+func (api *APIServer) handleDeleteTicketArea(w http.ResponseWriter, r *http.Request) {
+	if err := api.areaService.DeleteArea(mux.Vars(r)["key"]); err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Bereich gelöscht",
+	})
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// handleListTicketPanels - GET /api/tickets/panels
+func (api *APIServer) handleListTicketPanels(w http.ResponseWriter, r *http.Request) {
+	panels, err := api.areaService.ListPanels()
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(panels)
+}
+
+// handleCreateTicketPanel - POST /api/tickets/panels
+func (api *APIServer) handleCreateTicketPanel(w http.ResponseWriter, r *http.Request) {
+	panel := ticketService.Panel{AreaKeys: []string{}}
+	if err := json.NewDecoder(r.Body).Decode(&panel); err != nil {
+		http.Error(w, "Ungültiges JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.areaService.CreatePanel(&panel); err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(panel)
+}
+
+// handleUpdateTicketPanel - PUT /api/tickets/panels/{id}
+func (api *APIServer) handleUpdateTicketPanel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Ungültige ID", http.StatusBadRequest)
+		return
+	}
+
+	panel := ticketService.Panel{AreaKeys: []string{}}
+	if err := json.NewDecoder(r.Body).Decode(&panel); err != nil {
+		http.Error(w, "Ungültiges JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.areaService.UpdatePanel(id, &panel); err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(panel)
+}
+
+// handleDeleteTicketPanel - DELETE /api/tickets/panels/{id}
+func (api *APIServer) handleDeleteTicketPanel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Ungültige ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.areaService.DeletePanel(id); err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Panel gelöscht",
+	})
+}
+
+// writeTicketConfigError übersetzt Service-Fehler in HTTP-Statuscodes
+func (api *APIServer) writeTicketConfigError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ticketService.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ticketService.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ticketService.ErrDuplicate):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		utils.LogAndNotifyAdmins(api.bot, "medium", "Error", "ticket_config_handler.go", true, err, "Fehler bei der Verarbeitung der Ticket-Konfiguration")
+		http.Error(w, "Interner Fehler", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,330 @@
+// bot/api/ticket_handler.go
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bot/services/storage"
+	ticketService "bot/services/tickets"
+	"bot/services/transcripts"
+
+	"github.com/gorilla/mux"
+)
+
+// handleGetTicketEvents - GET /api/tickets/{id}/events
+// Liefert Ticket-Status und den Verlauf aller Statuswechsel, mit Scope "tickets_staff" auch die internen Notizen
+func (api *APIServer) handleGetTicketEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Ungültige ID", http.StatusBadRequest)
+		return
+	}
+
+	ticket, err := api.ticketService.GetTicket(id)
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+	events, err := api.ticketService.Events(id)
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"ticket": ticket,
+		"events": events,
+	}
+	if requestHasScope(r, "tickets_staff") {
+		notes, err := api.ticketService.Notes(id)
+		if err != nil {
+			api.writeTicketConfigError(w, err)
+			return
+		}
+		response["notes"] = notes
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleGetTicketParticipants - GET /api/tickets/{id}/participants
+// Liefert die zusätzlichen Teilnehmer eines Tickets
+func (api *APIServer) handleGetTicketParticipants(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Ungültige ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := api.ticketService.GetTicket(id); err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+	participants, err := api.ticketService.Participants(id)
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(participants)
+}
+
+// handleGetTicketNotes - GET /api/tickets/{id}/notes
+// Liefert die internen Notizen des Teams (nur Scope "tickets_staff")
+func (api *APIServer) handleGetTicketNotes(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Ungültige ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := api.ticketService.GetTicket(id); err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+	notes, err := api.ticketService.Notes(id)
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notes)
+}
+
+// handleGetTicketAssignments - GET /api/tickets/{id}/assignments
+// Liefert alle Zuweisungen eines Tickets mit Modus (manual, round_robin, least_load) und Begründung
+func (api *APIServer) handleGetTicketAssignments(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Ungültige ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := api.ticketService.GetTicket(id); err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+	assignments, err := api.ticketService.Assignments(id)
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(assignments)
+}
+
+// handleGetTicketSLAReport - GET /api/tickets/sla?days=30
+// Liefert Übernahme- und Schließzeiten je Bereich sowie offene SLA-Überschreitungen
+func (api *APIServer) handleGetTicketSLAReport(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 365 {
+			http.Error(w, "days muss zwischen 1 und 365 liegen", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	now := time.Now()
+	report, err := api.ticketService.SLAReport(now.AddDate(0, 0, -days), now)
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// handleGetTicketFunnel - GET /api/tickets/funnel?days=30
+// Liefert je Bewerbungsbereich beworben, angenommen, abgelehnt, offen und die durchschnittliche Entscheidungsdauer
+func (api *APIServer) handleGetTicketFunnel(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 365 {
+			http.Error(w, "days muss zwischen 1 und 365 liegen", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	now := time.Now()
+	report, err := api.ticketService.FunnelReport(now.AddDate(0, 0, -days), now)
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// handleGetTicketCSAT - GET /api/tickets/csat?days=30
+// Liefert Ø Bewertung und CSAT der Zufriedenheits-Umfrage je Bereich und Bearbeiter
+func (api *APIServer) handleGetTicketCSAT(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 365 {
+			http.Error(w, "days muss zwischen 1 und 365 liegen", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	now := time.Now()
+	report, err := api.ticketService.FeedbackReport(now.AddDate(0, 0, -days), now)
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// handleGetTicketStats - GET /api/tickets/stats?from=2006-01-02&to=2006-01-02&area=...
+// Liefert Volumen je Bereich, Median/P90 von Übernahme- und Schließzeit, Bearbeiter sowie Reopen- und User-Left-Quote
+func (api *APIServer) handleGetTicketStats(w http.ResponseWriter, r *http.Request) {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		location = time.Local
+	}
+	query := r.URL.Query()
+	from, to, err := ticketService.ParseStatsRange(query.Get("from"), query.Get("to"), time.Now().In(location))
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+	report, err := api.ticketService.StatsReport(from, to, query.Get("area"))
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// handleGetTicketFeedback - GET /api/tickets/{id}/feedback
+// Liefert Bewertung und Kommentar des Erstellers zu einem Ticket
+func (api *APIServer) handleGetTicketFeedback(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Ungültige ID", http.StatusBadRequest)
+		return
+	}
+	feedback, err := api.ticketService.Feedback(id)
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(feedback)
+}
+
+// handleGetTicketDecision - GET /api/tickets/{id}/decision
+// Liefert Regel, Stimmen und Entscheidung einer Bewerbung
+func (api *APIServer) handleGetTicketDecision(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Ungültige ID", http.StatusBadRequest)
+		return
+	}
+	state, err := api.ticketService.DecisionState(id)
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+// handleSearchTickets - GET /api/tickets/search?q=...&area=...&limit=25
+// Durchsucht Formular-Antworten, Tags, Ersteller und Transkripte ("#<id>" liefert direkt das Ticket)
+func (api *APIServer) handleSearchTickets(w http.ResponseWriter, r *http.Request) {
+	limit := 25
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 25 {
+			http.Error(w, "limit muss zwischen 1 und 25 liegen", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := api.ticketService.Search(r.URL.Query().Get("q"), r.URL.Query().Get("area"), limit)
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// handleGetTicketTranscript - GET /api/tickets/{id}/transcript
+// Liefert das HTML-Transkript, mit ?format=json das JSON-Transkript.
+// Die internen Notizen im HTML-Transkript werden ohne Scope "tickets_staff" entfernt.
+func (api *APIServer) handleGetTicketTranscript(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Ungültige ID", http.StatusBadRequest)
+		return
+	}
+
+	key, err := api.ticketService.TranscriptKey(id)
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+	if key == "" {
+		http.Error(w, "Kein Transkript vorhanden", http.StatusNotFound)
+		return
+	}
+
+	contentType := "text/html; charset=utf-8"
+	if r.URL.Query().Get("format") == "json" {
+		contentType = "application/json"
+	} else {
+		key = transcripts.HTMLKey(key)
+	}
+
+	store, err := storage.Default()
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+	reader, err := store.Get(r.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Kein Transkript vorhanden", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+	defer reader.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	if contentType == "application/json" || requestHasScope(r, "tickets_staff") {
+		io.Copy(w, reader)
+		return
+	}
+	page, err := io.ReadAll(reader)
+	if err != nil {
+		api.writeTicketConfigError(w, err)
+		return
+	}
+	w.Write(transcripts.StripStaffSection(page))
+}
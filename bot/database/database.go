@@ -66,6 +66,365 @@ func createTables() {
 		log.Fatalf("Fehler beim Erstellen der tickets-Tabelle: %v", err)
 	}
 
+	// Verlauf aller Statuswechsel eines Tickets (created_at als Unix-Zeit wie in tickets)
+	ticketEventsTable := `
+		CREATE TABLE IF NOT EXISTS ticket_events (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			ticket_id    INTEGER NOT NULL,
+			action       TEXT NOT NULL,
+			from_status  TEXT,
+			to_status    TEXT NOT NULL,
+			actor_id     TEXT,
+			actor_name   TEXT,
+			details      TEXT,
+			created_at   BIGINT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_ticket_events_ticket ON ticket_events(ticket_id);
+		`
+
+	_, err = DB.Exec(ticketEventsTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_events-Tabelle: %v", err)
+	}
+
+	// SLA-Stand je Ticket: letzte Nachrichten von Ersteller und Team, erreichte Eskalationsstufe,
+	// Inaktivitäts-Warnung, "Offen halten" und letztes Wiedereröffnen (Zeiten als Unix-Zeit)
+	ticketSLATable := `
+		CREATE TABLE IF NOT EXISTS ticket_sla (
+			ticket_id             INTEGER PRIMARY KEY,
+			last_creator_message  BIGINT DEFAULT 0,
+			last_team_message     BIGINT DEFAULT 0,
+			claim_escalation      INTEGER DEFAULT 0,
+			reply_reminded_at     BIGINT DEFAULT 0
+		);
+		`
+
+	_, err = DB.Exec(ticketSLATable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_sla-Tabelle: %v", err)
+	}
+	addColumnIfMissing("ticket_sla", "inactivity_warned_at", "BIGINT DEFAULT 0")
+	addColumnIfMissing("ticket_sla", "inactivity_kept_at", "BIGINT DEFAULT 0")
+	addColumnIfMissing("ticket_sla", "reopened_at", "BIGINT DEFAULT 0")
+
+	// Ausschlüsse vom Ticket-System, area_keys leer = alle Bereiche, expires_at 0 = dauerhaft (Unix-Zeit)
+	ticketBlacklistTable := `
+		CREATE TABLE IF NOT EXISTS ticket_blacklist (
+			id              INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id         TEXT NOT NULL UNIQUE,
+			reason          TEXT NOT NULL,
+			issued_by_id    TEXT NOT NULL,
+			issued_by_name  TEXT,
+			area_keys       TEXT NOT NULL DEFAULT '',
+			expires_at      BIGINT DEFAULT 0,
+			created_at      BIGINT NOT NULL
+		);
+		`
+
+	_, err = DB.Exec(ticketBlacklistTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_blacklist-Tabelle: %v", err)
+	}
+
+	// Interne Notizen des Teams zu einem Ticket, für den Ersteller nicht sichtbar
+	ticketNotesTable := `
+		CREATE TABLE IF NOT EXISTS ticket_notes (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			ticket_id    INTEGER NOT NULL,
+			author_id    TEXT NOT NULL,
+			author_name  TEXT,
+			content      TEXT NOT NULL,
+			created_at   BIGINT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_ticket_notes_ticket ON ticket_notes(ticket_id, created_at);
+		`
+
+	_, err = DB.Exec(ticketNotesTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_notes-Tabelle: %v", err)
+	}
+
+	// Tags eines Tickets (vorgegeben über TICKET_TAGS oder frei vergeben) und Priorität
+	ticketTagsTable := `
+		CREATE TABLE IF NOT EXISTS ticket_tags (
+			ticket_id  INTEGER NOT NULL,
+			tag        TEXT NOT NULL,
+			PRIMARY KEY (ticket_id, tag)
+		);
+		CREATE INDEX IF NOT EXISTS idx_ticket_tags_tag ON ticket_tags(tag);
+		`
+
+	_, err = DB.Exec(ticketTagsTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_tags-Tabelle: %v", err)
+	}
+	addColumnIfMissing("tickets", "ticket_priority", "TEXT DEFAULT 'normal'")
+
+	// Suchindex, mit "-tags sqlite_fts5" als FTS5-Tabelle (siehe search_fts5.go / search_plain.go)
+	_, err = DB.Exec(ticketSearchTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen des Ticket-Suchindex: %v", err)
+	}
+	migrateSearchTable()
+
+	// Zuweisungen mit Begründung (manuell oder automatisch), Rotation je Bereich und Abwesenheiten des Teams
+	ticketAssignmentsTable := `
+		CREATE TABLE IF NOT EXISTS ticket_assignments (
+			id             INTEGER PRIMARY KEY AUTOINCREMENT,
+			ticket_id      INTEGER NOT NULL,
+			assignee_id    TEXT NOT NULL,
+			assignee_name  TEXT,
+			mode           TEXT NOT NULL,
+			reason         TEXT,
+			actor_id       TEXT,
+			created_at     BIGINT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_ticket_assignments_ticket ON ticket_assignments(ticket_id);
+		CREATE TABLE IF NOT EXISTS ticket_assign_rotation (
+			area_key      TEXT PRIMARY KEY,
+			last_user_id  TEXT NOT NULL,
+			updated_at    BIGINT NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS ticket_staff_absence (
+			user_id     TEXT PRIMARY KEY,
+			user_name   TEXT,
+			reason      TEXT,
+			until       BIGINT DEFAULT 0,
+			created_at  BIGINT NOT NULL
+		);
+		`
+
+	_, err = DB.Exec(ticketAssignmentsTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_assignments-Tabellen: %v", err)
+	}
+
+	// Entscheidungen über Bewerbungen: Regel je Bereich (Quorum und Aktionen bei Annahme/Ablehnung),
+	// Stimmen des Teams und das Ergebnis direkt am Ticket
+	ticketDecisionsTable := `
+		CREATE TABLE IF NOT EXISTS ticket_decision_rules (
+			area_key          TEXT PRIMARY KEY,
+			quorum            INTEGER NOT NULL DEFAULT 1,
+			approve_roles     TEXT,
+			team              TEXT,
+			welcome_template  INTEGER,
+			welcome_channel   TEXT,
+			reject_template   INTEGER,
+			updated_at        BIGINT NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS ticket_decision_votes (
+			ticket_id   INTEGER NOT NULL,
+			user_id     TEXT NOT NULL,
+			user_name   TEXT,
+			vote        TEXT NOT NULL,
+			reason      TEXT,
+			created_at  BIGINT NOT NULL,
+			PRIMARY KEY (ticket_id, user_id)
+		);
+		`
+
+	_, err = DB.Exec(ticketDecisionsTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_decision-Tabellen: %v", err)
+	}
+	addColumnIfMissing("tickets", "ticket_decision", "TEXT")
+	addColumnIfMissing("tickets", "ticket_decision_at", "BIGINT")
+	addColumnIfMissing("tickets", "ticket_decision_reason", "TEXT")
+
+	// Zufriedenheits-Umfrage nach dem Schließen: eine Bewertung je Ticket, Bearbeiter und Bereich werden beim
+	// Versand festgehalten. Getrennt von survey_answers ("Woher kennt ihr uns?")
+	ticketFeedbackTable := `
+		CREATE TABLE IF NOT EXISTS ticket_feedback (
+			ticket_id     INTEGER PRIMARY KEY,
+			user_id       TEXT NOT NULL,
+			area_key      TEXT,
+			claimer_id    TEXT,
+			claimer_name  TEXT,
+			rating        INTEGER,
+			comment       TEXT,
+			requested_at  BIGINT NOT NULL,
+			answered_at   BIGINT
+		);
+		CREATE INDEX IF NOT EXISTS idx_ticket_feedback_answered ON ticket_feedback(answered_at);
+		`
+
+	_, err = DB.Exec(ticketFeedbackTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_feedback-Tabelle: %v", err)
+	}
+
+	// Zusätzliche Teilnehmer eines Tickets (z.B. Teamcaptain oder Elternteil), erhalten wie der Ersteller Zugriff
+	ticketParticipantsTable := `
+		CREATE TABLE IF NOT EXISTS ticket_participants (
+			ticket_id      INTEGER NOT NULL,
+			user_id        TEXT NOT NULL,
+			user_name      TEXT,
+			added_by_id    TEXT,
+			added_by_name  TEXT,
+			added_at       BIGINT NOT NULL,
+			PRIMARY KEY (ticket_id, user_id)
+		);
+		`
+
+	_, err = DB.Exec(ticketParticipantsTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_participants-Tabelle: %v", err)
+	}
+
+	// Modmail: per DM an den Bot eröffnete Tickets, Nachrichten werden zwischen DM und Team-Channel weitergeleitet
+	addColumnIfMissing("tickets", "ticket_modmail", "INTEGER DEFAULT 0")
+
+	/*==============================================*/
+	// TICKET AREAS / FIELDS / PANELS TABLES
+	/*==============================================*/
+
+	// Ticket-Bereiche inkl. Verschachtelung (parent_key) für Sub-Dropdowns
+	// support_role und category enthalten eine Discord-ID oder einen const_key aus bot_const_ids
+	ticketAreasTable := `
+		CREATE TABLE IF NOT EXISTS ticket_areas (
+			id            INTEGER PRIMARY KEY AUTOINCREMENT,
+			area_key      TEXT NOT NULL UNIQUE,
+			parent_key    TEXT,
+			label         TEXT NOT NULL,
+			description   TEXT,
+			display_name  TEXT NOT NULL,
+			modal_title   TEXT NOT NULL,
+			sub_prompt    TEXT,
+			support_role  TEXT,
+			mention_user  INTEGER DEFAULT 0,
+			category      TEXT,
+			name_pattern  TEXT NOT NULL DEFAULT '{id}-open-{user}',
+			sort_order    INTEGER DEFAULT 0,
+			enabled       INTEGER DEFAULT 1
+		);
+		`
+
+	_, err = DB.Exec(ticketAreasTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_areas-Tabelle: %v", err)
+	}
+
+	ticketAreaFieldsTable := `
+		CREATE TABLE IF NOT EXISTS ticket_area_fields (
+			id             INTEGER PRIMARY KEY AUTOINCREMENT,
+			area_key       TEXT NOT NULL,
+			position       INTEGER NOT NULL,
+			label          TEXT NOT NULL,
+			style          TEXT NOT NULL DEFAULT 'short',
+			required       INTEGER DEFAULT 1,
+			max_length     INTEGER DEFAULT 0,
+			placeholder    TEXT,
+			min_value      INTEGER,
+			error_message  TEXT,
+			UNIQUE(area_key, position)
+		);
+		`
+
+	_, err = DB.Exec(ticketAreaFieldsTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_area_fields-Tabelle: %v", err)
+	}
+
+	// SLA-Ziele je Bereich (0 = kein Ziel, Unterbereiche übernehmen sonst die Ziele des Oberbereichs)
+	addColumnIfMissing("ticket_areas", "sla_claim_minutes", "INTEGER DEFAULT 0")
+	addColumnIfMissing("ticket_areas", "sla_close_hours", "INTEGER DEFAULT 0")
+	addColumnIfMissing("ticket_areas", "sla_reply_hours", "INTEGER DEFAULT 0")
+
+	// Inaktivität: Warnung nach X Tagen ohne Nachricht des Erstellers, Schließen Y Tage später, optional mit Löschung
+	addColumnIfMissing("ticket_areas", "inactivity_warn_days", "INTEGER DEFAULT 0")
+	addColumnIfMissing("ticket_areas", "inactivity_close_days", "INTEGER DEFAULT 0")
+	addColumnIfMissing("ticket_areas", "inactivity_delete", "INTEGER DEFAULT 0")
+
+	// Maximal gleichzeitig offene Tickets pro User in diesem Bereich (0 = Oberbereich bzw. TICKET_MAX_OPEN_PER_AREA)
+	addColumnIfMissing("ticket_areas", "max_open_tickets", "INTEGER DEFAULT 0")
+
+	// Automatische Zuweisung: Modus (off, round_robin, least_load), Pool per Rolle oder feste User-Liste (kommagetrennt)
+	addColumnIfMissing("ticket_areas", "assign_mode", "TEXT")
+	addColumnIfMissing("ticket_areas", "assign_role", "TEXT")
+	addColumnIfMissing("ticket_areas", "assign_users", "TEXT")
+
+	// Schlüssel für ticket_answers und Eingabeprüfung (number, url, regex)
+	addColumnIfMissing("ticket_area_fields", "field_key", "TEXT")
+	addColumnIfMissing("ticket_area_fields", "validation", "TEXT")
+	addColumnIfMissing("ticket_area_fields", "max_value", "INTEGER")
+	addColumnIfMissing("ticket_area_fields", "pattern", "TEXT")
+
+	// Alle Formular-Antworten eines Tickets, auch über die 5 Spalten in tickets hinaus
+	ticketAnswersTable := `
+		CREATE TABLE IF NOT EXISTS ticket_answers (
+			id         INTEGER PRIMARY KEY AUTOINCREMENT,
+			ticket_id  INTEGER NOT NULL,
+			position   INTEGER NOT NULL,
+			field_key  TEXT NOT NULL,
+			label      TEXT NOT NULL,
+			value      TEXT NOT NULL DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS idx_ticket_answers_ticket ON ticket_answers(ticket_id);
+		`
+
+	_, err = DB.Exec(ticketAnswersTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_answers-Tabelle: %v", err)
+	}
+
+	// Zwischenstände mehrstufiger Formulare (answers als JSON, updated_at als Unix-Zeit)
+	ticketDraftsTable := `
+		CREATE TABLE IF NOT EXISTS ticket_drafts (
+			user_id     TEXT NOT NULL,
+			area_key    TEXT NOT NULL,
+			answers     TEXT NOT NULL,
+			updated_at  INTEGER NOT NULL,
+			PRIMARY KEY (user_id, area_key)
+		);
+		`
+
+	_, err = DB.Exec(ticketDraftsTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_drafts-Tabelle: %v", err)
+	}
+
+	// Panels für /ticket_view, area_keys leer = alle Hauptbereiche
+	ticketPanelsTable := `
+		CREATE TABLE IF NOT EXISTS ticket_panels (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			name         TEXT NOT NULL UNIQUE,
+			title        TEXT NOT NULL,
+			description  TEXT,
+			area_keys    TEXT NOT NULL DEFAULT '',
+			created_at   DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		`
+
+	_, err = DB.Exec(ticketPanelsTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der ticket_panels-Tabelle: %v", err)
+	}
+
+	seedTicketAreas()
+
+	// Textbausteine für Tickets (Markdown oder Embed-JSON), area_key leer = alle Bereiche
+	cannedResponsesTable := `
+		CREATE TABLE IF NOT EXISTS canned_responses (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			title        TEXT NOT NULL UNIQUE,
+			area_key     TEXT,
+			format       TEXT NOT NULL DEFAULT 'markdown',
+			body         TEXT NOT NULL,
+			usage_count  INTEGER DEFAULT 0,
+			last_used_at BIGINT DEFAULT 0,
+			created_by   TEXT,
+			created_at   BIGINT NOT NULL,
+			updated_at   BIGINT NOT NULL
+		);
+		`
+
+	_, err = DB.Exec(cannedResponsesTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der canned_responses-Tabelle: %v", err)
+	}
+
+	seedCannedResponses()
+
 	/*==============================================*/
 	// TEAM AREAS TABLE
 	/*==============================================*/
@@ -251,6 +610,16 @@ func createTables() {
 		log.Fatalf("Fehler beim Erstellen der quizQuestion-Tabelle: %v", err)
 	}
 
+	// Zeitpunkt der letzten Verwendung für die automatische Planung
+	addColumnIfMissing("quiz_questions", "asked_at", "DATETIME")
+
+	// Bis zu fünf Antworten, Erklärung, Bild und Antwortfenster in Minuten
+	addColumnIfMissing("quiz_questions", "answer4", "TEXT")
+	addColumnIfMissing("quiz_questions", "answer5", "TEXT")
+	addColumnIfMissing("quiz_questions", "explanation", "TEXT")
+	addColumnIfMissing("quiz_questions", "image_url", "TEXT")
+	addColumnIfMissing("quiz_questions", "answer_window", "INTEGER")
+
 	quizResponsesTable := `
 		CREATE TABLE IF NOT EXISTS quiz_responses (
 			id           INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -267,6 +636,58 @@ func createTables() {
 		log.Fatalf("Fehler beim Erstellen der quizResponse-Tabelle: %v", err)
 	}
 
+	// Zuordnung der Antwort zum konkreten Post (Deadline, Auflösung, Speed-Bonus)
+	addColumnIfMissing("quiz_responses", "post_id", "INTEGER")
+
+	quizPostsTable := `
+		CREATE TABLE IF NOT EXISTS quiz_posts (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			question_id  INTEGER NOT NULL REFERENCES quiz_questions(id),
+			channel_id   TEXT NOT NULL,
+			message_id   TEXT NOT NULL,
+			posted_at    BIGINT NOT NULL,
+			deadline     BIGINT NOT NULL,
+			revealed     INTEGER DEFAULT 0
+		);
+		`
+
+	_, err = DB.Exec(quizPostsTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der quiz_posts-Tabelle: %v", err)
+	}
+
+	quizSeasonsTable := `
+		CREATE TABLE IF NOT EXISTS quiz_seasons (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			name         TEXT NOT NULL,
+			start_date   TEXT NOT NULL,
+			end_date     TEXT NOT NULL,
+			finalized    INTEGER DEFAULT 0,
+			created_at   DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		`
+
+	_, err = DB.Exec(quizSeasonsTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der quiz_seasons-Tabelle: %v", err)
+	}
+
+	quizSeasonChampionsTable := `
+		CREATE TABLE IF NOT EXISTS quiz_season_champions (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			season_id    INTEGER NOT NULL REFERENCES quiz_seasons(id),
+			discord_id   TEXT NOT NULL,
+			rank         INTEGER NOT NULL,
+			score        REAL NOT NULL,
+			UNIQUE(season_id, discord_id)
+		);
+		`
+
+	_, err = DB.Exec(quizSeasonChampionsTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der quiz_season_champions-Tabelle: %v", err)
+	}
+
 	/*==============================================*/
 	// SURVEY TABLES
 	/*==============================================*/
@@ -370,9 +791,58 @@ func createTables() {
 		log.Fatalf("Fehler beim Erstellen des valo_event Index: %v", err)
 	}
 
+	/*==============================================*/
+	// API KEYS TABLE
+	/*==============================================*/
+
+	apiKeysTable := `
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id            INTEGER PRIMARY KEY AUTOINCREMENT,
+			name          TEXT NOT NULL,
+			key_hash      TEXT NOT NULL UNIQUE,
+			scopes        TEXT NOT NULL DEFAULT '*',
+			is_active     BOOLEAN DEFAULT true,
+			created_at    DATETIME DEFAULT CURRENT_TIMESTAMP,
+			last_used_at  DATETIME
+		);
+		`
+
+	_, err = DB.Exec(apiKeysTable)
+	if err != nil {
+		log.Fatalf("Fehler beim Erstellen der api_keys-Tabelle: %v", err)
+	}
+
 	/*==============================================*/
 	// END OF TABLE CREATION
 	/*==============================================*/
 
 	log.Println("Tabellen erfolgreich erstellt oder bereits vorhanden.")
 }
+
+// addColumnIfMissing ergänzt eine Spalte in bestehenden Datenbanken,
+// da CREATE TABLE IF NOT EXISTS neue Spalten nicht nachträglich anlegt
+func addColumnIfMissing(table, column, definition string) {
+	rows, err := DB.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		log.Fatalf("Fehler beim Lesen der Spalten von %s: %v", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue interface{}
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			log.Fatalf("Fehler beim Lesen der Spalten von %s: %v", table, err)
+		}
+		if name == column {
+			return
+		}
+	}
+	rows.Close()
+
+	_, err = DB.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+	if err != nil {
+		log.Fatalf("Fehler beim Hinzufügen der Spalte %s.%s: %v", table, column, err)
+	}
+}
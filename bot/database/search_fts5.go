@@ -0,0 +1,21 @@
+//go:build sqlite_fts5
+
+package database
+
+// SearchFTS5 ist gesetzt, wenn der Bot mit "-tags sqlite_fts5" gebaut wurde (go-sqlite3 enthält FTS5 nur mit diesem Tag)
+const SearchFTS5 = true
+
+// Volltextindex über Formular-Antworten, Tags und Transkripte, rowid = ticket_id
+const ticketSearchTable = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS ticket_search_fts USING fts5(
+		area UNINDEXED,
+		creator,
+		tags,
+		answers,
+		transcript,
+		tokenize = 'unicode61 remove_diacritics 2'
+	);
+	`
+
+// migrateSearchTable ist für FTS5 leer, die Groß-/Kleinschreibung behandelt der Tokenizer
+func migrateSearchTable() {}
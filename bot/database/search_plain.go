@@ -0,0 +1,25 @@
+//go:build !sqlite_fts5
+
+package database
+
+// SearchFTS5 ist gesetzt, wenn der Bot mit "-tags sqlite_fts5" gebaut wurde (go-sqlite3 enthält FTS5 nur mit diesem Tag)
+const SearchFTS5 = false
+
+// Ohne FTS5 wird derselbe Inhalt in einer normalen Tabelle per LIKE durchsucht.
+// content enthält den Text in Go kleingeschrieben, da LOWER() in SQLite nur ASCII umwandelt (Umlaute).
+const ticketSearchTable = `
+	CREATE TABLE IF NOT EXISTS ticket_search_plain (
+		ticket_id   INTEGER PRIMARY KEY,
+		area        TEXT,
+		creator     TEXT,
+		tags        TEXT,
+		answers     TEXT,
+		transcript  TEXT,
+		content     TEXT
+	);
+	`
+
+// migrateSearchTable ergänzt content in bestehenden Datenbanken, RebuildSearchIndex füllt die Spalte nach
+func migrateSearchTable() {
+	addColumnIfMissing("ticket_search_plain", "content", "TEXT")
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"log"
+	"time"
+)
+
+// Gemeinsamer Teil der bisherigen Antworten auf Pro-Team-Bewerbungen
+const cannedDiamondClubPitch = "Ich bin überzeugt, dass du super zu unserer Community passen würdest.\n\n" +
+	"Du findest im Diamond Club zahlreiche neue Mitspieler, hast die Möglichkeit, " +
+	"einem festen Team beizutreten und in Ligen und Turnieren zu spielen, " +
+	"erhältst dazu noch Rabatte auf Bootcamps und Produkte unserer Partner und " +
+	"kannst bei unseren online und offline Community-Events mitmachen.\n\n" +
+	"Das Ganze natürlich völlig kostenfrei. Wie klingt das für dich? 🙂"
+
+// Bisher fest in /ticket_response hinterlegte Varianten
+var defaultCannedResponses = []struct {
+	title, areaKey, body string
+}{
+	{"Pro-Team nicht möglich", "ticket_pro_teams",
+		"Hi {creator},\n\nvielen Dank für deine Bewerbung!\n\n" +
+			"Ich kann dir zwar derzeit keinen Platz in unserem Pro-Team anbieten, " +
+			"ich würde dir aber sehr gerne in einem kurzen Gespräch den Entropy Diamond Club vorstellen.\n\n" +
+			cannedDiamondClubPitch},
+	{"Nicht für Pro beworben", "ticket_pro_teams",
+		"Hi {creator},\n\nvielen Dank für deine Bewerbung!\n\n" +
+			"Ich würde dir sehr gerne in einem kurzen Gespräch den Entropy Diamond Club vorstellen.\n\n" +
+			cannedDiamondClubPitch},
+}
+
+// seedCannedResponses übernimmt die bisherigen Standardantworten einmalig in die Datenbank
+func seedCannedResponses() {
+	var count int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM canned_responses`).Scan(&count); err != nil {
+		log.Fatalf("Fehler beim Prüfen der canned_responses-Tabelle: %v", err)
+	}
+	if count > 0 {
+		return
+	}
+
+	now := time.Now().Unix()
+	for _, response := range defaultCannedResponses {
+		_, err := DB.Exec(`INSERT INTO canned_responses (title, area_key, format, body, created_at, updated_at) VALUES (?, ?, 'markdown', ?, ?, ?)`,
+			response.title, response.areaKey, response.body, now, now)
+		if err != nil {
+			log.Fatalf("Fehler beim Anlegen der Standardantwort %s: %v", response.title, err)
+		}
+	}
+	log.Println("Standardantworten für Tickets angelegt.")
+}
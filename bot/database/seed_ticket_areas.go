@@ -0,0 +1,133 @@
+package database
+
+import (
+	"log"
+	"strings"
+)
+
+type seedField struct {
+	label        string
+	paragraph    bool
+	required     bool
+	maxLength    int
+	validation   string
+	minValue     int
+	maxValue     int
+	errorMessage string
+}
+
+// Häufige Felder mit Eingabeprüfung
+var seedAgeField = seedField{label: "Alter", validation: "number", minValue: 10, maxValue: 99}
+
+func seedLinkField(label string) seedField {
+	return seedField{label: label, required: true, validation: "url"}
+}
+
+type seedArea struct {
+	key, parent, label, displayName, modalTitle, subPrompt, role string
+	mentionUser                                                  bool
+	fields                                                       []seedField
+}
+
+// Bisherige, fest im Code hinterlegte Ticket-Bereiche
+var defaultTicketAreas = []seedArea{
+	{key: "ticket_diamond_club", label: "Beitritt Diamond Club", displayName: "Diamond Club Bewerbung", modalTitle: "Bewerbung Diamond Club", role: "ROLE_TICKET_DIAMOND_CLUB",
+		fields: []seedField{{label: "Vorname", required: true}, {label: "Alter", required: true, validation: "number", minValue: 10, maxValue: 99}, {label: "Dein Main Game", required: true}, {label: "Gib uns kurz an wann du Zeit hast", paragraph: true, required: true, maxLength: 400}}},
+	{key: "ticket_community_teams", label: "Bewerbung Competetive Teams", displayName: "Competetive Teams", modalTitle: "Competetive Teams", subPrompt: "Wähle das Spiel aus, für das du dich bewerben möchtest:"},
+	{key: "ticket_bewerbung_staff", label: "Bewerbung Management", displayName: "Bewerbung Staff", modalTitle: "Bewerbung Staff", role: "ROLE_TICKET_STAFFAPPLICATION",
+		fields: []seedField{{label: "Vorname", required: true}, seedAgeField, {label: "Für was bewirbst du dich?", required: true}, {label: "Erfahrungen in dem Bereich?", paragraph: true, required: true, maxLength: 400}, {label: "Stelle dich kurz vor", paragraph: true, required: true, maxLength: 400}}},
+	{key: "ticket_content_creator", label: "Bewerbung Content Creator", displayName: "Content Creator", modalTitle: "Bewerbung Content Creator", role: "ROLE_TICKET_CONTENT_CREATOR",
+		fields: []seedField{{label: "Vorname", required: true}, seedAgeField, {label: "Social Links", paragraph: true, required: true, maxLength: 400}, {label: "Weiteres", paragraph: true, maxLength: 400}}},
+	{key: "ticket_pro_teams", label: "Bewerbung Pro Teams", displayName: "Pro Team Bewerbung", modalTitle: "Bewerbung für ein Pro Team", role: "ROLE_TICKET_PROTEAMS", mentionUser: true,
+		fields: []seedField{{label: "Vorname", required: true}, {label: "Alter (Zahl)", required: true, validation: "number", minValue: 16, maxValue: 99, errorMessage: "Du bist leider zu jung für ein Pro Team. Bitte öffne stattdessen ein 'Competitive Teams' Ticket."}, {label: "Welches Spiel?", required: true}, {label: "Erfahrungen im Team?", paragraph: true, required: true, maxLength: 400}, {label: "Tracker & Social Media", paragraph: true, required: true, maxLength: 400}}},
+	{key: "ticket_support_kontakt", label: "Support/Kontakt", displayName: "Kontakt/Support", modalTitle: "Support Anfrage", role: "ROLE_TICKET_SUPPORT_CONTACT",
+		fields: []seedField{{label: "Vorname", required: true}, {label: "Was ist dein Anliegen?", paragraph: true, required: true, maxLength: 750}}},
+	{key: "ticket_sonstiges", label: "Sonstiges", displayName: "Sonstiges", modalTitle: "Sonstige Anfragen", role: "ROLE_TICKET_SONSTIGE",
+		fields: []seedField{{label: "Vorname", required: true}, {label: "Was ist dein Anliegen?", paragraph: true, required: true, maxLength: 750}}},
+
+	{key: "ticket_game_lol", parent: "ticket_community_teams", label: "League of Legends", displayName: "League of Legends", modalTitle: "League of Legends Bewerbung", role: "ROLE_TICKET_GAME_LOL",
+		fields: []seedField{{label: "Vorname", required: true}, seedAgeField, {label: "Main Rolle", required: true}, {label: "Rang", required: true}, seedLinkField("op.gg Link")}},
+	{key: "ticket_game_r6", parent: "ticket_community_teams", label: "RainbowSix", displayName: "Rainbow Six", modalTitle: "RainbowSix Bewerbung", role: "ROLE_TICKET_GAME_R6",
+		fields: []seedField{{label: "Vorname", required: true}, seedAgeField, seedLinkField("R6 Tracker Link"), {label: "Plattform", required: true}, {label: "Infos über DICH!", paragraph: true, required: true, maxLength: 600}}},
+	{key: "ticket_game_cs2", parent: "ticket_community_teams", label: "CS2", displayName: "Counter Strike 2", modalTitle: "CS2 Bewerbung", role: "ROLE_TICKET_GAME_CS2",
+		fields: []seedField{{label: "Vorname", required: true}, seedAgeField, seedLinkField("Steam Profile Link"), {label: "Rang", required: true}, {label: "Infos über DICH!", paragraph: true, required: true, maxLength: 600}}},
+	{key: "ticket_game_valorant", parent: "ticket_community_teams", label: "Valorant", displayName: "Valorant", modalTitle: "Valorant Bewerbung", role: "ROLE_TICKET_GAME_VALORANT",
+		fields: []seedField{{label: "Vorname", required: true}, seedAgeField, {label: "InGame Name", required: true}, seedLinkField("Tracker Link")}},
+	{key: "ticket_game_rocket_league", parent: "ticket_community_teams", label: "Rocket League", displayName: "Rocket League", modalTitle: "Rocket League Bewerbung", role: "ROLE_TICKET_GAME_ROCKETLEAGUE",
+		fields: []seedField{{label: "Vorname", required: true}, seedAgeField, {label: "InGame Name", required: true}, seedLinkField("RL Tracker Network Link"), {label: "Wunsch Elo", required: true}}},
+	{key: "ticket_game_sonstige", parent: "ticket_community_teams", label: "Sonstige", displayName: "Spiel Sonstige", modalTitle: "Sonstige Bewerbungen", role: "ROLE_TICKET_GAME_SONSTIGE",
+		fields: []seedField{{label: "Vorname", required: true}, seedAgeField, {label: "Bitte erkläre kurz für was du dich bewirbst", paragraph: true, required: true, maxLength: 400}}},
+}
+
+// seedTicketAreas übernimmt die bisherigen Ticket-Bereiche einmalig in die Datenbank
+func seedTicketAreas() {
+	var count int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM ticket_areas`).Scan(&count); err != nil {
+		log.Fatalf("Fehler beim Prüfen der ticket_areas-Tabelle: %v", err)
+	}
+	if count > 0 {
+		return
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		log.Fatalf("Fehler beim Anlegen der Standard-Ticket-Bereiche: %v", err)
+	}
+	defer tx.Rollback()
+
+	for i, area := range defaultTicketAreas {
+		var parent, subPrompt, role, category interface{}
+		if area.parent != "" {
+			parent = area.parent
+		}
+		if area.subPrompt != "" {
+			subPrompt = area.subPrompt
+		}
+		if area.role != "" {
+			role = area.role
+		}
+		// Bereiche mit Formular bekamen ihre Kategorie bisher über CATEGORY_<KEY>
+		if len(area.fields) > 0 {
+			category = "CATEGORY_" + strings.ToUpper(area.key)
+		}
+		_, err := tx.Exec(`
+			INSERT INTO ticket_areas (area_key, parent_key, label, display_name, modal_title, sub_prompt, support_role, mention_user, category, sort_order)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			area.key, parent, area.label, area.displayName, area.modalTitle, subPrompt, role, area.mentionUser, category, i)
+		if err != nil {
+			log.Fatalf("Fehler beim Anlegen des Ticket-Bereichs %s: %v", area.key, err)
+		}
+
+		for pos, field := range area.fields {
+			style := "short"
+			if field.paragraph {
+				style = "paragraph"
+			}
+			var validation, minValue, maxValue, errorMessage interface{}
+			if field.validation != "" {
+				validation = field.validation
+			}
+			if field.minValue != 0 {
+				minValue = field.minValue
+			}
+			if field.maxValue != 0 {
+				maxValue = field.maxValue
+			}
+			if field.errorMessage != "" {
+				errorMessage = field.errorMessage
+			}
+			_, err := tx.Exec(`
+				INSERT INTO ticket_area_fields (area_key, position, label, style, required, max_length, validation, min_value, max_value, error_message)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				area.key, pos+1, field.label, style, field.required, field.maxLength, validation, minValue, maxValue, errorMessage)
+			if err != nil {
+				log.Fatalf("Fehler beim Anlegen der Felder für %s: %v", area.key, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Fatalf("Fehler beim Anlegen der Standard-Ticket-Bereiche: %v", err)
+	}
+	log.Println("Standard-Ticket-Bereiche angelegt.")
+}
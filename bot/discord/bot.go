@@ -6,6 +6,7 @@ import (
 	"bot/handlers/discord_administration/channel/text"
 	"bot/handlers/discord_administration/channel/voice"
 	"bot/handlers/quiz"
+	"bot/handlers/tickets"
 	"bot/handlers/tracking"
 	"bot/handlers/weekly_updates"
 	"bot/utils"
@@ -62,6 +63,10 @@ func StartBot() error {
 	bot.AddHandler(leaveTracker.OnGuildMemberRemove)
 	bot.AddHandler(voiceTracker.OnVoiceStateUpdate)
 	bot.AddHandler(msgTracker.OnMessageCreate)
+	bot.AddHandler(tickets.OnTicketMessage)
+	bot.AddHandler(tickets.OnModmailMessage)
+	bot.AddHandler(tickets.OnGuildMemberRemove)
+	bot.AddHandler(tickets.OnGuildMemberAdd)
 
 	// team member sync
 	discord_administration_utils.SetupRoleChangeHandler(bot)
@@ -79,6 +84,18 @@ func StartBot() error {
 	// Register Quiz Handler
 	quiz.RegisterQuiz(bot)
 
+	// Transkript-Aufbewahrung (löscht alte Transkripte und verwaiste Anhänge)
+	tickets.StartTranscriptRetention(bot)
+
+	// Ticket-SLA (Eskalation unbearbeiteter Tickets und Erinnerungen an Bearbeiter)
+	tickets.StartTicketSLA(bot)
+
+	// Ticket-Inaktivität (Warnung und automatisches Schließen)
+	tickets.StartTicketInactivity(bot)
+
+	// Ticket-Suchindex (einmaliger Aufbau, danach bei Erstellung, Tags und Transkript aktualisiert)
+	tickets.BuildTicketSearchIndex(bot)
+
 	// Weekly Updates Handler
 	weekleyUpdateManager := weekly_updates.InitializeWeeklyUpdates(database.DB, bot)
 
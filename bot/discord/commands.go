@@ -2,6 +2,7 @@ package discord
 
 import (
 	"bot/handlers/surveys"
+	"bot/handlers/tickets"
 	"bot/utils"
 	"log"
 
@@ -30,8 +31,183 @@ func RegisterCommands(bot *discordgo.Session) {
 
 		// ticket_view Command (sends the ticket view with 'Create Ticket' button)
 		{
-			Name:                     "ticket_view",
-			Description:              "Sendet das Ticket-View mit 'Create Ticket'-Button.",
+			Name:        "ticket_view",
+			Description: "Sendet das Ticket-View mit 'Create Ticket'-Button.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionInteger, Name: "panel", Description: "Panel mit eigener Auswahl an Bereichen (optional)", Required: false, Autocomplete: true},
+			},
+			DefaultMemberPermissions: &adminPermission,
+		},
+
+		/*----------------------------------------------------------*/
+
+		// ticket_admin Command (manages ticket areas, form fields and panels)
+		{
+			Name:        "ticket_admin",
+			Description: "Verwaltet Ticket-Bereiche, Formulare und Panels",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+					Name:        "area",
+					Description: "Ticket-Bereiche verwalten",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "add",
+							Description: "Neuen Ticket-Bereich anlegen",
+							Options:     ticketAreaOptions(true),
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "edit",
+							Description: "Ticket-Bereich bearbeiten",
+							Options:     ticketAreaOptions(false),
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "delete",
+							Description: "Ticket-Bereich löschen",
+							Options: []*discordgo.ApplicationCommandOption{
+								{Type: discordgo.ApplicationCommandOptionString, Name: "key", Description: "Schlüssel des Bereichs", Required: true, Autocomplete: true},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "show",
+							Description: "Ticket-Bereich inkl. Formular anzeigen",
+							Options: []*discordgo.ApplicationCommandOption{
+								{Type: discordgo.ApplicationCommandOptionString, Name: "key", Description: "Schlüssel des Bereichs", Required: true, Autocomplete: true},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "list",
+							Description: "Alle Ticket-Bereiche auflisten",
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+					Name:        "field",
+					Description: "Formularfelder verwalten",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "add",
+							Description: "Formularfeld hinzufügen",
+							Options: []*discordgo.ApplicationCommandOption{
+								{Type: discordgo.ApplicationCommandOptionString, Name: "key", Description: "Schlüssel des Bereichs", Required: true, Autocomplete: true},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "label", Description: "Beschriftung", Required: true, MaxLength: 45},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "style", Description: "Eingabeart", Required: false, Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{Name: "Einzeilig", Value: "short"},
+									{Name: "Mehrzeilig", Value: "paragraph"},
+								}},
+								{Type: discordgo.ApplicationCommandOptionBoolean, Name: "required", Description: "Pflichtfeld (Standard ja)", Required: false},
+								{Type: discordgo.ApplicationCommandOptionInteger, Name: "max_length", Description: "Maximale Länge", Required: false, MinValue: &ticketMinFieldLength, MaxValue: 4000},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "placeholder", Description: "Platzhalter", Required: false, MaxLength: 100},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "validation", Description: "Eingabeprüfung", Required: false, Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{Name: "Keine", Value: "none"},
+									{Name: "Zahl", Value: "number"},
+									{Name: "Link (http/https)", Value: "url"},
+									{Name: "Regulärer Ausdruck", Value: "regex"},
+								}},
+								{Type: discordgo.ApplicationCommandOptionInteger, Name: "min_value", Description: "Mindestwert für Zahlen (z.B. Alter)", Required: false},
+								{Type: discordgo.ApplicationCommandOptionInteger, Name: "max_value", Description: "Höchstwert für Zahlen", Required: false},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "pattern", Description: "Regulärer Ausdruck für die Prüfung regex", Required: false},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "error_message", Description: "Meldung bei ungültiger Eingabe", Required: false},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "field_key", Description: "Schlüssel der Antwort (Standard: aus dem Label)", Required: false, MaxLength: 40},
+								{Type: discordgo.ApplicationCommandOptionInteger, Name: "position", Description: "Position (Standard: am Ende)", Required: false, MinValue: &ticketMinFieldLength, MaxValue: 25},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "remove",
+							Description: "Formularfeld entfernen",
+							Options: []*discordgo.ApplicationCommandOption{
+								{Type: discordgo.ApplicationCommandOptionString, Name: "key", Description: "Schlüssel des Bereichs", Required: true, Autocomplete: true},
+								{Type: discordgo.ApplicationCommandOptionInteger, Name: "position", Description: "Position des Feldes", Required: true, MinValue: &ticketMinFieldLength, MaxValue: 25},
+							},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+					Name:        "panel",
+					Description: "Ticket-Panels verwalten",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "create",
+							Description: "Neues Panel anlegen",
+							Options: []*discordgo.ApplicationCommandOption{
+								{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Interner Name", Required: true, MaxLength: 50},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "title", Description: "Titel des Embeds", Required: true, MaxLength: 256},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "description", Description: "Beschreibung des Embeds", Required: false, MaxLength: 2000},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "areas", Description: "Kommagetrennte Bereichs-Schlüssel (leer = alle Hauptbereiche)", Required: false},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "edit",
+							Description: "Panel bearbeiten",
+							Options: []*discordgo.ApplicationCommandOption{
+								{Type: discordgo.ApplicationCommandOptionInteger, Name: "panel", Description: "Panel", Required: true, Autocomplete: true},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "title", Description: "Titel des Embeds", Required: false, MaxLength: 256},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "description", Description: "Beschreibung des Embeds ('-' entfernt sie)", Required: false, MaxLength: 2000},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "areas", Description: "Kommagetrennte Bereichs-Schlüssel ('-' = alle Hauptbereiche)", Required: false},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "delete",
+							Description: "Panel löschen",
+							Options: []*discordgo.ApplicationCommandOption{
+								{Type: discordgo.ApplicationCommandOptionInteger, Name: "panel", Description: "Panel", Required: true, Autocomplete: true},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "list",
+							Description: "Alle Panels auflisten",
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+					Name:        "decision",
+					Description: "Annehmen/Ablehnen-Abstimmung für Bewerbungsbereiche",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "set",
+							Description: "Regel für einen Bereich anlegen oder ändern",
+							Options: []*discordgo.ApplicationCommandOption{
+								{Type: discordgo.ApplicationCommandOptionString, Name: "key", Description: "Schlüssel des Bereichs", Required: true, Autocomplete: true},
+								{Type: discordgo.ApplicationCommandOptionInteger, Name: "quorum", Description: "Benötigte gleiche Stimmen (Standard 1)", Required: false, MinValue: &ticketDecisionMinQuorum, MaxValue: 10},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "roles", Description: "Rollen bei Annahme (Erwähnungen, IDs oder const_keys, '-' entfernt sie)", Required: false},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "team", Description: "Team bei Annahme", Required: false, Autocomplete: true},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "welcome_template", Description: "Textbaustein für die Willkommens-DM", Required: false, Autocomplete: true},
+								{Type: discordgo.ApplicationCommandOptionString, Name: "reject_template", Description: "Textbaustein für die Ablehnungs-DM ({reason} = Grund)", Required: false, Autocomplete: true},
+								{Type: discordgo.ApplicationCommandOptionChannel, Name: "welcome_channel", Description: "Channel für den Willkommens-Post", Required: false, ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText}},
+								{Type: discordgo.ApplicationCommandOptionBoolean, Name: "clear_welcome_channel", Description: "Willkommens-Post abschalten", Required: false},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "remove",
+							Description: "Regel eines Bereichs löschen",
+							Options: []*discordgo.ApplicationCommandOption{
+								{Type: discordgo.ApplicationCommandOptionString, Name: "key", Description: "Schlüssel des Bereichs", Required: true, Autocomplete: true},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "list",
+							Description: "Alle Regeln auflisten",
+						},
+					},
+				},
+			},
 			DefaultMemberPermissions: &adminPermission,
 		},
 
@@ -46,20 +222,77 @@ func RegisterCommands(bot *discordgo.Session) {
 
 		/*----------------------------------------------------------*/
 
-		// ticket_response Command (sends a standard response for applications)
+		// ticket_response Command (sends a canned response into the current ticket)
 		{
 			Name:        "ticket_response",
-			Description: "Gibt Standardantwort für Bewerbungen aus",
+			Description: "Sendet einen Textbaustein ins aktuelle Ticket",
 			Options: []*discordgo.ApplicationCommandOption{
-				{Type: discordgo.ApplicationCommandOptionString, Name: "variant", Description: "Antwort-Variante",
-					Required: true,
-					Choices: []*discordgo.ApplicationCommandOptionChoice{
-						{Name: "Pro-Team nicht möglich", Value: "pro_not_eligible"},
-						{Name: "Nicht für Pro beworben", Value: "not_applied_pro"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "response", Description: "Textbaustein", Required: true, Autocomplete: true},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "preview", Description: "Nur für mich anzeigen", Required: false},
+			},
+			DefaultMemberPermissions: nil,
+		},
+
+		/*----------------------------------------------------------*/
+
+		// reply Command (answers the creator of a modmail ticket via DM)
+		{
+			Name:        "reply",
+			Description: "Antwortet dem Ersteller eines Modmail-Tickets per DM",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "message", Description: "Antwort an den Ersteller", Required: true, MaxLength: 4000},
+				{Type: discordgo.ApplicationCommandOptionAttachment, Name: "attachment", Description: "Anhang", Required: false},
+			},
+			DefaultMemberPermissions: nil,
+		},
+
+		/*----------------------------------------------------------*/
+
+		// canned Command (manages the canned responses used by ticket_response)
+		{
+			Name:                     "canned",
+			Description:              "Verwaltet die Textbausteine für Tickets",
+			DefaultMemberPermissions: &adminPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "add",
+					Description: "Neuen Textbaustein anlegen",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionString, Name: "area", Description: "Nur für diesen Bereich und seine Unterbereiche (Standard: alle)", Required: false, Autocomplete: true},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "format", Description: "Format des Texts", Required: false,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Markdown", Value: "markdown"},
+								{Name: "Embed (JSON)", Value: "embed"},
+							},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "edit",
+					Description: "Textbaustein bearbeiten",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionString, Name: "response", Description: "Textbaustein", Required: true, Autocomplete: true},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "remove",
+					Description: "Textbaustein löschen",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionString, Name: "response", Description: "Textbaustein", Required: true, Autocomplete: true},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "Textbausteine mit Nutzung anzeigen",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionString, Name: "area", Description: "Nur für diesen Bereich verfügbare", Required: false, Autocomplete: true},
 					},
 				},
 			},
-			DefaultMemberPermissions: nil,
 		},
 
 		/*----------------------------------------------------------*/
@@ -136,13 +369,126 @@ func RegisterCommands(bot *discordgo.Session) {
 
 		// quiz_leaderboard Command (shows the top 25 quiz players)
 		{
-			Name:                     "quiz_leaderboard",
-			Description:              "Zeigt die besten 25 Quiz-Spieler an",
+			Name:        "quiz_leaderboard",
+			Description: "Zeigt die besten 25 Quiz-Spieler an",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "speed", Description: "Speed-Bonus für schnelle richtige Antworten einrechnen", Required: false},
+				{Type: discordgo.ApplicationCommandOptionInteger, Name: "season", Description: "Leaderboard einer Saison anzeigen", Required: false, Autocomplete: true},
+			},
+			DefaultMemberPermissions: nil,
+		},
+
+		/*----------------------------------------------------------*/
+
+		// quiz_stats Command (shows the personal quiz card)
+		{
+			Name:        "quiz_stats",
+			Description: "Zeigt deine Quiz-Statistiken und Serien an",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionUser, Name: "user", Description: "Statistiken eines anderen Users anzeigen", Required: false},
+			},
 			DefaultMemberPermissions: nil,
 		},
 
 		/*----------------------------------------------------------*/
 
+		// quiz_admin Command (manages the quiz question bank)
+		{
+			Name:        "quiz_admin",
+			Description: "Verwaltet die Quiz-Fragen",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "add",
+					Description: "Neue Quiz-Frage anlegen",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionString, Name: "question", Description: "Fragetext", Required: true, MaxLength: 1000},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "answer1", Description: "Antwort 1", Required: true, MaxLength: 100},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "answer2", Description: "Antwort 2", Required: true, MaxLength: 100},
+						{Type: discordgo.ApplicationCommandOptionInteger, Name: "correct", Description: "Richtige Antwort", Required: true, Choices: quizCorrectChoices()},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "answer3", Description: "Antwort 3 (optional)", Required: false, MaxLength: 100},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "answer4", Description: "Antwort 4 (optional)", Required: false, MaxLength: 100},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "answer5", Description: "Antwort 5 (optional)", Required: false, MaxLength: 100},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "date", Description: "Datum (YYYY-MM-DD, optional)", Required: false},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "category", Description: "Kategorie (optional)", Required: false},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "explanation", Description: "Erklärung zur Auflösung (optional)", Required: false, MaxLength: 1024},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "image_url", Description: "Bild-URL (optional)", Required: false},
+						{Type: discordgo.ApplicationCommandOptionInteger, Name: "window", Description: "Antwortzeit in Minuten (optional)", Required: false, MinValue: &quizMinWindow, MaxValue: 1440},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "edit",
+					Description: "Quiz-Frage bearbeiten",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionInteger, Name: "id", Description: "ID der Frage", Required: true},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "question", Description: "Fragetext", Required: false, MaxLength: 1000},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "answer1", Description: "Antwort 1", Required: false, MaxLength: 100},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "answer2", Description: "Antwort 2", Required: false, MaxLength: 100},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "answer3", Description: "Antwort 3 ('-' entfernt die Antwort)", Required: false, MaxLength: 100},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "answer4", Description: "Antwort 4 ('-' entfernt die Antwort)", Required: false, MaxLength: 100},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "answer5", Description: "Antwort 5 ('-' entfernt die Antwort)", Required: false, MaxLength: 100},
+						{Type: discordgo.ApplicationCommandOptionInteger, Name: "correct", Description: "Richtige Antwort (Nummer nach dem Entfernen von Antworten)", Required: false, Choices: quizCorrectChoices()},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "date", Description: "Datum (YYYY-MM-DD, '-' entfernt das Datum)", Required: false},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "category", Description: "Kategorie ('-' entfernt die Kategorie)", Required: false},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "explanation", Description: "Erklärung ('-' entfernt die Erklärung)", Required: false, MaxLength: 1024},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "image_url", Description: "Bild-URL ('-' entfernt das Bild)", Required: false},
+						{Type: discordgo.ApplicationCommandOptionInteger, Name: "window", Description: "Antwortzeit in Minuten (0 = Standard)", Required: false, MinValue: &quizMinEditWindow, MaxValue: 1440},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "delete",
+					Description: "Quiz-Frage löschen",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionInteger, Name: "id", Description: "ID der Frage", Required: true},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "Quiz-Fragen auflisten",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionInteger, Name: "page", Description: "Seite", Required: false},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "import",
+					Description: "Quiz-Fragen aus CSV oder JSON importieren",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionAttachment, Name: "file", Description: "CSV- oder JSON-Datei", Required: true},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "preview",
+					Description: "Zeigt den Quiz-Plan der nächsten Tage",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionInteger, Name: "days", Description: "Anzahl Tage (Standard 14)", Required: false, MinValue: &quizPreviewMinDays, MaxValue: 60},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "season_create",
+					Description: "Eigene Quiz-Saison anlegen",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Name der Saison", Required: true, MaxLength: 80},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "start", Description: "Startdatum (YYYY-MM-DD)", Required: true},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "end", Description: "Enddatum (YYYY-MM-DD, inklusive)", Required: true},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "season_list",
+					Description: "Quiz-Saisons auflisten",
+				},
+			},
+			DefaultMemberPermissions: &adminPermission,
+		},
+
+		/*----------------------------------------------------------*/
+
 		// send_survey Command (sends a survey to all members with a specific role)
 		{
 			Name:        "send_survey",
@@ -204,6 +550,195 @@ func RegisterCommands(bot *discordgo.Session) {
 
 		/*----------------------------------------------------------*/
 
+		// ticket_repair Command (re-links renamed ticket channels and lists tickets without channel)
+		{
+			Name:                     "ticket_repair",
+			Description:              "Verknüpft Ticket-Channels neu und meldet Tickets ohne Channel",
+			DefaultMemberPermissions: &adminPermission,
+		},
+
+		/*----------------------------------------------------------*/
+
+		// ticket Command (notes, tags and moving inside a ticket channel, search, absences, application funnel and CSAT, staff only)
+		{
+			Name:                     "ticket",
+			Description:              "Notizen, Tags, Teilnehmer, Suche, Abwesenheit und Auswertungen im Ticket-System",
+			DefaultMemberPermissions: &adminPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "note",
+					Description: "Interne Notiz speichern (nur für das Team sichtbar)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionString, Name: "text", Description: "Notiz", Required: true, MaxLength: 2000},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "tag",
+					Description: "Tags und Priorität des Tickets ändern",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionString, Name: "add", Description: "Tag hinzufügen (vorgegeben oder frei)", Required: false, Autocomplete: true, MaxLength: 32},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "remove", Description: "Tag entfernen", Required: false, Autocomplete: true},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "priority", Description: "Priorität", Required: false,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Niedrig", Value: "low"},
+								{Name: "Normal", Value: "normal"},
+								{Name: "Hoch", Value: "high"},
+								{Name: "Dringend", Value: "urgent"},
+							},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "move",
+					Description: "Ticket in einen anderen Bereich verschieben (Rolle, Kategorie und Name wechseln)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionString, Name: "area", Description: "Neuer Bereich", Required: true, Autocomplete: true},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "add",
+					Description: "User als zusätzlichen Teilnehmer zum Ticket hinzufügen (z.B. Teamcaptain oder Elternteil)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionUser, Name: "user", Description: "User", Required: true},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "remove",
+					Description: "Zusätzlichen Teilnehmer aus dem Ticket entfernen",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionUser, Name: "user", Description: "User", Required: true},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "search",
+					Description: "Tickets nach Formular-Antworten, Tags und Transkripten durchsuchen",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionString, Name: "query", Description: "Suchbegriffe oder #Ticket-ID", Required: true, Autocomplete: true},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "area", Description: "Nur in diesem Bereich", Required: false, Autocomplete: true},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "absent",
+					Description: "Als abwesend eintragen (keine automatische Zuweisung)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionInteger, Name: "days", Description: "Dauer in Tagen (leer = bis auf Weiteres)", Required: false, MinValue: &ticketAbsentMinDays},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "reason", Description: "Grund", Required: false, MaxLength: 200},
+						{Type: discordgo.ApplicationCommandOptionUser, Name: "user", Description: "Anderes Teammitglied eintragen", Required: false},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "present",
+					Description: "Abwesenheit beenden",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionUser, Name: "user", Description: "Anderes Teammitglied", Required: false},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "funnel",
+					Description: "Bewerbungen: beworben, angenommen, abgelehnt und Entscheidungsdauer pro Bereich",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionInteger, Name: "days", Description: "Zeitraum in Tagen (Standard: 30)", Required: false, MinValue: &ticketFunnelMinDays, MaxValue: 365},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "csat",
+					Description: "Zufriedenheit nach dem Schließen: Ø Bewertung und CSAT je Bereich und Bearbeiter",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionInteger, Name: "days", Description: "Zeitraum in Tagen (Standard: 30)", Required: false, MinValue: &ticketCSATMinDays, MaxValue: 365},
+					},
+				},
+			},
+		},
+
+		/*----------------------------------------------------------*/
+
+		// User-Kontextmenü: User als Teilnehmer zum Ticket des aktuellen Channels hinzufügen (wie /ticket add)
+		{
+			Name:                     tickets.ParticipantContextMenu,
+			Type:                     discordgo.UserApplicationCommand,
+			DefaultMemberPermissions: &adminPermission,
+		},
+
+		/*----------------------------------------------------------*/
+
+		// ticket_blacklist Command (excludes users from creating tickets, optionally per area and time-limited)
+		{
+			Name:                     "ticket_blacklist",
+			Description:              "Schließt User vom Ticket-System aus",
+			DefaultMemberPermissions: &adminPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "add",
+					Description: "User ausschließen (ersetzt einen bestehenden Ausschluss)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionUser, Name: "user", Description: "User", Required: true},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "reason", Description: "Grund (wird dem User angezeigt)", Required: true, MaxLength: 500},
+						{Type: discordgo.ApplicationCommandOptionInteger, Name: "days", Description: "Dauer in Tagen (Standard: dauerhaft)", Required: false, MinValue: &ticketBlacklistMinDays},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "areas", Description: "Nur diese Bereiche, kommagetrennte Schlüssel (Standard: alle)", Required: false},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "remove",
+					Description: "Ausschluss aufheben",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionUser, Name: "user", Description: "User", Required: true},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "Alle gültigen Ausschlüsse anzeigen",
+				},
+			},
+		},
+
+		/*----------------------------------------------------------*/
+
+		// ticket_sla Command (SLA report: time to claim / close per area and open breaches)
+		{
+			Name:                     "ticket_sla",
+			Description:              "Zeigt den SLA-Bericht der Tickets",
+			DefaultMemberPermissions: &adminPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "days",
+					Description: "Zeitraum in Tagen (Standard: 30)",
+					Required:    false,
+					MinValue:    &ticketSLAMinDays,
+					MaxValue:    365,
+				},
+			},
+		},
+
+		/*----------------------------------------------------------*/
+
+		// ticket_stats Command (ticket analytics: volume per area, time to claim/close, moderators, reopen and user-left rate)
+		{
+			Name:                     "ticket_stats",
+			Description:              "Zeigt die Ticket-Statistik mit Diagramm",
+			DefaultMemberPermissions: &adminPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "from", Description: "Von (JJJJ-MM-TT oder TT.MM.JJJJ, Standard: 30 Tage vor bis)", Required: false, MaxLength: 10},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "to", Description: "Bis einschließlich (JJJJ-MM-TT oder TT.MM.JJJJ, Standard: heute)", Required: false, MaxLength: 10},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "area", Description: "Nur dieser Bereich inkl. Unterbereiche", Required: false, Autocomplete: true},
+			},
+		},
+
+		/*----------------------------------------------------------*/
+
 		// Sync Team Members
 		{
 			Name:                     "sync_team_members",
@@ -261,3 +796,85 @@ func RegisterCommands(bot *discordgo.Session) {
 	}
 	log.Printf("Alle Commands erfolgreich registriert.")
 }
+
+// Mindestwerte für /quiz_admin Optionen (MinValue erwartet einen Pointer)
+var (
+	quizPreviewMinDays = 1.0
+	quizMinWindow      = 1.0
+	quizMinEditWindow  = 0.0
+)
+
+// Mindestwert für Länge und Position der Ticket-Formularfelder
+var ticketMinFieldLength = 1.0
+
+// Mindestwerte für die Zeiträume von /ticket_sla, /ticket funnel|csat|absent und /ticket_blacklist add
+var (
+	ticketSLAMinDays       = 1.0
+	ticketFunnelMinDays    = 1.0
+	ticketCSATMinDays      = 1.0
+	ticketAbsentMinDays    = 1.0
+	ticketBlacklistMinDays = 1.0
+)
+
+// Mindestwerte für SLA-Ziele, Inaktivität und Ticket-Limit der Bereiche (0 = Oberbereich/Standard)
+var (
+	ticketSLAMinClaimMinutes     = 0.0
+	ticketSLAMinCloseHours       = 0.0
+	ticketSLAMinReplyHours       = 0.0
+	ticketInactivityMinWarnDays  = 0.0
+	ticketInactivityMinCloseDays = 0.0
+	ticketMinMaxOpenTickets      = 0.0
+)
+
+// Mindest-Quorum für /ticket_admin decision set
+var ticketDecisionMinQuorum = 1.0
+
+// ticketAreaOptions liefert die Optionen für /ticket_admin area add|edit
+func ticketAreaOptions(create bool) []*discordgo.ApplicationCommandOption {
+	options := []*discordgo.ApplicationCommandOption{
+		{Type: discordgo.ApplicationCommandOptionString, Name: "key", Description: "Schlüssel des Bereichs (a-z, 0-9, _)", Required: true, Autocomplete: !create, MaxLength: 80},
+		{Type: discordgo.ApplicationCommandOptionString, Name: "label", Description: "Text im Dropdown", Required: create, MaxLength: 100},
+		{Type: discordgo.ApplicationCommandOptionString, Name: "description", Description: "Beschreibung im Dropdown", Required: false, MaxLength: 100},
+		{Type: discordgo.ApplicationCommandOptionString, Name: "display_name", Description: "Titel im Ticket-Channel", Required: false, MaxLength: 256},
+		{Type: discordgo.ApplicationCommandOptionString, Name: "modal_title", Description: "Titel des Formulars", Required: false, MaxLength: 45},
+		{Type: discordgo.ApplicationCommandOptionString, Name: "parent", Description: "Oberbereich ('-' = Hauptbereich)", Required: false, Autocomplete: true},
+		{Type: discordgo.ApplicationCommandOptionString, Name: "sub_prompt", Description: "Text über dem Dropdown der Unterbereiche", Required: false, MaxLength: 200},
+		{Type: discordgo.ApplicationCommandOptionRole, Name: "role", Description: "Support-Rolle, die gepingt wird", Required: false},
+		{Type: discordgo.ApplicationCommandOptionUser, Name: "user", Description: "Statt einer Rolle einen User pingen", Required: false},
+		{Type: discordgo.ApplicationCommandOptionChannel, Name: "category", Description: "Kategorie für die Ticket-Channels", Required: false, ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildCategory}},
+		{Type: discordgo.ApplicationCommandOptionString, Name: "name_pattern", Description: "Channel-Name, z.B. {id}-open-{user} oder {id}-{area}-{user}", Required: false, MaxLength: 100},
+		{Type: discordgo.ApplicationCommandOptionInteger, Name: "sort_order", Description: "Reihenfolge im Dropdown", Required: false},
+		{Type: discordgo.ApplicationCommandOptionInteger, Name: "sla_claim_minutes", Description: "SLA: Minuten bis zur Übernahme (0 = Oberbereich/keins)", Required: false, MinValue: &ticketSLAMinClaimMinutes},
+		{Type: discordgo.ApplicationCommandOptionInteger, Name: "sla_close_hours", Description: "SLA: Stunden bis zum Schließen (0 = Oberbereich/keins)", Required: false, MinValue: &ticketSLAMinCloseHours},
+		{Type: discordgo.ApplicationCommandOptionInteger, Name: "sla_reply_hours", Description: "Erinnerung nach Stunden ohne Antwort (0 = Oberbereich/keine)", Required: false, MinValue: &ticketSLAMinReplyHours},
+		{Type: discordgo.ApplicationCommandOptionInteger, Name: "inactivity_warn_days", Description: "Inaktivität: Warnung nach Tagen ohne Nachricht des Erstellers (0 = Oberbereich/aus)", Required: false, MinValue: &ticketInactivityMinWarnDays},
+		{Type: discordgo.ApplicationCommandOptionInteger, Name: "inactivity_close_days", Description: "Inaktivität: Schließen Tage nach der Warnung", Required: false, MinValue: &ticketInactivityMinCloseDays},
+		{Type: discordgo.ApplicationCommandOptionInteger, Name: "max_open_tickets", Description: "Offene Tickets pro User (0 = Oberbereich/Standard)", Required: false, MinValue: &ticketMinMaxOpenTickets},
+		{Type: discordgo.ApplicationCommandOptionBoolean, Name: "inactivity_delete", Description: "Inaktivität: nach dem Schließen Transkript erstellen und löschen", Required: false},
+		{Type: discordgo.ApplicationCommandOptionString, Name: "assign_mode", Description: "Automatische Zuweisung neuer Tickets", Required: false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Wie Oberbereich", Value: "-"},
+				{Name: "Aus", Value: "off"},
+				{Name: "Reihum", Value: "round_robin"},
+				{Name: "Wenigste offene Tickets", Value: "least_load"},
+			},
+		},
+		{Type: discordgo.ApplicationCommandOptionRole, Name: "assign_role", Description: "Zuweisungs-Pool: Rolle (Standard: Support-Rolle)", Required: false},
+		{Type: discordgo.ApplicationCommandOptionString, Name: "assign_users", Description: "Zuweisungs-Pool: feste User (@Erwähnungen oder IDs, '-' = Rolle)", Required: false, MaxLength: 1000},
+	}
+	if !create {
+		options = append(options, &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionBoolean, Name: "enabled", Description: "Bereich aktiv", Required: false})
+	}
+	return options
+}
+
+// quizCorrectChoices liefert die Auswahl für die richtige Antwort
+func quizCorrectChoices() []*discordgo.ApplicationCommandOptionChoice {
+	return []*discordgo.ApplicationCommandOptionChoice{
+		{Name: "Antwort 1", Value: 1},
+		{Name: "Antwort 2", Value: 2},
+		{Name: "Antwort 3", Value: 3},
+		{Name: "Antwort 4", Value: 4},
+		{Name: "Antwort 5", Value: 5},
+	}
+}
@@ -33,12 +33,48 @@ func interactionHandler(bot *discordgo.Session, bot_interaction *discordgo.Inter
 			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleDeveloper) {
 				tickets.HandleTicketView(bot, bot_interaction)
 			}
+		case "ticket_admin":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleProjektleitung) {
+				tickets.HandleTicketAdmin(bot, bot_interaction)
+			}
+		case "ticket_repair":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleProjektleitung) {
+				tickets.HandleTicketRepair(bot, bot_interaction)
+			}
+		case "ticket":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleTicketCommand(bot, bot_interaction)
+			}
+		case tickets.ParticipantContextMenu:
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleParticipantContextMenu(bot, bot_interaction)
+			}
+		case "ticket_blacklist":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleTicketBlacklist(bot, bot_interaction)
+			}
+		case "ticket_sla":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleTicketSLA(bot, bot_interaction)
+			}
+		case "ticket_stats":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleTicketStats(bot, bot_interaction)
+			}
 		case "create_ticket":
 			utils.EnsureUser(bot, bot_interaction.Member.User.ID)
 			tickets.HandleCreateTicket(bot, bot_interaction)
 		case "ticket_response":
 			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
-				discord_administration_utils.HandleTicketResponse(bot, bot_interaction)
+				tickets.HandleTicketResponse(bot, bot_interaction)
+			}
+		case "reply":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleModmailReply(bot, bot_interaction)
+			}
+		case "canned":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleCannedCommand(bot, bot_interaction)
 			}
 		case "create_team_area":
 			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
@@ -59,9 +95,16 @@ func interactionHandler(bot *discordgo.Session, bot_interaction *discordgo.Inter
 			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleDeveloper) {
 				quiz.HandleQuizCommand(bot, bot_interaction)
 			}
+		case "quiz_admin":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				quiz.HandleQuizAdmin(bot, bot_interaction)
+			}
 		case "quiz_leaderboard":
 			utils.EnsureUser(bot, bot_interaction.Member.User.ID)
 			quiz.HandleQuizLeaderboard(bot, bot_interaction)
+		case "quiz_stats":
+			utils.EnsureUser(bot, bot_interaction.Member.User.ID)
+			quiz.HandleQuizStats(bot, bot_interaction)
 		case "send_survey":
 			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleProjektleitung) {
 				surveys.SendSurvey(bot, bot_interaction, database.DB)
@@ -91,22 +134,40 @@ func interactionHandler(bot *discordgo.Session, bot_interaction *discordgo.Inter
 			return
 		}
 
+	/*==================================================================*/
+	// "Interaction-ApplicationCommandAutocomplete" (Autocomplete)
+	/*==================================================================*/
+
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		switch bot_interaction.ApplicationCommandData().Name {
+		case "quiz_leaderboard":
+			quiz.HandleSeasonAutocomplete(bot, bot_interaction)
+		case "ticket_admin", "ticket_view":
+			tickets.HandleTicketAdminAutocomplete(bot, bot_interaction)
+		case "ticket", "ticket_stats":
+			tickets.HandleTicketAutocomplete(bot, bot_interaction)
+		case "ticket_response", "canned":
+			tickets.HandleCannedAutocomplete(bot, bot_interaction)
+		}
+
 	/*==================================================================*/
 	// "Interaction-MessageComponent" (Button, Dropdown, etc.)
 	/*==================================================================*/
 
 	case discordgo.InteractionMessageComponent:
-		switch bot_interaction.MessageComponentData().CustomID {
+		// Moderations-Buttons tragen die Ticket-ID als Suffix ("ticket_button_claim_12")
+		customID, _ := tickets.SplitTicketCustomID(bot_interaction.MessageComponentData().CustomID)
+		switch customID {
 
 		// Ticket Creation Process
 		case "ticket_create_ticket":
 			utils.EnsureUser(bot, bot_interaction.Member.User.ID)
 			tickets.HandleCreateTicket(bot, bot_interaction)
 		case "ticket_dropdown":
-			// Blacklist Check
+			// Ausschluss, Limit und Cooldown werden in HandleTicketDropdown vor dem Formular geprüft
+			tickets.HandleTicketDropdown(bot, bot_interaction)
+		case "ticket_area_dropdown", "ticket_game_dropdown":
 			tickets.HandleTicketDropdown(bot, bot_interaction)
-		case "ticket_game_dropdown":
-			tickets.HandleGameDropdown(bot, bot_interaction)
 		// After Ticket Creation Survey Dropdown via DM
 		case "ticket_after_survey_dropdown":
 			surveys.HandleSurveyDropdown(bot, bot_interaction)
@@ -130,6 +191,53 @@ func interactionHandler(bot *discordgo.Session, bot_interaction *discordgo.Inter
 			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
 				tickets.HandleAssignButton(bot, bot_interaction)
 			}
+		case "ticket_button_notes":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleNotesButton(bot, bot_interaction)
+			}
+		case "ticket_button_add_note":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleAddNoteButton(bot, bot_interaction)
+			}
+		case "ticket_select_assignee":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleAssigneeSelect(bot, bot_interaction)
+			}
+		case "ticket_select_priority":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandlePrioritySelect(bot, bot_interaction)
+			}
+		case "ticket_select_tags":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleTagsSelect(bot, bot_interaction)
+			}
+		case "ticket_button_move":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleMoveButton(bot, bot_interaction)
+			}
+		case "ticket_select_move":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleMoveSelect(bot, bot_interaction)
+			}
+		case "ticket_button_approve":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleApproveButton(bot, bot_interaction)
+			}
+		case "ticket_button_reject":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleRejectButton(bot, bot_interaction)
+			}
+		case "ticket_select_team":
+			if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+				tickets.HandleTeamSelect(bot, bot_interaction)
+			}
+		// Zufriedenheits-Umfrage nach dem Schließen (DM an den Ersteller)
+		case "ticket_select_rating":
+			tickets.HandleRatingSelect(bot, bot_interaction)
+		case "ticket_button_feedback_comment":
+			tickets.HandleFeedbackCommentButton(bot, bot_interaction)
+		case "ticket_button_keep_open":
+			tickets.HandleKeepOpenButton(bot, bot_interaction)
 		case "ticket_confirm_delete_ticket":
 			tickets.HandleConfirmDelete(bot, bot_interaction)
 		case "ticket_cancel_delete_ticket":
@@ -146,21 +254,22 @@ func interactionHandler(bot *discordgo.Session, bot_interaction *discordgo.Inter
 			valo_event.HandleValoEventButton(bot, bot_interaction)
 
 		default:
-			// Survey Interaction handler
-			if strings.HasPrefix(bot_interaction.MessageComponentData().CustomID, "survey_") {
-				surveys.HandleSurveyInteraction(bot, bot_interaction, database.DB)
+			// Ticket-Panel Button ("ticket_create_ticket_<panelID>")
+			if strings.HasPrefix(bot_interaction.MessageComponentData().CustomID, "ticket_create_ticket_") {
+				utils.EnsureUser(bot, bot_interaction.Member.User.ID)
+				tickets.HandleCreateTicket(bot, bot_interaction)
 				return
 			}
 
-			// Assign Ticket Dropdown handling
-			if strings.HasPrefix(bot_interaction.MessageComponentData().CustomID, "ticket_assign_ticket_dropdown_") {
-				tickets.HandleAssignTicketUpdate(bot, bot_interaction, bot_interaction.MessageComponentData().CustomID)
+			// Weiter-Button mehrstufiger Ticket-Formulare ("ticket_form_next_<step>_<areaKey>")
+			if strings.HasPrefix(bot_interaction.MessageComponentData().CustomID, "ticket_form_next_") {
+				tickets.HandleTicketFormButton(bot, bot_interaction)
 				return
 			}
 
-			// Assign Suggestions Dropdown handling
-			if strings.HasPrefix(bot_interaction.MessageComponentData().CustomID, "ticket_assign_suggestions_") {
-				tickets.HandleAssignSuggestions(bot, bot_interaction, bot_interaction.MessageComponentData().CustomID)
+			// Survey Interaction handler
+			if strings.HasPrefix(bot_interaction.MessageComponentData().CustomID, "survey_") {
+				surveys.HandleSurveyInteraction(bot, bot_interaction, database.DB)
 				return
 			}
 
@@ -191,9 +300,30 @@ func interactionHandler(bot *discordgo.Session, bot_interaction *discordgo.Inter
 		// default in this case: Ticket-Submit Modal
 		// Überarbeitung nötig, da der default-Case eigentlich eine Fehlermeldung sein sollte, wenn die CustomID nicht existiert
 		default:
-			// Assign Ticket Modal handling
-			if strings.HasPrefix(bot_interaction.ModalSubmitData().CustomID, "ticket_assign_modal_") {
-				tickets.HandleAssignModal(bot, bot_interaction)
+			// Ticket-Notiz Modal handling
+			if strings.HasPrefix(bot_interaction.ModalSubmitData().CustomID, "ticket_note_modal_") {
+				if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+					tickets.HandleNoteModal(bot, bot_interaction)
+				}
+				return
+			}
+			// Begründung der Ablehnung einer Bewerbung
+			if strings.HasPrefix(bot_interaction.ModalSubmitData().CustomID, "ticket_reject_modal_") {
+				if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+					tickets.HandleRejectModal(bot, bot_interaction)
+				}
+				return
+			}
+			// Kommentar zur Ticket-Bewertung (DM, nur der Ersteller)
+			if strings.HasPrefix(bot_interaction.ModalSubmitData().CustomID, "ticket_feedback_modal_") {
+				tickets.HandleFeedbackModal(bot, bot_interaction)
+				return
+			}
+			// Textbaustein-Modal handling
+			if strings.HasPrefix(bot_interaction.ModalSubmitData().CustomID, "canned_modal_") {
+				if utils.CheckUserPermissions(bot, bot_interaction, utils.RequireRoleManagement) {
+					tickets.HandleCannedModal(bot, bot_interaction)
+				}
 				return
 			}
 
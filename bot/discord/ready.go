@@ -13,7 +13,7 @@ import (
 
 // ready-Handler wird noch ausgelagert in ready.go
 func ready(bot *discordgo.Session, event *discordgo.Ready) {
-	tickets.CheckAndNotifyInactiveUsers(bot) 					// Starting Inaktive-User-Notifier 
+	tickets.ReconcileTicketMembers(bot) 					// Einmaliger Abgleich der Ticket-Ersteller mit der Mitgliederliste
 	log.Printf("Bot logged in as %s#%s", event.User.Username, event.User.Discriminator) 		// Stauts-Update "Bot is working"
 }
 
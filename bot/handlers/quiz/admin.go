@@ -0,0 +1,444 @@
+package quiz
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	quizService "bot/services/quiz"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Standard-Vorlauf für Vorschau und Abdeckungswarnung, falls QUIZ_COVERAGE_DAYS fehlt
+const defaultCoverageDays = 7
+
+// Einträge pro Seite bei /quiz_admin list
+const listPageSize = 10
+
+// Optionsnamen der Antworten bei /quiz_admin add|edit
+var answerOptionNames = []string{"answer1", "answer2", "answer3", "answer4", "answer5"}
+
+// HandleQuizAdmin behandelt /quiz_admin add|edit|delete|list|import|preview|season_create|season_list
+func HandleQuizAdmin(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	data := bot_interaction.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return
+	}
+	sub := data.Options[0]
+	opts := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, opt := range sub.Options {
+		opts[opt.Name] = opt
+	}
+
+	service := quizService.NewQuestionService(bot)
+
+	switch sub.Name {
+	case "add":
+		handleQuizAdminAdd(bot, bot_interaction, service, opts)
+	case "edit":
+		handleQuizAdminEdit(bot, bot_interaction, service, opts)
+	case "delete":
+		handleQuizAdminDelete(bot, bot_interaction, service, opts)
+	case "list":
+		handleQuizAdminList(bot, bot_interaction, service, opts)
+	case "import":
+		handleQuizAdminImport(bot, bot_interaction, service, opts)
+	case "preview":
+		handleQuizAdminPreview(bot, bot_interaction, service, opts)
+	case "season_create":
+		handleQuizAdminSeasonCreate(bot, bot_interaction, service, opts)
+	case "season_list":
+		handleQuizAdminSeasonList(bot, bot_interaction, service)
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+func handleQuizAdminAdd(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *quizService.QuestionService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	q := &quizService.Question{
+		Question: opts["question"].StringValue(),
+		Correct:  int(opts["correct"].IntValue()),
+	}
+	for _, name := range answerOptionNames {
+		if opt, ok := opts[name]; ok {
+			q.Answers = append(q.Answers, opt.StringValue())
+		}
+	}
+	if opt, ok := opts["date"]; ok {
+		q.ScheduledDate = opt.StringValue()
+	}
+	if opt, ok := opts["category"]; ok {
+		q.Category = opt.StringValue()
+	}
+	if opt, ok := opts["explanation"]; ok {
+		q.Explanation = opt.StringValue()
+	}
+	if opt, ok := opts["image_url"]; ok {
+		q.ImageURL = opt.StringValue()
+	}
+	if opt, ok := opts["window"]; ok {
+		q.AnswerWindow = int(opt.IntValue())
+	}
+
+	id, err := service.Create(q)
+	if err != nil {
+		respondQuizAdminError(bot, bot_interaction, err, "Fehler beim Anlegen der Quiz-Frage")
+		return
+	}
+
+	utils.SendSuccessEmbed(bot, bot_interaction, "Quiz-Frage angelegt", fmt.Sprintf("Frage **#%d** wurde gespeichert.\n%s", id, formatQuestion(q)), true)
+}
+
+func handleQuizAdminEdit(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *quizService.QuestionService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	q, err := service.Get(int(opts["id"].IntValue()))
+	if err != nil {
+		respondQuizAdminError(bot, bot_interaction, err, "Fehler beim Laden der Quiz-Frage")
+		return
+	}
+
+	// Nur übergebene Felder überschreiben, "-" leert optionale Felder
+	if opt, ok := opts["question"]; ok {
+		q.Question = opt.StringValue()
+	}
+	answers := make([]string, len(answerOptionNames))
+	copy(answers, q.Answers)
+	for i, name := range answerOptionNames {
+		if opt, ok := opts[name]; ok {
+			answers[i] = clearValue(opt.StringValue())
+		}
+	}
+	// Die richtige Antwort wandert beim Zusammenschieben mit, ohne neue Angabe darf sie nicht geleert werden
+	_, correctGiven := opts["correct"]
+	oldCorrect := q.Correct
+	q.Answers = nil
+	for i, answer := range answers {
+		if answer == "" {
+			continue
+		}
+		q.Answers = append(q.Answers, answer)
+		if i+1 == oldCorrect {
+			q.Correct = len(q.Answers)
+		}
+	}
+	if !correctGiven && oldCorrect >= 1 && oldCorrect <= len(answers) && answers[oldCorrect-1] == "" {
+		respondQuizAdminError(bot, bot_interaction, fmt.Errorf("%w: Antwort %d ist die richtige Antwort und kann nur zusammen mit correct geleert werden", quizService.ErrInvalidInput, oldCorrect), "")
+		return
+	}
+	if opt, ok := opts["explanation"]; ok {
+		q.Explanation = clearValue(opt.StringValue())
+	}
+	if opt, ok := opts["image_url"]; ok {
+		q.ImageURL = clearValue(opt.StringValue())
+	}
+	if opt, ok := opts["window"]; ok {
+		q.AnswerWindow = int(opt.IntValue())
+	}
+	if opt, ok := opts["correct"]; ok {
+		q.Correct = int(opt.IntValue())
+	}
+	if opt, ok := opts["date"]; ok {
+		q.ScheduledDate = clearValue(opt.StringValue())
+	}
+	if opt, ok := opts["category"]; ok {
+		q.Category = clearValue(opt.StringValue())
+	}
+
+	if err := service.Update(q); err != nil {
+		respondQuizAdminError(bot, bot_interaction, err, "Fehler beim Bearbeiten der Quiz-Frage")
+		return
+	}
+
+	utils.SendSuccessEmbed(bot, bot_interaction, "Quiz-Frage aktualisiert", fmt.Sprintf("Frage **#%d** wurde aktualisiert.\n%s", q.ID, formatQuestion(q)), true)
+}
+
+func handleQuizAdminDelete(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *quizService.QuestionService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	id := int(opts["id"].IntValue())
+	if err := service.Delete(id); err != nil {
+		respondQuizAdminError(bot, bot_interaction, err, "Fehler beim Löschen der Quiz-Frage")
+		return
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Quiz-Frage gelöscht", fmt.Sprintf("Frage **#%d** wurde gelöscht.", id), true)
+}
+
+func handleQuizAdminList(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *quizService.QuestionService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	page := 1
+	if opt, ok := opts["page"]; ok && opt.IntValue() > 0 {
+		page = int(opt.IntValue())
+	}
+
+	questions, err := service.List(listPageSize, (page-1)*listPageSize)
+	if err != nil {
+		respondQuizAdminError(bot, bot_interaction, err, "Fehler beim Laden der Quiz-Fragen")
+		return
+	}
+	if len(questions) == 0 {
+		utils.SendInfoEmbed(bot, bot_interaction, "Quiz-Fragen", fmt.Sprintf("Keine Fragen auf Seite %d.", page), true)
+		return
+	}
+
+	var lines []string
+	for _, q := range questions {
+		date := q.ScheduledDate
+		if date == "" {
+			date = "ungeplant"
+		}
+		status := ""
+		if q.Asked {
+			status = " ✅"
+		}
+		lines = append(lines, fmt.Sprintf("**#%d** `%s`%s - %s", q.ID, date, status, truncate(q.Question, 80)))
+	}
+
+	utils.SendEmbedResponse(bot, bot_interaction, utils.EmbedResponseOptions{
+		Title:       fmt.Sprintf("Quiz-Fragen (Seite %d)", page),
+		Description: strings.Join(lines, "\n"),
+		Color:       utils.ColorInfo,
+		Ephemeral:   true,
+	})
+}
+
+func handleQuizAdminImport(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *quizService.QuestionService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	attachmentID, _ := opts["file"].Value.(string)
+	resolved := bot_interaction.ApplicationCommandData().Resolved
+	if resolved == nil || resolved.Attachments[attachmentID] == nil {
+		utils.SendErrorEmbed(bot, bot_interaction, "Import fehlgeschlagen", "Es wurde keine Datei übergeben.", true)
+		return
+	}
+	attachment := resolved.Attachments[attachmentID]
+
+	// Download und Import können länger dauern
+	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(attachment.URL)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "admin.go", false, err, "Fehler beim Herunterladen der Quiz-Importdatei")
+		editQuizAdminResponse(bot, bot_interaction, "❌ Die Datei konnte nicht heruntergeladen werden.")
+		return
+	}
+	defer resp.Body.Close()
+
+	var questions []quizService.Question
+	firstLine := 1
+	switch strings.ToLower(filepath.Ext(attachment.Filename)) {
+	case ".csv":
+		questions, err = quizService.ParseCSV(resp.Body)
+		firstLine = 2 // Zeile 1 ist der Header
+	case ".json":
+		questions, err = quizService.ParseJSON(resp.Body)
+	default:
+		editQuizAdminResponse(bot, bot_interaction, "❌ Nur .csv oder .json Dateien werden unterstützt.")
+		return
+	}
+	if err != nil {
+		editQuizAdminResponse(bot, bot_interaction, "❌ Datei konnte nicht gelesen werden: "+err.Error())
+		return
+	}
+
+	result := service.Import(questions, firstLine)
+
+	msg := fmt.Sprintf("✅ %d von %d Fragen importiert.", result.Imported, len(questions))
+	if len(result.Skipped) > 0 {
+		msg += "\n\n**Übersprungen:**"
+		for i, issue := range result.Skipped {
+			if i == 15 {
+				msg += fmt.Sprintf("\n... und %d weitere", len(result.Skipped)-i)
+				break
+			}
+			msg += fmt.Sprintf("\nZeile %d: %s", issue.Line, issue.Reason)
+		}
+	}
+	editQuizAdminResponse(bot, bot_interaction, msg)
+}
+
+func handleQuizAdminPreview(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *quizService.QuestionService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	days := 14
+	if opt, ok := opts["days"]; ok {
+		days = int(opt.IntValue())
+	}
+
+	preview, err := service.Preview(days)
+	if err != nil {
+		respondQuizAdminError(bot, bot_interaction, err, "Fehler beim Erstellen der Quiz-Vorschau")
+		return
+	}
+
+	var lines []string
+	for _, day := range preview.Days {
+		if day.QuestionID == 0 {
+			lines = append(lines, fmt.Sprintf("`%s` ⚠️ keine Frage", day.Date))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("`%s` **#%d** %s", day.Date, day.QuestionID, truncate(day.Question, 60)))
+	}
+
+	color := utils.ColorSuccess
+	if len(preview.Missing) > 0 {
+		color = utils.ColorWarning
+	}
+	footer := fmt.Sprintf("Pool: %d ungestellte Fragen", preview.Pool)
+	if quizPickOptions(bot).Auto {
+		footer += " (automatische Planung aktiv)"
+	}
+	utils.SendEmbedResponse(bot, bot_interaction, utils.EmbedResponseOptions{
+		Title:       fmt.Sprintf("Quiz-Plan: %d von %d Tagen abgedeckt", preview.Covered, days),
+		Description: strings.Join(lines, "\n"),
+		Color:       color,
+		Footer:      &discordgo.MessageEmbedFooter{Text: footer},
+		Ephemeral:   true,
+	})
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// checkQuizCoverage warnt die Admins, wenn in den nächsten Tagen Fragen fehlen
+func checkQuizCoverage(bot *discordgo.Session) {
+	days, err := strconv.Atoi(utils.GetOptionalIdFromDB(bot, "QUIZ_COVERAGE_DAYS", strconv.Itoa(defaultCoverageDays)))
+	if err != nil || days <= 0 {
+		days = defaultCoverageDays
+	}
+
+	preview, err := quizService.NewQuestionService(bot).Preview(days)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "admin.go", true, err, "Fehler beim Prüfen der Quiz-Abdeckung")
+		return
+	}
+	if len(preview.Missing) == 0 {
+		return
+	}
+
+	// Im Automatik-Modus füllt der Pool die fehlenden Tage auf
+	if quizPickOptions(bot).Auto {
+		if preview.Pool >= len(preview.Missing) {
+			return
+		}
+		utils.LogAndNotifyAdmins(bot, "warn", "Warnung", "admin.go", true, nil,
+			fmt.Sprintf("Quiz-Pool: nur noch %d ungestellte Fragen für %d ungeplante Tage in den nächsten %d Tagen. Danach werden alte Fragen wiederverwendet.\nMit /quiz_admin add oder /quiz_admin import nachtragen.",
+				preview.Pool, len(preview.Missing), days))
+		return
+	}
+
+	utils.LogAndNotifyAdmins(bot, "warn", "Warnung", "admin.go", true, nil,
+		fmt.Sprintf("Quiz-Plan: nur %d von %d Tagen abgedeckt. Fehlende Tage: %s\nMit /quiz_admin add oder /quiz_admin import nachtragen.",
+			preview.Covered, days, strings.Join(preview.Missing, ", ")))
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// quizPickOptions liest die Einstellungen der automatischen Planung
+// QUIZ_AUTO_SCHEDULE = true/1 aktiviert den Pool, QUIZ_CATEGORY_ROTATION = kommagetrennte Kategorien
+func quizPickOptions(bot *discordgo.Session) quizService.PickOptions {
+	auto := utils.GetOptionalIdFromDB(bot, "QUIZ_AUTO_SCHEDULE", "false")
+	opts := quizService.PickOptions{Auto: auto == "true" || auto == "1"}
+	for _, category := range strings.Split(utils.GetOptionalIdFromDB(bot, "QUIZ_CATEGORY_ROTATION", ""), ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			opts.Rotation = append(opts.Rotation, category)
+		}
+	}
+	return opts
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+func handleQuizAdminSeasonCreate(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *quizService.QuestionService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	season, err := service.CreateSeason(opts["name"].StringValue(), opts["start"].StringValue(), opts["end"].StringValue())
+	if err != nil {
+		respondQuizAdminError(bot, bot_interaction, err, "Fehler beim Anlegen der Saison")
+		return
+	}
+
+	utils.SendSuccessEmbed(bot, bot_interaction, "Saison angelegt",
+		fmt.Sprintf("**%s** (#%d) läuft vom %s bis %s.", season.Name, season.ID, season.StartDate, season.EndDate), true)
+}
+
+func handleQuizAdminSeasonList(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *quizService.QuestionService) {
+	seasons, err := service.ListSeasons(25)
+	if err != nil {
+		respondQuizAdminError(bot, bot_interaction, err, "Fehler beim Laden der Saisons")
+		return
+	}
+	if len(seasons) == 0 {
+		utils.SendInfoEmbed(bot, bot_interaction, "Quiz-Saisons", "Es wurden noch keine Saisons angelegt.", true)
+		return
+	}
+
+	var b strings.Builder
+	for _, season := range seasons {
+		status := "🟢 läuft"
+		if season.Finalized {
+			status = "🏁 abgeschlossen"
+		}
+		fmt.Fprintf(&b, "`#%d` **%s** - %s bis %s (%s)\n", season.ID, season.Name, season.StartDate, season.EndDate, status)
+	}
+	utils.SendInfoEmbed(bot, bot_interaction, "Quiz-Saisons", b.String(), true)
+}
+
+func respondQuizAdminError(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, err error, contextMsg string) {
+	// Eingabefehler gehen nur an den Nutzer, alles andere auch an die Admins
+	if errors.Is(err, quizService.ErrInvalidInput) || errors.Is(err, quizService.ErrNotFound) ||
+		errors.Is(err, quizService.ErrDuplicate) || errors.Is(err, quizService.ErrDateTaken) ||
+		errors.Is(err, quizService.ErrSeasonOverlap) {
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", err.Error(), true)
+		return
+	}
+	utils.LogAndNotifyAdmins(bot, "medium", "Error", "admin.go", true, err, contextMsg)
+	utils.SendErrorEmbed(bot, bot_interaction, "Fehler", contextMsg, true)
+}
+
+// clearValue wandelt "-" in einen leeren Wert um
+func clearValue(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "-" {
+		return ""
+	}
+	return value
+}
+
+func editQuizAdminResponse(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, content string) {
+	bot.InteractionResponseEdit(bot_interaction.Interaction, &discordgo.WebhookEdit{Content: &content})
+}
+
+func formatQuestion(q *quizService.Question) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s**\n", q.Question)
+	for i, answer := range q.Answers {
+		marker := "▫️"
+		if i+1 == q.Correct {
+			marker = "✅"
+		}
+		fmt.Fprintf(&b, "%s %s\n", marker, answer)
+	}
+	if q.ScheduledDate != "" {
+		fmt.Fprintf(&b, "📅 %s", q.ScheduledDate)
+	}
+	if q.Category != "" {
+		fmt.Fprintf(&b, " 🏷️ %s", q.Category)
+	}
+	if q.AnswerWindow > 0 {
+		fmt.Fprintf(&b, " ⏰ %d Min.", q.AnswerWindow)
+	}
+	if q.Explanation != "" {
+		fmt.Fprintf(&b, "\n💡 %s", q.Explanation)
+	}
+	if q.ImageURL != "" {
+		fmt.Fprintf(&b, "\n🖼️ %s", q.ImageURL)
+	}
+	return b.String()
+}
+
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"bot/database"
+	quizService "bot/services/quiz"
 	"bot/utils"
 
 	"github.com/bwmarrin/discordgo"
@@ -23,8 +24,31 @@ type LeaderboardEntry struct {
 
 // HandleQuizLeaderboard behandelt den /quiz_leaderboard Command
 func HandleQuizLeaderboard(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	// Optional: Speed-Bonus für schnelle richtige Antworten und Saison (0 = aktuelle Saison)
+	speedBonus := false
+	var season *quizService.Season
+	for _, opt := range bot_interaction.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "speed":
+			speedBonus = opt.BoolValue()
+		case "season":
+			var err error
+			season, err = resolveSeason(bot, int(opt.IntValue()))
+			if err != nil {
+				bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+					Type: discordgo.InteractionResponseChannelMessageWithSource,
+					Data: &discordgo.InteractionResponseData{
+						Content: "❌ Saison nicht gefunden.",
+						Flags:   discordgo.MessageFlagsEphemeral,
+					},
+				})
+				return
+			}
+		}
+	}
+
 	// Leaderboard-Daten aus der Datenbank abrufen
-	leaderboard, err := getQuizLeaderboard()
+	leaderboard, err := getQuizLeaderboard(speedBonus, season, 25)
 	if err != nil {
 		utils.LogAndNotifyAdmins(bot, "high", "Error", "leaderboard.go", true, err, "Error fetching quiz leaderboard")
 		bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
@@ -49,7 +73,7 @@ func HandleQuizLeaderboard(bot *discordgo.Session, bot_interaction *discordgo.In
 	}
 
 	// Embed erstellen
-	embed := createLeaderboardEmbed(leaderboard)
+	embed := createLeaderboardEmbed(leaderboard, speedBonus, season)
 
 	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -59,8 +83,29 @@ func HandleQuizLeaderboard(bot *discordgo.Session, bot_interaction *discordgo.In
 	})
 }
 
+// Speed-Bonus pro richtiger Antwort: 1 Punkt direkt nach dem Post, linear fallend bis 0 zur Deadline.
+// Antworten ohne Post (vor Einführung der Deadline) erhalten keinen Bonus.
+const speedBonusSQL = `
+		SUM(CASE WHEN qr.correct = 1 AND qp.id IS NOT NULL
+			THEN MAX(0.0, 1.0 - CAST(strftime('%s', qr.answered_at) - qp.posted_at AS FLOAT) / MAX(qp.deadline - qp.posted_at, 1))
+			ELSE 0 END)`
+
 // getQuizLeaderboard ruft die Leaderboard-Daten aus der Datenbank ab
-func getQuizLeaderboard() ([]LeaderboardEntry, error) {
+// Mit season werden nur Antworten aus dem Zeitraum der Saison gewertet
+func getQuizLeaderboard(speedBonus bool, season *quizService.Season, limit int) ([]LeaderboardEntry, error) {
+	bonus := "0"
+	if speedBonus {
+		bonus = speedBonusSQL
+	}
+
+	where := ""
+	args := []interface{}{}
+	if season != nil {
+		where = "WHERE date(qr.answered_at, 'localtime') BETWEEN ? AND ?"
+		args = append(args, season.StartDate, season.EndDate)
+	}
+	args = append(args, limit)
+
 	query := `
 	SELECT 
 		u.discord_id,
@@ -75,16 +120,18 @@ func getQuizLeaderboard() ([]LeaderboardEntry, error) {
 		-- Score-Berechnung: Gewichtung von Anzahl beantworteter Fragen und Genauigkeit
 		-- Formel: (correct_answers * 2) + (total_questions * 0.1) 
 		-- Dies belohnt sowohl Genauigkeit als auch Aktivität
-		(SUM(qr.correct) * 2) + (COUNT(*) * 0.1) as score
+		(SUM(qr.correct) * 2) + (COUNT(*) * 0.1) + ` + bonus + ` as score
 	FROM quiz_responses qr
 	INNER JOIN users u ON qr.user_id = u.id
+	LEFT JOIN quiz_posts qp ON qr.post_id = qp.id
+	` + where + `
 	GROUP BY qr.user_id, u.discord_id, u.username
 	HAVING COUNT(*) >= 1  -- Mindestens 1 Frage beantwortet
 	ORDER BY score DESC, accuracy_rate DESC, total_questions DESC
-	LIMIT 25;
+	LIMIT ?;
 	`
 
-	rows, err := database.DB.Query(query)
+	rows, err := database.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -112,9 +159,13 @@ func getQuizLeaderboard() ([]LeaderboardEntry, error) {
 }
 
 // createLeaderboardEmbed erstellt das Discord-Embed für das Leaderboard
-func createLeaderboardEmbed(leaderboard []LeaderboardEntry) *discordgo.MessageEmbed {
+func createLeaderboardEmbed(leaderboard []LeaderboardEntry, speedBonus bool, season *quizService.Season) *discordgo.MessageEmbed {
 	var description strings.Builder
-	description.WriteString("🏆 **Quiz-Leaderboard - Top 25**\n\n")
+	if season != nil {
+		description.WriteString(fmt.Sprintf("🏆 **%s - Top 25**\n*%s bis %s*\n\n", season.Name, season.StartDate, season.EndDate))
+	} else {
+		description.WriteString("🏆 **Quiz-Leaderboard - Top 25**\n\n")
+	}
 	description.WriteString("*Ranking basiert auf einem gewichteten Score aus Genauigkeit und Aktivität*\n\n")
 
 	// Medaillen für die Top 3
@@ -150,6 +201,9 @@ func createLeaderboardEmbed(leaderboard []LeaderboardEntry) *discordgo.MessageEm
 
 	// Footer-Information
 	footerText := "Score = (Richtige Antworten × 2) + (Gesamtfragen × 0.1)"
+	if speedBonus {
+		footerText += " + Speed-Bonus (bis 1 Punkt je schneller richtiger Antwort)"
+	}
 
 	embed := &discordgo.MessageEmbed{
 		Title:       "🧠 Quiz-Leaderboard",
@@ -10,6 +10,8 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/robfig/cron/v3"
 	"bot/database"
+	"bot/services/events"
+	quizService "bot/services/quiz"
 	"bot/utils"
 )
 
@@ -24,27 +26,40 @@ func startQuizScheduler(bot *discordgo.Session) {
 	if err != nil {
 		utils.LogAndNotifyAdmins(bot, "high", "Error", "questions.go", true, err, "Error adding quiz post function to cron scheduler")
 	}
+	_, err = c.AddFunc(utils.GetOptionalIdFromDB(bot, "QUIZ_COVERAGE_CRON_SPEC", "0 12 * * *"), func() { checkQuizCoverage(bot) })
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "questions.go", true, err, "Error adding quiz coverage check to cron scheduler")
+	}
+	_, err = c.AddFunc("@every 1m", func() { revealDuePosts(bot, time.Now()) })
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "questions.go", true, err, "Error adding quiz reveal job to cron scheduler")
+	}
+	_, err = c.AddFunc(utils.GetOptionalIdFromDB(bot, "QUIZ_SEASON_CRON_SPEC", "5 0 * * *"), func() { runSeasonJob(bot) })
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "questions.go", true, err, "Error adding quiz season job to cron scheduler")
+	}
 	c.Start()
 }
 
 func postDailyQuiz(bot *discordgo.Session) {
 	chID := utils.GetIdFromDB(bot, "CHANNEL_QUIZ_ID")
 	today := time.Now().Format("2006-01-02")
-	q := struct {
-		ID       int
-		Question string
-		A1, A2, A3 string
-	}{ }
-	err := database.DB.QueryRow(
-		`SELECT id, question, answer1, answer2, answer3 FROM quiz_questions WHERE scheduled_date = ?`,
-		today,
-	).Scan(&q.ID, &q.Question, &q.A1, &q.A2, &q.A3)
+
+	// Geplante Frage für heute, sonst (falls aktiviert) automatisch aus dem Pool
+	service := quizService.NewQuestionService(bot)
+	q, err := service.PickForDate(today, quizPickOptions(bot))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			utils.LogAndNotifyAdmins(bot, "info", "Info", "questions.go", false, nil, "No quiz question scheduled for today")
+		if err == quizService.ErrNotFound {
+			utils.LogAndNotifyAdmins(bot, "warn", "Warnung", "questions.go", true, nil, "Für heute ist keine Quiz-Frage geplant, es wurde kein Quiz gepostet")
+		} else {
+			utils.LogAndNotifyAdmins(bot, "high", "Error", "questions.go", true, err, "Error loading today's quiz question")
 		}
 		return
 	}
+
+	// Offene Quizze vom Vortag vor dem neuen Post auflösen
+	revealDuePosts(bot, time.Now().Add(365*24*time.Hour))
+
 	msgs, _ := bot.ChannelMessages(chID, 10, "", "", "")
 	roleID := utils.GetIdFromDB(bot, "ROLE_QUIZ")
 	for _, m := range msgs {
@@ -58,22 +73,29 @@ func postDailyQuiz(bot *discordgo.Session) {
 		bot.ChannelMessageSend(chID, fmt.Sprintf("<@&%s>", roleID))
 	}
 
+	postedAt := time.Now()
+	window := answerWindow(bot, q)
+
 	// build embed and select
 	emb := &discordgo.MessageEmbed{
 		Title:       "Quiz des Tages",
 		Description: "## " + q.Question,
 		Color:       0xff0000, // Rot
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "⏰ Antworten bis", Value: fmt.Sprintf("<t:%d:f> (<t:%d:R>)", postedAt.Add(window).Unix(), postedAt.Add(window).Unix())},
+		},
+	}
+	if q.ImageURL != "" {
+		emb.Image = &discordgo.MessageEmbedImage{URL: q.ImageURL}
 	}
 	sel := discordgo.SelectMenu{
 		CustomID: fmt.Sprintf("quiz_answer_%d", q.ID),
 		Placeholder: "Wähle deine Antwort",
-		Options: []discordgo.SelectMenuOption{
-			{Label: q.A1, Value: "1"},
-			{Label: q.A2, Value: "2"},
-			{Label: q.A3, Value: "3"},
-		},
 	}
-	_, err = bot.ChannelMessageSendComplex(chID, &discordgo.MessageSend{
+	for i, answer := range q.Answers {
+		sel.Options = append(sel.Options, discordgo.SelectMenuOption{Label: answer, Value: strconv.Itoa(i + 1)})
+	}
+	msg, err := bot.ChannelMessageSendComplex(chID, &discordgo.MessageSend{
 		Embeds: []*discordgo.MessageEmbed{emb},
 		Components: []discordgo.MessageComponent{
 			discordgo.ActionsRow{Components: []discordgo.MessageComponent{sel}},
@@ -81,7 +103,27 @@ func postDailyQuiz(bot *discordgo.Session) {
 	})
 	if err != nil {
 		utils.LogAndNotifyAdmins(bot, "high", "Error", "questions.go", true, err, "Error sending daily quiz message")
+		return
+	}
+
+	if err := service.MarkAsked(q.ID); err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "questions.go", true, err, "Error marking quiz question as asked")
+	}
+	if _, err := service.CreatePost(q.ID, chID, msg.ID, postedAt, window); err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "questions.go", true, err, "Error saving quiz post")
+	}
+}
+
+// answerWindow liefert das Antwortfenster der Frage oder den Standard aus QUIZ_ANSWER_WINDOW_MINUTES
+func answerWindow(bot *discordgo.Session, q *quizService.Question) time.Duration {
+	minutes := q.AnswerWindow
+	if minutes <= 0 {
+		minutes, _ = strconv.Atoi(utils.GetOptionalIdFromDB(bot, "QUIZ_ANSWER_WINDOW_MINUTES", "1380"))
 	}
+	if minutes <= 0 {
+		minutes = 1380
+	}
+	return time.Duration(minutes) * time.Minute
 }
 
 func HandleAnswerSelect(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
@@ -105,12 +147,42 @@ func HandleAnswerSelect(bot *discordgo.Session, bot_interaction *discordgo.Inter
 		return
 	}
 
+	// Aktuellen Post der Frage laden (fehlt bei Quizzen von vor der Einführung der Deadline)
+	var postID interface{}
+	post, err := quizService.NewQuestionService(bot).LatestPostForQuestion(qid)
+	if err != nil && err != quizService.ErrNotFound {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "questions.go", true, err, "Error loading quiz post")
+	}
+	if post != nil {
+		postID = post.ID
+		if post.Revealed || time.Now().Unix() > post.Deadline {
+			bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "⏰ Die Antwortzeit für dieses Quiz ist leider abgelaufen.",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+	}
+
 	// 2) Sicherstellen, dass der User nicht schon geantwortet hat
+	// (nur seit dem letzten Posten, da Fragen aus dem Pool wiederverwendet werden können)
 	var exists int
-	err = database.DB.QueryRow(
-		`SELECT 1 FROM quiz_responses WHERE user_id = ? AND question_id = ?`, 
-		uid, qid,
-	).Scan(&exists)
+	if post != nil {
+		err = database.DB.QueryRow(
+			`SELECT 1 FROM quiz_responses WHERE user_id = ? AND post_id = ?`,
+			uid, post.ID,
+		).Scan(&exists)
+	} else {
+		err = database.DB.QueryRow(
+			`SELECT 1 FROM quiz_responses
+			WHERE user_id = ? AND question_id = ?
+			AND answered_at >= (SELECT COALESCE(asked_at, '') FROM quiz_questions WHERE id = ?)`,
+			uid, qid, qid,
+		).Scan(&exists)
+	}
 	if err != sql.ErrNoRows {
 		bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -134,12 +206,18 @@ func HandleAnswerSelect(bot *discordgo.Session, bot_interaction *discordgo.Inter
 		isCorrect = 1
 	}
 	_, err = database.DB.Exec(
-		`INSERT INTO quiz_responses (user_id, question_id, selected, correct, answered_at)
-		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)`,
-		uid, qid, sel, isCorrect,
+		`INSERT INTO quiz_responses (user_id, question_id, selected, correct, answered_at, post_id)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, ?)`,
+		uid, qid, sel, isCorrect, postID,
 	)
 	if err != nil {
 		utils.LogAndNotifyAdmins(bot, "low", "Error", "questions.go", true, err, "Error saving quiz response")
+	} else {
+		events.Publish(events.TypeQuizAnswer, map[string]interface{}{
+			"user_id":     bot_interaction.Member.User.ID,
+			"question_id": qid,
+			"correct":     isCorrect == 1,
+		})
 	}
 
 	// 5) Ephemeral Feedback
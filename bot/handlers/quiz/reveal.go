@@ -0,0 +1,101 @@
+package quiz
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	quizService "bot/services/quiz"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// revealDuePosts löst alle Quizze auf, deren Deadline vor until liegt
+func revealDuePosts(bot *discordgo.Session, until time.Time) {
+	service := quizService.NewQuestionService(bot)
+	posts, err := service.UnrevealedPosts(until)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "reveal.go", true, err, "Error loading quiz posts to reveal")
+		return
+	}
+
+	for _, post := range posts {
+		revealPost(bot, service, post)
+	}
+}
+
+// revealPost ersetzt das Tagesquiz durch die Auflösung mit Antwortverteilung
+func revealPost(bot *discordgo.Session, service *quizService.QuestionService, post quizService.Post) {
+	q, err := service.Get(post.QuestionID)
+	if err != nil {
+		// Frage wurde gelöscht, Post trotzdem abschließen
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "reveal.go", false, err, fmt.Sprintf("Quiz question %d for post %d not found", post.QuestionID, post.ID))
+		service.MarkRevealed(post.ID)
+		return
+	}
+
+	result, err := service.PostResult(post.ID, len(q.Answers))
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "reveal.go", true, err, "Error loading quiz results")
+		return
+	}
+
+	correctAnswer := "-"
+	if q.Correct >= 1 && q.Correct <= len(q.Answers) {
+		correctAnswer = q.Answers[q.Correct-1]
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Auflösung: Quiz vom " + time.Unix(post.PostedAt, 0).Format("02.01.2006"),
+		Description: "## " + q.Question,
+		Color:       utils.ColorSuccess,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "✅ Richtige Antwort", Value: correctAnswer},
+			{Name: "📊 Verteilung", Value: formatDistribution(q, result)},
+			{Name: "👥 Teilnehmer", Value: fmt.Sprintf("%d (%d richtig)", result.Participants, result.Correct), Inline: true},
+		},
+	}
+	if q.Explanation != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "💡 Erklärung", Value: q.Explanation})
+	}
+	if q.ImageURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: q.ImageURL}
+	}
+
+	// Select entfernen, damit keine Antworten mehr möglich sind
+	components := []discordgo.MessageComponent{}
+	_, err = bot.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         post.MessageID,
+		Channel:    post.ChannelID,
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	})
+	if err != nil {
+		// Nachricht wurde evtl. gelöscht, Auflösung nicht endlos wiederholen
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "reveal.go", true, err, fmt.Sprintf("Error editing quiz message for post %d", post.ID))
+	}
+
+	if err := service.MarkRevealed(post.ID); err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "reveal.go", true, err, "Error marking quiz post as revealed")
+	}
+}
+
+// formatDistribution baut einen Balken pro Antwort
+func formatDistribution(q *quizService.Question, result *quizService.Result) string {
+	var b strings.Builder
+	for i, answer := range q.Answers {
+		count := result.Distribution[i]
+		percent := 0.0
+		if result.Participants > 0 {
+			percent = float64(count) / float64(result.Participants) * 100
+		}
+		marker := "▫️"
+		if i+1 == q.Correct {
+			marker = "✅"
+		}
+		bar := strings.Repeat("█", int(percent/10)) + strings.Repeat("░", 10-int(percent/10))
+		fmt.Fprintf(&b, "%s %s\n`%s` %d (%.0f%%)\n", marker, answer, bar, count, percent)
+	}
+	return b.String()
+}
@@ -0,0 +1,282 @@
+package quiz
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	quizService "bot/services/quiz"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Standardanzahl der Quiz Champions, falls QUIZ_CHAMPION_TOP_N fehlt
+const defaultChampionCount = 3
+
+// runSeasonJob schließt beendete Saisons ab und legt bei Bedarf die Monats-Saison an
+func runSeasonJob(bot *discordgo.Session) {
+	service := quizService.NewQuestionService(bot)
+
+	seasons, err := service.EndedSeasons(time.Now())
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "seasons.go", true, err, "Error loading ended quiz seasons")
+		return
+	}
+	for _, season := range seasons {
+		finalizeSeason(bot, service, season)
+	}
+
+	if _, err := currentSeason(bot, service); err != nil && err != quizService.ErrNotFound {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "seasons.go", true, err, "Error creating monthly quiz season")
+	}
+}
+
+// currentSeason liefert die laufende Saison und legt bei QUIZ_MONTHLY_SEASONS (Standard true) die Monats-Saison an
+func currentSeason(bot *discordgo.Session, service *quizService.QuestionService) (*quizService.Season, error) {
+	monthly := utils.GetOptionalIdFromDB(bot, "QUIZ_MONTHLY_SEASONS", "true")
+	if monthly == "true" || monthly == "1" {
+		return service.EnsureMonthlySeason(time.Now())
+	}
+	return service.SeasonForDate(time.Now())
+}
+
+// finalizeSeason vergibt die Champion-Rolle an die Top N und postet den Saison-Rückblick
+func finalizeSeason(bot *discordgo.Session, service *quizService.QuestionService, season quizService.Season) {
+	topN, err := strconv.Atoi(utils.GetOptionalIdFromDB(bot, "QUIZ_CHAMPION_TOP_N", strconv.Itoa(defaultChampionCount)))
+	if err != nil || topN <= 0 {
+		topN = defaultChampionCount
+	}
+
+	leaderboard, err := getQuizLeaderboard(false, &season, 10)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "seasons.go", true, err, "Error loading quiz season leaderboard for "+season.Name)
+		return
+	}
+
+	var champions []quizService.Champion
+	for i, entry := range leaderboard {
+		if i >= topN {
+			break
+		}
+		champions = append(champions, quizService.Champion{DiscordID: strconv.FormatInt(entry.UserID, 10), Rank: i + 1, Score: entry.Score})
+	}
+
+	previous, err := service.PreviousChampions(season.ID)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "seasons.go", true, err, "Error loading previous quiz champions")
+	}
+
+	if err := service.FinalizeSeason(season.ID, champions); err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "seasons.go", true, err, "Error finalizing quiz season "+season.Name)
+		return
+	}
+
+	updateChampionRole(bot, previous, champions)
+	postSeasonRecap(bot, season, leaderboard, champions)
+}
+
+// updateChampionRole entfernt die Rolle bei den bisherigen Champions und vergibt sie an die neuen
+func updateChampionRole(bot *discordgo.Session, previous, champions []quizService.Champion) {
+	roleID := utils.GetOptionalIdFromDB(bot, "ROLE_QUIZ_CHAMPION", "")
+	if roleID == "" {
+		return
+	}
+	guildID := utils.GetIdFromDB(bot, "GUILD_ID")
+
+	stays := make(map[string]bool)
+	for _, champion := range champions {
+		stays[champion.DiscordID] = true
+	}
+	for _, champion := range previous {
+		if stays[champion.DiscordID] {
+			continue
+		}
+		if err := bot.GuildMemberRoleRemove(guildID, champion.DiscordID, roleID); err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "seasons.go", false, err, "Error removing quiz champion role from "+champion.DiscordID)
+		}
+	}
+	for _, champion := range champions {
+		if err := bot.GuildMemberRoleAdd(guildID, champion.DiscordID, roleID); err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "seasons.go", true, err, "Error adding quiz champion role to "+champion.DiscordID)
+		}
+	}
+}
+
+func postSeasonRecap(bot *discordgo.Session, season quizService.Season, leaderboard []LeaderboardEntry, champions []quizService.Champion) {
+	chID := utils.GetIdFromDB(bot, "CHANNEL_QUIZ_ID")
+
+	embed := &discordgo.MessageEmbed{
+		Title: "🏁 " + season.Name + " ist beendet!",
+		Color: utils.ColorGold,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%s bis %s", season.StartDate, season.EndDate),
+		},
+	}
+
+	if len(leaderboard) == 0 {
+		embed.Description = "In dieser Saison wurden keine Quiz-Fragen beantwortet."
+	} else {
+		var mentions []string
+		for _, champion := range champions {
+			mentions = append(mentions, fmt.Sprintf("<@%s>", champion.DiscordID))
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "Glückwunsch an unsere Quiz Champions: %s 🎉\n\n", strings.Join(mentions, ", "))
+		medals := []string{"🥇", "🥈", "🥉"}
+		for i, entry := range leaderboard {
+			rank := fmt.Sprintf("`%2d.`", i+1)
+			if i < len(medals) {
+				rank = medals[i]
+			}
+			fmt.Fprintf(&b, "%s <@%d> - %d/%d richtig | Score: %.1f\n", rank, entry.UserID, entry.CorrectAnswers, entry.TotalQuestions, entry.Score)
+		}
+		embed.Description = b.String()
+	}
+
+	_, err := bot.ChannelMessageSendComplex(chID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		// Nur die Champions pingen, nicht alle im Rückblick
+		AllowedMentions: &discordgo.MessageAllowedMentions{Users: championIDs(champions)},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "seasons.go", true, err, "Error sending quiz season recap")
+	}
+}
+
+func championIDs(champions []quizService.Champion) []string {
+	ids := make([]string, 0, len(champions))
+	for _, champion := range champions {
+		ids = append(ids, champion.DiscordID)
+	}
+	return ids
+}
+
+// resolveSeason liefert die Saison zur ID, 0 steht für die aktuelle Saison
+func resolveSeason(bot *discordgo.Session, id int) (*quizService.Season, error) {
+	service := quizService.NewQuestionService(bot)
+	if id == 0 {
+		return currentSeason(bot, service)
+	}
+	return service.GetSeason(id)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleSeasonAutocomplete schlägt Saisons für die Option "season" vor
+func HandleSeasonAutocomplete(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	var input string
+	for _, opt := range bot_interaction.ApplicationCommandData().Options {
+		if opt.Name == "season" && opt.Focused {
+			input = strings.ToLower(fmt.Sprint(opt.Value))
+		}
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{{Name: "Aktuelle Saison", Value: 0}}
+	seasons, err := quizService.NewQuestionService(bot).ListSeasons(24)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "seasons.go", false, err, "Error loading quiz seasons for autocomplete")
+	}
+	for _, season := range seasons {
+		if input != "" && !strings.Contains(strings.ToLower(season.Name), input) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  fmt.Sprintf("%s (%s bis %s)", season.Name, season.StartDate, season.EndDate),
+			Value: season.ID,
+		})
+	}
+
+	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleQuizStats behandelt /quiz_stats [user] und zeigt die persönliche Quiz-Karte
+func HandleQuizStats(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	user := bot_interaction.Member.User
+	for _, opt := range bot_interaction.ApplicationCommandData().Options {
+		if opt.Name == "user" {
+			user = opt.UserValue(bot)
+		}
+	}
+
+	uid, err := utils.EnsureUser(bot, user.ID)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "seasons.go", true, err, "Error ensuring user for quiz stats")
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Die Statistiken konnten nicht geladen werden.", true)
+		return
+	}
+
+	service := quizService.NewQuestionService(bot)
+	streaks, err := service.UserStreaks(uid)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "seasons.go", true, err, "Error loading quiz streaks")
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Die Statistiken konnten nicht geladen werden.", true)
+		return
+	}
+
+	allTime := findLeaderboardEntry(nil, user.ID)
+	season, err := currentSeason(bot, service)
+	if err != nil && err != quizService.ErrNotFound {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "seasons.go", false, err, "Error loading current quiz season")
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:     "🧠 Quiz-Statistiken von " + user.Username,
+		Color:     utils.ColorGold,
+		Thumbnail: &discordgo.MessageEmbedThumbnail{URL: user.AvatarURL("128")},
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "🏆 Gesamt", Value: formatStatsEntry(allTime), Inline: false},
+		},
+	}
+	if season != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "📅 " + season.Name,
+			Value: formatStatsEntry(findLeaderboardEntry(season, user.ID)),
+		})
+	}
+	embed.Fields = append(embed.Fields,
+		&discordgo.MessageEmbedField{Name: "🔥 Teilnahme-Serie", Value: fmt.Sprintf("%d Tage (Rekord: %d)", streaks.CurrentParticipation, streaks.BestParticipation), Inline: true},
+		&discordgo.MessageEmbedField{Name: "✅ Richtig-Serie", Value: fmt.Sprintf("%d Tage (Rekord: %d)", streaks.CurrentCorrect, streaks.BestCorrect), Inline: true},
+	)
+
+	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+type rankedEntry struct {
+	LeaderboardEntry
+	Rank int
+}
+
+// findLeaderboardEntry sucht den User im (Saison-)Leaderboard und liefert seinen Platz
+func findLeaderboardEntry(season *quizService.Season, discordID string) *rankedEntry {
+	leaderboard, err := getQuizLeaderboard(false, season, -1)
+	if err != nil {
+		return nil
+	}
+	for i, entry := range leaderboard {
+		if strconv.FormatInt(entry.UserID, 10) == discordID {
+			return &rankedEntry{LeaderboardEntry: entry, Rank: i + 1}
+		}
+	}
+	return nil
+}
+
+func formatStatsEntry(entry *rankedEntry) string {
+	if entry == nil {
+		return "Noch keine Antworten"
+	}
+	return fmt.Sprintf("Platz **%d** | %d/%d richtig (%.1f%%) | Score: %.1f",
+		entry.Rank, entry.CorrectAnswers, entry.TotalQuestions, entry.AccuracyRate, entry.Score)
+}
@@ -8,6 +8,11 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
+// Die Umfrage "Woher kennst du uns?" wird einmal pro User beantwortet (Grundlage der Weekly Updates),
+// eine erneute Antwort überschreibt die vorherige. Die Zufriedenheit mit Tickets liegt getrennt in ticket_feedback.
+const upsertOriginAnswer = `
+	INSERT INTO survey_answers (user_id, username, answer, timestamp) VALUES (?, ?, ?, ?)
+	ON CONFLICT(user_id) DO UPDATE SET username = excluded.username, answer = excluded.answer, timestamp = excluded.timestamp`
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
@@ -48,8 +53,7 @@ func HandleSurveyDropdown(bot *discordgo.Session, bot_interaction *discordgo.Int
 			log.Println("Fehler: Benutzerinformationen nicht verfügbar")
 			return
 		}
-		_, err := database.DB.Exec("INSERT INTO survey_answers (user_id, username, answer, timestamp) VALUES (?, ?, ?, ?)",
-			userID, username, selected, time.Now().Unix())
+		_, err := database.DB.Exec(upsertOriginAnswer, userID, username, selected, time.Now().Unix())
 		if err != nil {
 			log.Println("Fehler beim Speichern der Umfrageantwort:", err)
 		}
@@ -90,7 +94,7 @@ func HandleSurveyModalSubmit(bot *discordgo.Session, bot_interaction *discordgo.
         }
         for _, component := range row.Components {
             input, ok := component.(*discordgo.TextInput)
-            if ok && input.CustomID == "custom_answer" {
+            if ok && input.CustomID == "ticket_after_custom_answer" {
                 customAnswer = input.Value
             }
         }
@@ -112,8 +116,7 @@ func HandleSurveyModalSubmit(bot *discordgo.Session, bot_interaction *discordgo.
         return
     }
 
-    _, err := database.DB.Exec("INSERT INTO survey_answers (user_id, username, answer, timestamp) VALUES (?, ?, ?, ?)",
-        userID, username, customAnswer, time.Now().Unix())
+    _, err := database.DB.Exec(upsertOriginAnswer, userID, username, customAnswer, time.Now().Unix())
     if err != nil {
         log.Println("Fehler beim Speichern der Umfrageantwort:", err)
     }
@@ -1,12 +1,12 @@
 package tickets
 
 import (
+	"errors"
 	"fmt"
-	"time"
 	"strconv"
-	"strings"
 
 	"bot/database"
+	ticketService "bot/services/tickets"
 	"bot/utils"
 
 	"github.com/bwmarrin/discordgo"
@@ -14,332 +14,139 @@ import (
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
-// HandleAssignButton zeigt ein Modal zur Eingabe des Benutzernamens an
+// HandleAssignButton zeigt ephemer eine User-Auswahl zum Zuweisen an
 func HandleAssignButton(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
-	ticketID, err := GetTicketIDFromInteraction(bot, bot_interaction)
-	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_assign.go", true, err, "Fehler beim Abrufen der Ticket-ID aus der Interaktion")
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "mod_assign.go")
+	if !ok {
+		return
+	}
+	if !ticket.Status.Can(ticketService.ActionAssign) {
+		handleTransitionError(bot, bot_interaction, "mod_assign.go", ticket, ticketService.ErrInvalidTransition)
 		return
 	}
 
-	// Modal für User-Eingabe anzeigen
-	modal := &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseModal,
+	err := bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			CustomID: fmt.Sprintf("ticket_assign_modal_%d", ticketID),
-			Title:    "Ticket zuweisen",
+			Content: fmt.Sprintf("Wem soll Ticket #%d zugewiesen werden?", ticket.ID),
+			Flags:   discordgo.MessageFlagsEphemeral,
 			Components: []discordgo.MessageComponent{
 				discordgo.ActionsRow{
 					Components: []discordgo.MessageComponent{
-						discordgo.TextInput{
-							CustomID:    "assign_username",
-							Label:       "Username oder Display Name eingeben",
-							Style:       discordgo.TextInputShort,
-							Placeholder: "z.B. MaxMustermann oder Max",
-							Required:    true,
-							MaxLength:   100,
+						discordgo.SelectMenu{
+							MenuType:    discordgo.UserSelectMenu,
+							CustomID:    ticketCustomID("ticket_select_assignee", ticket.ID),
+							Placeholder: "Teammitglied auswählen",
 						},
 					},
 				},
 			},
 		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_assign.go", true, err, "Fehler beim Anzeigen der User-Auswahl für Ticket #"+strconv.Itoa(ticket.ID))
 	}
-
-	bot.InteractionRespond(bot_interaction.Interaction, modal)
 }
 
-/*--------------------------------------------------------------------------------------------------------------------------*/
-
-// HandleAssignModal verarbeitet die Modal-Eingabe und sucht nach passenden Usern
-func HandleAssignModal(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
-	// Ticket-ID aus CustomID extrahieren
-	customID := bot_interaction.ModalSubmitData().CustomID
-	ticketIDStr := strings.TrimPrefix(customID, "ticket_assign_modal_")
-	ticketID, err := strconv.Atoi(ticketIDStr)
-	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_assign.go", true, err, "Fehler beim Parsen der Ticket-ID aus der Modal CustomID")
+// HandleAssigneeSelect weist das Ticket dem ausgewählten Teammitglied zu
+func HandleAssigneeSelect(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "mod_assign.go")
+	if !ok {
 		return
 	}
-
-	// Eingabe aus Modal abrufen
-	username := bot_interaction.ModalSubmitData().Components[0].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value
-
-	// User in Datenbank suchen (sowohl username als auch display_name)
-	var discordID, displayName string
-	err = database.DB.QueryRow(`
-		SELECT discord_id, display_name 
-		FROM users 
-		WHERE role_management = true 
-		AND (LOWER(username) LIKE LOWER(?) OR LOWER(display_name) LIKE LOWER(?))
-		LIMIT 1`,
-		"%"+username+"%", "%"+username+"%").Scan(&discordID, &displayName)
-
-	if err != nil {
-		// Keine exakte Übereinstimmung - zeige ähnliche User
-		rows, err := database.DB.Query(`
-			SELECT discord_id, display_name 
-			FROM users 
-			WHERE role_management = true 
-			AND (LOWER(username) LIKE LOWER(?) OR LOWER(display_name) LIKE LOWER(?))
-			LIMIT 10`,
-			"%"+username+"%", "%"+username+"%")
-		
-		if err != nil {
-			bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "Fehler beim Suchen des Users.",
-					Flags:   discordgo.MessageFlagsEphemeral,
-				},
-			})
-			return
-		}
-		defer rows.Close()
-
-		var suggestions []discordgo.SelectMenuOption
-		for rows.Next() {
-			var sugDiscordID, sugDisplayName string
-			rows.Scan(&sugDiscordID, &sugDisplayName)
-			suggestions = append(suggestions, discordgo.SelectMenuOption{
-				Label: sugDisplayName,
-				Value: sugDiscordID,
-			})
-		}
-
-		if len(suggestions) == 0 {
-			bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: fmt.Sprintf("Kein Management-User mit '%s' gefunden.", username),
-					Flags:   discordgo.MessageFlagsEphemeral,
-				},
-			})
-			return
-		}
-
-		// Ähnliche User zur Auswahl anzeigen
-		components := []discordgo.MessageComponent{
-			discordgo.ActionsRow{
-				Components: []discordgo.MessageComponent{
-					&discordgo.SelectMenu{
-						CustomID:    fmt.Sprintf("ticket_assign_suggestions_%d", ticketID),
-						Placeholder: "Meintest du einen dieser User?",
-						Options:     suggestions,
-					},
-				},
-			},
-		}
-
-		bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content:    fmt.Sprintf("Mehrere User mit '%s' gefunden:", username),
-				Flags:      discordgo.MessageFlagsEphemeral,
-				Components: components,
-			},
-		})
+	data := bot_interaction.MessageComponentData()
+	if len(data.Values) == 0 {
 		return
 	}
+	userID := data.Values[0]
 
-	// Exakte Übereinstimmung - direkt zuweisen
-	assignTicketToUser(bot, bot_interaction, ticketID, discordID, displayName)
-}
-
-/*--------------------------------------------------------------------------------------------------------------------------*/
-
-// HandleAssignSuggestions verarbeitet die Auswahl aus dem Suggestions-Dropdown
-func HandleAssignSuggestions(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, customID string) {
-	// Ticket-ID extrahieren
-	ticketIDStr := strings.TrimPrefix(customID, "ticket_assign_suggestions_")
-	ticketID, err := strconv.Atoi(ticketIDStr)
-	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_assign.go", true, err, "Fehler beim Parsen der Ticket-ID aus dem Suggestions CustomID")
-		return
+	// Nur Management (oder höher) darf Tickets bearbeiten
+	member := data.Resolved.Members[userID]
+	if member != nil {
+		member.User = data.Resolved.Users[userID]
 	}
-
-	// Ausgewählten User abrufen
-	selectedDiscordID := bot_interaction.MessageComponentData().Values[0]
-	
-	// Display Name aus DB abrufen
-	var displayName string
-	err = database.DB.QueryRow("SELECT display_name FROM users WHERE discord_id = ?", selectedDiscordID).Scan(&displayName)
-	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_assign.go", true, err, "Fehler beim Abrufen des Display Names für Discord ID: " + selectedDiscordID)
+	if member == nil || member.User == nil || member.User.Bot || !utils.HasRequiredRole(bot, member, utils.RequireRoleManagement) {
+		utils.SendWarningEmbed(bot, bot_interaction, "Nicht möglich", fmt.Sprintf("<@%s> gehört nicht zum Team und kann das Ticket nicht bearbeiten.", userID), true)
 		return
 	}
 
-	assignTicketToUser(bot, bot_interaction, ticketID, selectedDiscordID, displayName)
-}
-
-/*--------------------------------------------------------------------------------------------------------------------------*/
-
-// assignTicketToUser führt die tatsächliche Zuweisung des Tickets durch
-func assignTicketToUser(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, ticketID int, discordID, displayName string) {
-	// Ticket in der Datenbank aktualisieren
-	_, err := database.DB.Exec(`
-		UPDATE tickets 
-		SET ticket_bearbeiter_id = ?, ticket_bearbeiter_name = ?, ticket_bearbeitungszeit = ?, ticket_status = "Claimed"
-		WHERE ticket_id = ?`,
-		discordID, displayName, time.Now().Unix(), ticketID)
+	actor := ticketService.Actor{ID: bot_interaction.Member.User.ID, Name: bot_interaction.Member.User.Username}
+	assignee := ticketService.Actor{ID: userID, Name: assignableName(userID, member.User.Username)}
+	reason := "Manuell zugewiesen von " + actor.Name
+	updated, err := ticketService.NewTicketService(bot).AssignWithReason(ticket.ID, actor, assignee, ticketService.AssignManual, reason)
 	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "mod_assign.go", true, err, "Fehler beim Aktualisieren des Tickets in der Datenbank")
+		handleTransitionError(bot, bot_interaction, "mod_assign.go", updated, err)
 		return
 	}
 
-	// Ticket-Informationen abrufen
-	ticket_db_info := getTicketDbInfo(bot, ticketID)
-
-	// Kanal aktualisieren
-	bot.ChannelEdit(bot_interaction.ChannelID, &discordgo.ChannelEdit{
-		Name:  fmt.Sprintf("%d-claimed-%s-%s", ticketID, ticket_db_info[5], ticket_db_info[8]),
-		Topic: fmt.Sprintf("Ticket #%d - Status: Claimed - Ticket von <@%s> - Ticket Bearbeiter <@%s>", ticketID, ticket_db_info[4], discordID),
-	})
-
-	// Bestätigungsnachricht
 	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Type: discordgo.InteractionResponseUpdateMessage,
 		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("Ticket #%d erfolgreich an %s zugewiesen.", ticketID, displayName),
-			Flags:   discordgo.MessageFlagsEphemeral,
+			Content:    fmt.Sprintf("Ticket #%d erfolgreich an <@%s> zugewiesen.", ticket.ID, userID),
+			Components: []discordgo.MessageComponent{},
 		},
 	})
+	announceAssignment(bot, updated, ticketChannelID(updated, bot_interaction), assignee, reason, actor.ID)
+}
 
-	// Nachricht im Kanal senden
-	_, err = bot.ChannelMessageSend(bot_interaction.ChannelID, fmt.Sprintf("Das Ticket #%d wurde von <@%s> geclaimt.", ticketID, discordID))
-	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_assign.go", true, err, "Fehler beim Senden der Benachrichtigung über den User dem das Ticket zugewiesen wurde in Ticket #" + strconv.Itoa(ticketID))
-	}
-
-	// Embed für Moderation Panel aktualisieren
-	embed := &discordgo.MessageEmbed{
-		Title: fmt.Sprintf("Ticket #%d Moderation", ticketID),
-		Fields: []*discordgo.MessageEmbedField{
-			{Name: "Erstellt von", Value: ticket_db_info[5], Inline: true},
-			{Name: "Status", Value: "Claimed", Inline: true},
-			{Name: "Zugewiesen an", Value: displayName, Inline: true},
-		},
-		Color: 0xFFD700, // Goldfarbe
-	}
-
-	// Buttons aktualisieren (Claim und Assign deaktivieren)
-	updatedComponents := []discordgo.MessageComponent{
-		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
-				&discordgo.Button{Style: discordgo.PrimaryButton, Label: "Claim", CustomID: "ticket_button_claim", Disabled: true},
-				&discordgo.Button{Style: discordgo.SecondaryButton, Label: "Close", CustomID: "ticket_button_close"},
-				&discordgo.Button{Style: discordgo.PrimaryButton, Label: "Assign", CustomID: "ticket_button_assign", Disabled: true},
-				&discordgo.Button{Style: discordgo.DangerButton, Label: "Delete", CustomID: "ticket_button_delete"},
-			},
-		},
-	}
-
-	// Moderation Panel aktualisieren (suche nach der Message mit den Buttons)
-	messages, err := bot.ChannelMessages(bot_interaction.ChannelID, 50, "", "", "")
-	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "mod_assign.go", true, err, "Fehler beim Abrufen der letzten Nachrichten im Kanal für Ticket #" + strconv.Itoa(ticketID) + " zur Aktualisierung des Moderation Panels")
-		return
-	}
-
-	for _, message := range messages {
-		if len(message.Components) > 0 && len(message.Embeds) > 0 {
-			// Prüfe ob es das Moderation Panel ist
-			if strings.Contains(message.Embeds[0].Title, "Moderation") {
-				bot.ChannelMessageEditComplex(&discordgo.MessageEdit{
-					ID:         message.ID,
-					Channel:    bot_interaction.ChannelID,
-					Embeds:     &[]*discordgo.MessageEmbed{embed},
-					Components: &updatedComponents,
-				})
-				break
-			}
-		}
+// assignableName liefert den Anzeigenamen aus users, falls der User dort bekannt ist
+func assignableName(discordID, fallback string) string {
+	var displayName string
+	if err := database.DB.QueryRow(`SELECT display_name FROM users WHERE discord_id = ?`, discordID).Scan(&displayName); err != nil || displayName == "" {
+		return fallback
 	}
+	return displayName
 }
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
-// HandleAssignTicketUpdate führt die Zuweisung durch (Rückwärtskompatibilität für bestehende Dropdown-Funktionalität)
-func HandleAssignTicketUpdate(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, CustomID string) {
-	var err error
-	if strings.HasPrefix(CustomID, "ticket_assign_suggestions_") {
-		HandleAssignSuggestions(bot, bot_interaction, CustomID)
+// autoAssignTicket verteilt ein neues Ticket nach der Regel seines Bereichs (ohne Regel passiert nichts)
+func autoAssignTicket(bot *discordgo.Session, ticketID int) {
+	ticket, assignment, err := ticketService.NewTicketService(bot).AutoAssign(ticketID)
+	if errors.Is(err, ticketService.ErrNoAssignee) {
+		utils.LogAndNotifyAdmins(bot, "info", "Info", "mod_assign.go", false, err, fmt.Sprintf("Ticket #%d konnte nicht automatisch zugewiesen werden", ticketID))
 		return
 	}
-	idString := strings.TrimPrefix(CustomID, "ticket_assign_ticket_dropdown_")
-	ids := strings.Split(idString, "_")
-	if len(ids) != 2 {
-		utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_assign.go", true, fmt.Errorf("invalid CustomID format: %s", CustomID), "Fehler beim Parsen der CustomID für die Ticket-Zuweisung")
-		return
-	}
-	ticketID, err := strconv.Atoi(ids[0])
 	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_assign.go", true, err, "Fehler beim Parsen der Ticket-ID aus der CustomID")
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_assign.go", true, err, fmt.Sprintf("Fehler bei der automatischen Zuweisung von Ticket #%d", ticketID))
 		return
 	}
-	messageID := ids[1]
-	moderatorID := bot_interaction.MessageComponentData().Values[0]
-	moderatorUsername := GetUsernameByID(bot, moderatorID)
-	ticket_db_info := getTicketDbInfo(bot, ticketID)
-	_, err = database.DB.Exec(`
-		UPDATE tickets 
-		SET ticket_bearbeiter_id = ?, ticket_bearbeiter_name = ?, ticket_bearbeitungszeit = ?, ticket_status = "Claimed"
-		WHERE ticket_id = ?`,
-		moderatorID, moderatorUsername, time.Now().Unix(), ticketID)
-	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "mod_assign.go", true, err, "Fehler beim Aktualisieren des Tickets in der Datenbank")
+	if assignment == nil {
 		return
 	}
+	assignee := ticketService.Actor{ID: assignment.AssigneeID, Name: assignment.AssigneeName}
+	announceAssignment(bot, ticket, ticket.ChannelID, assignee, assignment.Reason, "")
+}
+
+// announceAssignment benennt den Channel um, informiert im Ticket, aktualisiert das Panel und schreibt dem Bearbeiter
+func announceAssignment(bot *discordgo.Session, ticket *ticketService.Ticket, channelID string, assignee ticketService.Actor, reason, actorID string) {
+	publishTicketStatus(ticket.ID, string(ticket.Status), actorID)
 
-	// Kanal aktualisieren
-	bot.ChannelEdit(bot_interaction.ChannelID, &discordgo.ChannelEdit{
-		Name:  fmt.Sprintf("%d-claimed-%s-%s", ticketID, ticket_db_info[5], ticket_db_info[8]),
-		Topic: fmt.Sprintf("Ticket #%d - Status: Claimed - Ticket von <@%s> - Ticket Bearbeiter <@%s>", ticketID, ticket_db_info[4], moderatorID),
+	bot.ChannelEdit(channelID, &discordgo.ChannelEdit{
+		Name:  fmt.Sprintf("%d-claimed-%s-%s", ticket.ID, ticket.CreatorName, ticket.ClaimerName),
+		Topic: fmt.Sprintf("Ticket #%d - Status: Claimed - Ticket von <@%s> - Ticket Bearbeiter <@%s>", ticket.ID, ticket.CreatorID, assignee.ID),
 	})
 
-	// Nachricht senden, um den Benutzer über den neuen Status zu informieren
-	_, err = bot.ChannelMessageSend(bot_interaction.ChannelID, fmt.Sprintf("Das Ticket #%d wurde von <@%s> geclaimt.", ticketID, moderatorID))
+	_, err := bot.ChannelMessageSend(channelID, fmt.Sprintf("Das Ticket #%d wurde <@%s> zugewiesen.", ticket.ID, assignee.ID))
 	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_assign.go", true, err, "Fehler beim Senden der Benachrichtigung über den User dem das Ticket zugewiesen wurde in Ticket #" + strconv.Itoa(ticketID))
-	}
-
-	// Embed aktualisieren
-	embed := &discordgo.MessageEmbed{
-		Title: fmt.Sprintf("Ticket #%d Moderation", ticketID),
-		Fields: []*discordgo.MessageEmbedField{
-			{Name: "Erstellt von", Value: ticket_db_info[5], Inline: true},
-			{Name: "Status", Value: "Claimed", Inline: true},
-		},
-		Color: 0xFFD700, // Goldfarbe
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_assign.go", true, err, "Fehler beim Senden der Benachrichtigung über den User dem das Ticket zugewiesen wurde in Ticket #"+strconv.Itoa(ticket.ID))
+	}
+	updateModerationPanel(bot, channelID, ticket)
+
+	// Der Bearbeiter bekommt eine DM mit Link und Begründung, geschlossene DMs sind kein Fehler
+	dmChannel, err := bot.UserChannelCreate(assignee.ID)
+	if err == nil {
+		_, err = bot.ChannelMessageSendEmbed(dmChannel.ID, &discordgo.MessageEmbed{
+			Title:       fmt.Sprintf("Ticket #%d wurde dir zugewiesen", ticket.ID),
+			Description: fmt.Sprintf("<#%s>\nVon <@%s> im Bereich `%s`", channelID, ticket.CreatorID, ticket.Area),
+			Fields:      []*discordgo.MessageEmbedField{{Name: "Grund", Value: reason}},
+			Color:       utils.ColorInfo,
+			URL:         fmt.Sprintf("https://discord.com/channels/%s/%s", utils.GetIdFromDB(bot, "GUILD_ID"), channelID),
+		})
 	}
-
-	// Buttons aktualisieren (Claim und Assign deaktivieren)
-	updatedComponents := []discordgo.MessageComponent{
-		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
-				&discordgo.Button{Style: discordgo.PrimaryButton, Label: "Claim", CustomID: "ticket_button_claim", Disabled: true},
-				&discordgo.Button{Style: discordgo.SecondaryButton, Label: "Close", CustomID: "ticket_button_close"},
-				&discordgo.Button{Style: discordgo.PrimaryButton, Label: "Assign", CustomID: "ticket_button_assign", Disabled: true},
-				&discordgo.Button{Style: discordgo.DangerButton, Label: "Delete", CustomID: "ticket_button_delete"},
-			},
-		},
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Info", "mod_assign.go", false, err, "DM an den Bearbeiter von Ticket #"+strconv.Itoa(ticket.ID)+" nicht möglich")
 	}
-
-	// View aktualisieren
-	bot.ChannelMessageEditComplex(&discordgo.MessageEdit{
-		ID:         messageID,
-		Channel:    bot_interaction.ChannelID,
-		Embeds:     &[]*discordgo.MessageEmbed{embed},
-		Components: &updatedComponents,
-	})
-
-	// Nachricht zur Bestätigung senden
-	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseUpdateMessage,
-		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("Das Ticket wurde <@%s> zugewiesen.", moderatorID),
-			Flags:   discordgo.MessageFlagsEphemeral,
-		},
-	})
 }
 
-/*--------------------------------------------------------------------------------------------------------------------------*/
\ No newline at end of file
+/*--------------------------------------------------------------------------------------------------------------------------*/
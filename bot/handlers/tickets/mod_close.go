@@ -2,9 +2,8 @@ package tickets
 
 import (
 	"fmt"
-	"time"
 
-	"bot/database"
+	ticketService "bot/services/tickets"
 	"bot/utils"
 
 	"github.com/bwmarrin/discordgo"
@@ -13,62 +12,53 @@ import (
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
 func HandleCloseButton(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
-	ticketID, err := GetTicketIDFromInteraction(bot, bot_interaction)
-	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "mod_close.go", true, err, "Fehler beim Abrufen der Ticket-ID aus der Interaktion")
+	current, ok := loadTicketFromInteraction(bot, bot_interaction, "mod_close.go")
+	if !ok {
 		return
 	}
-	ticket_db_info := getTicketDbInfo(bot, ticketID)
+	ticketID := current.ID
+	channelID := ticketChannelID(current, bot_interaction)
 
-	_, err = database.DB.Exec(`
-		UPDATE tickets 
-		SET ticket_status = "Closed", ticket_schliesser_id = ?, ticket_schliesser_name = ?, ticket_schliesszeit = ?
-		WHERE ticket_id = ?`,
-		bot_interaction.Member.User.ID, bot_interaction.Member.User.Username, time.Now().Unix(), ticketID)
+	actor := ticketService.Actor{ID: bot_interaction.Member.User.ID, Name: bot_interaction.Member.User.Username}
+	ticket, err := closeTicket(bot, ticketID, channelID, actor, fmt.Sprintf("Das Ticket #%d wurde von <@%s> geschlossen.", ticketID, actor.ID))
 	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "mod_close.go", true, err, "Fehler beim Aktualisieren des Tickets in der Datenbank")
+		handleTransitionError(bot, bot_interaction, "mod_close.go", ticket, err)
 		return
 	}
 
-	bot.ChannelEdit(bot_interaction.ChannelID, &discordgo.ChannelEdit{
-		Name: fmt.Sprintf("%d-closed-%s-%s", ticketID, ticket_db_info[5], ticket_db_info[8]),
-		Topic: fmt.Sprintf("Ticket #%d - Status: Closed - Ticket von <@%s> - Ticket Bearbeiter <@%s> - Ticket geschlossen von <@%s>", ticketID, ticket_db_info[4], ticket_db_info[7], bot_interaction.Member.User.ID),
-	})
+	respondModerationPanel(bot, bot_interaction, ticket)
+}
 
-	removeUserChannelPermission(bot, bot_interaction.ChannelID, ticket_db_info[4])
+/*--------------------------------------------------------------------------------------------------------------------------*/
 
-	_, err = bot.ChannelMessageSend(bot_interaction.ChannelID, fmt.Sprintf("Das Ticket #%d wurde von <@%s> geschlossen.", ticketID, bot_interaction.Member.User.ID))
+// closeTicket schließt das Ticket, benennt den Channel um, entzieht Ersteller und Teilnehmern den Zugriff, postet notice
+// und schickt dem Ersteller die Zufriedenheits-Umfrage. Modmail-Ersteller werden zusätzlich per DM informiert.
+func closeTicket(bot *discordgo.Session, ticketID int, channelID string, actor ticketService.Actor, notice string) (*ticketService.Ticket, error) {
+	ticket, err := ticketService.NewTicketService(bot).Close(ticketID, actor)
 	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_close.go", true, err, "Fehler beim Senden der Benachrichtigung über den User der das Ticket geschlossen hat in Ticket #" + fmt.Sprint(ticketID))
+		return ticket, err
 	}
+	publishTicketStatus(ticketID, string(ticket.Status), actor.ID)
 
-	embed := &discordgo.MessageEmbed{
-		Title: fmt.Sprintf("Ticket #%d Moderation", ticketID),
-		Fields: []*discordgo.MessageEmbedField{
-			{Name: "Erstellt von", Value: ticket_db_info[5], Inline: true},
-			{Name: "Status", Value: "Closed", Inline: true},
-		},
-		Color: 0xFFD700, // Gold
+	bot.ChannelEdit(channelID, &discordgo.ChannelEdit{
+		Name: fmt.Sprintf("%d-closed-%s-%s", ticketID, ticket.CreatorName, ticket.ClaimerName),
+		Topic: fmt.Sprintf("Ticket #%d - Status: Closed - Ticket von <@%s> - Ticket Bearbeiter <@%s> - Ticket geschlossen von <@%s>", ticketID, ticket.CreatorID, ticket.ClaimerID, actor.ID),
+	})
+
+	if ticket.Modmail {
+		notifyModmailCreator(bot, ticket, "Ticket geschlossen", "Dein Ticket wurde geschlossen. Schreibst du mir erneut, wird ein neues Ticket eröffnet.")
+	} else {
+		removeUserChannelPermission(bot, channelID, ticket.CreatorID)
 	}
+	setParticipantAccess(bot, ticketID, channelID, false)
 
-	updatedComponents := []discordgo.MessageComponent{
-		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
-				&discordgo.Button{Style: discordgo.PrimaryButton, Label: "Claim", CustomID: "ticket_button_claim", Disabled: true},
-				&discordgo.Button{Style: discordgo.SecondaryButton, Label: "Reopen", CustomID: "ticket_button_reopen"},
-				&discordgo.Button{Style: discordgo.PrimaryButton, Label: "Assign", CustomID: "ticket_button_assign", Disabled: true},
-				&discordgo.Button{Style: discordgo.DangerButton, Label: "Delete", CustomID: "ticket_button_delete"},
-			},
-		},
+	_, err = bot.ChannelMessageSend(channelID, notice)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_close.go", true, err, "Fehler beim Senden der Benachrichtigung über den User der das Ticket geschlossen hat in Ticket #" + fmt.Sprint(ticketID))
 	}
 
-	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseUpdateMessage,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-			Components: updatedComponents,
-		},
-	})
+	sendFeedbackRequest(bot, ticket)
+	return ticket, nil
 }
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
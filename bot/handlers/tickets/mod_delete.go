@@ -1,30 +1,49 @@
 package tickets
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"bot/database"
+	"bot/services/storage"
+	ticketService "bot/services/tickets"
+	"bot/services/transcripts"
 	"bot/utils"
 
 	"github.com/bwmarrin/discordgo"
 )
 
+// Upload-Limit für Anhänge ohne Boost
+const maxTranscriptUploadSize = 10 * 1024 * 1024
+
+// Der Name des Erstellers landet im Schlüssel des Transkripts und darf keine Pfade bilden
+var transcriptNameReplacer = strings.NewReplacer("/", "_", "\\", "_", "..", "_")
+
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
 func HandleDeleteButton(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "mod_delete.go")
+	if !ok {
+		return
+	}
+	if !ticket.Status.Can(ticketService.ActionDelete) {
+		handleTransitionError(bot, bot_interaction, "mod_delete.go", ticket, ticketService.ErrInvalidTransition)
+		return
+	}
+
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
 				&discordgo.Button{
 					Style:    discordgo.DangerButton,
 					Label:    "Bestätigen",
-					CustomID: "ticket_confirm_delete_ticket",
+					CustomID: ticketCustomID("ticket_confirm_delete_ticket", ticket.ID),
 				},
 				&discordgo.Button{
 					Style:    discordgo.SecondaryButton,
@@ -57,6 +76,13 @@ func HandleDeleteButton(bot *discordgo.Session, bot_interaction *discordgo.Inter
 
 // HandleConfirmDelete erstellt das Transkript und löscht das Ticket
 func HandleConfirmDelete(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	// Ticket über die CustomID (bzw. den Channel) auflösen, bevor die Bestätigung ersetzt wird
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "mod_delete.go")
+	if !ok {
+		return
+	}
+	channelID := ticketChannelID(ticket, bot_interaction)
+
 	// Sende eine Nachricht: Transkript Erstellung und Ticket Löschung
 	embed := &discordgo.MessageEmbed{
 		Title:       "Löschung Bestätigt",
@@ -77,42 +103,71 @@ func HandleConfirmDelete(bot *discordgo.Session, bot_interaction *discordgo.Inte
 		return
 	}
 
-	// Ticket-ID abrufen
-	ticketID, err := GetTicketIDFromInteraction(bot, bot_interaction)
-	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "mod_delete.go", true, err, "Fehler beim Abrufen der Ticket-ID aus der Interaktion")
+	// Ein bereits gelöschtes Ticket (z.B. Doppelklick) nicht erneut verarbeiten
+	if !ticket.Status.Can(ticketService.ActionDelete) {
+		embeds := []*discordgo.MessageEmbed{{
+			Title:       "Nicht möglich",
+			Description: fmt.Sprintf("Das Ticket kann bei Status **%s** nicht gelöscht werden.", ticket.Status),
+			Color:       utils.ColorWarning,
+		}}
+		bot.InteractionResponseEdit(bot_interaction.Interaction, &discordgo.WebhookEdit{Embeds: &embeds})
 		return
 	}
 
+	deleteTicket(bot, ticket, channelID, ticketService.Actor{ID: bot_interaction.Member.User.ID, Name: bot_interaction.Member.User.Username})
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// deleteTicket erstellt Transkript und Zusammenfassung, markiert das Ticket als gelöscht und entfernt den Channel
+func deleteTicket(bot *discordgo.Session, ticket *ticketService.Ticket, channelID string, actor ticketService.Actor) {
+	ticketID := ticket.ID
+
 	// Datenbank-Informationen abrufen
 	ticket_db_info := getTicketDbInfo(bot, ticketID)
 
-	// Erstelle einen Ordner für Attachments in "ticket_bot/transcripts/attachements/<ticketID>"
-	attachmentDir := fmt.Sprintf("./transcripts/attachements/%d", ticketID)
-	if err := os.MkdirAll(attachmentDir, 0755); err != nil {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "mod_delete.go", true, err, "Fehler beim Erstellen des Verzeichnisses für Attachments")
+	// Speicher für Transkripte und Anhänge (lokal oder S3, siehe .env)
+	store, err := storage.Default()
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "critical", "Error", "mod_delete.go", true, err, "Fehler beim Initialisieren des Transkript-Speichers")
 		return
 	}
 
 	// Erstelle Transkript (Nachrichten sammeln)
-	transcript, err := CollectTranscript(bot, bot_interaction.ChannelID, attachmentDir)
+	transcript, err := CollectTranscript(bot, channelID)
 	if err != nil {
 		utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_delete.go", true, err, "Fehler beim Sammeln des Transkripts")
 		return
 	}
-	
+
+	// Anhänge, Embed-Bilder und Avatare speichern (per Hash dedupliziert, parallel begrenzt)
+	storeTranscriptFiles(bot, store, transcript)
+
 	// Transkript speichern
-	transcriptPath := fmt.Sprintf("./transcripts/%d_%s.json", ticketID, ticket_db_info[5])
-	err = WriteTranscriptToFile(bot, transcriptPath, transcript)
+	transcriptKey := fmt.Sprintf("%d_%s.json", ticketID, transcriptNameReplacer.Replace(ticket_db_info[5]))
+	err = WriteTranscript(bot, store, transcriptKey, transcript)
 	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "critical", "Error", "mod_delete.go", true, err, "Fehler beim Schreiben des Transkripts in die Datei")
+		utils.LogAndNotifyAdmins(bot, "critical", "Error", "mod_delete.go", true, err, "Fehler beim Schreiben des Transkripts in den Speicher")
 		return
 	}
 
+	// Transkript-Text in den Suchindex aufnehmen
+	if err := ticketService.NewTicketService(bot).IndexTranscript(ticketID, transcripts.PlainText(transcript)); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_delete.go", true, err, "Fehler beim Aktualisieren des Ticket-Suchindex")
+	}
+
+	// HTML-Transkript (Bilder eingebettet) neben dem JSON speichern, das JSON bleibt für die Web-App maßgeblich
+	transcriptHTML, err := writeHTMLTranscript(store, buildTranscript(bot, ticketID, channelID, transcript, store), transcriptKey)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_delete.go", true, err, "Fehler beim Erstellen des HTML-Transkripts")
+	}
+
 	// Datenbank aktualisieren
-	_, err = database.DB.Exec(`UPDATE tickets SET ticket_status = "Deleted", ticket_loescher_id = ?, ticket_loescher_name = ?, ticket_loeschzeit = ? WHERE ticket_id = ?`, bot_interaction.Member.User.ID, bot_interaction.Member.User.Username, time.Now().Unix(), ticketID)
+	_, err = ticketService.NewTicketService(bot).Delete(ticketID, actor)
 	if err != nil {
 		utils.LogAndNotifyAdmins(bot, "warn", "Error", "mod_delete.go", true, err, "Fehler beim Aktualisieren des Ticket-Status in der Datenbank")
+	} else {
+		publishTicketStatus(ticketID, string(ticketService.StatusDeleted), actor.ID)
 	}
 
 	// SQL-Abfrage für die Datenbank, um die Werte aus den Spalten ticket_erstellungszeit, ticket_bearbeitungszeit und ticket_schliesszeit zu holen
@@ -153,7 +208,7 @@ func HandleConfirmDelete(bot *discordgo.Session, bot_interaction *discordgo.Inte
 	}
 	summaryEmbed.Fields = append(summaryEmbed.Fields, &discordgo.MessageEmbedField{
 		Name:   "Deleted by",
-		Value:  fmt.Sprintf("<@%s> <t:%d:R> | <t:%d>", bot_interaction.Member.User.ID, time.Now().Unix(), time.Now().Unix()),
+		Value:  fmt.Sprintf("<@%s> <t:%d:R> | <t:%d>", actor.ID, time.Now().Unix(), time.Now().Unix()),
 		Inline: false,
 	})
 
@@ -179,9 +234,22 @@ func HandleConfirmDelete(bot *discordgo.Session, bot_interaction *discordgo.Inte
 	// Channel-ID für Transkript abrufen
 	transcriptChannelID := utils.GetIdFromDB(bot, "CHANNEL_TICKET_TRANSCRIPS")
 
-	// Statt das Transkript als Datei zu senden, wird ein Button am Embed hinzugefügt
+	// HTML-Transkript anhängen, solange es unter dem Upload-Limit bleibt, sonst bleibt nur der Button
+	var files []*discordgo.File
+	if transcriptHTML != nil && len(transcriptHTML) <= maxTranscriptUploadSize {
+		files = append(files, &discordgo.File{
+			Name:        fmt.Sprintf("ticket-%d.html", ticketID),
+			ContentType: "text/html",
+			Reader:      bytes.NewReader(transcriptHTML),
+		})
+	} else if transcriptHTML != nil {
+		utils.LogAndNotifyAdmins(bot, "info", "Info", "mod_delete.go", false, nil, fmt.Sprintf("HTML-Transkript für Ticket #%d ist zu groß für den Upload (%d Bytes)", ticketID, len(transcriptHTML)))
+	}
+
+	// Zusätzlich zum Anhang wird ein Button zur Web-App am Embed hinzugefügt
 	_, err = bot.ChannelMessageSendComplex(transcriptChannelID, &discordgo.MessageSend{
 		Embeds: []*discordgo.MessageEmbed{summaryEmbed},
+		Files:  files,
 		Components: []discordgo.MessageComponent{
 			discordgo.ActionsRow{
 				Components: []discordgo.MessageComponent{
@@ -198,8 +266,8 @@ func HandleConfirmDelete(bot *discordgo.Session, bot_interaction *discordgo.Inte
 		utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_delete.go", true, err, "Fehler beim Senden der Ticket-Zusammenfassung in den Transkript-Kanal")
 	}
 
-	// Transkript-Pfad in die Datenbank einfügen
-	_, err = database.DB.Exec(`UPDATE tickets SET ticket_transcript = ? WHERE ticket_id = ?`, transcriptPath, ticketID)
+	// Transkript-Schlüssel in die Datenbank einfügen
+	_, err = database.DB.Exec(`UPDATE tickets SET ticket_transcript = ? WHERE ticket_id = ?`, transcriptKey, ticketID)
 	if err != nil {
 		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_delete.go", true, err, "Fehler beim Einfügen des Transkript-Pfades in die Datenbank für Ticket-ID "+fmt.Sprint(ticketID))
 	}
@@ -208,18 +276,13 @@ func HandleConfirmDelete(bot *discordgo.Session, bot_interaction *discordgo.Inte
 	time.Sleep(5 * time.Second)
 
 	// Kanal löschen
-	bot.ChannelDelete(bot_interaction.ChannelID)
+	bot.ChannelDelete(channelID)
 }
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
-// CollectTranscript liest alle Nachrichten (inkl. Attachments) aus dem Discord-Channel
-func CollectTranscript(bot *discordgo.Session, channelID, attachmentDir string) ([]MessageData, error) {
-    // Falls das Verzeichnis noch nicht existiert, erstellen
-    if err := os.MkdirAll(attachmentDir, 0755); err != nil {
-        return nil, err
-    }
-
+// CollectTranscript liest alle Nachrichten (inkl. Attachments) aus dem Discord-Channel, gespeichert wird über storeTranscriptFiles
+func CollectTranscript(bot *discordgo.Session, channelID string) ([]MessageData, error) {
     var messages []MessageData
     var beforeID string
 
@@ -239,30 +302,46 @@ func CollectTranscript(bot *discordgo.Session, channelID, attachmentDir string)
         // Über alle abgefragten Nachrichten iterieren
         for _, msg := range msgs {
             var attachments []AttachmentData
-
-            // Falls die Nachricht Attachments besitzt, downloaden
             for _, att := range msg.Attachments {
-                localPath, err := saveAttachmentLocally(att.URL, att.Filename, attachmentDir)
-                if err != nil {
-                    utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_delete.go", true, err, "Fehler beim Speichern des Anhangs lokal")
-                }
-
                 attachments = append(attachments, AttachmentData{
-                    ID:        att.ID,
-                    Filename:  att.Filename,
-                    URL:       att.URL,
-                    LocalPath: localPath,
+                    ID:          att.ID,
+                    Filename:    att.Filename,
+                    URL:         att.URL,
+                    ContentType: att.ContentType,
                 })
             }
 
             // MessageData zusammenbauen
-            messages = append(messages, MessageData{
+            message := MessageData{
+                ID:          msg.ID,
                 UserID:      msg.Author.ID,
                 Username:    msg.Author.Username,
+                DisplayName: msg.Author.DisplayName(),
+                AvatarURL:   msg.Author.AvatarURL("64"),
+                Bot:         msg.Author.Bot,
                 Message:     msg.Content,
                 Timestamp:   msg.Timestamp.Format(time.RFC3339),
+                Pinned:      msg.Pinned,
                 Attachments: attachments,
-            })
+                Embeds:      collectEmbeds(msg),
+            }
+            if msg.EditedTimestamp != nil {
+                message.EditedTimestamp = msg.EditedTimestamp.Format(time.RFC3339)
+            }
+            if msg.Type == discordgo.MessageTypeReply && msg.MessageReference != nil {
+                message.ReplyTo = msg.MessageReference.MessageID
+            }
+            for _, reaction := range msg.Reactions {
+                if reaction.Emoji == nil {
+                    continue
+                }
+                emoji := reaction.Emoji.Name
+                if reaction.Emoji.ID != "" {
+                    emoji = ":" + reaction.Emoji.Name + ":"
+                }
+                message.Reactions = append(message.Reactions, transcripts.Reaction{Emoji: emoji, Count: reaction.Count})
+            }
+            messages = append(messages, message)
         }
 
         // ID der letzten Nachricht als "before"-Parameter für den nächsten API-Call
@@ -274,36 +353,228 @@ func CollectTranscript(bot *discordgo.Session, channelID, attachmentDir string)
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
-// saveAttachmentLocally lädt die Datei aus der übergebenen URL herunter
-func saveAttachmentLocally(url, filename, attachmentDir string) (string, error) {
-    resp, err := http.Get(url)
-    if err != nil {
-        return "", err
+// collectEmbeds übernimmt die Embeds einer Nachricht
+func collectEmbeds(msg *discordgo.Message) []transcripts.Embed {
+    var embeds []transcripts.Embed
+    for _, embed := range msg.Embeds {
+        data := transcripts.Embed{
+            Title:       embed.Title,
+            Description: embed.Description,
+            URL:         embed.URL,
+            Color:       embed.Color,
+        }
+        if embed.Author != nil {
+            data.Author = embed.Author.Name
+        }
+        if embed.Footer != nil {
+            data.Footer = embed.Footer.Text
+        }
+        for _, field := range embed.Fields {
+            data.Fields = append(data.Fields, transcripts.EmbedField{Name: field.Name, Value: field.Value, Inline: field.Inline})
+        }
+        if embed.Image != nil && embed.Image.URL != "" {
+            data.Image = &transcripts.Image{URL: embed.Image.URL}
+        }
+        if embed.Thumbnail != nil && embed.Thumbnail.URL != "" {
+            data.Thumbnail = &transcripts.Image{URL: embed.Thumbnail.URL}
+        }
+        embeds = append(embeds, data)
     }
-    defer resp.Body.Close()
+    return embeds
+}
 
-    // Lokalen Pfad erzeugen (ggf. Anpassungen an Filename vornehmen)
-    localPath := filepath.Join(attachmentDir, filename)
+/*--------------------------------------------------------------------------------------------------------------------------*/
 
-    out, err := os.Create(localPath)
-    if err != nil {
-        return "", err
+// transcriptFetchPolicy liest Größen- und Typ-Grenzen für Transkript-Downloads aus der Datenbank
+func transcriptFetchPolicy(bot *discordgo.Session) storage.Policy {
+    policy := storage.DefaultPolicy
+    if maxMB, err := strconv.Atoi(utils.GetOptionalIdFromDB(bot, "TRANSCRIPT_ATTACHMENT_MAX_MB", "")); err == nil && maxMB > 0 {
+        policy.MaxSize = int64(maxMB) * 1024 * 1024
+    }
+    if types := utils.GetOptionalIdFromDB(bot, "TRANSCRIPT_ATTACHMENT_TYPES", ""); types != "" {
+        policy.AllowedTypes = nil
+        for _, allowed := range strings.Split(types, ",") {
+            if allowed = strings.TrimSpace(allowed); allowed != "" {
+                policy.AllowedTypes = append(policy.AllowedTypes, allowed)
+            }
+        }
+    }
+    if concurrency, err := strconv.Atoi(utils.GetOptionalIdFromDB(bot, "TRANSCRIPT_DOWNLOAD_CONCURRENCY", "")); err == nil && concurrency > 0 {
+        policy.Concurrency = concurrency
+    }
+    return policy
+}
+
+// storeTranscriptFiles lädt Anhänge, Embed-Bilder und Avatare in den Speicher und trägt die Schlüssel ein.
+// Abgelehnte Dateien (Größe, Typ) bleiben als Link mit Begründung im Transkript.
+func storeTranscriptFiles(bot *discordgo.Session, store storage.Storage, messages []MessageData) {
+    var requests []storage.FetchRequest
+    var apply [][]func(storage.FetchResult)
+    index := make(map[string]int)
+    add := func(url, filename string, fn func(storage.FetchResult)) {
+        // Gleiche URLs (v.a. Avatare) nur einmal laden
+        i, ok := index[url]
+        if !ok {
+            i = len(requests)
+            index[url] = i
+            requests = append(requests, storage.FetchRequest{URL: url, Filename: filename})
+            apply = append(apply, nil)
+        }
+        apply[i] = append(apply[i], fn)
     }
-    defer out.Close()
 
-    // Inhalt kopieren
-    _, err = io.Copy(out, resp.Body)
-    if err != nil {
-        return "", err
+    for i := range messages {
+        msg := &messages[i]
+        if msg.AvatarURL != "" {
+            add(msg.AvatarURL, "avatar.png", func(result storage.FetchResult) { msg.AvatarKey = result.Key })
+        }
+        for j := range msg.Attachments {
+            att := &msg.Attachments[j]
+            add(att.URL, att.Filename, func(result storage.FetchResult) {
+                if result.Err != nil {
+                    att.Skipped = result.Err.Error()
+                    return
+                }
+                att.Key, att.Size, att.ContentType = result.Key, result.Size, result.ContentType
+            })
+        }
+        for j := range msg.Embeds {
+            for _, image := range []*transcripts.Image{msg.Embeds[j].Image, msg.Embeds[j].Thumbnail} {
+                if image != nil {
+                    image := image
+                    add(image.URL, "", func(result storage.FetchResult) { image.Key = result.Key })
+                }
+            }
+        }
     }
 
-    return localPath, nil
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+    defer cancel()
+    failed := 0
+    for i, result := range storage.NewFetcher(store, transcriptFetchPolicy(bot), transcripts.AttachmentPrefix).FetchAll(ctx, requests) {
+        if result.Err != nil && !errors.Is(result.Err, storage.ErrRejected) {
+            failed++
+        }
+        for _, fn := range apply[i] {
+            fn(result)
+        }
+    }
+    if failed > 0 {
+        utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_delete.go", true, nil, fmt.Sprintf("%d von %d Dateien für das Transkript konnten nicht gespeichert werden", failed, len(requests)))
+    }
 }
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
-// WriteTranscriptToFile speichert ein JSON-Transkript in der angegebenen Datei ab.
-func WriteTranscriptToFile(bot *discordgo.Session, path string, messages []MessageData) error {
+// buildTranscript ergänzt die Nachrichten um Namen, Avatare, Rollen und Channels für die HTML-Ausgabe
+func buildTranscript(bot *discordgo.Session, ticketID int, channelID string, messages []MessageData, store storage.Storage) *transcripts.Transcript {
+    guildID := utils.GetIdFromDB(bot, "GUILD_ID")
+    transcript := &transcripts.Transcript{
+        TicketID:    ticketID,
+        Messages:    messages,
+        Users:       make(map[string]transcripts.User),
+        Roles:       make(map[string]string),
+        Channels:    make(map[string]string),
+        GeneratedAt: time.Now(),
+        Store:       store,
+    }
+    if channel, err := bot.State.Channel(channelID); err == nil {
+        transcript.ChannelName = channel.Name
+    } else if channel, err := bot.Channel(channelID); err == nil {
+        transcript.ChannelName = channel.Name
+    }
+    if guild, err := bot.State.Guild(guildID); err == nil {
+        transcript.GuildName = guild.Name
+    }
+
+    // Autoren inkl. Avatar
+    for _, msg := range messages {
+        if _, ok := transcript.Users[msg.UserID]; ok {
+            continue
+        }
+        user := transcripts.User{Name: msg.DisplayName, AvatarKey: msg.AvatarKey}
+        if user.Name == "" {
+            user.Name = msg.Username
+        }
+        transcript.Users[msg.UserID] = user
+    }
+
+    // Zusätzliche Teilnehmer für die Kopfzeile
+    if participants, err := ticketService.NewTicketService(bot).Participants(ticketID); err == nil {
+        for _, participant := range participants {
+            name := participant.UserName
+            if user, ok := transcript.Users[participant.UserID]; ok {
+                name = user.Name
+            }
+            if name == "" {
+                name = participant.UserID
+            }
+            transcript.Participants = append(transcript.Participants, name)
+        }
+    } else {
+        utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_delete.go", true, err, "Fehler beim Laden der Teilnehmer für das Transkript von Ticket #"+fmt.Sprint(ticketID))
+    }
+
+    // Interne Notizen als eigener Abschnitt, Erwähnungen darin werden mit aufgelöst
+    mentionSources := append([]MessageData(nil), messages...)
+    if notes, err := ticketService.NewTicketService(bot).Notes(ticketID); err == nil {
+        for _, note := range notes {
+            transcript.Notes = append(transcript.Notes, transcripts.Note{AuthorName: note.AuthorName, Content: note.Content, CreatedAt: time.Unix(note.CreatedAt, 0)})
+            mentionSources = append(mentionSources, MessageData{Message: note.Content})
+        }
+    } else {
+        utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_delete.go", true, err, "Fehler beim Laden der Notizen für das Transkript von Ticket #"+fmt.Sprint(ticketID))
+    }
+
+    // Erwähnungen auflösen, unbekannte IDs zeigt der Renderer als "@unbekannt"
+    userIDs, roleIDs, channelIDs := transcripts.MentionedIDs(mentionSources)
+    for _, userID := range userIDs {
+        if _, ok := transcript.Users[userID]; ok {
+            continue
+        }
+        if member, err := bot.GuildMember(guildID, userID); err == nil {
+            name := member.Nick
+            if name == "" {
+                name = member.User.DisplayName()
+            }
+            transcript.Users[userID] = transcripts.User{Name: name}
+        } else if user, err := bot.User(userID); err == nil {
+            transcript.Users[userID] = transcripts.User{Name: user.DisplayName()}
+        }
+    }
+    if len(roleIDs) > 0 {
+        if roles, err := bot.GuildRoles(guildID); err == nil {
+            for _, role := range roles {
+                transcript.Roles[role.ID] = role.Name
+            }
+        }
+    }
+    for _, mentionedChannelID := range channelIDs {
+        if channel, err := bot.State.Channel(mentionedChannelID); err == nil {
+            transcript.Channels[mentionedChannelID] = channel.Name
+        } else if channel, err := bot.Channel(mentionedChannelID); err == nil {
+            transcript.Channels[mentionedChannelID] = channel.Name
+        }
+    }
+    return transcript
+}
+
+// writeHTMLTranscript rendert das HTML-Transkript und speichert es neben dem JSON-Transkript
+func writeHTMLTranscript(store storage.Storage, transcript *transcripts.Transcript, jsonKey string) ([]byte, error) {
+    page, err := transcripts.RenderHTML(transcript)
+    if err != nil {
+        return nil, err
+    }
+    if err := store.Put(context.Background(), transcripts.HTMLKey(jsonKey), bytes.NewReader(page), "text/html; charset=utf-8"); err != nil {
+        return nil, err
+    }
+    return page, nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// WriteTranscript speichert ein JSON-Transkript unter dem angegebenen Schlüssel ab.
+func WriteTranscript(bot *discordgo.Session, store storage.Storage, key string, messages []MessageData) error {
     // JSON-Daten erstellen (schön formatiert)
     data, err := json.MarshalIndent(messages, "", "    ")
     if err != nil {
@@ -312,17 +583,9 @@ func WriteTranscriptToFile(bot *discordgo.Session, path string, messages []Messa
     }
 
     // Datei erstellen/überschreiben
-    file, err := os.Create(path)
-    if err != nil {
-        utils.LogAndNotifyAdmins(bot, "high", "Error", "mod_delete.go", true, err, "Fehler beim Erstellen der Transkript-Datei")
-        return err
-    }
-    defer file.Close()
-
-    // JSON reinschreiben
-    _, err = file.Write(data)
+    err = store.Put(context.Background(), key, bytes.NewReader(data), "application/json")
     if err != nil {
-        utils.LogAndNotifyAdmins(bot, "high", "Error", "mod_delete.go", true, err, "Fehler beim Schreiben des Transkripts in die Datei")
+        utils.LogAndNotifyAdmins(bot, "high", "Error", "mod_delete.go", true, err, "Fehler beim Speichern des Transkripts")
         return err
     }
 
@@ -0,0 +1,216 @@
+package tickets
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleMoveButton zeigt die Bereiche, in die das Ticket verschoben werden kann (ephemer)
+func HandleMoveButton(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "mod_move.go")
+	if !ok {
+		return
+	}
+	if ticket.Status != ticketService.StatusOpen && ticket.Status != ticketService.StatusClaimed {
+		handleTransitionError(bot, bot_interaction, "mod_move.go", ticket, ticketService.ErrInvalidTransition)
+		return
+	}
+
+	options, err := moveOptions(bot, ticket)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_move.go", true, err, "Fehler beim Laden der Ticket-Bereiche zum Verschieben")
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Die Bereiche konnten nicht geladen werden.", true)
+		return
+	}
+	if len(options) == 0 {
+		utils.SendWarningEmbed(bot, bot_interaction, "Nicht möglich", "Es gibt keinen anderen aktiven Bereich.", true)
+		return
+	}
+
+	err = bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("In welchen Bereich soll Ticket #%d verschoben werden?", ticket.ID),
+			Flags:   discordgo.MessageFlagsEphemeral,
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.SelectMenu{CustomID: ticketCustomID("ticket_select_move", ticket.ID), Placeholder: "Neuer Bereich", Options: options},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_move.go", true, err, "Fehler beim Anzeigen der Bereichs-Auswahl für Ticket #"+strconv.Itoa(ticket.ID))
+	}
+}
+
+// moveOptions liefert die aktiven Bereiche ohne Unterbereiche außer dem aktuellen, Unterbereiche mit Oberbereich im Label
+func moveOptions(bot *discordgo.Session, ticket *ticketService.Ticket) ([]discordgo.SelectMenuOption, error) {
+	areas, err := ticketService.NewAreaService(bot).ListAreas()
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[string]string)
+	parents := make(map[string]bool)
+	for _, area := range areas {
+		labels[area.Key] = area.Label
+		parents[area.ParentKey] = true
+	}
+
+	options := []discordgo.SelectMenuOption{}
+	for _, area := range areas {
+		if !area.Enabled || parents[area.Key] || area.Key == ticket.Area || len(options) >= ticketService.MaxDropdownOptions {
+			continue
+		}
+		label := area.Label
+		if area.ParentKey != "" {
+			label = labels[area.ParentKey] + " › " + label
+		}
+		options = append(options, discordgo.SelectMenuOption{Label: truncate(label, 100), Value: area.Key, Description: truncate(area.Description, 100)})
+	}
+	return options, nil
+}
+
+// HandleMoveSelect verschiebt das Ticket in den ausgewählten Bereich
+func HandleMoveSelect(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "mod_move.go")
+	if !ok {
+		return
+	}
+	data := bot_interaction.MessageComponentData()
+	if len(data.Values) == 0 {
+		return
+	}
+
+	actor := ticketService.Actor{ID: bot_interaction.Member.User.ID, Name: bot_interaction.Member.User.Username}
+	moved, from, to, err := moveTicket(bot, ticket, ticketChannelID(ticket, bot_interaction), data.Values[0], actor)
+	if err != nil {
+		respondMoveError(bot, bot_interaction, moved, err)
+		return
+	}
+
+	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    fmt.Sprintf("Ticket #%d wurde von **%s** nach **%s** verschoben.", moved.ID, from.DisplayName, to.DisplayName),
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
+
+// handleTicketMoveCommand behandelt /ticket move area im Ticket-Channel
+func handleTicketMoveCommand(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "mod_move.go")
+	if !ok {
+		return
+	}
+
+	actor := ticketService.Actor{ID: bot_interaction.Member.User.ID, Name: bot_interaction.Member.User.Username}
+	moved, from, to, err := moveTicket(bot, ticket, ticketChannelID(ticket, bot_interaction), opts["area"].StringValue(), actor)
+	if err != nil {
+		respondMoveError(bot, bot_interaction, moved, err)
+		return
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Ticket verschoben", fmt.Sprintf("Ticket #%d wurde von **%s** nach **%s** verschoben.", moved.ID, from.DisplayName, to.DisplayName), true)
+}
+
+// respondMoveError meldet unerlaubte Status und Eingabefehler nur dem Nutzer
+func respondMoveError(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, ticket *ticketService.Ticket, err error) {
+	if errors.Is(err, ticketService.ErrInvalidTransition) {
+		handleTransitionError(bot, bot_interaction, "mod_move.go", ticket, err)
+		return
+	}
+	respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Verschieben des Tickets")
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// moveTicket verschiebt das Ticket und passt den Channel an: Berechtigung der Support-Rolle tauschen, Kategorie und
+// Name wechseln und die neue Support-Rolle pingen. Danach greift die automatische Zuweisung des neuen Bereichs.
+func moveTicket(bot *discordgo.Session, ticket *ticketService.Ticket, channelID, areaKey string, actor ticketService.Actor) (*ticketService.Ticket, *ticketService.Area, *ticketService.Area, error) {
+	service := ticketService.NewTicketService(bot)
+	moved, from, to, err := service.Move(ticket.ID, areaKey, actor)
+	if err != nil {
+		return moved, nil, nil, err
+	}
+
+	areas := ticketService.NewAreaService(bot)
+	oldRole, newRole := areas.SupportRoleID(from), areas.SupportRoleID(to)
+	if oldRole != newRole {
+		// Die Rolle des alten Bereichs stammt aus dessen Kategorie und bleibt beim Verschieben sonst erhalten.
+		// Bei MentionUser ist es ein User-Overwrite, der auch dem Ersteller, Bearbeiter oder einem Teilnehmer gehören kann.
+		if oldRole != "" && !keepsChannelAccess(bot, moved, oldRole) {
+			if err := bot.ChannelPermissionDelete(channelID, oldRole); err != nil {
+				utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_move.go", true, err, fmt.Sprintf("Fehler beim Entfernen der Berechtigung von %s in Ticket #%d", oldRole, moved.ID))
+			}
+		}
+		if newRole != "" {
+			overwriteType := discordgo.PermissionOverwriteTypeRole
+			if to.MentionUser {
+				overwriteType = discordgo.PermissionOverwriteTypeMember
+			}
+			if err := bot.ChannelPermissionSet(channelID, newRole, overwriteType, discordgo.PermissionAllText, 0); err != nil {
+				utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_move.go", true, err, fmt.Sprintf("Fehler beim Setzen der Berechtigung von %s in Ticket #%d", newRole, moved.ID))
+			}
+		}
+	}
+
+	// Nur offene Tickets tragen das Namensmuster des Bereichs, bearbeitete heißen <id>-claimed-<ersteller>-<bearbeiter>
+	edit := &discordgo.ChannelEdit{ParentID: areas.CategoryID(to)}
+	if moved.Status == ticketService.StatusOpen {
+		edit.Name = to.ChannelName(int64(moved.ID), moved.CreatorName)
+	}
+	if _, err := bot.ChannelEdit(channelID, edit); err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_move.go", true, err, fmt.Sprintf("Fehler beim Verschieben des Channels von Ticket #%d", moved.ID))
+	}
+
+	mention := ""
+	if newRole != "" {
+		mention = fmt.Sprintf("<@&%s> ", newRole)
+		if to.MentionUser {
+			mention = fmt.Sprintf("<@%s> ", newRole)
+		}
+	}
+	_, err = bot.ChannelMessageSend(channelID, fmt.Sprintf("%sDas Ticket #%d wurde von <@%s> von **%s** nach **%s** verschoben.", mention, moved.ID, actor.ID, from.DisplayName, to.DisplayName))
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_move.go", true, err, "Fehler beim Senden der Verschiebe-Nachricht in Ticket #"+strconv.Itoa(moved.ID))
+	}
+
+	if err := service.IndexTicket(moved.ID); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_move.go", true, err, "Fehler beim Aktualisieren des Ticket-Suchindex")
+	}
+	updateModerationPanel(bot, channelID, moved)
+	if moved.Status == ticketService.StatusOpen && moved.ClaimerID == "" {
+		autoAssignTicket(bot, moved.ID)
+	}
+	return moved, from, to, nil
+}
+
+// keepsChannelAccess prüft, ob der Overwrite einem User gehört, der den Channel weiter sehen muss
+func keepsChannelAccess(bot *discordgo.Session, ticket *ticketService.Ticket, id string) bool {
+	if id == ticket.CreatorID || id == ticket.ClaimerID {
+		return true
+	}
+	participants, err := ticketService.NewTicketService(bot).Participants(ticket.ID)
+	if err != nil {
+		// Im Zweifel bleibt die Berechtigung bestehen
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "mod_move.go", true, err, "Fehler beim Laden der Teilnehmer von Ticket #"+strconv.Itoa(ticket.ID))
+		return true
+	}
+	for _, participant := range participants {
+		if participant.UserID == id {
+			return true
+		}
+	}
+	return false
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
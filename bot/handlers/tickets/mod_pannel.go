@@ -1,8 +1,12 @@
 package tickets
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
+	ticketService "bot/services/tickets"
+	"bot/services/transcripts"
 	"bot/utils"
 
 	"github.com/bwmarrin/discordgo"
@@ -10,41 +14,21 @@ import (
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
-// message structure for transcript
-type MessageData struct {
-    UserID      string           `json:"userID"`
-    Username    string           `json:"username"`
-    Message     string           `json:"message"`
-    Timestamp   string           `json:"timestamp"`
-    Attachments []AttachmentData `json:"attachments,omitempty"`
-}
+// message structure for transcript (Aufbau und JSON-Format in services/transcripts)
+type MessageData = transcripts.Message
 
 // AttachmentData enthält Metadaten zu einem Anhang
-type AttachmentData struct {
-    ID        string `json:"id"`
-    Filename  string `json:"filename"`
-    URL       string `json:"url"`
-    LocalPath string `json:"localPath,omitempty"`
-}
+type AttachmentData = transcripts.Attachment
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
 // sends a pinned moderation view to the channel
-func SendModerationView(bot *discordgo.Session, channelID string, ticketID int, creatorName string) {
-	embed := &discordgo.MessageEmbed{
-		Title:       fmt.Sprintf("Ticket #%d Moderation", ticketID),
-		Fields: []*discordgo.MessageEmbedField{
-			{Name: "Erstellt von", Value: creatorName, Inline: true},
-			{Name: "Status", Value: "Open", Inline: true},
-		},
-		Color: 0xFFD700, // gold
-	}
-
-	components := getDefaultComponents()
+func SendModerationView(bot *discordgo.Session, channelID string, ticketID int, creatorName, areaKey string) {
+	ticket := &ticketService.Ticket{ID: ticketID, Status: ticketService.StatusOpen, Area: areaKey, CreatorName: creatorName, Priority: ticketService.PriorityNormal}
 
 	_, err := bot.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
-		Embeds:     []*discordgo.MessageEmbed{embed},
-		Components: components,
+		Embeds:     []*discordgo.MessageEmbed{moderationEmbed(ticket)},
+		Components: moderationComponents(bot, ticket),
 	})
 	if err != nil {
 		utils.LogAndNotifyAdmins(bot, "critical", "Error", "mod_pannel.go", true, err, "Fehler beim Senden der Moderation View in Ticket #" + fmt.Sprint(ticketID))
@@ -53,18 +37,148 @@ func SendModerationView(bot *discordgo.Session, channelID string, ticketID int,
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
-func getDefaultComponents() []discordgo.MessageComponent {
-	return []discordgo.MessageComponent{
+// moderationEmbed zeigt Ersteller, Status, Bearbeiter, Priorität und Tags des Tickets
+func moderationEmbed(ticket *ticketService.Ticket) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("Ticket #%d Moderation", ticket.ID),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Erstellt von", Value: ticket.CreatorName, Inline: true},
+			{Name: "Status", Value: string(ticket.Status), Inline: true},
+		},
+		Color: 0xFFD700, // gold
+	}
+	if ticket.ClaimerName != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Bearbeiter", Value: ticket.ClaimerName, Inline: true})
+	}
+	if ticket.Priority != "" && ticket.Priority != ticketService.PriorityNormal {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Priorität", Value: ticket.Priority.Label(), Inline: true})
+	}
+	if ticket.Decision != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Entscheidung", Value: ticket.Decision.Label(), Inline: true})
+	}
+	if len(ticket.Tags) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Tags", Value: "`" + strings.Join(ticket.Tags, "` `") + "`", Inline: false})
+	}
+	return embed
+}
+
+// moderationComponents leitet die Buttons aus dem Status ab, nicht erlaubte Aktionen sind deaktiviert.
+// Die CustomIDs enthalten die Ticket-ID, damit die Buttons unabhängig vom Channel-Namen funktionieren.
+// In der zweiten Reihe liegen Notes und Move, in Bewerbungs-Bereichen zusätzlich Annehmen/Ablehnen.
+// Darunter die Auswahlmenüs für Priorität und Tags (vorgegebene Tags aus TICKET_TAGS und bereits vergebene).
+func moderationComponents(bot *discordgo.Session, ticket *ticketService.Ticket) []discordgo.MessageComponent {
+	status := ticket.Status
+
+	// Close und Reopen teilen sich einen Platz
+	toggle := &discordgo.Button{Style: discordgo.SecondaryButton, Label: "Close", CustomID: ticketCustomID("ticket_button_close", ticket.ID), Disabled: !status.Can(ticketService.ActionClose)}
+	if status.Can(ticketService.ActionReopen) {
+		toggle = &discordgo.Button{Style: discordgo.SecondaryButton, Label: "Reopen", CustomID: ticketCustomID("ticket_button_reopen", ticket.ID)}
+	}
+
+	locked := status == ticketService.StatusDeleted
+	movable := status == ticketService.StatusOpen || status == ticketService.StatusClaimed
+	priorityOptions := make([]discordgo.SelectMenuOption, 0, len(ticketService.Priorities))
+	for _, priority := range ticketService.Priorities {
+		priorityOptions = append(priorityOptions, discordgo.SelectMenuOption{
+			Label:   "Priorität: " + priority.Label(),
+			Value:   string(priority),
+			Default: priority == ticket.Priority || (ticket.Priority == "" && priority == ticketService.PriorityNormal),
+		})
+	}
+
+	var tagOptions []discordgo.SelectMenuOption
+	seen := map[string]bool{}
+	for _, tag := range append(ticketService.NewTicketService(bot).PredefinedTags(), ticket.Tags...) {
+		if seen[tag] || len(tagOptions) >= ticketService.MaxDropdownOptions {
+			continue
+		}
+		seen[tag] = true
+		tagOptions = append(tagOptions, discordgo.SelectMenuOption{Label: tag, Value: tag})
+	}
+	for i := range tagOptions {
+		for _, tag := range ticket.Tags {
+			tagOptions[i].Default = tagOptions[i].Default || tagOptions[i].Value == tag
+		}
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				&discordgo.Button{Style: discordgo.SuccessButton, Label: "Claim", CustomID: ticketCustomID("ticket_button_claim", ticket.ID), Disabled: !status.Can(ticketService.ActionClaim)},
+				toggle,
+				&discordgo.Button{Style: discordgo.PrimaryButton, Label: "Assign", CustomID: ticketCustomID("ticket_button_assign", ticket.ID), Disabled: !status.Can(ticketService.ActionAssign)},
+				&discordgo.Button{Style: discordgo.DangerButton, Label: "Delete", CustomID: ticketCustomID("ticket_button_delete", ticket.ID), Disabled: !status.Can(ticketService.ActionDelete)},
+			},
+		},
+		discordgo.ActionsRow{
+			Components: append([]discordgo.MessageComponent{
+				// Notizen werden ephemer angezeigt, der Ersteller sieht sie nicht
+				&discordgo.Button{Style: discordgo.SecondaryButton, Label: "Notes", CustomID: ticketCustomID("ticket_button_notes", ticket.ID)},
+				&discordgo.Button{Style: discordgo.SecondaryButton, Label: "Move", CustomID: ticketCustomID("ticket_button_move", ticket.ID), Disabled: !movable},
+			}, decisionButtons(bot, ticket)...),
+		},
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				&discordgo.Button{Style: discordgo.SuccessButton, Label: "Claim", CustomID: "ticket_button_claim"},
-				&discordgo.Button{Style: discordgo.SecondaryButton, Label: "Close", CustomID: "ticket_button_close"},
-				&discordgo.Button{Style: discordgo.PrimaryButton, Label: "Assign", CustomID: "ticket_button_assign"},
-				&discordgo.Button{Style: discordgo.DangerButton, Label: "Delete", CustomID: "ticket_button_delete"},
+				discordgo.SelectMenu{CustomID: ticketCustomID("ticket_select_priority", ticket.ID), Placeholder: "Priorität", Options: priorityOptions, Disabled: locked},
 			},
 		},
 	}
+	if len(tagOptions) > 0 {
+		minValues := 0
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{CustomID: ticketCustomID("ticket_select_tags", ticket.ID), Placeholder: "Tags", Options: tagOptions,
+					MinValues: &minValues, MaxValues: len(tagOptions), Disabled: locked},
+			},
+		})
+	}
+	return components
 }
 
-/*--------------------------------------------------------------------------------------------------------------------------*/
+// respondModerationPanel ersetzt das Panel, auf dessen Button geklickt wurde
+func respondModerationPanel(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, ticket *ticketService.Ticket) {
+	err := bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{moderationEmbed(ticket)},
+			Components: moderationComponents(bot, ticket),
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "mod_pannel.go", true, err, "Fehler beim Aktualisieren der Moderation View in Ticket #"+fmt.Sprint(ticket.ID))
+	}
+}
 
+// updateModerationPanel sucht das Panel im Ticket-Channel und aktualisiert es (z.B. nach Assign über ein Modal)
+func updateModerationPanel(bot *discordgo.Session, channelID string, ticket *ticketService.Ticket) {
+	messages, err := bot.ChannelMessages(channelID, 50, "", "", "")
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "mod_pannel.go", true, err, "Fehler beim Abrufen der letzten Nachrichten im Kanal für Ticket #"+fmt.Sprint(ticket.ID)+" zur Aktualisierung des Moderation Panels")
+		return
+	}
+
+	components := moderationComponents(bot, ticket)
+	for _, message := range messages {
+		if len(message.Components) > 0 && len(message.Embeds) > 0 && strings.Contains(message.Embeds[0].Title, "Moderation") {
+			bot.ChannelMessageEditComplex(&discordgo.MessageEdit{
+				ID:         message.ID,
+				Channel:    channelID,
+				Embeds:     &[]*discordgo.MessageEmbed{moderationEmbed(ticket)},
+				Components: &components,
+			})
+			return
+		}
+	}
+}
+
+// handleTransitionError meldet unerlaubte Aktionen an den Klickenden, alles andere an die Admins
+func handleTransitionError(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, file string, ticket *ticketService.Ticket, err error) {
+	if errors.Is(err, ticketService.ErrInvalidTransition) && ticket != nil {
+		utils.SendWarningEmbed(bot, bot_interaction, "Nicht möglich", fmt.Sprintf("Diese Aktion ist bei Status **%s** nicht möglich.", ticket.Status), true)
+		return
+	}
+	utils.LogAndNotifyAdmins(bot, "high", "Error", file, true, err, "Fehler beim Aktualisieren des Ticket-Status")
+	utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Der Ticket-Status konnte nicht geändert werden.", true)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
@@ -0,0 +1,84 @@
+package tickets
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// handleTicketAbsentCommand behandelt /ticket absent [days] [reason] [user], abwesende Teammitglieder werden nicht automatisch zugewiesen
+func handleTicketAbsentCommand(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	user := absenceUser(bot, bot_interaction, opts)
+	var until time.Time
+	if opt, ok := opts["days"]; ok && opt.IntValue() > 0 {
+		until = time.Now().AddDate(0, 0, int(opt.IntValue()))
+	}
+	reason := ""
+	if opt, ok := opts["reason"]; ok {
+		reason = opt.StringValue()
+	}
+
+	service := ticketService.NewTicketService(bot)
+	if _, err := service.SetAbsent(user, until, reason); err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Eintragen der Abwesenheit")
+		return
+	}
+	absences, err := service.Absences()
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Laden der Abwesenheiten")
+		return
+	}
+
+	description := fmt.Sprintf("<@%s> bekommt bis auf Weiteres keine Tickets automatisch zugewiesen.", user.ID)
+	if !until.IsZero() {
+		description = fmt.Sprintf("<@%s> bekommt bis <t:%d:f> keine Tickets automatisch zugewiesen.", user.ID, until.Unix())
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Abwesenheit eingetragen", description+"\n\n"+formatAbsences(absences), true)
+}
+
+// handleTicketPresentCommand behandelt /ticket present [user]
+func handleTicketPresentCommand(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	user := absenceUser(bot, bot_interaction, opts)
+	if err := ticketService.NewTicketService(bot).ClearAbsent(user.ID); err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Beenden der Abwesenheit")
+		return
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Abwesenheit beendet", fmt.Sprintf("<@%s> wird wieder automatisch zugewiesen.", user.ID), true)
+}
+
+// absenceUser liefert den angegebenen User, ohne Option den Ausführenden
+func absenceUser(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) ticketService.Actor {
+	if opt, ok := opts["user"]; ok {
+		user := opt.UserValue(bot)
+		return ticketService.Actor{ID: user.ID, Name: user.Username}
+	}
+	return ticketService.Actor{ID: bot_interaction.Member.User.ID, Name: bot_interaction.Member.User.Username}
+}
+
+// formatAbsences listet die laufenden Abwesenheiten
+func formatAbsences(absences []ticketService.Absence) string {
+	if len(absences) == 0 {
+		return "Niemand ist abwesend."
+	}
+	lines := []string{"**Abwesend:**"}
+	for _, absence := range absences {
+		line := fmt.Sprintf("<@%s>", absence.UserID)
+		if absence.Until > 0 {
+			line += fmt.Sprintf(" bis <t:%d:d>", absence.Until)
+		}
+		if absence.Reason != "" {
+			line += " – " + absence.Reason
+		}
+		lines = append(lines, line)
+	}
+	return truncate(strings.Join(lines, "\n"), 3500)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
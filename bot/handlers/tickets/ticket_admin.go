@@ -0,0 +1,563 @@
+package tickets
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// HandleTicketAdmin behandelt /ticket_admin area|field|panel|decision
+func HandleTicketAdmin(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	data := bot_interaction.ApplicationCommandData()
+	if len(data.Options) == 0 || len(data.Options[0].Options) == 0 {
+		return
+	}
+	group := data.Options[0]
+	sub := group.Options[0]
+	opts := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, opt := range sub.Options {
+		opts[opt.Name] = opt
+	}
+
+	service := ticketService.NewAreaService(bot)
+
+	switch group.Name + " " + sub.Name {
+	case "area add":
+		handleTicketAdminAreaAdd(bot, bot_interaction, service, opts)
+	case "area edit":
+		handleTicketAdminAreaEdit(bot, bot_interaction, service, opts)
+	case "area delete":
+		handleTicketAdminAreaDelete(bot, bot_interaction, service, opts)
+	case "area show":
+		handleTicketAdminAreaShow(bot, bot_interaction, service, opts)
+	case "area list":
+		handleTicketAdminAreaList(bot, bot_interaction, service)
+	case "field add":
+		handleTicketAdminFieldAdd(bot, bot_interaction, service, opts)
+	case "field remove":
+		handleTicketAdminFieldRemove(bot, bot_interaction, service, opts)
+	case "panel create":
+		handleTicketAdminPanelCreate(bot, bot_interaction, service, opts)
+	case "panel edit":
+		handleTicketAdminPanelEdit(bot, bot_interaction, service, opts)
+	case "panel delete":
+		handleTicketAdminPanelDelete(bot, bot_interaction, service, opts)
+	case "panel list":
+		handleTicketAdminPanelList(bot, bot_interaction, service)
+	case "decision set", "decision remove", "decision list":
+		handleTicketAdminDecision(bot, bot_interaction, sub.Name, opts)
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+func handleTicketAdminAreaAdd(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *ticketService.AreaService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	area := &ticketService.Area{
+		Key:     strings.ToLower(opts["key"].StringValue()),
+		Enabled: true,
+		Fields:  []ticketService.Field{},
+	}
+	applyAreaOptions(bot, area, opts)
+
+	if err := service.CreateArea(area); err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Anlegen des Ticket-Bereichs")
+		return
+	}
+
+	hint := "Füge mit `/ticket_admin field add` die Formularfelder hinzu."
+	if area.ParentKey == "" {
+		hint += "\nDer Bereich erscheint in allen Panels ohne feste Bereichsliste."
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Ticket-Bereich angelegt", formatArea(area)+"\n"+hint, true)
+}
+
+func handleTicketAdminAreaEdit(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *ticketService.AreaService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	area, err := service.GetArea(opts["key"].StringValue())
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Laden des Ticket-Bereichs")
+		return
+	}
+	applyAreaOptions(bot, area, opts)
+	if opt, ok := opts["enabled"]; ok {
+		area.Enabled = opt.BoolValue()
+	}
+
+	if err := service.UpdateArea(area.Key, area); err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Speichern des Ticket-Bereichs")
+		return
+	}
+
+	utils.SendSuccessEmbed(bot, bot_interaction, "Ticket-Bereich gespeichert", formatArea(area), true)
+}
+
+// applyAreaOptions übernimmt nur die übergebenen Optionen, "-" leert optionale Texte
+func applyAreaOptions(bot *discordgo.Session, area *ticketService.Area, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	if opt, ok := opts["label"]; ok {
+		area.Label = opt.StringValue()
+	}
+	if opt, ok := opts["description"]; ok {
+		area.Description = clearValue(opt.StringValue())
+	}
+	if opt, ok := opts["display_name"]; ok {
+		area.DisplayName = clearValue(opt.StringValue())
+	}
+	if opt, ok := opts["modal_title"]; ok {
+		area.ModalTitle = clearValue(opt.StringValue())
+	}
+	if opt, ok := opts["parent"]; ok {
+		area.ParentKey = clearValue(opt.StringValue())
+	}
+	if opt, ok := opts["sub_prompt"]; ok {
+		area.SubPrompt = clearValue(opt.StringValue())
+	}
+	if opt, ok := opts["role"]; ok {
+		area.SupportRole = opt.RoleValue(bot, "").ID
+		area.MentionUser = false
+	}
+	if opt, ok := opts["user"]; ok {
+		area.SupportRole = opt.UserValue(bot).ID
+		area.MentionUser = true
+	}
+	if opt, ok := opts["category"]; ok {
+		area.Category = opt.ChannelValue(bot).ID
+	}
+	if opt, ok := opts["name_pattern"]; ok {
+		area.NamePattern = opt.StringValue()
+	}
+	if opt, ok := opts["sort_order"]; ok {
+		area.SortOrder = int(opt.IntValue())
+	}
+	if opt, ok := opts["sla_claim_minutes"]; ok {
+		area.SLAClaimMinutes = int(opt.IntValue())
+	}
+	if opt, ok := opts["sla_close_hours"]; ok {
+		area.SLACloseHours = int(opt.IntValue())
+	}
+	if opt, ok := opts["sla_reply_hours"]; ok {
+		area.SLAReplyHours = int(opt.IntValue())
+	}
+	if opt, ok := opts["max_open_tickets"]; ok {
+		area.MaxOpenTickets = int(opt.IntValue())
+	}
+	if opt, ok := opts["inactivity_warn_days"]; ok {
+		area.InactivityWarnDays = int(opt.IntValue())
+	}
+	if opt, ok := opts["inactivity_close_days"]; ok {
+		area.InactivityCloseDays = int(opt.IntValue())
+	}
+	if opt, ok := opts["inactivity_delete"]; ok {
+		area.InactivityDelete = opt.BoolValue()
+	}
+	if opt, ok := opts["assign_mode"]; ok {
+		area.AssignMode = ticketService.AssignMode(clearValue(opt.StringValue()))
+	}
+	if opt, ok := opts["assign_role"]; ok {
+		area.AssignRole = opt.RoleValue(bot, "").ID
+	}
+	if opt, ok := opts["assign_users"]; ok {
+		area.AssignUsers = userIDPattern.FindAllString(clearValue(opt.StringValue()), -1)
+	}
+}
+
+// userIDPattern findet Discord-IDs in Erwähnungen (<@123>) und kommagetrennten Listen
+var userIDPattern = regexp.MustCompile(`\d{17,20}`)
+
+func handleTicketAdminAreaDelete(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *ticketService.AreaService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	key := opts["key"].StringValue()
+	if err := service.DeleteArea(key); err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Löschen des Ticket-Bereichs")
+		return
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Ticket-Bereich gelöscht", fmt.Sprintf("Bereich `%s` wurde gelöscht. Bestehende Tickets bleiben erhalten.", key), true)
+}
+
+func handleTicketAdminAreaShow(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *ticketService.AreaService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	area, err := service.GetArea(opts["key"].StringValue())
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Laden des Ticket-Bereichs")
+		return
+	}
+	utils.SendInfoEmbed(bot, bot_interaction, area.DisplayName, formatArea(area), true)
+}
+
+func handleTicketAdminAreaList(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *ticketService.AreaService) {
+	areas, err := service.ListAreas()
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Laden der Ticket-Bereiche")
+		return
+	}
+	if len(areas) == 0 {
+		utils.SendInfoEmbed(bot, bot_interaction, "Ticket-Bereiche", "Es sind keine Ticket-Bereiche angelegt.", true)
+		return
+	}
+
+	// Hauptbereiche mit eingerückten Unterbereichen
+	children := make(map[string][]ticketService.Area)
+	for _, area := range areas {
+		children[area.ParentKey] = append(children[area.ParentKey], area)
+	}
+	var b strings.Builder
+	var write func(parent string, depth int)
+	write = func(parent string, depth int) {
+		for _, area := range children[parent] {
+			status := ""
+			if !area.Enabled {
+				status = " *(deaktiviert)*"
+			}
+			fmt.Fprintf(&b, "%s• **%s** `%s` - %d Felder%s\n", strings.Repeat("  ", depth), area.Label, area.Key, len(area.Fields), status)
+			write(area.Key, depth+1)
+		}
+	}
+	write("", 0)
+
+	utils.SendInfoEmbed(bot, bot_interaction, "Ticket-Bereiche", truncate(b.String(), 4000), true)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+func handleTicketAdminFieldAdd(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *ticketService.AreaService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	field := ticketService.Field{
+		Label:    opts["label"].StringValue(),
+		Style:    "short",
+		Required: true,
+	}
+	if opt, ok := opts["style"]; ok {
+		field.Style = opt.StringValue()
+	}
+	if opt, ok := opts["required"]; ok {
+		field.Required = opt.BoolValue()
+	}
+	if opt, ok := opts["max_length"]; ok {
+		field.MaxLength = int(opt.IntValue())
+	}
+	if opt, ok := opts["placeholder"]; ok {
+		field.Placeholder = opt.StringValue()
+	}
+	if opt, ok := opts["validation"]; ok && opt.StringValue() != "none" {
+		field.Validation = opt.StringValue()
+	}
+	if opt, ok := opts["min_value"]; ok {
+		minValue := int(opt.IntValue())
+		field.MinValue = &minValue
+	}
+	if opt, ok := opts["max_value"]; ok {
+		maxValue := int(opt.IntValue())
+		field.MaxValue = &maxValue
+	}
+	if opt, ok := opts["pattern"]; ok {
+		field.Pattern = opt.StringValue()
+	}
+	if opt, ok := opts["field_key"]; ok {
+		field.Key = opt.StringValue()
+	}
+	if opt, ok := opts["error_message"]; ok {
+		field.ErrorMessage = opt.StringValue()
+	}
+	position := 0
+	if opt, ok := opts["position"]; ok {
+		position = int(opt.IntValue())
+	}
+
+	area, err := service.AddField(opts["key"].StringValue(), field, position)
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Hinzufügen des Formularfelds")
+		return
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Formularfeld hinzugefügt", formatArea(area), true)
+}
+
+func handleTicketAdminFieldRemove(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *ticketService.AreaService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	area, err := service.RemoveField(opts["key"].StringValue(), int(opts["position"].IntValue()))
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Entfernen des Formularfelds")
+		return
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Formularfeld entfernt", formatArea(area), true)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+func handleTicketAdminPanelCreate(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *ticketService.AreaService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	panel := &ticketService.Panel{
+		Name:     opts["name"].StringValue(),
+		Title:    opts["title"].StringValue(),
+		AreaKeys: []string{},
+	}
+	if opt, ok := opts["description"]; ok {
+		panel.Description = opt.StringValue()
+	}
+	if opt, ok := opts["areas"]; ok {
+		panel.AreaKeys = ticketService.SplitAreaKeys(opt.StringValue())
+	}
+
+	if err := service.CreatePanel(panel); err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Anlegen des Panels")
+		return
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Panel angelegt",
+		formatPanel(panel)+"\nMit `/ticket_view` und der Option `panel` im gewünschten Channel posten.", true)
+}
+
+func handleTicketAdminPanelEdit(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *ticketService.AreaService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	panel, err := service.GetPanel(int(opts["panel"].IntValue()))
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Laden des Panels")
+		return
+	}
+	if opt, ok := opts["title"]; ok {
+		panel.Title = opt.StringValue()
+	}
+	if opt, ok := opts["description"]; ok {
+		panel.Description = clearValue(opt.StringValue())
+	}
+	if opt, ok := opts["areas"]; ok {
+		panel.AreaKeys = ticketService.SplitAreaKeys(clearValue(opt.StringValue()))
+	}
+
+	if err := service.UpdatePanel(panel.ID, panel); err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Speichern des Panels")
+		return
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Panel gespeichert",
+		formatPanel(panel)+"\nBereits gepostete Panels zeigen die neuen Bereiche sofort, Titel und Beschreibung erst nach erneutem Posten.", true)
+}
+
+func handleTicketAdminPanelDelete(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *ticketService.AreaService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	if err := service.DeletePanel(int(opts["panel"].IntValue())); err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Löschen des Panels")
+		return
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Panel gelöscht", "Bereits gepostete Buttons zeigen ab jetzt alle Hauptbereiche.", true)
+}
+
+func handleTicketAdminPanelList(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *ticketService.AreaService) {
+	panels, err := service.ListPanels()
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Laden der Panels")
+		return
+	}
+	if len(panels) == 0 {
+		utils.SendInfoEmbed(bot, bot_interaction, "Ticket-Panels", "Es sind keine Panels angelegt. `/ticket_view` ohne Panel zeigt alle Hauptbereiche.", true)
+		return
+	}
+
+	var b strings.Builder
+	for _, panel := range panels {
+		b.WriteString(formatPanel(&panel) + "\n")
+	}
+	utils.SendInfoEmbed(bot, bot_interaction, "Ticket-Panels", truncate(b.String(), 4000), true)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleTicketAdminAutocomplete schlägt Bereiche (key, parent) und Panels (panel) vor
+func HandleTicketAdminAutocomplete(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	focused := findFocusedOption(bot_interaction.ApplicationCommandData().Options)
+	if focused == nil {
+		return
+	}
+	input := strings.ToLower(fmt.Sprint(focused.Value))
+	service := ticketService.NewAreaService(bot)
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	switch focused.Name {
+	case "key", "parent":
+		areas, err := service.ListAreas()
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_admin.go", false, err, "Fehler beim Laden der Ticket-Bereiche für Autocomplete")
+		}
+		if focused.Name == "parent" {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: "Hauptbereich (kein Oberbereich)", Value: "-"})
+		}
+		for _, area := range areas {
+			if input != "" && !strings.Contains(strings.ToLower(area.Key+" "+area.Label), input) {
+				continue
+			}
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: truncate(area.Label+" ("+area.Key+")", 100), Value: area.Key})
+		}
+	case "welcome_template", "reject_template":
+		responses, err := ticketService.NewTicketService(bot).CannedResponses("", input)
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_admin.go", false, err, "Fehler beim Laden der Textbausteine für Autocomplete")
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: "Standardtext", Value: "-"})
+		for _, canned := range responses {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: truncate(canned.Title, 100), Value: strconv.Itoa(canned.ID)})
+		}
+	case "team":
+		teams, err := ticketService.NewTicketService(bot).ActiveTeams()
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_admin.go", false, err, "Fehler beim Laden der Teams für Autocomplete")
+		}
+		choices = append(choices,
+			&discordgo.ApplicationCommandOptionChoice{Name: "Kein Team", Value: "-"},
+			&discordgo.ApplicationCommandOptionChoice{Name: "Auswahl nach der Annahme", Value: ticketService.TeamSelect},
+		)
+		for _, team := range teams {
+			if input != "" && !strings.Contains(strings.ToLower(team.Name+" "+team.Game), input) {
+				continue
+			}
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: truncate(team.Game+" · "+team.Name, 100), Value: strconv.Itoa(team.ID)})
+		}
+	case "panel":
+		panels, err := service.ListPanels()
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_admin.go", false, err, "Fehler beim Laden der Ticket-Panels für Autocomplete")
+		}
+		for _, panel := range panels {
+			if input != "" && !strings.Contains(strings.ToLower(panel.Name+" "+panel.Title), input) {
+				continue
+			}
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: truncate(panel.Name+" - "+panel.Title, 100), Value: panel.ID})
+		}
+	}
+	if len(choices) > ticketService.MaxDropdownOptions {
+		choices = choices[:ticketService.MaxDropdownOptions]
+	}
+
+	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+// findFocusedOption sucht die fokussierte Option auch in Subcommands und Gruppen
+func findFocusedOption(options []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, opt := range options {
+		if opt.Focused {
+			return opt
+		}
+		if found := findFocusedOption(opt.Options); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+func respondTicketAdminError(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, err error, contextMsg string) {
+	// Eingabefehler gehen nur an den Nutzer, alles andere auch an die Admins
+	if errors.Is(err, ticketService.ErrInvalidInput) || errors.Is(err, ticketService.ErrNotFound) || errors.Is(err, ticketService.ErrDuplicate) {
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", err.Error(), true)
+		return
+	}
+	utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_admin.go", true, err, contextMsg)
+	utils.SendErrorEmbed(bot, bot_interaction, "Fehler", contextMsg, true)
+}
+
+// clearValue wandelt "-" in einen leeren Wert um
+func clearValue(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "-" {
+		return ""
+	}
+	return value
+}
+
+func truncate(value string, max int) string {
+	if len([]rune(value)) <= max {
+		return value
+	}
+	return string([]rune(value)[:max-3]) + "..."
+}
+
+func formatArea(area *ticketService.Area) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** `%s`\n", area.Label, area.Key)
+	if area.ParentKey != "" {
+		fmt.Fprintf(&b, "Oberbereich: `%s`\n", area.ParentKey)
+	}
+	fmt.Fprintf(&b, "Titel: %s | Formular: %s\n", area.DisplayName, area.ModalTitle)
+	if area.SupportRole != "" {
+		if area.MentionUser {
+			fmt.Fprintf(&b, "Ping: User %s\n", formatConfigRef(area.SupportRole, "<@%s>"))
+		} else {
+			fmt.Fprintf(&b, "Ping: Rolle %s\n", formatConfigRef(area.SupportRole, "<@&%s>"))
+		}
+	}
+	if area.Category != "" {
+		fmt.Fprintf(&b, "Kategorie: %s\n", formatConfigRef(area.Category, "<#%s>"))
+	}
+	fmt.Fprintf(&b, "Channel-Name: `%s`\n", area.NamePattern)
+	if area.SLAClaimMinutes > 0 || area.SLACloseHours > 0 || area.SLAReplyHours > 0 {
+		fmt.Fprintf(&b, "SLA: Übernahme %d Min. | Schließen %d Std. | Antwort %d Std.\n", area.SLAClaimMinutes, area.SLACloseHours, area.SLAReplyHours)
+	}
+	if area.MaxOpenTickets > 0 {
+		fmt.Fprintf(&b, "Offene Tickets pro User: %d\n", area.MaxOpenTickets)
+	}
+	if area.InactivityWarnDays > 0 {
+		fmt.Fprintf(&b, "Inaktivität: Warnung nach %d Tagen, Schließen %d Tage später", area.InactivityWarnDays, area.InactivityCloseDays)
+		if area.InactivityDelete {
+			b.WriteString(" inkl. Löschung")
+		}
+		b.WriteString("\n")
+	}
+	if area.AssignMode != "" {
+		fmt.Fprintf(&b, "Zuweisung: %s", area.AssignMode.Label())
+		if area.AssignMode != ticketService.AssignOff {
+			switch {
+			case len(area.AssignUsers) > 0:
+				b.WriteString(" | Pool: <@" + strings.Join(area.AssignUsers, "> <@") + ">")
+			case area.AssignRole != "":
+				b.WriteString(" | Pool: " + formatConfigRef(area.AssignRole, "<@&%s>"))
+			default:
+				b.WriteString(" | Pool: Support-Rolle")
+			}
+		}
+		b.WriteString("\n")
+	}
+	if !area.Enabled {
+		b.WriteString("*Deaktiviert*\n")
+	}
+	steps := area.StepCount()
+	for i, field := range area.Fields {
+		if steps > 1 && i%ticketService.MaxModalFields == 0 {
+			fmt.Fprintf(&b, "__Schritt %d/%d__\n", i/ticketService.MaxModalFields+1, steps)
+		}
+		required := ""
+		if field.Required {
+			required = " *"
+		}
+		fmt.Fprintf(&b, "`%d.` %s%s `%s` (%s", field.Position, field.Label, required, field.Key, field.Style)
+		if field.MaxLength > 0 {
+			fmt.Fprintf(&b, ", max %d", field.MaxLength)
+		}
+		if field.Validation != "" {
+			fmt.Fprintf(&b, ", %s", field.Validation)
+		}
+		if field.MinValue != nil {
+			fmt.Fprintf(&b, ", min. Wert %d", *field.MinValue)
+		}
+		if field.MaxValue != nil {
+			fmt.Fprintf(&b, ", max. Wert %d", *field.MaxValue)
+		}
+		b.WriteString(")\n")
+	}
+	return b.String()
+}
+
+// formatConfigRef zeigt Discord-IDs als Mention und const_keys als Code
+func formatConfigRef(value, mention string) string {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return "`" + value + "`"
+		}
+	}
+	return fmt.Sprintf(mention, value)
+}
+
+func formatPanel(panel *ticketService.Panel) string {
+	areas := "alle Hauptbereiche"
+	if len(panel.AreaKeys) > 0 {
+		areas = "`" + strings.Join(panel.AreaKeys, "`, `") + "`"
+	}
+	return fmt.Sprintf("`#%d` **%s** - %s\nBereiche: %s", panel.ID, panel.Name, panel.Title, areas)
+}
@@ -0,0 +1,173 @@
+package tickets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleTicketBlacklist behandelt /ticket_blacklist add|remove|list
+func HandleTicketBlacklist(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	data := bot_interaction.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return
+	}
+	sub := data.Options[0]
+	opts := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, opt := range sub.Options {
+		opts[opt.Name] = opt
+	}
+
+	service := ticketService.NewTicketService(bot)
+	switch sub.Name {
+	case "add":
+		handleTicketBlacklistAdd(bot, bot_interaction, service, opts)
+	case "remove":
+		user := opts["user"].UserValue(bot)
+		if err := service.Unblacklist(user.ID); err != nil {
+			respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Aufheben des Ticket-Ausschlusses")
+			return
+		}
+		utils.SendSuccessEmbed(bot, bot_interaction, "Ausschluss aufgehoben", fmt.Sprintf("<@%s> kann wieder Tickets erstellen.", user.ID), true)
+	case "list":
+		handleTicketBlacklistList(bot, bot_interaction, service)
+	}
+}
+
+func handleTicketBlacklistAdd(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *ticketService.TicketService, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	user := opts["user"].UserValue(bot)
+	entry := &ticketService.BlacklistEntry{
+		UserID:       user.ID,
+		Reason:       opts["reason"].StringValue(),
+		IssuedByID:   bot_interaction.Member.User.ID,
+		IssuedByName: bot_interaction.Member.User.Username,
+	}
+	if opt, ok := opts["days"]; ok {
+		entry.ExpiresAt = time.Now().AddDate(0, 0, int(opt.IntValue())).Unix()
+	}
+	if opt, ok := opts["areas"]; ok {
+		for _, key := range strings.Split(opt.StringValue(), ",") {
+			if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+				entry.AreaKeys = append(entry.AreaKeys, key)
+			}
+		}
+	}
+
+	if err := service.Blacklist(entry); err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Speichern des Ticket-Ausschlusses")
+		return
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Vom Ticket-System ausgeschlossen", formatBlacklistEntry(entry), true)
+}
+
+func handleTicketBlacklistList(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, service *ticketService.TicketService) {
+	entries, err := service.ListBlacklist(time.Now())
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Laden der Ticket-Ausschlüsse")
+		return
+	}
+	if len(entries) == 0 {
+		utils.SendInfoEmbed(bot, bot_interaction, "Ticket-Ausschlüsse", "Aktuell ist niemand vom Ticket-System ausgeschlossen.", true)
+		return
+	}
+
+	var b strings.Builder
+	for i := range entries {
+		b.WriteString(formatBlacklistEntry(&entries[i]) + "\n")
+	}
+	utils.SendInfoEmbed(bot, bot_interaction, fmt.Sprintf("Ticket-Ausschlüsse (%d)", len(entries)), truncate(b.String(), 4000), true)
+}
+
+func formatBlacklistEntry(entry *ticketService.BlacklistEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<@%s> - %s\n", entry.UserID, entry.Reason)
+	if len(entry.AreaKeys) > 0 {
+		fmt.Fprintf(&b, "Bereiche: `%s` | ", strings.Join(entry.AreaKeys, "`, `"))
+	} else {
+		b.WriteString("Alle Bereiche | ")
+	}
+	if entry.ExpiresAt > 0 {
+		fmt.Fprintf(&b, "bis <t:%d:f>", entry.ExpiresAt)
+	} else {
+		b.WriteString("dauerhaft")
+	}
+	fmt.Fprintf(&b, " | von <@%s>\n", entry.IssuedByID)
+	return b.String()
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// checkTicketCreate prüft Ausschluss, Limit und Cooldown und erklärt eine Ablehnung ephemer.
+// Ohne area wird vor der Bereichsauswahl geprüft. Bei Datenbankfehlern wird die Erstellung nicht blockiert.
+func checkTicketCreate(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, area *ticketService.Area) bool {
+	embed := ticketCreateDenial(bot, bot_interaction.Member.User.ID, area)
+	if embed == nil {
+		return true
+	}
+	err := bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_blacklist.go", true, err, "Fehler beim Senden der Ablehnung der Ticket-Erstellung")
+	}
+	return false
+}
+
+// ticketCreateDenial liefert das Embed zur Ablehnung oder nil, wenn der User ein Ticket erstellen darf
+func ticketCreateDenial(bot *discordgo.Session, userID string, area *ticketService.Area) *discordgo.MessageEmbed {
+	err := ticketService.NewTicketService(bot).CheckCreate(userID, area, time.Now())
+	if err == nil {
+		return nil
+	}
+	var denial *ticketService.CreateDenial
+	if !errors.As(err, &denial) {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_blacklist.go", true, err, "Fehler bei der Prüfung vor der Ticket-Erstellung")
+		return nil
+	}
+
+	embed := &discordgo.MessageEmbed{Color: utils.ColorWarning}
+	switch {
+	case errors.Is(denial, ticketService.ErrBlacklisted):
+		embed.Title = "Ticket-Erstellung nicht möglich"
+		embed.Color = utils.ColorError
+		scope := "Du kannst aktuell keine Tickets erstellen."
+		if len(denial.Entry.AreaKeys) > 0 {
+			scope = "Du kannst in diesem Bereich aktuell keine Tickets erstellen."
+		}
+		embed.Description = fmt.Sprintf("%s\n**Grund:** %s", scope, denial.Entry.Reason)
+		if denial.Entry.ExpiresAt > 0 {
+			embed.Description += fmt.Sprintf("\n**Gültig bis:** <t:%d:f>", denial.Entry.ExpiresAt)
+		}
+		embed.Description += "\n\nBei Fragen wende dich bitte an das Management."
+	case errors.Is(denial, ticketService.ErrCooldown):
+		embed.Title = "Bitte kurz warten"
+		embed.Description = fmt.Sprintf("Du hast gerade erst ein Ticket erstellt. Ein neues Ticket kannst du <t:%d:R> erstellen.", denial.Until)
+	case errors.Is(denial, ticketService.ErrTicketLimit):
+		embed.Title = "Du hast bereits ein offenes Ticket"
+		var channels []string
+		for _, ticket := range denial.Open {
+			if ticket.ChannelID != "" {
+				channels = append(channels, "<#"+ticket.ChannelID+">")
+			} else {
+				channels = append(channels, fmt.Sprintf("#%d", ticket.ID))
+			}
+		}
+		embed.Description = fmt.Sprintf("In diesem Bereich sind maximal %d offene Tickets gleichzeitig möglich. Bitte nutze dein bestehendes Ticket: %s",
+			denial.Limit, strings.Join(channels, ", "))
+	}
+	return embed
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
@@ -0,0 +1,289 @@
+package tickets
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleTicketResponse behandelt /ticket_response response [preview]: im Ticket wird der Textbaustein mit eingesetzten
+// Platzhaltern in den Channel gesendet, außerhalb eines Tickets oder mit preview nur ephemer angezeigt
+func HandleTicketResponse(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	opts := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, opt := range bot_interaction.ApplicationCommandData().Options {
+		opts[opt.Name] = opt
+	}
+	service := ticketService.NewTicketService(bot)
+	canned, ok := cannedFromOption(bot, bot_interaction, opts)
+	if !ok {
+		return
+	}
+
+	var ticket *ticketService.Ticket
+	if ticketID, err := service.TicketIDForChannel(bot_interaction.ChannelID); err == nil {
+		ticket, _ = service.GetTicket(ticketID)
+	}
+	message, err := service.RenderCannedResponse(canned, ticket, nil)
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Aufbereiten des Textbausteins")
+		return
+	}
+
+	preview := ticket == nil
+	if opt, ok := opts["preview"]; ok && opt.BoolValue() {
+		preview = true
+	}
+	data := &discordgo.InteractionResponseData{Content: message.Content, Embeds: message.Embeds}
+	if preview {
+		data.Flags = discordgo.MessageFlagsEphemeral
+	}
+	err = bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_canned.go", true, err, "Fehler beim Senden des Textbausteins "+canned.Title)
+		return
+	}
+	if preview {
+		return
+	}
+	// Modmail-Ersteller sehen den Channel nicht, der Textbaustein geht zusätzlich per DM
+	if ticket.Modmail && ticket.Status.HasAccess() {
+		if err := sendModmailDM(bot, ticket, &discordgo.MessageSend{Content: message.Content, Embeds: message.Embeds}); err != nil {
+			_, err = bot.FollowupMessageCreate(bot_interaction.Interaction, true, &discordgo.WebhookParams{
+				Embeds: []*discordgo.MessageEmbed{{Title: "Nicht per DM zugestellt", Description: modmailErrorText(bot, err), Color: utils.ColorWarning}},
+				Flags:  discordgo.MessageFlagsEphemeral,
+			})
+			if err != nil {
+				utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_canned.go", true, err, "Fehler beim Melden des nicht zugestellten Textbausteins")
+			}
+		}
+	}
+	if err := service.RecordCannedUse(canned.ID); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_canned.go", false, err, "Fehler beim Zählen der Nutzung von Textbaustein "+canned.Title)
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleCannedCommand behandelt /canned add|edit|remove|list
+func HandleCannedCommand(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	data := bot_interaction.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return
+	}
+	sub := data.Options[0]
+	opts := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, opt := range sub.Options {
+		opts[opt.Name] = opt
+	}
+
+	switch sub.Name {
+	case "add":
+		canned := &ticketService.CannedResponse{Format: ticketService.CannedMarkdown}
+		if opt, ok := opts["area"]; ok {
+			canned.AreaKey = opt.StringValue()
+		}
+		if opt, ok := opts["format"]; ok {
+			canned.Format = opt.StringValue()
+		}
+		respondCannedModal(bot, bot_interaction, canned)
+	case "edit":
+		canned, ok := cannedFromOption(bot, bot_interaction, opts)
+		if !ok {
+			return
+		}
+		respondCannedModal(bot, bot_interaction, canned)
+	case "remove":
+		canned, ok := cannedFromOption(bot, bot_interaction, opts)
+		if !ok {
+			return
+		}
+		if err := ticketService.NewTicketService(bot).DeleteCannedResponse(canned.ID); err != nil {
+			respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Löschen des Textbausteins")
+			return
+		}
+		utils.SendSuccessEmbed(bot, bot_interaction, "Textbaustein gelöscht", fmt.Sprintf("**%s** wurde gelöscht (%d× verwendet).", canned.Title, canned.UsageCount), true)
+	case "list":
+		areaKey := ""
+		if opt, ok := opts["area"]; ok {
+			areaKey = opt.StringValue()
+		}
+		responses, err := ticketService.NewTicketService(bot).CannedResponses(areaKey, "")
+		if err != nil {
+			respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Laden der Textbausteine")
+			return
+		}
+		utils.SendInfoEmbed(bot, bot_interaction, fmt.Sprintf("Textbausteine (%d)", len(responses)), formatCannedList(responses), true)
+	}
+}
+
+// cannedFromOption lädt den Textbaustein aus der Option response (Autocomplete liefert die ID)
+func cannedFromOption(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) (*ticketService.CannedResponse, bool) {
+	opt, ok := opts["response"]
+	if !ok {
+		return nil, false
+	}
+	id, err := strconv.Atoi(opt.StringValue())
+	if err != nil {
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Bitte einen Textbaustein aus der Liste auswählen.", true)
+		return nil, false
+	}
+	canned, err := ticketService.NewTicketService(bot).CannedResponse(id)
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Laden des Textbausteins")
+		return nil, false
+	}
+	return canned, true
+}
+
+// formatCannedList listet die Textbausteine mit Bereich und Nutzung
+func formatCannedList(responses []ticketService.CannedResponse) string {
+	if len(responses) == 0 {
+		return "Keine Textbausteine vorhanden. Neue mit `/canned add` anlegen."
+	}
+	lines := make([]string, 0, len(responses))
+	for _, canned := range responses {
+		area := "alle Bereiche"
+		if canned.AreaKey != "" {
+			area = "`" + canned.AreaKey + "`"
+		}
+		line := fmt.Sprintf("**%s** · %s · %s · %d× verwendet", canned.Title, area, canned.Format, canned.UsageCount)
+		if canned.LastUsedAt > 0 {
+			line += fmt.Sprintf(", zuletzt <t:%d:R>", canned.LastUsedAt)
+		}
+		lines = append(lines, line)
+	}
+	return truncate(strings.Join(lines, "\n"), 4000)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// respondCannedModal öffnet das Modal zum Anlegen (ID 0) oder Bearbeiten eines Textbausteins
+func respondCannedModal(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, canned *ticketService.CannedResponse) {
+	title := "Neuer Textbaustein"
+	if canned.ID > 0 {
+		title = truncate("Textbaustein: "+canned.Title, 45)
+	}
+	err := bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: fmt.Sprintf("canned_modal_%d", canned.ID),
+			Title:    title,
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{CustomID: "title", Label: "Titel", Style: discordgo.TextInputShort, Value: canned.Title, Required: true, MaxLength: 80},
+				}},
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{CustomID: "area", Label: "Bereich (Schlüssel, leer = alle Bereiche)", Style: discordgo.TextInputShort, Value: canned.AreaKey, Required: false, MaxLength: 100},
+				}},
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{CustomID: "format", Label: "Format (markdown oder embed)", Style: discordgo.TextInputShort, Value: canned.Format, Required: true, MaxLength: 8},
+				}},
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:    "body",
+						Label:       "Text bzw. Embed-JSON",
+						Style:       discordgo.TextInputParagraph,
+						Value:       canned.Body,
+						Placeholder: "Hi {creator}, ... Platzhalter: {creator} {claimer} {area} {ticket_id}",
+						Required:    true,
+						MaxLength:   4000,
+					},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_canned.go", true, err, "Fehler beim Öffnen des Textbaustein-Modals")
+	}
+}
+
+// HandleCannedModal speichert den Textbaustein aus dem Modal (canned_modal_0 = neu)
+func HandleCannedModal(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	data := bot_interaction.ModalSubmitData()
+	id, err := strconv.Atoi(strings.TrimPrefix(data.CustomID, "canned_modal_"))
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_canned.go", true, err, "Fehler beim Parsen der Textbaustein-ID aus der Modal CustomID")
+		return
+	}
+	values := modalValues(data, nil)
+	canned := &ticketService.CannedResponse{
+		ID:      id,
+		Title:   values["title"],
+		AreaKey: values["area"],
+		Format:  values["format"],
+		Body:    values["body"],
+	}
+
+	service := ticketService.NewTicketService(bot)
+	title := "Textbaustein gespeichert"
+	if id == 0 {
+		err = service.CreateCannedResponse(canned, ticketService.Actor{ID: bot_interaction.Member.User.ID, Name: bot_interaction.Member.User.Username})
+		title = "Textbaustein angelegt"
+	} else {
+		err = service.UpdateCannedResponse(canned)
+	}
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Speichern des Textbausteins")
+		return
+	}
+
+	// Vorschau ohne Ticket, die Platzhalter bleiben sichtbar
+	preview := canned.Body
+	if message, err := service.RenderCannedResponse(canned, nil, nil); err == nil && message.Content != "" {
+		preview = message.Content
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, title, fmt.Sprintf("**%s** (%s)\n\n%s", canned.Title, canned.Format, truncate(preview, 3800)), true)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleCannedAutocomplete liefert Textbausteine (im Ticket nur die des Bereichs) und Bereiche für /canned und /ticket_response
+func HandleCannedAutocomplete(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	focused := findFocusedOption(bot_interaction.ApplicationCommandData().Options)
+	if focused == nil {
+		return
+	}
+	input := strings.TrimSpace(fmt.Sprint(focused.Value))
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	switch focused.Name {
+	case "response":
+		service := ticketService.NewTicketService(bot)
+		areaKey := ""
+		if ticketID, err := service.TicketIDForChannel(bot_interaction.ChannelID); err == nil {
+			if ticket, err := service.GetTicket(ticketID); err == nil {
+				areaKey = ticket.Area
+			}
+		}
+		responses, err := service.CannedResponses(areaKey, input)
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_canned.go", false, err, "Fehler beim Laden der Textbausteine für Autocomplete")
+		}
+		for _, canned := range responses {
+			label := fmt.Sprintf("%s (%d×)", canned.Title, canned.UsageCount)
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: truncate(label, 100), Value: strconv.Itoa(canned.ID)})
+		}
+	case "area":
+		choices = areaChoices(bot, input)
+	}
+	if len(choices) > ticketService.MaxDropdownOptions {
+		choices = choices[:ticketService.MaxDropdownOptions]
+	}
+
+	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
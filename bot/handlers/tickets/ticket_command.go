@@ -0,0 +1,143 @@
+package tickets
+
+import (
+	"fmt"
+	"strings"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleTicketCommand behandelt /ticket <subcommand>
+func HandleTicketCommand(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	data := bot_interaction.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return
+	}
+	sub := data.Options[0]
+	opts := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, opt := range sub.Options {
+		opts[opt.Name] = opt
+	}
+
+	switch sub.Name {
+	case "note":
+		ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "ticket_command.go")
+		if !ok {
+			return
+		}
+		saveTicketNote(bot, bot_interaction, ticket.ID, opts["text"].StringValue())
+	case "tag":
+		handleTicketTagCommand(bot, bot_interaction, opts)
+	case "search":
+		handleTicketSearchCommand(bot, bot_interaction, opts)
+	case "absent":
+		handleTicketAbsentCommand(bot, bot_interaction, opts)
+	case "present":
+		handleTicketPresentCommand(bot, bot_interaction, opts)
+	case "move":
+		handleTicketMoveCommand(bot, bot_interaction, opts)
+	case "add":
+		handleTicketAddCommand(bot, bot_interaction, opts)
+	case "remove":
+		handleTicketRemoveCommand(bot, bot_interaction, opts)
+	case "funnel":
+		handleTicketFunnelCommand(bot, bot_interaction, opts)
+	case "csat":
+		handleTicketCSATCommand(bot, bot_interaction, opts)
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleTicketAutocomplete liefert Vorschläge für /ticket (Tags, Suchtreffer und Bereiche) und den Bereich von /ticket_stats
+func HandleTicketAutocomplete(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	focused := findFocusedOption(bot_interaction.ApplicationCommandData().Options)
+	if focused == nil {
+		return
+	}
+	input := strings.TrimSpace(fmt.Sprint(focused.Value))
+	service := ticketService.NewTicketService(bot)
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	switch focused.Name {
+	case "add":
+		tags, err := service.KnownTags(input)
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_command.go", false, err, "Fehler beim Laden der Tags für Autocomplete")
+		}
+		if tag := ticketService.NormalizeTag(input); tag != "" && !containsString(tags, tag) {
+			// Freie Tags sind erlaubt, die Eingabe steht deshalb immer zur Auswahl
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: tag + " (neu)", Value: tag})
+		}
+		for _, tag := range tags {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: tag, Value: tag})
+		}
+	case "remove":
+		if ticketID, err := service.TicketIDForChannel(bot_interaction.ChannelID); err == nil {
+			if ticket, err := service.GetTicket(ticketID); err == nil {
+				for _, tag := range ticket.Tags {
+					if strings.HasPrefix(tag, strings.ToLower(input)) {
+						choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: tag, Value: tag})
+					}
+				}
+			}
+		}
+	case "query":
+		if len([]rune(input)) < 2 {
+			break
+		}
+		results, err := service.Search(input, "", ticketService.MaxDropdownOptions)
+		if err != nil {
+			break
+		}
+		for _, result := range results {
+			label := fmt.Sprintf("#%d · %s · %s", result.Ticket.ID, result.Ticket.Area, result.Ticket.CreatorName)
+			if result.Snippet != "" {
+				label += " – " + strings.ReplaceAll(result.Snippet, "**", "")
+			}
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: truncate(label, 100), Value: fmt.Sprintf("#%d", result.Ticket.ID)})
+		}
+	case "area":
+		choices = areaChoices(bot, input)
+	}
+	if len(choices) > ticketService.MaxDropdownOptions {
+		choices = choices[:ticketService.MaxDropdownOptions]
+	}
+
+	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+// areaChoices liefert die Ticket-Bereiche als Autocomplete-Vorschläge
+func areaChoices(bot *discordgo.Session, input string) []*discordgo.ApplicationCommandOptionChoice {
+	areas, err := ticketService.NewAreaService(bot).ListAreas()
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_command.go", false, err, "Fehler beim Laden der Ticket-Bereiche für Autocomplete")
+	}
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, area := range areas {
+		if input != "" && !strings.Contains(strings.ToLower(area.Key+" "+area.Label), strings.ToLower(input)) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: truncate(area.Label+" ("+area.Key+")", 100), Value: area.Key})
+	}
+	return choices
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
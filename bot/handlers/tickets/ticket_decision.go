@@ -0,0 +1,498 @@
+package tickets
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleApproveButton zählt die Zustimmung des Klickenden und aktualisiert das Panel
+func HandleApproveButton(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "ticket_decision.go")
+	if !ok {
+		return
+	}
+	voter := ticketService.Actor{ID: bot_interaction.Member.User.ID, Name: bot_interaction.Member.User.Username}
+	updated, state, decided, ok := castDecisionVote(bot, bot_interaction, ticket.ID, voter, ticketService.DecisionApproved, "")
+	if !ok {
+		return
+	}
+	respondModerationPanel(bot, bot_interaction, updated)
+	if decided {
+		completeDecision(bot, updated, state)
+	}
+}
+
+// HandleRejectButton fragt die Begründung ab, sie wird dem Bewerber bei der Ablehnung geschickt
+func HandleRejectButton(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "ticket_decision.go")
+	if !ok {
+		return
+	}
+	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: fmt.Sprintf("ticket_reject_modal_%d", ticket.ID),
+			Title:    fmt.Sprintf("Bewerbung #%d ablehnen", ticket.ID),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "reason",
+							Label:       "Begründung (wird dem Bewerber geschickt)",
+							Style:       discordgo.TextInputParagraph,
+							Placeholder: "z.B. Aktuell sind alle Plätze im Team vergeben",
+							Required:    false,
+							MaxLength:   1000,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// HandleRejectModal zählt die Ablehnung inkl. Begründung
+func HandleRejectModal(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticketID, err := strconv.Atoi(strings.TrimPrefix(bot_interaction.ModalSubmitData().CustomID, "ticket_reject_modal_"))
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_decision.go", true, err, "Fehler beim Parsen der Ticket-ID aus der Modal CustomID")
+		return
+	}
+	reason := modalValues(bot_interaction.ModalSubmitData(), nil)["reason"]
+	voter := ticketService.Actor{ID: bot_interaction.Member.User.ID, Name: bot_interaction.Member.User.Username}
+	updated, state, decided, ok := castDecisionVote(bot, bot_interaction, ticketID, voter, ticketService.DecisionRejected, reason)
+	if !ok {
+		return
+	}
+
+	description := fmt.Sprintf("Deine Ablehnung wurde gezählt (%d/%d).", state.Rejections, state.Rule.Quorum)
+	if decided {
+		description = "Die Bewerbung wurde abgelehnt."
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Stimme gespeichert", description, true)
+	updateModerationPanel(bot, updated.ChannelID, updated)
+	if decided {
+		completeDecision(bot, updated, state)
+	}
+}
+
+// castDecisionVote speichert die Stimme und antwortet bei Fehlern selbst
+func castDecisionVote(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, ticketID int, voter ticketService.Actor, vote ticketService.Decision, reason string) (*ticketService.Ticket, *ticketService.DecisionState, bool, bool) {
+	ticket, state, decided, err := ticketService.NewTicketService(bot).CastVote(ticketID, voter, vote, reason)
+	switch {
+	case err == nil:
+		return ticket, state, decided, true
+	case errors.Is(err, ticketService.ErrAlreadyDecided), errors.Is(err, ticketService.ErrNoDecisionRule):
+		utils.SendWarningEmbed(bot, bot_interaction, "Nicht möglich", err.Error(), true)
+	default:
+		handleTransitionError(bot, bot_interaction, "ticket_decision.go", ticket, err)
+	}
+	return nil, nil, false, false
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// completeDecision verkündet das Ergebnis im Ticket und führt die Aktionen der Regel aus
+func completeDecision(bot *discordgo.Session, ticket *ticketService.Ticket, state *ticketService.DecisionState) {
+	service := ticketService.NewTicketService(bot)
+	rule := state.Rule
+	if ticket.Decision == ticketService.DecisionRejected {
+		announceDecision(bot, ticket, state, "")
+		fallback := "Hi {creator},\n\nvielen Dank für deine Bewerbung ({area}). Leider können wir sie dieses Mal nicht annehmen."
+		if state.Reason != "" {
+			fallback += "\n\n**Begründung:** {reason}"
+		}
+		sendDecisionDM(bot, ticket, rule.RejectTemplate, fallback, map[string]string{"reason": state.Reason})
+		return
+	}
+
+	teamID := 0
+	if rule.Team != ticketService.TeamSelect {
+		teamID, _ = strconv.Atoi(rule.Team)
+	}
+	granted, err := service.Onboard(ticket, service.ApproveRoleIDs(rule), teamID)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_decision.go", true, err, fmt.Sprintf("Fehler beim Onboarding nach der Annahme von Ticket #%d", ticket.ID))
+	}
+	summary := ""
+	if len(granted) > 0 {
+		summary = "Vergebene Rollen: <@&" + strings.Join(granted, "> <@&") + ">"
+	}
+	announceDecision(bot, ticket, state, summary)
+	if rule.Team == ticketService.TeamSelect {
+		sendTeamSelect(bot, ticket)
+	}
+
+	sendDecisionDM(bot, ticket, rule.WelcomeTemplate, "Hi {creator},\n\ndeine Bewerbung ({area}) wurde angenommen – herzlich willkommen! 🎉", nil)
+	if channelID := service.WelcomeChannelID(rule); channelID != "" {
+		area := ticket.Area
+		if a, err := ticketService.NewAreaService(bot).GetArea(ticket.Area); err == nil {
+			area = a.DisplayName
+		}
+		_, err := bot.ChannelMessageSendEmbed(channelID, &discordgo.MessageEmbed{
+			Title:       "Herzlich willkommen!",
+			Description: fmt.Sprintf("<@%s> ist neu dabei: **%s**", ticket.CreatorID, area),
+			Color:       utils.ColorSuccess,
+		})
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_decision.go", true, err, "Fehler beim Willkommens-Post für Ticket #"+strconv.Itoa(ticket.ID))
+		}
+	}
+}
+
+// announceDecision schreibt das Ergebnis mit den Stimmen in den Ticket-Channel
+func announceDecision(bot *discordgo.Session, ticket *ticketService.Ticket, state *ticketService.DecisionState, summary string) {
+	embed := &discordgo.MessageEmbed{
+		Title:  fmt.Sprintf("Bewerbung %s", strings.ToLower(ticket.Decision.Label())),
+		Fields: []*discordgo.MessageEmbedField{{Name: "Stimmen", Value: formatVotes(state.Votes)}},
+		Color:  utils.ColorSuccess,
+	}
+	if ticket.Decision == ticketService.DecisionRejected {
+		embed.Color = utils.ColorError
+	}
+	if state.Reason != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Begründung", Value: truncate(state.Reason, 1024)})
+	}
+	embed.Description = summary
+	if _, err := bot.ChannelMessageSendEmbed(ticket.ChannelID, embed); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_decision.go", true, err, "Fehler beim Senden der Entscheidung in Ticket #"+strconv.Itoa(ticket.ID))
+	}
+}
+
+// sendDecisionDM schickt dem Bewerber den Textbaustein (templateID 0 = Standardtext), geschlossene DMs sind kein Fehler
+func sendDecisionDM(bot *discordgo.Session, ticket *ticketService.Ticket, templateID int, fallback string, extra map[string]string) {
+	service := ticketService.NewTicketService(bot)
+	canned := &ticketService.CannedResponse{Format: ticketService.CannedMarkdown, Body: fallback}
+	if templateID > 0 {
+		loaded, err := service.CannedResponse(templateID)
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_decision.go", true, err, fmt.Sprintf("Textbaustein %d für die Entscheidung über Ticket #%d nicht gefunden, sende Standardtext", templateID, ticket.ID))
+		} else {
+			canned = loaded
+		}
+	}
+	message, err := service.RenderCannedResponse(canned, ticket, extra)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_decision.go", true, err, "Fehler beim Aufbereiten der DM zur Entscheidung über Ticket #"+strconv.Itoa(ticket.ID))
+		return
+	}
+
+	dmChannel, err := bot.UserChannelCreate(ticket.CreatorID)
+	if err == nil {
+		_, err = bot.ChannelMessageSendComplex(dmChannel.ID, message)
+	}
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Info", "ticket_decision.go", false, err, "DM an den Ersteller von Ticket #"+strconv.Itoa(ticket.ID)+" nicht möglich")
+		return
+	}
+	if canned.ID > 0 {
+		service.RecordCannedUse(canned.ID)
+	}
+}
+
+// formatVotes listet die Stimmen für Panel und Ergebnis
+func formatVotes(votes []ticketService.Vote) string {
+	if len(votes) == 0 {
+		return "-"
+	}
+	lines := make([]string, 0, len(votes))
+	for _, vote := range votes {
+		icon := "✅"
+		if vote.Vote == ticketService.DecisionRejected {
+			icon = "❌"
+		}
+		lines = append(lines, fmt.Sprintf("%s <@%s>", icon, vote.UserID))
+	}
+	return truncate(strings.Join(lines, "\n"), 1024)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// sendTeamSelect postet nach der Annahme die Auswahl des Teams (Regel mit team = select)
+func sendTeamSelect(bot *discordgo.Session, ticket *ticketService.Ticket) {
+	teams, err := ticketService.NewTicketService(bot).ActiveTeams()
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_decision.go", true, err, "Fehler beim Laden der Teams für Ticket #"+strconv.Itoa(ticket.ID))
+		return
+	}
+	if len(teams) == 0 {
+		bot.ChannelMessageSend(ticket.ChannelID, "Es gibt keine aktiven Teams, die Rolle muss von Hand vergeben werden.")
+		return
+	}
+
+	options := make([]discordgo.SelectMenuOption, 0, len(teams))
+	for _, team := range teams {
+		if len(options) == ticketService.MaxDropdownOptions {
+			break
+		}
+		options = append(options, discordgo.SelectMenuOption{Label: truncate(team.Name, 100), Description: team.Game, Value: strconv.Itoa(team.ID)})
+	}
+	_, err = bot.ChannelMessageSendComplex(ticket.ChannelID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("In welches Team kommt <@%s>?", ticket.CreatorID),
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.SelectMenu{CustomID: ticketCustomID("ticket_select_team", ticket.ID), Placeholder: "Team auswählen", Options: options},
+				},
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_decision.go", true, err, "Fehler beim Senden der Team-Auswahl in Ticket #"+strconv.Itoa(ticket.ID))
+	}
+}
+
+// HandleTeamSelect trägt den angenommenen Bewerber ins ausgewählte Team ein und vergibt die Team-Rolle
+func HandleTeamSelect(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "ticket_decision.go")
+	if !ok {
+		return
+	}
+	data := bot_interaction.MessageComponentData()
+	if len(data.Values) == 0 {
+		return
+	}
+	if ticket.Decision != ticketService.DecisionApproved {
+		utils.SendWarningEmbed(bot, bot_interaction, "Nicht möglich", "Die Bewerbung wurde nicht angenommen.", true)
+		return
+	}
+	teamID, err := strconv.Atoi(data.Values[0])
+	if err != nil || teamID <= 0 {
+		return
+	}
+	service := ticketService.NewTicketService(bot)
+	team, err := service.GetTeam(teamID)
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Laden des Teams")
+		return
+	}
+	granted, err := service.Onboard(ticket, nil, teamID)
+
+	// Ohne vergebene Rolle wird das Team mit Namen genannt, die Admins kümmern sich um den Rest
+	content := fmt.Sprintf("<@%s> wurde von <@%s> ins Team **%s** eingetragen.", ticket.CreatorID, bot_interaction.Member.User.ID, team.Name)
+	if slices.Contains(granted, team.RoleID) {
+		content = fmt.Sprintf("<@%s> wurde von <@%s> ins Team <@&%s> eingetragen.", ticket.CreatorID, bot_interaction.Member.User.ID, team.RoleID)
+	}
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_decision.go", true, err, fmt.Sprintf("Fehler beim Eintragen von Ticket #%d ins Team %s", ticket.ID, team.Name))
+		content += "\n⚠️ Rolle oder Team-Eintrag konnten nicht vollständig gespeichert werden, die Admins wurden informiert."
+	}
+
+	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:         content,
+			Components:      []discordgo.MessageComponent{},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// decisionButtons liefert die Buttons für Annahme und Ablehnung, nil für Bereiche ohne Regel
+func decisionButtons(bot *discordgo.Session, ticket *ticketService.Ticket) []discordgo.MessageComponent {
+	state, err := ticketService.NewTicketService(bot).DecisionState(ticket.ID)
+	if err != nil || state.Rule == nil {
+		return nil
+	}
+	approve, reject := "Annehmen", "Ablehnen"
+	if state.Rule.Quorum > 1 {
+		approve = fmt.Sprintf("Annehmen (%d/%d)", state.Approvals, state.Rule.Quorum)
+		reject = fmt.Sprintf("Ablehnen (%d/%d)", state.Rejections, state.Rule.Quorum)
+	}
+	disabled := ticket.Decision != "" || (ticket.Status != ticketService.StatusOpen && ticket.Status != ticketService.StatusClaimed)
+	return []discordgo.MessageComponent{
+		&discordgo.Button{Style: discordgo.SuccessButton, Label: approve, CustomID: ticketCustomID("ticket_button_approve", ticket.ID), Disabled: disabled},
+		&discordgo.Button{Style: discordgo.DangerButton, Label: reject, CustomID: ticketCustomID("ticket_button_reject", ticket.ID), Disabled: disabled},
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// handleTicketFunnelCommand zeigt die Bewerbungs-Kennzahlen je Bereich (/ticket funnel [days])
+func handleTicketFunnelCommand(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	days := 30
+	if opt, ok := opts["days"]; ok {
+		days = int(opt.IntValue())
+	}
+
+	now := time.Now()
+	report, err := ticketService.NewTicketService(bot).FunnelReport(now.AddDate(0, 0, -days), now)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_decision.go", true, err, "Fehler beim Erstellen des Bewerbungs-Berichts")
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Der Bewerbungs-Bericht konnte nicht erstellt werden.", true)
+		return
+	}
+	if len(report.Areas) == 0 {
+		utils.SendInfoEmbed(bot, bot_interaction, "Bewerbungen", fmt.Sprintf("In den letzten %d Tagen gab es keine Bewerbungen.", days), true)
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Bewerbungen · letzte %d Tage", days),
+		Description: "Bereiche mit Entscheidungs-Regel (`/ticket_admin decision`).",
+		Color:       utils.ColorInfo,
+	}
+	for _, area := range report.Areas {
+		if len(embed.Fields) == 25 {
+			break
+		}
+		value := fmt.Sprintf("%d beworben · %d angenommen · %d abgelehnt · %d offen\nØ bis zur Entscheidung: %s",
+			area.Applied, area.Approved, area.Rejected, area.Undecided, formatSLAAverage(area.AvgDecisionSeconds, area.Approved+area.Rejected))
+		if area.Applied > 0 {
+			value += fmt.Sprintf(" · Annahmequote %d%%", area.Approved*100/area.Applied)
+		}
+		label := area.Label
+		if label == "" {
+			label = "Ohne Bereich"
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: truncate(label, 256), Value: value})
+	}
+
+	err = bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_decision.go", true, err, "Fehler beim Senden des Bewerbungs-Berichts")
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// handleTicketAdminDecision behandelt /ticket_admin decision set|remove|list
+func handleTicketAdminDecision(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, sub string, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	service := ticketService.NewTicketService(bot)
+	rules, err := service.DecisionRules()
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Laden der Entscheidungs-Regeln")
+		return
+	}
+
+	switch sub {
+	case "set":
+		key := opts["key"].StringValue()
+		// Nur übergebene Optionen ändern eine bestehende Regel
+		rule := &ticketService.DecisionRule{AreaKey: key, Quorum: 1}
+		for i := range rules {
+			if rules[i].AreaKey == key {
+				rule = &rules[i]
+			}
+		}
+		if opt, ok := opts["quorum"]; ok {
+			rule.Quorum = int(opt.IntValue())
+		}
+		if opt, ok := opts["roles"]; ok {
+			rule.ApproveRoles = parseRoleRefs(clearValue(opt.StringValue()))
+		}
+		if opt, ok := opts["team"]; ok {
+			rule.Team = clearValue(opt.StringValue())
+		}
+		if opt, ok := opts["welcome_template"]; ok {
+			rule.WelcomeTemplate, _ = strconv.Atoi(clearValue(opt.StringValue()))
+		}
+		if opt, ok := opts["reject_template"]; ok {
+			rule.RejectTemplate, _ = strconv.Atoi(clearValue(opt.StringValue()))
+		}
+		if opt, ok := opts["welcome_channel"]; ok {
+			rule.WelcomeChannel = opt.ChannelValue(bot).ID
+		}
+		if opt, ok := opts["clear_welcome_channel"]; ok && opt.BoolValue() {
+			rule.WelcomeChannel = ""
+		}
+		if err := service.SetDecisionRule(rule); err != nil {
+			respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Speichern der Entscheidungs-Regel")
+			return
+		}
+		utils.SendSuccessEmbed(bot, bot_interaction, "Entscheidungs-Regel gespeichert", formatDecisionRule(service, rule), true)
+	case "remove":
+		key := opts["key"].StringValue()
+		if err := service.DeleteDecisionRule(key); err != nil {
+			respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Löschen der Entscheidungs-Regel")
+			return
+		}
+		utils.SendSuccessEmbed(bot, bot_interaction, "Entscheidungs-Regel gelöscht", fmt.Sprintf("Tickets in `%s` bekommen keine Annehmen/Ablehnen-Buttons mehr (außer über den Oberbereich).", key), true)
+	case "list":
+		if len(rules) == 0 {
+			utils.SendInfoEmbed(bot, bot_interaction, "Entscheidungs-Regeln", "Es sind keine Regeln eingerichtet. Anlegen mit `/ticket_admin decision set`.", true)
+			return
+		}
+		entries := make([]string, 0, len(rules))
+		for i := range rules {
+			entries = append(entries, formatDecisionRule(service, &rules[i]))
+		}
+		utils.SendInfoEmbed(bot, bot_interaction, "Entscheidungs-Regeln", truncate(strings.Join(entries, "\n\n"), 4000), true)
+	}
+}
+
+// roleRefPattern findet Rollen-Erwähnungen (<@&123>), IDs und const_keys wie ROLE_DIAMOND_CLUB
+var roleRefPattern = regexp.MustCompile(`<@&(\d+)>|[A-Za-z0-9_]+`)
+
+// parseRoleRefs liest Rollen aus einer Liste von Erwähnungen, IDs und const_keys
+func parseRoleRefs(value string) []string {
+	roles := []string{}
+	for _, match := range roleRefPattern.FindAllStringSubmatch(value, -1) {
+		if match[1] != "" {
+			roles = append(roles, match[1])
+		} else {
+			roles = append(roles, match[0])
+		}
+	}
+	return roles
+}
+
+// formatDecisionRule zeigt Quorum und Aktionen einer Regel
+func formatDecisionRule(service *ticketService.TicketService, rule *ticketService.DecisionRule) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**`%s`** · Quorum %d\n", rule.AreaKey, rule.Quorum)
+	if len(rule.ApproveRoles) > 0 {
+		refs := make([]string, 0, len(rule.ApproveRoles))
+		for _, role := range rule.ApproveRoles {
+			refs = append(refs, formatConfigRef(role, "<@&%s>"))
+		}
+		b.WriteString("Rollen: " + strings.Join(refs, " ") + "\n")
+	}
+	switch rule.Team {
+	case "":
+	case ticketService.TeamSelect:
+		b.WriteString("Team: Auswahl nach der Annahme\n")
+	default:
+		fmt.Fprintf(&b, "Team: #%s\n", rule.Team)
+	}
+	for _, template := range []struct {
+		label string
+		id    int
+	}{{"Willkommens-DM", rule.WelcomeTemplate}, {"Ablehnungs-DM", rule.RejectTemplate}} {
+		name := "Standardtext"
+		if template.id > 0 {
+			name = fmt.Sprintf("#%d", template.id)
+			if canned, err := service.CannedResponse(template.id); err == nil {
+				name = canned.Title
+			}
+		}
+		fmt.Fprintf(&b, "%s: %s\n", template.label, name)
+	}
+	if rule.WelcomeChannel != "" {
+		b.WriteString("Willkommens-Post: " + formatConfigRef(rule.WelcomeChannel, "<#%s>") + "\n")
+	}
+	return b.String()
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
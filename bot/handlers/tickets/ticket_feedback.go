@@ -0,0 +1,294 @@
+package tickets
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// sendFeedbackRequest schickt dem Ersteller nach dem Schließen einmalig die Zufriedenheits-Umfrage per DM
+func sendFeedbackRequest(bot *discordgo.Session, ticket *ticketService.Ticket) {
+	if ticket.CreatorID == "" {
+		return
+	}
+	first, err := ticketService.NewTicketService(bot).RequestFeedback(ticket)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_feedback.go", false, err, "Fehler beim Speichern der Zufriedenheits-Umfrage für Ticket #"+strconv.Itoa(ticket.ID))
+		return
+	}
+	if !first {
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Wie zufrieden warst du mit deinem Ticket #%d?", ticket.ID),
+		Description: "Dein Ticket wurde geschlossen. Bitte bewerte kurz, wie gut dir geholfen wurde – das hilft unserem Team, besser zu werden.",
+		Color:       utils.ColorInfo,
+	}
+	if ticket.ClaimerName != "" {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: "Bearbeitet von " + ticket.ClaimerName}
+	}
+
+	dmChannel, err := bot.UserChannelCreate(ticket.CreatorID)
+	if err == nil {
+		_, err = bot.ChannelMessageSendComplex(dmChannel.ID, &discordgo.MessageSend{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: feedbackComponents(ticket.ID, 0),
+		})
+	}
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Info", "ticket_feedback.go", false, err, "Zufriedenheits-Umfrage zu Ticket #"+strconv.Itoa(ticket.ID)+" konnte nicht per DM gesendet werden")
+	}
+}
+
+// feedbackComponents liefert die Bewertungs-Auswahl, nach einer Bewertung zusätzlich den Kommentar-Button
+func feedbackComponents(ticketID, rating int) []discordgo.MessageComponent {
+	options := make([]discordgo.SelectMenuOption, 0, ticketService.MaxRating)
+	for value := ticketService.MaxRating; value >= ticketService.MinRating; value-- {
+		options = append(options, discordgo.SelectMenuOption{
+			Label:   fmt.Sprintf("%s %s", formatStars(value), ratingLabels[value]),
+			Value:   strconv.Itoa(value),
+			Default: value == rating,
+		})
+	}
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    ticketCustomID("ticket_select_rating", ticketID),
+				Placeholder: "Bewertung auswählen",
+				Options:     options,
+			},
+		}},
+	}
+	if rating > 0 {
+		components = append(components, discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Style: discordgo.SecondaryButton, Label: "Kommentar hinzufügen", CustomID: ticketCustomID("ticket_button_feedback_comment", ticketID)},
+		}})
+	}
+	return components
+}
+
+var ratingLabels = map[int]string{
+	1: "Sehr unzufrieden",
+	2: "Unzufrieden",
+	3: "Neutral",
+	4: "Zufrieden",
+	5: "Sehr zufrieden",
+}
+
+// formatStars zeigt eine Bewertung als Sterne
+func formatStars(rating int) string {
+	return strings.Repeat("★", rating) + strings.Repeat("☆", ticketService.MaxRating-rating)
+}
+
+// interactionUser liefert den User einer Interaktion, in DMs ist Member nil
+func interactionUser(bot_interaction *discordgo.InteractionCreate) *discordgo.User {
+	if bot_interaction.Member != nil {
+		return bot_interaction.Member.User
+	}
+	return bot_interaction.User
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleRatingSelect speichert die Bewertung aus der DM und bietet danach einen Kommentar an
+func HandleRatingSelect(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	data := bot_interaction.MessageComponentData()
+	_, ticketID := SplitTicketCustomID(data.CustomID)
+	if ticketID == 0 || len(data.Values) == 0 {
+		return
+	}
+	rating, _ := strconv.Atoi(data.Values[0])
+
+	feedback, err := ticketService.NewTicketService(bot).RateTicket(ticketID, interactionUser(bot_interaction).ID, rating)
+	if err != nil {
+		respondFeedbackError(bot, bot_interaction, err)
+		return
+	}
+
+	err = bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       "Danke für deine Bewertung!",
+				Description: fmt.Sprintf("Ticket #%d: %s %s\n\nDu kannst die Bewertung noch ändern oder einen Kommentar hinzufügen.", ticketID, formatStars(feedback.Rating), ratingLabels[feedback.Rating]),
+				Color:       utils.ColorSuccess,
+			}},
+			Components: feedbackComponents(ticketID, feedback.Rating),
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_feedback.go", false, err, "Fehler beim Aktualisieren der Zufriedenheits-Umfrage zu Ticket #"+strconv.Itoa(ticketID))
+	}
+}
+
+// HandleFeedbackCommentButton öffnet das Modal für den Kommentar zur Bewertung
+func HandleFeedbackCommentButton(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	_, ticketID := SplitTicketCustomID(bot_interaction.MessageComponentData().CustomID)
+	if ticketID == 0 {
+		return
+	}
+	comment := ""
+	if feedback, err := ticketService.NewTicketService(bot).Feedback(ticketID); err == nil {
+		comment = feedback.Comment
+	}
+
+	err := bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: fmt.Sprintf("ticket_feedback_modal_%d", ticketID),
+			Title:    fmt.Sprintf("Kommentar zu Ticket #%d", ticketID),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:  "comment",
+						Label:     "Was war gut, was können wir besser machen?",
+						Style:     discordgo.TextInputParagraph,
+						Value:     comment,
+						Required:  true,
+						MaxLength: 1000,
+					},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_feedback.go", false, err, "Fehler beim Öffnen des Kommentar-Modals zu Ticket #"+strconv.Itoa(ticketID))
+	}
+}
+
+// HandleFeedbackModal speichert den Kommentar und schließt die Umfrage ab
+func HandleFeedbackModal(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	data := bot_interaction.ModalSubmitData()
+	ticketID, err := strconv.Atoi(strings.TrimPrefix(data.CustomID, "ticket_feedback_modal_"))
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_feedback.go", true, err, "Fehler beim Parsen der Ticket-ID aus der Modal CustomID")
+		return
+	}
+
+	feedback, err := ticketService.NewTicketService(bot).CommentTicket(ticketID, interactionUser(bot_interaction).ID, modalValues(data, nil)["comment"])
+	if err != nil {
+		respondFeedbackError(bot, bot_interaction, err)
+		return
+	}
+
+	err = bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       "Danke für dein Feedback!",
+				Description: fmt.Sprintf("Ticket #%d: %s %s\n\n> %s", ticketID, formatStars(feedback.Rating), ratingLabels[feedback.Rating], truncate(feedback.Comment, 1000)),
+				Color:       utils.ColorSuccess,
+			}},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_feedback.go", false, err, "Fehler beim Aktualisieren der Zufriedenheits-Umfrage zu Ticket #"+strconv.Itoa(ticketID))
+	}
+}
+
+// respondFeedbackError beantwortet Fehler aus der DM, nur unerwartete Fehler gehen an die Admins
+func respondFeedbackError(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, err error) {
+	if errors.Is(err, ticketService.ErrNotCreator) || errors.Is(err, ticketService.ErrNotFound) || errors.Is(err, ticketService.ErrInvalidInput) {
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", err.Error(), true)
+		return
+	}
+	utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_feedback.go", true, err, "Fehler beim Speichern der Ticket-Bewertung")
+	utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Deine Bewertung konnte nicht gespeichert werden.", true)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// handleTicketCSATCommand zeigt die Zufriedenheit je Bereich und Bearbeiter (/ticket csat [days])
+func handleTicketCSATCommand(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	days := 30
+	if opt, ok := opts["days"]; ok {
+		days = int(opt.IntValue())
+	}
+
+	now := time.Now()
+	report, err := ticketService.NewTicketService(bot).FeedbackReport(now.AddDate(0, 0, -days), now)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_feedback.go", true, err, "Fehler beim Erstellen des Zufriedenheits-Berichts")
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Der Zufriedenheits-Bericht konnte nicht erstellt werden.", true)
+		return
+	}
+	if report.Total.Responses == 0 {
+		utils.SendInfoEmbed(bot, bot_interaction, "Zufriedenheit", fmt.Sprintf("In den letzten %d Tagen gab es keine Bewertungen (%d Umfragen verschickt).", days, report.Requested), true)
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("Zufriedenheit · letzte %d Tage", days),
+		Description: fmt.Sprintf("%d von %d Umfragen beantwortet · %s\nCSAT = Anteil der Bewertungen mit mindestens %d Sternen.",
+			report.Total.Responses, report.Requested, formatFeedbackGroup(report.Total), ticketService.SatisfiedRating),
+		Color: utils.ColorInfo,
+	}
+	embed.Fields = append(embed.Fields,
+		&discordgo.MessageEmbedField{Name: "Bereiche", Value: formatFeedbackGroups(report.Areas, func(group ticketService.FeedbackGroup) string {
+			if group.Label == "" {
+				return "**Ohne Bereich**"
+			}
+			return "**" + group.Label + "**"
+		})},
+		&discordgo.MessageEmbedField{Name: "Bearbeiter", Value: formatFeedbackGroups(report.Moderators, func(group ticketService.FeedbackGroup) string {
+			return "<@" + group.Key + ">"
+		})},
+	)
+	if len(report.Comments) > 0 {
+		var b strings.Builder
+		for _, feedback := range report.Comments {
+			line := fmt.Sprintf("• #%d %s „%s“\n", feedback.TicketID, formatStars(feedback.Rating), truncate(strings.ReplaceAll(feedback.Comment, "\n", " "), 120))
+			if b.Len()+len(line) > 1000 {
+				break
+			}
+			b.WriteString(line)
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Letzte Kommentare", Value: b.String()})
+	}
+
+	err = bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_feedback.go", true, err, "Fehler beim Senden des Zufriedenheits-Berichts")
+	}
+}
+
+// formatFeedbackGroup zeigt Durchschnitt und CSAT einer Gruppe
+func formatFeedbackGroup(group ticketService.FeedbackGroup) string {
+	return fmt.Sprintf("Ø %.1f ★ · CSAT %.0f%% (%d)", group.Average, group.CSAT, group.Responses)
+}
+
+// formatFeedbackGroups listet die Gruppen für ein Embed-Feld, name liefert die Anzeige (Bereich oder Erwähnung)
+func formatFeedbackGroups(groups []ticketService.FeedbackGroup, name func(ticketService.FeedbackGroup) string) string {
+	if len(groups) == 0 {
+		return "-"
+	}
+	var b strings.Builder
+	for _, group := range groups {
+		line := "• " + name(group) + " " + formatFeedbackGroup(group) + "\n"
+		if b.Len()+len(line) > 1000 {
+			b.WriteString("…")
+			break
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
@@ -1,6 +1,11 @@
 package tickets
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	ticketService "bot/services/tickets"
 	"bot/utils"
 
 	"github.com/bwmarrin/discordgo"
@@ -9,7 +14,20 @@ import (
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
 // HandleTicketView sendet ein Embed mit dem "Create Ticket"-Button
+// Mit der Option "panel" werden Titel, Beschreibung und Bereiche aus ticket_panels verwendet
 func HandleTicketView(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	var panel *ticketService.Panel
+	for _, opt := range bot_interaction.ApplicationCommandData().Options {
+		if opt.Name == "panel" {
+			var err error
+			panel, err = ticketService.NewAreaService(bot).GetPanel(int(opt.IntValue()))
+			if err != nil {
+				utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Panel nicht gefunden.", true)
+				return
+			}
+		}
+	}
+
 	embed := &discordgo.MessageEmbed{
 		Title:       "Ticket-System – Bewerbung & Support",
 		Description: "Willkommen beim Ticket-System von **Entropy Gaming**!",
@@ -31,7 +49,14 @@ func HandleTicketView(bot *discordgo.Session, bot_interaction *discordgo.Interac
 			Text: "Entropy Gaming | Ticket System",
 		},
 	}
-	
+
+	customID := "ticket_create_ticket"
+	if panel != nil {
+		embed.Title = panel.Title
+		embed.Description = panel.Description
+		embed.Fields = nil
+		customID = fmt.Sprintf("ticket_create_ticket_%d", panel.ID)
+	}
 
 	// Button erstellen
 	components := []discordgo.MessageComponent{
@@ -40,7 +65,7 @@ func HandleTicketView(bot *discordgo.Session, bot_interaction *discordgo.Interac
 				&discordgo.Button{
 					Style:    discordgo.PrimaryButton,
 					Label:    "Create Ticket",
-					CustomID: "ticket_create_ticket",
+					CustomID: customID,
 				},
 			},
 		},
@@ -62,87 +87,94 @@ func HandleTicketView(bot *discordgo.Session, bot_interaction *discordgo.Interac
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
 // HandleCreateTicket zeigt das Dropdown-Menü für die Ticket-Bereiche an
+// Buttons von Panels tragen die Panel-ID als Suffix ("ticket_create_ticket_<id>")
 func HandleCreateTicket(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
-	options := []discordgo.SelectMenuOption{
-		{Label: "Beitritt Diamond Club", Value: "ticket_diamond_club"},
-		{Label: "Bewerbung Competetive Teams", Value: "ticket_community_teams"},
-		{Label: "Bewerbung Management", Value: "ticket_bewerbung_staff"},
-		{Label: "Bewerbung Content Creator", Value: "ticket_content_creator"},
-		{Label: "Bewerbung Pro Teams", Value: "ticket_pro_teams"},
-		{Label: "Support/Kontakt", Value: "ticket_support_kontakt"},
-		{Label: "Sonstiges", Value: "ticket_sonstiges"},
+	// Globale Ausschlüsse und Cooldown vor der Bereichsauswahl prüfen
+	if !checkTicketCreate(bot, bot_interaction, nil) {
+		return
 	}
 
-	components := []discordgo.MessageComponent{
-		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
-				&discordgo.SelectMenu{
-					CustomID:    "ticket_dropdown",
-					Placeholder: "Wähle einen Bereich...",
-					Options:     options,
-				},
-			},
-		},
-	}
+	service := ticketService.NewAreaService(bot)
 
-	err := bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content:    "Wähle einen Ticket-Bereich aus:",
-			Flags:      discordgo.MessageFlagsEphemeral,
-			Components: components,
-		},
-	})
+	var panel *ticketService.Panel
+	if bot_interaction.Type == discordgo.InteractionMessageComponent {
+		suffix := strings.TrimPrefix(bot_interaction.MessageComponentData().CustomID, "ticket_create_ticket_")
+		if panelID, err := strconv.Atoi(suffix); err == nil {
+			// Gelöschte Panels fallen auf alle Hauptbereiche zurück
+			panel, _ = service.GetPanel(panelID)
+		}
+	}
 
+	areas, err := service.AreasForPanel(panel)
 	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "critical", "Error", "ticket_handler.go", true, err, "Fehler beim Anzeigen des Ticket-Dropdowns")
+		utils.LogAndNotifyAdmins(bot, "critical", "Error", "ticket_handler.go", true, err, "Fehler beim Laden der Ticket-Bereiche")
+		return
 	}
+
+	showAreaDropdown(bot, bot_interaction, "ticket_dropdown", "Wähle einen Ticket-Bereich aus:", "Wähle einen Bereich...", areas)
 }
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
-// HandleTicketDropdown zeigt Modals oder zusätzliche Dropdowns basierend auf der Auswahl
+// HandleTicketDropdown zeigt das Modal oder bei Unterbereichen ein weiteres Dropdown an
+// Wird für "ticket_dropdown", "ticket_area_dropdown" und das alte "ticket_game_dropdown" verwendet
 func HandleTicketDropdown(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
 	data := bot_interaction.MessageComponentData()
+	if len(data.Values) == 0 {
+		return
+	}
+
+	service := ticketService.NewAreaService(bot)
+	area, err := service.GetArea(data.Values[0])
+	if err != nil || !area.Enabled {
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Dieser Ticket-Bereich ist nicht mehr verfügbar.", true)
+		return
+	}
+
+	children, err := service.Children(area.Key)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "critical", "Error", "ticket_handler.go", true, err, "Fehler beim Laden der Unterbereiche von "+area.Key)
+		return
+	}
+	if len(children) > 0 {
+		prompt := area.SubPrompt
+		if prompt == "" {
+			prompt = "Wähle einen Bereich aus:"
+		}
+		showAreaDropdown(bot, bot_interaction, "ticket_area_dropdown", prompt, "Wähle einen Bereich...", children)
+		return
+	}
 
-	switch data.Values[0] {
-	case "ticket_diamond_club":
-		HandleTicketModal(bot, bot_interaction, "ticket_diamond_club")
-	case "ticket_pro_teams":
-		HandleTicketModal(bot, bot_interaction, "ticket_pro_teams")
-	case "ticket_community_teams":
-		ShowGameDropdown(bot, bot_interaction)
-	case "ticket_bewerbung_staff":
-		HandleTicketModal(bot, bot_interaction, "ticket_bewerbung_staff")
-	case "ticket_content_creator":
-		HandleTicketModal(bot, bot_interaction, "ticket_content_creator")
-	case "ticket_support_kontakt":
-		HandleTicketModal(bot, bot_interaction, "ticket_support_kontakt")
-	case "ticket_sonstiges":
-		HandleTicketModal(bot, bot_interaction, "ticket_sonstiges")
+	// Ausschluss für den Bereich, Limit offener Tickets und Cooldown vor dem Formular prüfen
+	if !checkTicketCreate(bot, bot_interaction, area) {
+		return
 	}
+	HandleTicketModal(bot, bot_interaction, area)
 }
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
-// HandleGameDropdown zeigt ein Dropdown-Menü zur Auswahl eines Spiels an
-func ShowGameDropdown(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
-	options := []discordgo.SelectMenuOption{
-		{Label: "League of Legends", Value: "ticket_game_lol"},
-		{Label: "RainbowSix", Value: "ticket_game_r6"},
-		{Label: "CS2", Value: "ticket_game_cs2"},
-		{Label: "Valorant", Value: "ticket_game_valorant"},
-		{Label: "Rocket League", Value: "ticket_game_rocket_league"},
-		{Label: "Sonstige", Value: "ticket_game_sonstige"},
-		// {Label: "Splatoon", Value: "ticket_game_splatoon"},
+// showAreaDropdown sendet ein ephemeres Dropdown mit den übergebenen Bereichen
+func showAreaDropdown(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, customID, content, placeholder string, areas []ticketService.Area) {
+	if len(areas) == 0 {
+		utils.SendWarningEmbed(bot, bot_interaction, "Keine Bereiche", "Aktuell können hier keine Tickets erstellt werden.", true)
+		return
+	}
+
+	var options []discordgo.SelectMenuOption
+	for _, area := range areas {
+		if len(options) == ticketService.MaxDropdownOptions {
+			break
+		}
+		options = append(options, discordgo.SelectMenuOption{Label: area.Label, Value: area.Key, Description: area.Description})
 	}
 
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
 				&discordgo.SelectMenu{
-					CustomID:    "ticket_game_dropdown",
-					Placeholder: "Wähle ein Spiel...",
+					CustomID:    customID,
+					Placeholder: placeholder,
 					Options:     options,
 				},
 			},
@@ -152,37 +184,15 @@ func ShowGameDropdown(bot *discordgo.Session, bot_interaction *discordgo.Interac
 	err := bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content:    "Wähle das Spiel aus, für das du dich bewerben möchtest:",
+			Content:    content,
 			Flags:      discordgo.MessageFlagsEphemeral,
 			Components: components,
 		},
 	})
 
 	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "critical", "Error", "ticket_handler.go", true, err, "Fehler beim Anzeigen des Spiel-Dropdowns")
+		utils.LogAndNotifyAdmins(bot, "critical", "Error", "ticket_handler.go", true, err, "Fehler beim Anzeigen des Ticket-Dropdowns")
 	}
 }
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
-
-// HandleGameDropdown zeigt Modalsbasierend auf der Auswahl
-func HandleGameDropdown(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
-	data := bot_interaction.MessageComponentData()
-
-	switch data.Values[0] {
-	case "ticket_game_lol":
-		HandleTicketModal(bot, bot_interaction, "ticket_game_lol")
-	case "ticket_game_r6":
-		HandleTicketModal(bot, bot_interaction, "ticket_game_r6")
-	case "ticket_game_cs2":
-		HandleTicketModal(bot, bot_interaction, "ticket_game_cs2")
-	case "ticket_game_valorant":
-		HandleTicketModal(bot, bot_interaction, "ticket_game_valorant")
-	case "ticket_game_rocket_league":
-		HandleTicketModal(bot, bot_interaction, "ticket_game_rocket_league")
-	case "ticket_game_sonstige":
-		HandleTicketModal(bot, bot_interaction, "ticket_game_sonstige")
-	// case "game_splatoon":
-	// 	HandleTicketModal(bot, bot_interaction, "game_splatoon")
-	}
-}
\ No newline at end of file
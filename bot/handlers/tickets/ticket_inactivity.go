@@ -0,0 +1,152 @@
+package tickets
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robfig/cron/v3"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// StartTicketInactivity warnt bei Tickets ohne Nachricht des Erstellers und schließt sie nach Ablauf der Frist
+// (Regel je Bereich, siehe /ticket_admin area edit)
+func StartTicketInactivity(bot *discordgo.Session) {
+	c := cron.New(cron.WithLocation(time.Local))
+	_, err := c.AddFunc(utils.GetOptionalIdFromDB(bot, "TICKET_INACTIVITY_CRON_SPEC", "15 * * * *"), func() { runTicketInactivity(bot) })
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_inactivity.go", true, err, "Fehler beim Einrichten der Ticket-Inaktivitätsprüfung im Cron-Scheduler")
+		return
+	}
+	c.Start()
+}
+
+func runTicketInactivity(bot *discordgo.Session) {
+	service := ticketService.NewTicketService(bot)
+	now := time.Now()
+
+	warnings, closings, err := service.DueInactivity(now)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_inactivity.go", true, err, "Fehler beim Ermitteln inaktiver Tickets")
+		return
+	}
+
+	for _, inactive := range warnings {
+		if !sendInactivityWarning(bot, inactive) {
+			continue
+		}
+		if err := service.MarkInactivityWarned(inactive.Ticket.ID, now); err != nil {
+			utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_inactivity.go", true, err, "Fehler beim Speichern der Inaktivitäts-Warnung für Ticket "+strconv.Itoa(inactive.Ticket.ID))
+		}
+	}
+
+	actor := systemActor(bot)
+	for _, inactive := range closings {
+		ticketID := inactive.Ticket.ID
+		channelID := inactive.Ticket.ChannelID
+		ticket, err := closeTicket(bot, ticketID, channelID, actor,
+			fmt.Sprintf("Das Ticket #%d wurde nach %d Tagen ohne Rückmeldung automatisch geschlossen.", ticketID, inactive.Policy.WarnDays+inactive.Policy.CloseDays))
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_inactivity.go", true, err, "Fehler beim automatischen Schließen von Ticket "+strconv.Itoa(ticketID))
+			continue
+		}
+		utils.LogAndNotifyAdmins(bot, "info", "Info", "ticket_inactivity.go", false, nil, fmt.Sprintf("Ticket #%d wegen Inaktivität geschlossen", ticketID))
+
+		if inactive.Policy.Delete {
+			deleteTicket(bot, ticket, channelID, actor)
+			continue
+		}
+		updateModerationPanel(bot, channelID, ticket)
+	}
+}
+
+// systemActor trägt den Bot als Auslöser automatischer Aktionen ein
+func systemActor(bot *discordgo.Session) ticketService.Actor {
+	return ticketService.Actor{ID: bot.State.User.ID, Name: "System"}
+}
+
+// sendInactivityWarning postet die Warnung mit "Offen halten"-Button und pingt den Ersteller
+func sendInactivityWarning(bot *discordgo.Session, inactive ticketService.InactiveTicket) bool {
+	ticket := inactive.Ticket
+	if ticket.ChannelID == "" {
+		return false
+	}
+
+	action := "geschlossen"
+	if inactive.Policy.Delete {
+		action = "geschlossen und gelöscht"
+	}
+	closeAt := time.Now().AddDate(0, 0, inactive.Policy.CloseDays).Unix()
+	_, err := bot.ChannelMessageSendComplex(ticket.ChannelID, &discordgo.MessageSend{
+		Content: "<@" + ticket.CreatorID + ">",
+		Embeds: []*discordgo.MessageEmbed{{
+			Title: "⏳ Ticket inaktiv",
+			Description: fmt.Sprintf("In diesem Ticket gab es seit %d Tagen keine Nachricht von <@%s>.\nOhne Rückmeldung wird das Ticket <t:%d:R> automatisch %s.",
+				inactive.Policy.WarnDays, ticket.CreatorID, closeAt, action),
+			Color:  utils.ColorWarning,
+			Footer: &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Ticket #%d", ticket.ID)},
+		}},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Offen halten",
+						Style:    discordgo.SuccessButton,
+						CustomID: ticketCustomID("ticket_button_keep_open", ticket.ID),
+					},
+				},
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{Users: []string{ticket.CreatorID}},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_inactivity.go", true, err, "Fehler beim Senden der Inaktivitäts-Warnung für Ticket "+strconv.Itoa(ticket.ID))
+		return false
+	}
+	// Modmail-Ersteller sehen den Channel nicht, eine Antwort per DM zählt als Rückmeldung
+	notifyModmailCreator(bot, &ticket, "⏳ Ticket inaktiv", fmt.Sprintf("Ohne Rückmeldung wird dein Ticket <t:%d:R> automatisch %s. Antworte einfach hier, um es offen zu halten.", closeAt, action))
+	return true
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleKeepOpenButton setzt die Inaktivitätsfrist zurück und entfernt den Button
+func HandleKeepOpenButton(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "ticket_inactivity.go")
+	if !ok {
+		return
+	}
+	if ticket.Status != ticketService.StatusOpen && ticket.Status != ticketService.StatusClaimed {
+		utils.SendWarningEmbed(bot, bot_interaction, "Nicht möglich", fmt.Sprintf("Das Ticket ist bereits **%s**.", ticket.Status), true)
+		return
+	}
+
+	if err := ticketService.NewTicketService(bot).KeepOpen(ticket.ID, time.Now()); err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_inactivity.go", true, err, "Fehler beim Zurücksetzen der Inaktivitätsfrist für Ticket "+strconv.Itoa(ticket.ID))
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Das Ticket konnte nicht offen gehalten werden.", true)
+		return
+	}
+
+	err := bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       "Ticket bleibt offen",
+				Description: fmt.Sprintf("<@%s> hat das Ticket offen gehalten, die Inaktivitätsfrist beginnt neu.", bot_interaction.Member.User.ID),
+				Color:       utils.ColorSuccess,
+				Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Ticket #%d", ticket.ID)},
+			}},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_inactivity.go", true, err, "Fehler beim Aktualisieren der Inaktivitäts-Warnung für Ticket "+strconv.Itoa(ticket.ID))
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
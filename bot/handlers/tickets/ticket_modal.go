@@ -2,168 +2,89 @@ package tickets
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"bot/database"
+	"bot/services/events"
+	ticketService "bot/services/tickets"
 	"bot/utils"
-	
+
 	"github.com/bwmarrin/discordgo"
 )
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
-// shows the modal for choosen ticket type
-// The customID is used to determine which modal to show
-func HandleTicketModal(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, customID string) {
-	modalTitle := ""
-	var fields []discordgo.TextInput
-
-	if customID == "" {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, fmt.Errorf("customID is empty"), "Fehler beim Abrufen des customID für das Ticket Modal")
-		return
-	}
-
-	switch customID {
-	case "ticket_diamond_club":
-		modalTitle = "Bewerbung Diamond Club"
-		fields = []discordgo.TextInput{
-			{Label: "Vorname", Style: discordgo.TextInputShort, CustomID: "field_one", Required: true},
-			{Label: "Alter", Style: discordgo.TextInputShort, CustomID: "field_two", Required: true},
-			{Label: "Dein Main Game", Style: discordgo.TextInputShort, CustomID: "field_three", Required: true},
-			{Label: "Gib uns kurz an wann du Zeit hast", Style: discordgo.TextInputParagraph, CustomID: "field_four", Required: true, MaxLength: 400},
-		}	
-	
-	case "ticket_pro_teams":
-		modalTitle = "Bewerbung für ein Pro Team"
-		fields = []discordgo.TextInput{
-			{Label: "Vorname", Style: discordgo.TextInputShort, CustomID: "field_one", Required: true},
-			{Label: "Alter (Zahl)", Style: discordgo.TextInputShort, CustomID: "field_two", Required: true},
-			{Label: "Welches Spiel?", Style: discordgo.TextInputShort, CustomID: "field_three", Required: true},
-			{Label: "Erfahrungen im Team?", Style: discordgo.TextInputParagraph, CustomID: "field_four", Required: true, MaxLength: 400},
-			{Label: "Tracker & Social Media", Style: discordgo.TextInputParagraph, CustomID: "field_five", Required: true, MaxLength: 400},
-		}
-
-	case "ticket_bewerbung_staff":
-		modalTitle = "Bewerbung Staff"
-		fields = []discordgo.TextInput{
-			{Label: "Vorname", Style: discordgo.TextInputShort, CustomID: "field_one", Required: true},
-			{Label: "Alter", Style: discordgo.TextInputShort, CustomID: "field_two", Required: false},
-			{Label: "Für was bewirbst du dich?", Style: discordgo.TextInputShort, CustomID: "field_three", Required: true},
-			{Label: "Erfahrungen in dem Bereich?", Style: discordgo.TextInputParagraph, CustomID: "field_four", Required: true, MaxLength: 400},
-			{Label: "Stelle dich kurz vor", Style: discordgo.TextInputParagraph, CustomID: "field_five", Required: true, MaxLength: 400},
-		}
+// Legacy-CustomIDs der Modal-Felder, noch offene alte Modals werden darüber den Feldern zugeordnet
+var modalFieldIDs = []string{"field_one", "field_two", "field_three", "field_four", "field_five"}
 
-	case "ticket_content_creator":
-		modalTitle = "Bewerbung Content Creator"
-		fields = []discordgo.TextInput{
-			{Label: "Vorname", Style: discordgo.TextInputShort, CustomID: "field_one", Required: true},
-			{Label: "Alter", Style: discordgo.TextInputShort, CustomID: "field_two", Required: false},
-			{Label: "Social Links", Style: discordgo.TextInputParagraph, CustomID: "field_three", Required: true, MaxLength: 400},
-			{Label: "Weiteres", Style: discordgo.TextInputParagraph, CustomID: "field_four", Required: false, MaxLength: 400},
-		}
+// Schritt 1 nutzt weiterhin den Bereichsschlüssel als Modal-CustomID, weitere Schritte "ticket_form_<step>_<areaKey>"
+const (
+	formStepPrefix   = "ticket_form_"
+	formButtonPrefix = "ticket_form_next_"
+)
 
-	case "ticket_support_kontakt":
-		modalTitle = "Support Anfrage"
-		fields = []discordgo.TextInput{
-			{Label: "Vorname", Style: discordgo.TextInputShort, CustomID: "field_one", Required: true},
-			{Label: "Was ist dein Anliegen?", Style: discordgo.TextInputParagraph, CustomID: "field_two", Required: true, MaxLength: 750},
-		}
+// Discord-Limits für das angepinnte Antwort-Embed
+const (
+	maxEmbedFields     = 25
+	maxEmbedFieldValue = 1024
+	maxEmbedLength     = 5500 // etwas Luft zum Gesamtlimit von 6000 Zeichen
+)
 
-	case "ticket_sonstiges":
-		modalTitle = "Sonstige Anfragen"
-		fields = []discordgo.TextInput{
-			{Label: "Vorname", Style: discordgo.TextInputShort, CustomID: "field_one", Required: true},
-			{Label: "Was ist dein Anliegen?", Style: discordgo.TextInputParagraph, CustomID: "field_two", Required: true, MaxLength: 750},
-		}
+// shows the modal for choosen ticket area
+// The area key is used as modal customID to find the area again on submit
+func HandleTicketModal(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, area *ticketService.Area) {
+	showFormStep(bot, bot_interaction, area, 1)
+}
 
-	case "ticket_community_teams":
-		HandleGameDropdown(bot, bot_interaction)
+// showFormStep zeigt die Felder eines Formularschritts, bereits gespeicherte Eingaben werden vorausgefüllt
+func showFormStep(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, area *ticketService.Area, step int) {
+	if len(area.Fields) == 0 {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, fmt.Errorf("no fields defined for area %s", area.Key), "Fehler: Keine Felder für das Ticket definiert")
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Für diesen Bereich ist noch kein Formular hinterlegt.", true)
 		return
+	}
 
-	case "ticket_game_lol":
-		modalTitle = "League of Legends Bewerbung"
-		fields = []discordgo.TextInput{
-			{Label: "Vorname", Style: discordgo.TextInputShort, CustomID: "field_one", Required: true},
-			{Label: "Alter", Style: discordgo.TextInputShort, CustomID: "field_two", Required: false},
-			{Label: "Main Rolle", Style: discordgo.TextInputShort, CustomID: "field_three", Required: true},
-			{Label: "Rang", Style: discordgo.TextInputShort, CustomID: "field_four", Required: true},
-			{Label: "op.gg Link", Style: discordgo.TextInputShort, CustomID: "field_five", Required: true},
-		}
-
-	case "ticket_game_r6":
-		modalTitle = "RainbowSix Bewerbung"
-		fields = []discordgo.TextInput{
-			{Label: "Vorname", Style: discordgo.TextInputShort, CustomID: "field_one", Required: true},
-			{Label: "Alter", Style: discordgo.TextInputShort, CustomID: "field_two", Required: false},
-			{Label: "R6 Tracker Link", Style: discordgo.TextInputShort, CustomID: "field_three", Required: true},
-			{Label: "Plattform", Style: discordgo.TextInputShort, CustomID: "field_four", Required: true},
-			{Label: "Infos über DICH!", Style: discordgo.TextInputParagraph, CustomID: "field_five", Required: true, MaxLength: 600},
-		}
-
-	case "ticket_game_cs2":
-		modalTitle = "CS2 Bewerbung"
-		fields = []discordgo.TextInput{
-			{Label: "Vorname", Style: discordgo.TextInputShort, CustomID: "field_one", Required: true},
-			{Label: "Alter", Style: discordgo.TextInputShort, CustomID: "field_two", Required: false},
-			{Label: "Steam Profile Link", Style: discordgo.TextInputShort, CustomID: "field_three", Required: true},
-			{Label: "Rang", Style: discordgo.TextInputShort, CustomID: "field_four", Required: true},
-			{Label: "Infos über DICH!", Style: discordgo.TextInputParagraph, CustomID: "field_five", Required: true, MaxLength: 600},
-		}
-
-	case "ticket_game_valorant":
-		modalTitle = "Valorant Bewerbung"
-		fields = []discordgo.TextInput{
-			{Label: "Vorname", Style: discordgo.TextInputShort, CustomID: "field_one", Required: true},
-			{Label: "Alter", Style: discordgo.TextInputShort, CustomID: "field_two", Required: false},
-			{Label: "InGame Name", Style: discordgo.TextInputShort, CustomID: "field_three", Required: true},
-			{Label: "Tracker Link", Style: discordgo.TextInputShort, CustomID: "field_four", Required: true},
-		}
+	draft, err := ticketService.NewAreaService(bot).GetDraft(bot_interaction.Member.User.ID, area.Key)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_modal.go", false, err, "Fehler beim Laden des Formular-Entwurfs")
+	}
 
-	case "ticket_game_rocket_league":
-		modalTitle = "Rocket League Bewerbung"
-		fields = []discordgo.TextInput{
-			{Label: "Vorname", Style: discordgo.TextInputShort, CustomID: "field_one", Required: true},
-			{Label: "Alter", Style: discordgo.TextInputShort, CustomID: "field_two", Required: false},
-			{Label: "InGame Name", Style: discordgo.TextInputShort, CustomID: "field_three", Required: true},
-			{Label: "RL Tracker Network Link", Style: discordgo.TextInputShort, CustomID: "field_four", Required: true},
-			{Label: "Wunsch Elo", Style: discordgo.TextInputShort, CustomID: "field_five", Required: true},
+	var components []discordgo.MessageComponent
+	for _, field := range area.StepFields(step) {
+		style := discordgo.TextInputShort
+		if field.Style == "paragraph" {
+			style = discordgo.TextInputParagraph
 		}
-
-	case "ticket_game_sonstige":
-		modalTitle = "Sonstige Bewerbungen"
-		fields = []discordgo.TextInput{
-			{Label: "Vorname", Style: discordgo.TextInputShort, CustomID: "field_one", Required: true},
-			{Label: "Alter", Style: discordgo.TextInputShort, CustomID: "field_two", Required: false},
-			{Label: "Bitte erkläre kurz für was du dich bewirbst", Style: discordgo.TextInputParagraph, CustomID: "field_three", Required: true, MaxLength: 400},
+		input := &discordgo.TextInput{
+			Label:       field.Label,
+			Style:       style,
+			CustomID:    field.Key,
+			Required:    field.Required,
+			MaxLength:   field.MaxLength,
+			Placeholder: field.Placeholder,
+			Value:       draft[field.Key],
 		}
-
-		// already in code as possible option in future, but not used yet
-		// case "ticket_game_splatoon":
-		// 	modalTitle = "Rocket League Bewerbung"
-		// 	fields = []discordgo.TextInput{
-		//		{Label: "Vorname", Style: discordgo.TextInputShort, CustomID: "field_one"},
-		//		{Label: "Alter", Style: discordgo.TextInputShort, CustomID: "field_two", Required: false},
-		// 		{Label: "NOCH NICHT DEFINIERT", Style: discordgo.TextInputShort, CustomID: "field_three"}, // Noch nicht Definiert
-		// 	}
+		components = append(components, discordgo.ActionsRow{Components: []discordgo.MessageComponent{input}})
 	}
 
-	if len(fields) == 0 {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, fmt.Errorf("no fields defined for customID %s", customID), "Fehler: Keine Felder für das Ticket definiert")
-		return
-	}
-
-	var components []discordgo.MessageComponent
-	for _, field := range fields {
-		components = append(components, discordgo.ActionsRow{Components: []discordgo.MessageComponent{&field}})
+	customID := area.Key
+	title := area.ModalTitle
+	if steps := area.StepCount(); steps > 1 {
+		suffix := fmt.Sprintf(" (%d/%d)", step, steps)
+		title = truncate(title, 45-len(suffix)) + suffix
+		if step > 1 {
+			customID = fmt.Sprintf("%s%d_%s", formStepPrefix, step, area.Key)
+		}
 	}
 
 	// Modal anzeigen
-	err := bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+	err = bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseModal,
 		Data: &discordgo.InteractionResponseData{
 			CustomID:   customID,
-			Title:      modalTitle,
+			Title:      title,
 			Components: components,
 		},
 	})
@@ -173,6 +94,111 @@ func HandleTicketModal(bot *discordgo.Session, bot_interaction *discordgo.Intera
 	}
 }
 
+// HandleTicketFormButton öffnet den nächsten (oder erneut einen fehlerhaften) Formularschritt
+func HandleTicketFormButton(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	step, areaKey, ok := parseFormStep(strings.TrimPrefix(bot_interaction.MessageComponentData().CustomID, formButtonPrefix))
+	if !ok {
+		utils.LogAndNotifyAdmins(bot, "warn", "Warnung", "ticket_modal.go", true, nil, "Ungültige Formular-CustomID: "+bot_interaction.MessageComponentData().CustomID)
+		return
+	}
+
+	area, err := ticketService.NewAreaService(bot).GetArea(areaKey)
+	if err != nil || !area.Enabled || step > area.StepCount() {
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Dieses Formular ist nicht mehr verfügbar. Bitte starte ein neues Ticket.", true)
+		return
+	}
+	if !checkTicketCreate(bot, bot_interaction, area) {
+		return
+	}
+	showFormStep(bot, bot_interaction, area, step)
+}
+
+// parseFormStep zerlegt "<step>_<areaKey>"
+func parseFormStep(value string) (int, string, bool) {
+	parts := strings.SplitN(value, "_", 2)
+	if len(parts) != 2 {
+		return 0, "", false
+	}
+	step, err := strconv.Atoi(parts[0])
+	if err != nil || step < 1 {
+		return 0, "", false
+	}
+	return step, parts[1], true
+}
+
+// modalValues liest die Eingaben als Feld-Schlüssel -> Wert
+func modalValues(data discordgo.ModalSubmitInteractionData, area *ticketService.Area) map[string]string {
+	values := make(map[string]string)
+	for _, component := range data.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, item := range row.Components {
+			input, ok := item.(*discordgo.TextInput)
+			if !ok {
+				continue
+			}
+			key := input.CustomID
+			for i, legacyID := range modalFieldIDs {
+				if key == legacyID && i < len(area.Fields) {
+					key = area.Fields[i].Key
+				}
+			}
+			values[key] = input.Value
+		}
+	}
+	return values
+}
+
+// respondFormStep ersetzt die vorläufige Antwort durch einen Hinweis mit Button zum nächsten Schritt
+func respondFormStep(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, embed *discordgo.MessageEmbed, buttonLabel string, step int, areaKey string) {
+	embeds := []*discordgo.MessageEmbed{embed}
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    buttonLabel,
+				Style:    discordgo.PrimaryButton,
+				CustomID: fmt.Sprintf("%s%d_%s", formButtonPrefix, step, areaKey),
+			},
+		}},
+	}
+	_, err := bot.InteractionResponseEdit(bot_interaction.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &embeds,
+		Components: &components,
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, err, "Fehler beim Senden des Formular-Schritts")
+	}
+}
+
+// answerEmbeds verteilt alle Antworten auf Embeds innerhalb der Discord-Limits
+func answerEmbeds(title string, answers []ticketService.Answer) []*discordgo.MessageEmbed {
+	current := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: "Details des Tickets:",
+		Color:       0xff0000, // Rot
+	}
+	embeds := []*discordgo.MessageEmbed{current}
+	length := len(current.Title) + len(current.Description)
+
+	for _, answer := range answers {
+		value := answer.Value
+		if value == "" {
+			value = "-"
+		}
+		field := &discordgo.MessageEmbedField{Name: answer.Label, Value: truncate(value, maxEmbedFieldValue), Inline: false}
+		if len(current.Fields) == maxEmbedFields || length+len(field.Name)+len(field.Value) > maxEmbedLength {
+			current = &discordgo.MessageEmbed{Title: title + " (Fortsetzung)", Color: 0xff0000}
+			embeds = append(embeds, current)
+			length = len(current.Title)
+		}
+		current.Fields = append(current.Fields, field)
+		length += len(field.Name) + len(field.Value)
+	}
+	return embeds
+}
+
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
 func HandleTicketSubmit(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
@@ -189,75 +215,99 @@ func HandleTicketSubmit(bot *discordgo.Session, bot_interaction *discordgo.Inter
 	}
 
 	data := bot_interaction.ModalSubmitData()
-	customID := bot_interaction.ModalSubmitData().CustomID
-
-	var fields []string
-
-	for _, component := range data.Components {
-		if row, ok := component.(*discordgo.ActionsRow); ok {
-			for _, item := range row.Components {
-				if input, ok := item.(*discordgo.TextInput); ok {
-					fields = append(fields, input.Value)
-				}
-			}
+	customID := data.CustomID
+	step := 1
+	if strings.HasPrefix(customID, formStepPrefix) {
+		var ok bool
+		if step, customID, ok = parseFormStep(strings.TrimPrefix(customID, formStepPrefix)); !ok {
+			utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, fmt.Errorf("invalid form customID %s", data.CustomID), "Fehler: Ungültiger Formularschritt")
+			return
 		}
 	}
 
-	var labels = getLabelsForTicket(customID)
-	if len(labels) == 0 {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, fmt.Errorf("no labels defined for customID %s", customID), "Fehler: Keine Labels für das Ticket definiert")
-		return
-	}
-	if len(fields) == 0 {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, fmt.Errorf("no fields provided for customID %s", customID), "Fehler: Keine Felder für das Ticket definiert")
+	service := ticketService.NewAreaService(bot)
+	area, err := service.GetArea(customID)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, err, "Fehler: Ticket-Bereich "+customID+" nicht gefunden")
 		return
 	}
 
-	var labelOne, fieldOne string
-	var labelTwo, fieldTwo string
-	var labelThree, fieldThree string
-	var labelFour, fieldFour string
-	var labelFive, fieldFive string
-
-	if len(fields) > 0 {
-		labelOne, fieldOne = labels[0], fields[0]
-	}
-	if len(labels) > 1 && len(fields) > 1 {
-		labelTwo, fieldTwo = labels[1], fields[1]
-	}
-	if len(labels) > 2 && len(fields) > 2 {
-		labelThree, fieldThree = labels[2], fields[2]
+	userID := bot_interaction.Member.User.ID
+	values, err := service.GetDraft(userID, area.Key)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_modal.go", false, err, "Fehler beim Laden des Formular-Entwurfs")
+		values = make(map[string]string)
 	}
-	if len(labels) > 3 && len(fields) > 3 {
-		labelFour, fieldFour = labels[3], fields[3]
+	submitted := modalValues(data, area)
+	if len(submitted) == 0 {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, fmt.Errorf("no fields provided for customID %s", customID), "Fehler: Keine Felder für das Ticket definiert")
+		return
 	}
-	if len(labels) > 4 && len(fields) > 4 {
-		labelFive, fieldFive = labels[4], fields[4]
+	for key, value := range submitted {
+		values[key] = value
 	}
 
-	if customID == "ticket_pro_teams" {
-		age := 0
-		fmt.Sscanf(fieldTwo, "%d", &age)
-		if age < 16 {
-			_, err = bot.FollowupMessageCreate(bot_interaction.Interaction, false, &discordgo.WebhookParams{
-				Content: "Du bist leider zu jung für ein Pro Team. Bitte öffne stattdessen ein 'Competitive Teams' Ticket.",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			})
-			if err != nil {
-				utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, err, "Fehler beim Senden der Nachricht für zu junges Alter im Pro Team Ticket")
+	// Alle Felder bis zum aktuellen Schritt prüfen, bei Fehlern zurück zum ersten fehlerhaften Schritt
+	steps := area.StepCount()
+	for s := 1; s <= step && s <= steps; s++ {
+		var problems []string
+		for _, field := range area.StepFields(s) {
+			if err := field.ValidateAnswer(values[field.Key]); err != nil {
+				problems = append(problems, "• "+strings.TrimPrefix(err.Error(), ticketService.ErrInvalidInput.Error()+": "))
 			}
+		}
+		if len(problems) == 0 {
+			continue
+		}
+		if err := service.SaveDraft(userID, area.Key, values); err != nil {
+			utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_modal.go", true, err, "Fehler beim Speichern des Formular-Entwurfs")
+		}
+		respondFormStep(bot, bot_interaction, &discordgo.MessageEmbed{
+			Title:       "Bitte überprüfe deine Angaben",
+			Description: strings.Join(problems, "\n"),
+			Color:       utils.ColorError,
+		}, "Angaben korrigieren", s, area.Key)
+		return
+	}
+
+	if step < steps {
+		if err := service.SaveDraft(userID, area.Key, values); err != nil {
+			utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, err, "Fehler beim Speichern des Formular-Entwurfs")
 			return
 		}
+		respondFormStep(bot, bot_interaction, &discordgo.MessageEmbed{
+			Title:       fmt.Sprintf("Schritt %d/%d gespeichert", step, steps),
+			Description: "Klicke auf **Weiter**, um das Formular fortzusetzen. Deine Angaben bleiben 24 Stunden gespeichert.",
+			Color:       0x3498DB,
+		}, "Weiter", step+1, area.Key)
+		return
+	}
+
+	// Erneut prüfen, da zwischen Formular und Absenden z.B. ein zweites Ticket erstellt worden sein kann
+	if denial := ticketCreateDenial(bot, userID, area); denial != nil {
+		embeds := []*discordgo.MessageEmbed{denial}
+		bot.InteractionResponseEdit(bot_interaction.Interaction, &discordgo.WebhookEdit{Embeds: &embeds})
+		return
+	}
+
+	answers := area.Answers(values)
+
+	// Der Bot liest nur ticket_answers. Die ersten fünf Antworten werden zusätzlich in die festen Spalten kopiert,
+	// da das Webinterface (Ticket-Liste, Details und Suche) nur ticket_modal_field_* kennt.
+	columns := make([]string, len(modalFieldIDs))
+	for i := 0; i < len(columns) && i < len(answers); i++ {
+		columns[i] = answers[i].Value
 	}
 
-	categoryID := utils.GetIdFromDB(bot, "CATEGORY_" + strings.ToUpper(customID))
-	roleID := getRoleIDForTicket(bot, customID)
-	ticketArea := getTicketAreaForTicket(customID)
+	categoryID := service.CategoryID(area)
+	roleID := service.SupportRoleID(area)
+	ticketArea := area.DisplayName
 
 	_, err = database.DB.Exec(`
 		INSERT INTO tickets (ticket_status, ticket_bereich, ticket_ersteller_id, ticket_ersteller_name, ticket_erstellungszeit, ticket_modal_field_one, ticket_modal_field_two, ticket_modal_field_three, ticket_modal_field_four, ticket_modal_field_five)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		"Open", customID, bot_interaction.Member.User.ID, bot_interaction.Member.User.Username, time.Now().Unix(), fieldOne, fieldTwo, fieldThree, fieldFour, fieldFive)
+		string(ticketService.StatusOpen), customID, bot_interaction.Member.User.ID, bot_interaction.Member.User.Username, time.Now().Unix(),
+		columns[0], columns[1], columns[2], columns[3], columns[4])
 	if err != nil {
 		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, err, "Fehler beim Einfügen des Tickets in die Datenbank")
 		return
@@ -270,8 +320,22 @@ func HandleTicketSubmit(bot *discordgo.Session, bot_interaction *discordgo.Inter
 		return
 	}
 
+	creator := ticketService.Actor{ID: userID, Name: bot_interaction.Member.User.Username}
+	if err := ticketService.NewTicketService(bot).RecordCreated(int(ticketID), creator); err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_modal.go", true, err, "Fehler beim Speichern des Ticket-Verlaufs")
+	}
+	if err := service.SaveAnswers(ticketID, answers); err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, err, "Fehler beim Speichern der Formular-Antworten")
+	}
+	if err := ticketService.NewTicketService(bot).IndexTicket(int(ticketID)); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modal.go", true, err, "Fehler beim Aktualisieren des Ticket-Suchindex")
+	}
+	if err := service.DeleteDraft(userID, area.Key); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modal.go", false, err, "Fehler beim Löschen des Formular-Entwurfs")
+	}
+
 	channel, err := bot.GuildChannelCreateComplex(bot_interaction.GuildID, discordgo.GuildChannelCreateData{
-		Name:     fmt.Sprintf("%d-open-%s", ticketID, bot_interaction.Member.User.Username),
+		Name:     area.ChannelName(ticketID, bot_interaction.Member.User.Username),
 		Type:     discordgo.ChannelTypeGuildText,
 		Topic:    fmt.Sprintf("Ticket #%d - Status: Open - Ticket von <@%s>", ticketID, bot_interaction.Member.User.ID),
 		ParentID: categoryID,
@@ -283,49 +347,48 @@ func HandleTicketSubmit(bot *discordgo.Session, bot_interaction *discordgo.Inter
 
 	addUserChannelPermission(bot, channel.ID, bot_interaction.Member.User.ID)
 
-	_, err = database.DB.Exec(`UPDATE tickets SET ticket_channel_id = ? WHERE ticket_id = ?`, channel.ID, ticketID)
+	err = ticketService.NewTicketService(bot).LinkChannel(int(ticketID), channel.ID)
 	if err != nil {
 		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, err, "Fehler beim Aktualisieren der Ticket-Channel-ID in der Datenbank")
 		return
 	}
 
-	embed_ticket_channel := &discordgo.MessageEmbed{
-		Title:       ticketArea,
-		Description: "Details des Tickets:",
-		Fields: []*discordgo.MessageEmbedField{
-			{Name: labelOne, Value: fieldOne, Inline: false},
-			{Name: labelTwo, Value: fieldTwo, Inline: false},
-			{Name: labelThree, Value: fieldThree, Inline: false},
-			{Name: labelFour, Value: fieldFour, Inline: false},
-			{Name: labelFive, Value: fieldFive, Inline: false},
-		},
-		Color: 0xff0000, // Rot
-	}
-	userID := utils.GetIdFromDB(bot, "ROLE_TICKET_PROTEAMS")
+	events.Publish(events.TypeTicketCreate, map[string]interface{}{
+		"ticket_id":  ticketID,
+		"area":       customID,
+		"channel_id": channel.ID,
+		"creator_id": bot_interaction.Member.User.ID,
+	})
 
 	var mention string
-	if customID == "ticket_pro_teams" {
-		mention = fmt.Sprintf("<@%s>", userID)
+	if area.MentionUser {
+		mention = fmt.Sprintf("<@%s>", roleID)
 	} else {
 		mention = fmt.Sprintf("<@&%s>", roleID)
 	}
 
-	message, err := bot.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
-		Content: mention,
-		Embeds:  []*discordgo.MessageEmbed{embed_ticket_channel},
-	})
-	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, err, "Fehler beim Senden der Ticket-Channel-Nachricht")
-		return
-	}
-
-	err = bot.ChannelMessagePin(channel.ID, message.ID)
-	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, err, "Fehler beim Anpinnen der Ticket-Channel-Nachricht")
-		return
+	// Lange Formulare werden auf mehrere Nachrichten verteilt, angepinnt wird die erste
+	for i, embed := range answerEmbeds(ticketArea, answers) {
+		send := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+		if i == 0 {
+			send.Content = mention
+		}
+		message, err := bot.ChannelMessageSendComplex(channel.ID, send)
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, err, "Fehler beim Senden der Ticket-Channel-Nachricht")
+			return
+		}
+		if i == 0 {
+			err = bot.ChannelMessagePin(channel.ID, message.ID)
+			if err != nil {
+				utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modal.go", true, err, "Fehler beim Anpinnen der Ticket-Channel-Nachricht")
+				return
+			}
+		}
 	}
 
-	SendModerationView(bot, channel.ID, int(ticketID), bot_interaction.Member.User.Username)
+	SendModerationView(bot, channel.ID, int(ticketID), bot_interaction.Member.User.Username, customID)
+	autoAssignTicket(bot, int(ticketID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       "Ticket erstellt",
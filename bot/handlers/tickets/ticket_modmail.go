@@ -0,0 +1,499 @@
+package tickets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"bot/services/events"
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxRelayFileSize ist die Upload-Grenze für weitergeleitete Anhänge, größere Dateien werden als Link weitergegeben
+const maxRelayFileSize = 10 * 1024 * 1024
+
+// modmailLocks hält je User einen Mutex, damit zwei schnelle DMs nicht zwei Tickets eröffnen.
+// Der Eintrag wird entfernt, sobald kein Aufruf mehr auf den Mutex wartet (siehe lockModmailUser).
+var (
+	modmailLocksMu sync.Mutex
+	modmailLocks   = map[string]*modmailLock{}
+)
+
+type modmailLock struct {
+	sync.Mutex
+	users int // Aufrufe, die den Mutex halten oder darauf warten
+}
+
+var relayClient = &http.Client{Timeout: 30 * time.Second}
+
+// relayFile ist ein heruntergeladener Anhang, der in DM und Channel erneut hochgeladen wird
+type relayFile struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// OnModmailMessage leitet DMs an den Bot in das Modmail-Ticket des Users weiter
+// und Nachrichten mit Präfix (TICKET_MODMAIL_PREFIX) aus dem Ticket-Channel zurück in die DM
+func OnModmailMessage(bot *discordgo.Session, message *discordgo.MessageCreate) {
+	if message.Author == nil || message.Author.Bot {
+		return
+	}
+	if message.GuildID == "" {
+		handleModmailDM(bot, message)
+		return
+	}
+	handleModmailPrefixReply(bot, message)
+}
+
+// handleModmailDM hängt die DM an das offene Modmail-Ticket an oder eröffnet ein neues im Bereich TICKET_MODMAIL_AREA
+func handleModmailDM(bot *discordgo.Session, message *discordgo.MessageCreate) {
+	service := ticketService.NewTicketService(bot)
+	areaKey := service.ModmailAreaKey()
+	if areaKey == "" || (strings.TrimSpace(message.Content) == "" && len(message.Attachments) == 0) {
+		return
+	}
+
+	ticket, ok := modmailTicketFor(bot, message.ChannelID, message.Author, areaKey)
+	if !ok {
+		return
+	}
+	if ticket.ChannelID == "" {
+		// Der Channel wurde gelöscht oder nie verknüpft, /ticket_repair ordnet ihn wieder zu
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modmail.go", true, fmt.Errorf("modmail ticket %d has no channel", ticket.ID), "Fehler: Modmail-Ticket ohne Channel, DM von "+message.Author.ID+" nicht zugestellt")
+		sendModmailNotice(bot, message.ChannelID, "Nachricht nicht zugestellt", "Deine Nachricht konnte gerade nicht an das Team weitergeleitet werden. Bitte versuche es später erneut.", utils.ColorError)
+		return
+	}
+
+	files, links := downloadRelayFiles(bot, message.Attachments)
+	embed := &discordgo.MessageEmbed{
+		Author:      &discordgo.MessageEmbedAuthor{Name: message.Author.Username, IconURL: message.Author.AvatarURL("")},
+		Description: relayText(message.Content, links),
+		Color:       utils.ColorInfo,
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Per DM · Ticket #%d", ticket.ID)},
+		Timestamp:   message.Timestamp.Format(time.RFC3339),
+	}
+	_, err := bot.ChannelMessageSendComplex(ticket.ChannelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Files:  relayDiscordFiles(files),
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modmail.go", true, err, "Fehler beim Weiterleiten der DM in Ticket #"+strconv.Itoa(ticket.ID))
+		sendModmailNotice(bot, message.ChannelID, "Nachricht nicht zugestellt", "Deine Nachricht konnte gerade nicht an das Team weitergeleitet werden. Bitte versuche es später erneut.", utils.ColorError)
+		return
+	}
+
+	if err := bot.MessageReactionAdd(message.ChannelID, message.ID, "✅"); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", false, err, "Fehler beim Bestätigen der DM für Ticket #"+strconv.Itoa(ticket.ID))
+	}
+	if err := service.RecordMessage(ticket.ID, true, message.Timestamp); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", true, err, "Fehler beim Speichern der letzten Nachricht für Ticket "+strconv.Itoa(ticket.ID))
+	}
+}
+
+// modmailTicketFor liefert das offene Modmail-Ticket des Users oder eröffnet ein neues.
+// Nur Suche und Anlage laufen unter dem Lock des Users, Downloads und Weiterleitung danach parallel.
+func modmailTicketFor(bot *discordgo.Session, dmChannelID string, user *discordgo.User, areaKey string) (*ticketService.Ticket, bool) {
+	defer lockModmailUser(user.ID)()
+
+	ticket, err := ticketService.NewTicketService(bot).ModmailTicket(user.ID)
+	if errors.Is(err, ticketService.ErrNotFound) {
+		return openModmailTicket(bot, dmChannelID, user, areaKey)
+	}
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modmail.go", true, err, "Fehler beim Laden des Modmail-Tickets von "+user.ID)
+		sendModmailNotice(bot, dmChannelID, "Nachricht nicht zugestellt", "Deine Nachricht konnte gerade nicht an das Team weitergeleitet werden. Bitte versuche es später erneut.", utils.ColorError)
+		return nil, false
+	}
+	return ticket, true
+}
+
+// lockModmailUser sperrt den Mutex des Users und liefert die Freigabe. Der letzte Aufruf entfernt den Eintrag wieder,
+// ein gerade wartender Aufruf behält so denselben Mutex und kein zweiter kann parallel ein Ticket eröffnen.
+func lockModmailUser(userID string) func() {
+	modmailLocksMu.Lock()
+	lock := modmailLocks[userID]
+	if lock == nil {
+		lock = &modmailLock{}
+		modmailLocks[userID] = lock
+	}
+	lock.users++
+	modmailLocksMu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		modmailLocksMu.Lock()
+		lock.users--
+		if lock.users == 0 {
+			delete(modmailLocks, userID)
+		}
+		modmailLocksMu.Unlock()
+	}
+}
+
+// openModmailTicket prüft Ausschluss, Limit und Cooldown wie beim Formular und legt Ticket und Team-Channel an.
+// Der Ersteller erhält keinen Zugriff auf den Channel, die Unterhaltung läuft über die DM.
+func openModmailTicket(bot *discordgo.Session, dmChannelID string, user *discordgo.User, areaKey string) (*ticketService.Ticket, bool) {
+	areaService := ticketService.NewAreaService(bot)
+	area, err := areaService.GetArea(areaKey)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modmail.go", true, err, "Fehler: Modmail-Bereich "+areaKey+" nicht gefunden")
+		sendModmailNotice(bot, dmChannelID, "Modmail nicht verfügbar", "Tickets per DM sind gerade nicht möglich. Bitte nutze das Ticket-Panel auf dem Server.", utils.ColorError)
+		return nil, false
+	}
+	if !modmailGuildMember(bot, dmChannelID, user) {
+		return nil, false
+	}
+	if denial := ticketCreateDenial(bot, user.ID, area); denial != nil {
+		if _, err := bot.ChannelMessageSendEmbed(dmChannelID, denial); err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", false, err, "Fehler beim Senden der Ablehnung per DM an "+user.ID)
+		}
+		return nil, false
+	}
+
+	service := ticketService.NewTicketService(bot)
+	creator := ticketService.Actor{ID: user.ID, Name: user.Username}
+	ticket, err := service.CreateModmailTicket(area.Key, creator)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modmail.go", true, err, "Fehler beim Einfügen des Modmail-Tickets in die Datenbank")
+		sendModmailNotice(bot, dmChannelID, "Ticket nicht erstellt", "Dein Ticket konnte gerade nicht erstellt werden. Bitte versuche es später erneut.", utils.ColorError)
+		return nil, false
+	}
+	if err := service.IndexTicket(ticket.ID); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", true, err, "Fehler beim Aktualisieren des Ticket-Suchindex")
+	}
+
+	channel, err := bot.GuildChannelCreateComplex(utils.GetIdFromDB(bot, "GUILD_ID"), discordgo.GuildChannelCreateData{
+		Name:     area.ChannelName(int64(ticket.ID), user.Username),
+		Type:     discordgo.ChannelTypeGuildText,
+		Topic:    fmt.Sprintf("Ticket #%d - Status: Open - Modmail von <@%s>", ticket.ID, user.ID),
+		ParentID: areaService.CategoryID(area),
+	})
+	if err == nil {
+		err = service.LinkChannel(ticket.ID, channel.ID)
+	}
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modmail.go", true, err, fmt.Sprintf("Fehler beim Erstellen des Channels für Modmail-Ticket #%d", ticket.ID))
+		// Ohne Channel würde jede weitere DM an ein Ticket ohne Ziel gehen, die nächste DM versucht es neu
+		if _, err := service.Delete(ticket.ID, systemActor(bot)); err != nil {
+			utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modmail.go", true, err, fmt.Sprintf("Fehler beim Verwerfen von Modmail-Ticket #%d", ticket.ID))
+		}
+		sendModmailNotice(bot, dmChannelID, "Ticket nicht erstellt", "Dein Ticket konnte gerade nicht erstellt werden. Bitte versuche es später erneut.", utils.ColorError)
+		return nil, false
+	}
+	ticket.ChannelID = channel.ID
+
+	events.Publish(events.TypeTicketCreate, map[string]interface{}{
+		"ticket_id":  ticket.ID,
+		"area":       area.Key,
+		"channel_id": channel.ID,
+		"creator_id": user.ID,
+		"modmail":    true,
+	})
+
+	mention := fmt.Sprintf("<@&%s>", areaService.SupportRoleID(area))
+	if area.MentionUser {
+		mention = fmt.Sprintf("<@%s>", areaService.SupportRoleID(area))
+	}
+	reply := "`/reply`"
+	if prefix := service.ModmailPrefix(); prefix != "" {
+		reply += fmt.Sprintf(" oder `%s <Text>`", prefix)
+	}
+	intro, err := bot.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+		Content: mention,
+		Embeds: []*discordgo.MessageEmbed{{
+			Title: area.DisplayName + " · Modmail",
+			Description: fmt.Sprintf("<@%s> hat dieses Ticket per DM eröffnet und sieht diesen Channel nicht.\n"+
+				"Antworten mit %s werden per DM weitergeleitet, alle anderen Nachrichten bleiben intern.", user.ID, reply),
+			Color: 0x3498DB,
+		}},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modmail.go", true, err, "Fehler beim Senden der Ticket-Channel-Nachricht")
+	} else if err := bot.ChannelMessagePin(channel.ID, intro.ID); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", true, err, "Fehler beim Anpinnen der Ticket-Channel-Nachricht")
+	}
+
+	SendModerationView(bot, channel.ID, ticket.ID, user.Username, area.Key)
+	autoAssignTicket(bot, ticket.ID)
+
+	sendModmailNotice(bot, dmChannelID, "Ticket erstellt", fmt.Sprintf("Deine Nachricht wurde als Ticket #%d an das Team weitergeleitet. "+
+		"Antworten erhältst du hier, weitere Nachrichten an mich werden deinem Ticket angehängt.", ticket.ID), 0x3498DB)
+	return ticket, true
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// handleModmailPrefixReply leitet Nachrichten des Teams mit Präfix aus dem Ticket-Channel an den Ersteller weiter
+func handleModmailPrefixReply(bot *discordgo.Session, message *discordgo.MessageCreate) {
+	service := ticketService.NewTicketService(bot)
+	prefix := service.ModmailPrefix()
+	if prefix == "" || !strings.HasPrefix(message.Content, prefix) {
+		return
+	}
+	// "!r" soll nicht auf "!rules" reagieren
+	content := strings.TrimPrefix(message.Content, prefix)
+	if first, _ := utf8.DecodeRuneInString(content); content != "" && !unicode.IsSpace(first) {
+		return
+	}
+
+	ticketID, err := service.TicketIDForChannel(message.ChannelID)
+	if errors.Is(err, ticketService.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", true, err, "Fehler beim Zuordnen der Nachricht zu einem Ticket")
+		return
+	}
+	ticket, err := service.GetTicket(ticketID)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", true, err, "Fehler beim Laden von Ticket "+strconv.Itoa(ticketID))
+		return
+	}
+	// Teilnehmer ohne Team-Rolle schreiben nur intern
+	if !ticket.Modmail || !utils.HasRequiredRole(bot, message.Member, utils.RequireRoleManagement) {
+		return
+	}
+
+	files, links := downloadRelayFiles(bot, message.Attachments)
+	err = sendModmailReply(bot, ticket, message.Author, strings.TrimSpace(content), files, links)
+	reaction := "✅"
+	if err != nil {
+		reaction = "❌"
+		if _, sendErr := bot.ChannelMessageSendReply(message.ChannelID, modmailErrorText(bot, err), message.Reference()); sendErr != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", true, sendErr, "Fehler beim Melden der nicht zugestellten Antwort in Ticket #"+strconv.Itoa(ticket.ID))
+		}
+	} else if err := service.RecordMessage(ticket.ID, false, message.Timestamp); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", true, err, "Fehler beim Speichern der letzten Nachricht für Ticket "+strconv.Itoa(ticket.ID))
+	}
+	if err := bot.MessageReactionAdd(message.ChannelID, message.ID, reaction); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", false, err, "Fehler beim Bestätigen der Antwort in Ticket #"+strconv.Itoa(ticket.ID))
+	}
+}
+
+// HandleModmailReply sendet die Antwort per DM an den Ersteller und zeigt sie im Ticket-Channel (/reply message [attachment])
+func HandleModmailReply(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "ticket_modmail.go")
+	if !ok {
+		return
+	}
+	if !ticket.Modmail {
+		utils.SendWarningEmbed(bot, bot_interaction, "Kein Modmail-Ticket", "Dieses Ticket wurde nicht per DM eröffnet, der Ersteller liest direkt im Channel mit.", true)
+		return
+	}
+	if !ticket.Status.HasAccess() {
+		utils.SendWarningEmbed(bot, bot_interaction, "Ticket geschlossen", "Antworten sind nur in offenen Tickets möglich. Öffne das Ticket erneut, um dem Ersteller zu schreiben.", true)
+		return
+	}
+
+	data := bot_interaction.ApplicationCommandData()
+	var content string
+	var attachments []*discordgo.MessageAttachment
+	for _, opt := range data.Options {
+		switch opt.Name {
+		case "message":
+			content = opt.StringValue()
+		case "attachment":
+			if id, ok := opt.Value.(string); ok && data.Resolved != nil && data.Resolved.Attachments[id] != nil {
+				attachments = append(attachments, data.Resolved.Attachments[id])
+			}
+		}
+	}
+
+	// Anhänge laden kann länger als drei Sekunden dauern
+	err := bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_modmail.go", true, err, "Fehler beim Senden der Interaktionsantwort für /reply")
+		return
+	}
+
+	staff := bot_interaction.Member.User
+	files, links := downloadRelayFiles(bot, attachments)
+	if err := sendModmailReply(bot, ticket, staff, content, files, links); err != nil {
+		embeds := []*discordgo.MessageEmbed{{Title: "Antwort nicht zugestellt", Description: modmailErrorText(bot, err), Color: utils.ColorError}}
+		if _, err := bot.InteractionResponseEdit(bot_interaction.Interaction, &discordgo.WebhookEdit{Embeds: &embeds}); err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", true, err, "Fehler beim Aktualisieren der Interaktionsantwort für /reply")
+		}
+		return
+	}
+
+	// Die Kopie im Channel hält die Antwort samt Anhängen für das Transkript fest
+	embed := modmailReplyEmbed(ticket, staff, content, links)
+	embed.Footer.Text = fmt.Sprintf("Per DM an %s · Ticket #%d", ticket.CreatorName, ticket.ID)
+	embeds := []*discordgo.MessageEmbed{embed}
+	_, err = bot.InteractionResponseEdit(bot_interaction.Interaction, &discordgo.WebhookEdit{Embeds: &embeds, Files: relayDiscordFiles(files)})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", true, err, "Fehler beim Aktualisieren der Interaktionsantwort für /reply")
+	}
+	if err := ticketService.NewTicketService(bot).RecordMessage(ticket.ID, false, time.Now()); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", true, err, "Fehler beim Speichern der letzten Nachricht für Ticket "+strconv.Itoa(ticket.ID))
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// sendModmailReply schickt eine Antwort des Teams per DM an den Ersteller, nur solange das Ticket offen ist
+func sendModmailReply(bot *discordgo.Session, ticket *ticketService.Ticket, staff *discordgo.User, content string, files []relayFile, links []string) error {
+	if !ticket.Status.HasAccess() {
+		return fmt.Errorf("%w: Antwort bei Status %s", ticketService.ErrInvalidTransition, ticket.Status)
+	}
+	if strings.TrimSpace(content) == "" && len(files) == 0 && len(links) == 0 {
+		return fmt.Errorf("%w: leere Antwort", ticketService.ErrInvalidInput)
+	}
+	return sendModmailDM(bot, ticket, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{modmailReplyEmbed(ticket, staff, content, links)},
+		Files:  relayDiscordFiles(files),
+	})
+}
+
+// sendModmailDM schickt eine Nachricht an den Ersteller, auch für Textbausteine und Statusmeldungen
+func sendModmailDM(bot *discordgo.Session, ticket *ticketService.Ticket, send *discordgo.MessageSend) error {
+	dmChannel, err := bot.UserChannelCreate(ticket.CreatorID)
+	if err != nil {
+		return err
+	}
+	_, err = bot.ChannelMessageSendComplex(dmChannel.ID, send)
+	return err
+}
+
+// notifyModmailCreator meldet Statuswechsel per DM, bei normalen Tickets steht der Hinweis im Channel
+func notifyModmailCreator(bot *discordgo.Session, ticket *ticketService.Ticket, title, description string) {
+	if !ticket.Modmail || ticket.CreatorID == "" {
+		return
+	}
+	err := sendModmailDM(bot, ticket, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{{
+		Title:       title,
+		Description: description,
+		Color:       0x3498DB,
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Ticket #%d", ticket.ID)},
+	}}})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Info", "ticket_modmail.go", false, err, "Statusmeldung zu Ticket #"+strconv.Itoa(ticket.ID)+" konnte nicht per DM gesendet werden")
+	}
+}
+
+func modmailReplyEmbed(ticket *ticketService.Ticket, staff *discordgo.User, content string, links []string) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Author:      &discordgo.MessageEmbedAuthor{Name: staff.Username, IconURL: staff.AvatarURL("")},
+		Description: relayText(content, links),
+		Color:       utils.ColorSuccess,
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Antwort des Teams · Ticket #%d", ticket.ID)},
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+}
+
+// modmailGuildMember prüft, ob der User auf dem Server ist. Per DM erreichen den Bot auch User, die den Server
+// verlassen haben oder nur einen anderen Server mit ihm teilen, Tickets gibt es aber nur für Mitglieder.
+func modmailGuildMember(bot *discordgo.Session, dmChannelID string, user *discordgo.User) bool {
+	_, err := bot.GuildMember(utils.GetIdFromDB(bot, "GUILD_ID"), user.ID)
+	if err == nil {
+		return true
+	}
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeUnknownMember {
+		sendModmailNotice(bot, dmChannelID, "Ticket nicht erstellt", "Tickets per DM sind nur für Mitglieder des Servers möglich.", utils.ColorError)
+		return false
+	}
+	utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_modmail.go", true, err, "Fehler beim Prüfen der Server-Mitgliedschaft von "+user.ID+" für Modmail")
+	sendModmailNotice(bot, dmChannelID, "Ticket nicht erstellt", "Dein Ticket konnte gerade nicht erstellt werden. Bitte versuche es später erneut.", utils.ColorError)
+	return false
+}
+
+// modmailErrorText erklärt, warum eine Antwort nicht zugestellt wurde, unerwartete Fehler gehen an die Admins
+func modmailErrorText(bot *discordgo.Session, err error) string {
+	switch {
+	case errors.Is(err, ticketService.ErrInvalidTransition):
+		return "Das Ticket ist geschlossen, Antworten sind nur in offenen Tickets möglich."
+	case errors.Is(err, ticketService.ErrInvalidInput):
+		return "Die Antwort ist leer."
+	}
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeCannotSendMessagesToThisUser {
+		return "Der Ersteller hat DMs deaktiviert oder ist nicht mehr auf dem Server."
+	}
+	utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_modmail.go", true, err, "Fehler beim Senden einer Modmail-Antwort")
+	return "Die Antwort konnte nicht per DM gesendet werden."
+}
+
+func sendModmailNotice(bot *discordgo.Session, channelID, title, description string, color int) {
+	_, err := bot.ChannelMessageSendEmbed(channelID, &discordgo.MessageEmbed{Title: title, Description: description, Color: color})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Info", "ticket_modmail.go", false, err, "Modmail-Hinweis konnte nicht per DM gesendet werden")
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// downloadRelayFiles lädt Anhänge zum erneuten Hochladen, da die CDN-Links der DM nicht dauerhaft gültig sind.
+// Zu große oder nicht ladbare Dateien werden als Link weitergegeben.
+func downloadRelayFiles(bot *discordgo.Session, attachments []*discordgo.MessageAttachment) ([]relayFile, []string) {
+	var files []relayFile
+	var links []string
+	var total int
+	for _, attachment := range attachments {
+		if attachment.Size > maxRelayFileSize || total+attachment.Size > maxRelayFileSize {
+			links = append(links, fmt.Sprintf("[%s](%s)", attachment.Filename, attachment.URL))
+			continue
+		}
+		data, err := downloadRelayFile(attachment.URL)
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_modmail.go", false, err, "Fehler beim Laden des Anhangs "+attachment.Filename)
+			links = append(links, fmt.Sprintf("[%s](%s)", attachment.Filename, attachment.URL))
+			continue
+		}
+		total += len(data)
+		files = append(files, relayFile{name: attachment.Filename, contentType: attachment.ContentType, data: data})
+	}
+	return files, links
+}
+
+func downloadRelayFile(url string) ([]byte, error) {
+	resp, err := relayClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxRelayFileSize))
+}
+
+// relayDiscordFiles erstellt je Versand neue Reader, dieselben Anhänge gehen an DM und Channel
+func relayDiscordFiles(files []relayFile) []*discordgo.File {
+	var out []*discordgo.File
+	for _, file := range files {
+		out = append(out, &discordgo.File{Name: file.name, ContentType: file.contentType, Reader: bytes.NewReader(file.data)})
+	}
+	return out
+}
+
+// relayText hängt nicht hochgeladene Anhänge als Links an den Text
+func relayText(content string, links []string) string {
+	text := strings.TrimSpace(content)
+	if len(links) > 0 {
+		if text != "" {
+			text += "\n\n"
+		}
+		text += "📎 " + strings.Join(links, "\n📎 ")
+	}
+	return truncate(text, 4096)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
@@ -0,0 +1,123 @@
+package tickets
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleNotesButton zeigt die internen Notizen ephemer an, damit der Ersteller sie im Panel nicht sieht
+func HandleNotesButton(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "ticket_notes.go")
+	if !ok {
+		return
+	}
+	notes, err := ticketService.NewTicketService(bot).Notes(ticket.ID)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_notes.go", true, err, "Fehler beim Laden der Notizen für Ticket #"+strconv.Itoa(ticket.ID))
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Die Notizen konnten nicht geladen werden.", true)
+		return
+	}
+
+	description := "Noch keine Notizen. Notizen sind nur für das Team sichtbar."
+	if len(notes) > 0 {
+		// Neueste Notizen haben Vorrang, wenn das Embed-Limit erreicht ist
+		var entries []string
+		length := 0
+		for i := len(notes) - 1; i >= 0; i-- {
+			entry := fmt.Sprintf("**%s** <t:%d:f>\n%s", notes[i].AuthorName, notes[i].CreatedAt, notes[i].Content)
+			if length+len(entry) > 3800 {
+				entries = append(entries, fmt.Sprintf("*... %d ältere Notizen im Transkript*", i+1))
+				break
+			}
+			entries = append(entries, entry)
+			length += len(entry)
+		}
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+		description = strings.Join(entries, "\n\n")
+	}
+
+	err = bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       fmt.Sprintf("Interne Notizen zu Ticket #%d (%d)", ticket.ID, len(notes)),
+				Description: description,
+				Color:       utils.ColorInfo,
+			}},
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.Button{Label: "Notiz hinzufügen", Style: discordgo.PrimaryButton, CustomID: ticketCustomID("ticket_button_add_note", ticket.ID)},
+					},
+				},
+			},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_notes.go", true, err, "Fehler beim Anzeigen der Notizen für Ticket #"+strconv.Itoa(ticket.ID))
+	}
+}
+
+// HandleAddNoteButton öffnet das Modal für eine neue Notiz
+func HandleAddNoteButton(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "ticket_notes.go")
+	if !ok {
+		return
+	}
+	bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: fmt.Sprintf("ticket_note_modal_%d", ticket.ID),
+			Title:    fmt.Sprintf("Notiz zu Ticket #%d", ticket.ID),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "note_text",
+							Label:       "Notiz (nur für das Team sichtbar)",
+							Style:       discordgo.TextInputParagraph,
+							Placeholder: "z.B. Rücksprache mit der Teamleitung gehalten",
+							Required:    true,
+							MaxLength:   2000,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// HandleNoteModal speichert die Notiz aus dem Modal
+func HandleNoteModal(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticketID, err := strconv.Atoi(strings.TrimPrefix(bot_interaction.ModalSubmitData().CustomID, "ticket_note_modal_"))
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_notes.go", true, err, "Fehler beim Parsen der Ticket-ID aus der Modal CustomID")
+		return
+	}
+	text := bot_interaction.ModalSubmitData().Components[0].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value
+	saveTicketNote(bot, bot_interaction, ticketID, text)
+}
+
+// saveTicketNote speichert die Notiz und bestätigt sie ephemer
+func saveTicketNote(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, ticketID int, text string) {
+	author := ticketService.Actor{ID: bot_interaction.Member.User.ID, Name: bot_interaction.Member.User.Username}
+	note, err := ticketService.NewTicketService(bot).AddNote(ticketID, author, text)
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Speichern der Notiz")
+		return
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Notiz gespeichert", fmt.Sprintf("Notiz zu Ticket #%d (nur für das Team sichtbar):\n%s", ticketID, truncate(note.Content, 3900)), true)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
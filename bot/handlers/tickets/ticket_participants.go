@@ -0,0 +1,125 @@
+package tickets
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ParticipantContextMenu ist der Name des User-Kontextmenüs, das den User zum Ticket des aktuellen Channels hinzufügt
+const ParticipantContextMenu = "Zum Ticket hinzufügen"
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// handleTicketAddCommand behandelt /ticket add user im Ticket-Channel
+func handleTicketAddCommand(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	addTicketParticipant(bot, bot_interaction, opts["user"].UserValue(bot))
+}
+
+// HandleParticipantContextMenu fügt den über das Kontextmenü gewählten User zum Ticket des Channels hinzu
+func HandleParticipantContextMenu(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	data := bot_interaction.ApplicationCommandData()
+	user := &discordgo.User{ID: data.TargetID}
+	if data.Resolved != nil && data.Resolved.Users[data.TargetID] != nil {
+		user = data.Resolved.Users[data.TargetID]
+	}
+	addTicketParticipant(bot, bot_interaction, user)
+}
+
+// addTicketParticipant speichert den Teilnehmer und gibt ihm Zugriff, bei geschlossenen Tickets erst beim erneuten Öffnen
+func addTicketParticipant(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, user *discordgo.User) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "ticket_participants.go")
+	if !ok {
+		return
+	}
+	if user.Bot {
+		utils.SendWarningEmbed(bot, bot_interaction, "Nicht möglich", "Bots können nicht als Teilnehmer hinzugefügt werden.", true)
+		return
+	}
+
+	actor := ticketService.Actor{ID: bot_interaction.Member.User.ID, Name: bot_interaction.Member.User.Username}
+	ticket, _, err := ticketService.NewTicketService(bot).AddParticipant(ticket.ID, ticketService.Actor{ID: user.ID, Name: user.Username}, actor)
+	if err != nil {
+		respondParticipantError(bot, bot_interaction, ticket, err)
+		return
+	}
+
+	description := fmt.Sprintf("<@%s> wurde zu Ticket #%d hinzugefügt.", user.ID, ticket.ID)
+	if ticket.Status.HasAccess() {
+		channelID := ticketChannelID(ticket, bot_interaction)
+		addUserChannelPermission(bot, channelID, user.ID)
+		_, err = bot.ChannelMessageSend(channelID, fmt.Sprintf("<@%s> wurde von <@%s> zum Ticket hinzugefügt.", user.ID, actor.ID))
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_participants.go", true, err, "Fehler beim Senden der Teilnehmer-Nachricht in Ticket #"+strconv.Itoa(ticket.ID))
+		}
+	} else {
+		description += " Das Ticket ist geschlossen, der Zugriff folgt beim erneuten Öffnen."
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Teilnehmer hinzugefügt", description, true)
+}
+
+// handleTicketRemoveCommand behandelt /ticket remove user im Ticket-Channel
+func handleTicketRemoveCommand(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "ticket_participants.go")
+	if !ok {
+		return
+	}
+	user := opts["user"].UserValue(bot)
+
+	actor := ticketService.Actor{ID: bot_interaction.Member.User.ID, Name: bot_interaction.Member.User.Username}
+	ticket, _, err := ticketService.NewTicketService(bot).RemoveParticipant(ticket.ID, user.ID, actor)
+	if err != nil {
+		respondParticipantError(bot, bot_interaction, ticket, err)
+		return
+	}
+
+	// Geschlossene Tickets haben die Berechtigung bereits beim Schließen verloren
+	if ticket.Status.HasAccess() {
+		channelID := ticketChannelID(ticket, bot_interaction)
+		if err := bot.ChannelPermissionDelete(channelID, user.ID); err != nil {
+			utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_participants.go", true, err, fmt.Sprintf("Fehler beim Entfernen der Berechtigung von %s in Ticket #%d", user.ID, ticket.ID))
+		}
+		_, err = bot.ChannelMessageSend(channelID, fmt.Sprintf("<@%s> wurde von <@%s> aus dem Ticket entfernt.", user.ID, actor.ID))
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_participants.go", true, err, "Fehler beim Senden der Teilnehmer-Nachricht in Ticket #"+strconv.Itoa(ticket.ID))
+		}
+	}
+	utils.SendSuccessEmbed(bot, bot_interaction, "Teilnehmer entfernt", fmt.Sprintf("<@%s> wurde aus Ticket #%d entfernt.", user.ID, ticket.ID), true)
+}
+
+// respondParticipantError meldet unerlaubte Status und Eingabefehler nur dem Nutzer
+func respondParticipantError(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, ticket *ticketService.Ticket, err error) {
+	if errors.Is(err, ticketService.ErrInvalidTransition) {
+		handleTransitionError(bot, bot_interaction, "ticket_participants.go", ticket, err)
+		return
+	}
+	respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Ändern der Ticket-Teilnehmer")
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// setParticipantAccess gibt den Teilnehmern beim erneuten Öffnen wieder Zugriff bzw. entfernt ihn beim Schließen.
+// Anders als beim Ersteller wird die Berechtigung gelöscht statt verweigert, Teilnehmer können auch Teammitglieder sein.
+func setParticipantAccess(bot *discordgo.Session, ticketID int, channelID string, access bool) {
+	participants, err := ticketService.NewTicketService(bot).Participants(ticketID)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_participants.go", true, err, "Fehler beim Laden der Teilnehmer von Ticket #"+strconv.Itoa(ticketID))
+		return
+	}
+	for _, participant := range participants {
+		if access {
+			addUserChannelPermission(bot, channelID, participant.UserID)
+			continue
+		}
+		if err := bot.ChannelPermissionDelete(channelID, participant.UserID); err != nil {
+			utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_participants.go", true, err, fmt.Sprintf("Fehler beim Entfernen der Berechtigung von %s in Ticket #%d", participant.UserID, ticketID))
+		}
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
@@ -0,0 +1,66 @@
+package tickets
+
+import (
+	"fmt"
+	"strings"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleTicketRepair verknüpft verwaiste Ticket-Channels neu und meldet Tickets ohne Channel
+func HandleTicketRepair(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	err := bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_repair.go", false, err, "Fehler beim Antworten auf /ticket_repair")
+		return
+	}
+
+	channels, err := bot.GuildChannels(utils.GetIdFromDB(bot, "GUILD_ID"))
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_repair.go", true, err, "Fehler beim Abrufen der Guild-Channels")
+		editTicketRepairResponse(bot, bot_interaction, "Fehler", "Die Channels konnten nicht geladen werden.", utils.ColorError)
+		return
+	}
+
+	report, err := ticketService.NewTicketService(bot).Repair(channels)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_repair.go", true, err, "Fehler beim Abgleich der Ticket-Channels")
+		editTicketRepairResponse(bot, bot_interaction, "Fehler", "Der Abgleich der Ticket-Channels ist fehlgeschlagen.", utils.ColorError)
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Neu verknüpft: %d**\n", len(report.Relinked))
+	for _, ticket := range report.Relinked {
+		fmt.Fprintf(&b, "#%d – <#%s> – <@%s>\n", ticket.ID, ticket.ChannelID, ticket.CreatorID)
+	}
+	fmt.Fprintf(&b, "\n**Ohne Channel: %d**\n", len(report.Missing))
+	for _, ticket := range report.Missing {
+		fmt.Fprintf(&b, "#%d – %s – <@%s>\n", ticket.ID, ticket.Status, ticket.CreatorID)
+	}
+
+	color := utils.ColorSuccess
+	if len(report.Missing) > 0 {
+		color = utils.ColorWarning
+	}
+	editTicketRepairResponse(bot, bot_interaction, "Ticket-Channels abgeglichen", truncate(b.String(), 4000), color)
+}
+
+func editTicketRepairResponse(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, title, description string, color int) {
+	_, err := bot.InteractionResponseEdit(bot_interaction.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{{Title: title, Description: description, Color: color}},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_repair.go", false, err, "Fehler beim Bearbeiten der Antwort auf /ticket_repair")
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
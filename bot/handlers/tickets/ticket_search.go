@@ -0,0 +1,74 @@
+package tickets
+
+import (
+	"fmt"
+	"strings"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// BuildTicketSearchIndex füllt den Suchindex beim Start im Hintergrund, falls er noch leer ist
+func BuildTicketSearchIndex(bot *discordgo.Session) {
+	go func() {
+		indexed, err := ticketService.NewTicketService(bot).RebuildSearchIndex()
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_search.go", true, err, "Fehler beim Aufbau des Ticket-Suchindex")
+			return
+		}
+		if indexed > 0 {
+			utils.LogAndNotifyAdmins(bot, "info", "Info", "ticket_search.go", false, nil, fmt.Sprintf("Ticket-Suchindex mit %d Tickets aufgebaut", indexed))
+		}
+	}()
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// handleTicketSearchCommand behandelt /ticket search query [area]
+func handleTicketSearchCommand(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	query := opts["query"].StringValue()
+	area := ""
+	if opt, ok := opts["area"]; ok {
+		area = opt.StringValue()
+	}
+
+	results, err := ticketService.NewTicketService(bot).Search(query, area, 10)
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler bei der Ticket-Suche")
+		return
+	}
+	if len(results) == 0 {
+		utils.SendInfoEmbed(bot, bot_interaction, "Ticket-Suche", fmt.Sprintf("Keine Tickets zu `%s` gefunden.", query), true)
+		return
+	}
+
+	var b strings.Builder
+	for _, result := range results {
+		b.WriteString(formatSearchResult(&result) + "\n\n")
+	}
+	utils.SendInfoEmbed(bot, bot_interaction, fmt.Sprintf("Ticket-Suche: %s (%d)", truncate(query, 100), len(results)), truncate(b.String(), 4000), true)
+}
+
+// formatSearchResult zeigt Ticket, Status, Priorität, Tags und den Textausschnitt eines Treffers
+func formatSearchResult(result *ticketService.SearchResult) string {
+	ticket := result.Ticket
+	var b strings.Builder
+	fmt.Fprintf(&b, "**#%d** · %s · %s · %s", ticket.ID, ticket.Area, ticket.Status, ticket.Priority.Label())
+	if ticket.ChannelID != "" && ticket.Status != ticketService.StatusDeleted {
+		fmt.Fprintf(&b, " · <#%s>", ticket.ChannelID)
+	}
+	fmt.Fprintf(&b, "\nvon <@%s>", ticket.CreatorID)
+	if len(ticket.Tags) > 0 {
+		b.WriteString(" · `" + strings.Join(ticket.Tags, "` `") + "`")
+	}
+	if result.Snippet != "" {
+		b.WriteString("\n> " + strings.ReplaceAll(truncate(result.Snippet, 200), "\n", " "))
+	}
+	return b.String()
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
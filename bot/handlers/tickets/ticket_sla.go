@@ -0,0 +1,304 @@
+package tickets
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"bot/services/stats"
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robfig/cron/v3"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// StartTicketSLA prüft regelmäßig die SLA-Ziele: unbearbeitete Tickets eskalieren an die Support-Rolle und danach
+// an das Head Management, Bearbeiter werden an unbeantwortete Nachrichten des Erstellers erinnert
+func StartTicketSLA(bot *discordgo.Session) {
+	c := cron.New(cron.WithLocation(time.Local))
+	_, err := c.AddFunc(utils.GetOptionalIdFromDB(bot, "TICKET_SLA_CRON_SPEC", "*/5 * * * *"), func() { runTicketSLA(bot) })
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_sla.go", true, err, "Fehler beim Einrichten der Ticket-SLA-Prüfung im Cron-Scheduler")
+		return
+	}
+	c.Start()
+}
+
+func runTicketSLA(bot *discordgo.Session) {
+	service := ticketService.NewTicketService(bot)
+	now := time.Now()
+
+	factor, err := strconv.Atoi(utils.GetOptionalIdFromDB(bot, "TICKET_SLA_ESCALATION_FACTOR", "2"))
+	if err != nil {
+		factor = 2
+	}
+	escalations, err := service.DueEscalations(now, factor)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_sla.go", true, err, "Fehler beim Ermitteln fälliger Ticket-Eskalationen")
+	}
+	for _, escalation := range escalations {
+		if !sendTicketEscalation(bot, escalation) {
+			continue
+		}
+		if err := service.MarkEscalated(escalation.Ticket.ID, escalation.Level); err != nil {
+			utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_sla.go", true, err, "Fehler beim Speichern der Eskalationsstufe für Ticket "+strconv.Itoa(escalation.Ticket.ID))
+		}
+	}
+
+	reminders, err := service.DueReminders(now)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_sla.go", true, err, "Fehler beim Ermitteln fälliger Ticket-Erinnerungen")
+	}
+	for _, reminder := range reminders {
+		if !sendTicketReminder(bot, reminder) {
+			continue
+		}
+		if err := service.MarkReminded(reminder.Ticket.ID, now); err != nil {
+			utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_sla.go", true, err, "Fehler beim Speichern der Erinnerung für Ticket "+strconv.Itoa(reminder.Ticket.ID))
+		}
+	}
+}
+
+// sendTicketEscalation pingt in Stufe 1 die Support-Rolle des Bereichs, in Stufe 2 das Head Management
+func sendTicketEscalation(bot *discordgo.Session, escalation ticketService.Escalation) bool {
+	ticket := escalation.Ticket
+	if ticket.ChannelID == "" {
+		return false
+	}
+
+	mention := ""
+	allowed := &discordgo.MessageAllowedMentions{}
+	if escalation.Level >= 2 {
+		roleID := utils.GetIdFromDB(bot, "ROLE_HEAD_MANAGEMENT")
+		mention = "<@&" + roleID + ">"
+		allowed.Roles = []string{roleID}
+	} else {
+		areaService := ticketService.NewAreaService(bot)
+		area, err := areaService.GetArea(ticket.Area)
+		if err != nil {
+			utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_sla.go", true, err, "Fehler beim Laden des Bereichs für die Eskalation von Ticket "+strconv.Itoa(ticket.ID))
+			return false
+		}
+		targetID := areaService.SupportRoleID(area)
+		if area.MentionUser {
+			mention = "<@" + targetID + ">"
+			allowed.Users = []string{targetID}
+		} else {
+			mention = "<@&" + targetID + ">"
+			allowed.Roles = []string{targetID}
+		}
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "⏰ Ticket wartet auf Bearbeitung",
+		Description: fmt.Sprintf("Dieses Ticket von <@%s> wartet seit **%s** auf Bearbeitung (Ziel: %s).",
+			ticket.CreatorID, stats.FormatDuration(int(escalation.Waiting.Seconds())), stats.FormatDuration(int(escalation.Target.Seconds()))),
+		Color:  utils.ColorWarning,
+		Footer: &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Ticket #%d · Eskalationsstufe %d", ticket.ID, escalation.Level)},
+	}
+	if escalation.Level >= 2 {
+		embed.Color = utils.ColorError
+	}
+	_, err := bot.ChannelMessageSendComplex(ticket.ChannelID, &discordgo.MessageSend{
+		Content:         mention,
+		Embeds:          []*discordgo.MessageEmbed{embed},
+		AllowedMentions: allowed,
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_sla.go", true, err, "Fehler beim Senden der Eskalation für Ticket "+strconv.Itoa(ticket.ID))
+		return false
+	}
+	return true
+}
+
+// sendTicketReminder erinnert den Bearbeiter im Ticket-Channel an die unbeantwortete Nachricht
+func sendTicketReminder(bot *discordgo.Session, reminder ticketService.Reminder) bool {
+	ticket := reminder.Ticket
+	if ticket.ChannelID == "" {
+		return false
+	}
+	_, err := bot.ChannelMessageSendComplex(ticket.ChannelID, &discordgo.MessageSend{
+		Content: "<@" + ticket.ClaimerID + ">",
+		Embeds: []*discordgo.MessageEmbed{{
+			Title: "💬 Unbeantwortete Nachricht",
+			Description: fmt.Sprintf("Die letzte Nachricht von <@%s> (<t:%d:R>) ist seit **%s** unbeantwortet.",
+				ticket.CreatorID, reminder.Since, stats.FormatDuration(int(reminder.Waiting.Seconds()))),
+			Color:  utils.ColorInfo,
+			Footer: &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Ticket #%d", ticket.ID)},
+		}},
+		AllowedMentions: &discordgo.MessageAllowedMentions{Users: []string{ticket.ClaimerID}},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_sla.go", true, err, "Fehler beim Senden der Erinnerung für Ticket "+strconv.Itoa(ticket.ID))
+		return false
+	}
+	return true
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// OnTicketMessage merkt sich die letzte Nachricht von Ersteller bzw. Team in Ticket-Channels
+func OnTicketMessage(bot *discordgo.Session, message *discordgo.MessageCreate) {
+	if message.Author == nil || message.Author.Bot || message.GuildID == "" {
+		return
+	}
+	service := ticketService.NewTicketService(bot)
+	ticketID, err := service.TicketIDForChannel(message.ChannelID)
+	if errors.Is(err, ticketService.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_sla.go", true, err, "Fehler beim Zuordnen der Nachricht zu einem Ticket")
+		return
+	}
+	ticket, err := service.GetTicket(ticketID)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_sla.go", true, err, "Fehler beim Laden von Ticket "+strconv.Itoa(ticketID))
+		return
+	}
+	// Bei Modmail schreibt der Ersteller per DM und das Team über /reply bzw. Präfix, der Channel ist intern
+	if ticket.Modmail {
+		return
+	}
+	fromCreator := message.Author.ID == ticket.CreatorID
+	// Hinzugefügte Teilnehmer ohne Team-Rolle zählen nicht als Antwort des Teams
+	if !fromCreator && !isTicketTeam(bot, message.Member, message.Author.ID, ticket) {
+		return
+	}
+	if err := service.RecordMessage(ticketID, fromCreator, message.Timestamp); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_sla.go", true, err, "Fehler beim Speichern der letzten Nachricht für Ticket "+strconv.Itoa(ticketID))
+	}
+}
+
+// isTicketTeam prüft, ob der Autor zum Team gehört: Management oder die Support-Rolle (bzw. der Support-User) des Bereichs
+func isTicketTeam(bot *discordgo.Session, member *discordgo.Member, userID string, ticket *ticketService.Ticket) bool {
+	if member == nil {
+		return false
+	}
+	if utils.HasRequiredRole(bot, member, utils.RequireRoleManagement) {
+		return true
+	}
+	areaService := ticketService.NewAreaService(bot)
+	area, err := areaService.GetArea(ticket.Area)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_sla.go", false, err, "Fehler beim Laden des Bereichs von Ticket "+strconv.Itoa(ticket.ID))
+		return false
+	}
+	supportID := areaService.SupportRoleID(area)
+	if area.MentionUser {
+		return supportID == userID
+	}
+	return slices.Contains(member.Roles, supportID)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleTicketSLA zeigt den SLA-Bericht der letzten Tage (/ticket_sla [days])
+func HandleTicketSLA(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	days := 30
+	for _, opt := range bot_interaction.ApplicationCommandData().Options {
+		if opt.Name == "days" {
+			days = int(opt.IntValue())
+		}
+	}
+
+	now := time.Now()
+	service := ticketService.NewTicketService(bot)
+	report, err := service.SLAReport(now.AddDate(0, 0, -days), now)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_sla.go", true, err, "Fehler beim Erstellen des SLA-Berichts")
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Der SLA-Bericht konnte nicht erstellt werden.", true)
+		return
+	}
+	// Zufriedenheit je Bereich als zusätzliche Zeile, der Bericht funktioniert auch ohne
+	satisfaction := make(map[string]ticketService.FeedbackGroup)
+	if feedback, err := service.FeedbackReport(now.AddDate(0, 0, -days), now); err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_sla.go", false, err, "Fehler beim Laden der Zufriedenheit für den SLA-Bericht")
+	} else {
+		for _, group := range feedback.Areas {
+			satisfaction[group.Key] = group
+		}
+	}
+	if len(report.Areas) == 0 {
+		utils.SendInfoEmbed(bot, bot_interaction, "SLA-Bericht", fmt.Sprintf("In den letzten %d Tagen wurden keine Tickets erstellt.", days), true)
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("SLA-Bericht · letzte %d Tage", days),
+		Description: "Übernahme- und Schließzeiten je Bereich, Überschreitungen inkl. noch offener Tickets.",
+		Color:       utils.ColorInfo,
+	}
+	for _, area := range report.Areas {
+		if len(embed.Fields) == 24 {
+			break
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d Tickets · %d übernommen · %d geschlossen\n", area.Tickets, area.Claimed, area.Closed)
+		fmt.Fprintf(&b, "Ø Übernahme: %s%s\n", formatSLAAverage(area.AvgClaimSeconds, area.Claimed), formatSLATarget(area.ClaimBreaches, area.ClaimMinutes*60))
+		fmt.Fprintf(&b, "Ø Schließen: %s%s", formatSLAAverage(area.AvgCloseSeconds, area.Closed), formatSLATarget(area.CloseBreaches, area.CloseHours*3600))
+		if group, ok := satisfaction[area.Area]; ok {
+			b.WriteString("\nZufriedenheit: " + formatFeedbackGroup(group))
+		}
+		label := area.Label
+		if label == "" {
+			label = "Ohne Bereich"
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: truncate(label, 256), Value: b.String()})
+	}
+
+	if len(report.Breaches) > 0 {
+		var b strings.Builder
+		for _, breach := range report.Breaches {
+			kind := "nicht übernommen"
+			if breach.Kind == "close" {
+				kind = "nicht geschlossen"
+			}
+			line := fmt.Sprintf("• Ticket #%d", breach.Ticket.ID)
+			if breach.Ticket.ChannelID != "" {
+				line = fmt.Sprintf("• <#%s>", breach.Ticket.ChannelID)
+			}
+			line += fmt.Sprintf(" %s, %s über Ziel\n", kind, stats.FormatDuration(int(breach.OverdueSeconds)))
+			if b.Len()+len(line) > 1000 {
+				b.WriteString("…")
+				break
+			}
+			b.WriteString(line)
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: fmt.Sprintf("Offene Überschreitungen (%d)", len(report.Breaches)), Value: b.String()})
+	}
+
+	err = bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_sla.go", true, err, "Fehler beim Senden des SLA-Berichts")
+	}
+}
+
+func formatSLAAverage(seconds int64, count int) string {
+	if count == 0 {
+		return "-"
+	}
+	return stats.FormatDuration(int(seconds))
+}
+
+// formatSLATarget zeigt Ziel und Anzahl der Überschreitungen, sofern ein Ziel gesetzt ist
+func formatSLATarget(breaches, targetSeconds int) string {
+	if targetSeconds <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (Ziel %s, %d× überschritten)", stats.FormatDuration(targetSeconds), breaches)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
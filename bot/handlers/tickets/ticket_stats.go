@@ -0,0 +1,147 @@
+package tickets
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// statsChartFile ist der Dateiname des Diagramms, das Embed verweist per attachment:// darauf
+const statsChartFile = "ticket_stats.png"
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandleTicketStats zeigt die Ticket-Statistik mit Diagramm (/ticket_stats [from] [to] [area])
+func HandleTicketStats(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	opts := make(map[string]string)
+	for _, opt := range bot_interaction.ApplicationCommandData().Options {
+		opts[opt.Name] = opt.StringValue()
+	}
+
+	from, to, err := ticketService.ParseStatsRange(opts["from"], opts["to"], time.Now().In(statsLocation()))
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Lesen des Zeitraums")
+		return
+	}
+	report, err := ticketService.NewTicketService(bot).StatsReport(from, to, opts["area"])
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Erstellen der Ticket-Statistik")
+		return
+	}
+	message, err := TicketStatsMessage(bot, report)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_stats.go", true, err, "Fehler beim Zeichnen des Ticket-Diagramms")
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Das Diagramm konnte nicht erstellt werden.", true)
+		return
+	}
+
+	err = bot.InteractionRespond(bot_interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: message.Embeds,
+			Files:  message.Files,
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "ticket_stats.go", true, err, "Fehler beim Senden der Ticket-Statistik")
+	}
+}
+
+// statsLocation ist die Zeitzone für Datumsangaben und die Einteilung des Volumens
+func statsLocation() *time.Location {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		return time.Local
+	}
+	return location
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// TicketStatsMessage baut Embed und Diagramm eines Statistik-Berichts, auch für den wöchentlichen Report per DM
+func TicketStatsMessage(bot *discordgo.Session, report *ticketService.StatsReport) (*discordgo.MessageSend, error) {
+	location := statsLocation()
+	// to ist exklusiv, angezeigt wird der letzte enthaltene Tag
+	title := fmt.Sprintf("Ticket-Statistik · %s – %s", time.Unix(report.From, 0).In(location).Format("02.01.2006"),
+		time.Unix(report.To-1, 0).In(location).Format("02.01.2006"))
+	if report.Area != "" {
+		if area, err := ticketService.NewAreaService(bot).GetArea(report.Area); err == nil {
+			title += " · " + area.DisplayName
+		}
+	}
+
+	chart, err := ticketService.RenderStatsChart(report, chartTitle(report))
+	if err != nil {
+		return nil, err
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       truncate(title, 256),
+		Description: formatTicketStats(report.Total),
+		Color:       utils.ColorInfo,
+		Image:       &discordgo.MessageEmbedImage{URL: "attachment://" + statsChartFile},
+	}
+	if report.Total.Tickets == 0 {
+		embed.Description = "Im Zeitraum wurden keine Tickets erstellt."
+	}
+	for _, area := range report.Areas {
+		// Höchstens zehn Bereiche, damit das Embed mit den Bearbeitern unter 6000 Zeichen bleibt.
+		// Bei nur einem Bereich steht alles bereits in der Beschreibung.
+		if len(embed.Fields) == 10 || len(report.Areas) == 1 {
+			break
+		}
+		label := area.Label
+		if label == "" {
+			label = "Ohne Bereich"
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: truncate(label, 256), Value: formatTicketStats(area)})
+	}
+	if len(report.Moderators) > 0 {
+		var b strings.Builder
+		for _, moderator := range report.Moderators {
+			line := fmt.Sprintf("• <@%s> %d bearbeitet · %d geschlossen\n", moderator.ID, moderator.Handled, moderator.Closed)
+			if b.Len()+len(line) > 1000 {
+				b.WriteString("…")
+				break
+			}
+			b.WriteString(line)
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Bearbeiter", Value: b.String()})
+	}
+
+	return &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Files:  []*discordgo.File{{Name: statsChartFile, ContentType: "image/png", Reader: bytes.NewReader(chart)}},
+	}, nil
+}
+
+// chartTitle beschreibt die Einteilung des Volumens im Diagramm
+func chartTitle(report *ticketService.StatsReport) string {
+	switch report.Interval {
+	case ticketService.StatsIntervalWeek:
+		return fmt.Sprintf("Neue Tickets pro Woche (%d gesamt)", report.Total.Tickets)
+	case ticketService.StatsIntervalMonth:
+		return fmt.Sprintf("Neue Tickets pro Monat (%d gesamt)", report.Total.Tickets)
+	default:
+		return fmt.Sprintf("Neue Tickets pro Tag (%d gesamt)", report.Total.Tickets)
+	}
+}
+
+// formatTicketStats zeigt Anzahl, Median/P90 der Übernahme- und Schließzeit sowie Reopen- und User-Left-Quote
+func formatTicketStats(group ticketService.TicketStats) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d Tickets · %d übernommen · %d geschlossen\n", group.Tickets, group.Claimed, group.Closed)
+	fmt.Fprintf(&b, "Übernahme: Median %s · P90 %s\n", formatSLAAverage(group.ClaimMedian, group.Claimed), formatSLAAverage(group.ClaimP90, group.Claimed))
+	fmt.Fprintf(&b, "Schließen: Median %s · P90 %s\n", formatSLAAverage(group.CloseMedian, group.Closed), formatSLAAverage(group.CloseP90, group.Closed))
+	fmt.Fprintf(&b, "Reopen: %.0f%% (%d) · User-Left: %.0f%% (%d)", group.ReopenRate, group.Reopened, group.UserLeftRate, group.UserLeft)
+	return b.String()
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
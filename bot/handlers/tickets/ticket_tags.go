@@ -0,0 +1,96 @@
+package tickets
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	ticketService "bot/services/tickets"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// handleTicketTagCommand behandelt /ticket tag [add] [remove] [priority] im Ticket-Channel
+func handleTicketTagCommand(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	if len(opts) == 0 {
+		utils.SendWarningEmbed(bot, bot_interaction, "Keine Änderung", "Gib einen Tag zum Hinzufügen oder Entfernen oder eine Priorität an.", true)
+		return
+	}
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "ticket_tags.go")
+	if !ok {
+		return
+	}
+
+	service := ticketService.NewTicketService(bot)
+	var err error
+	if opt, ok := opts["add"]; ok {
+		if ticket, err = service.AddTag(ticket.ID, opt.StringValue()); err != nil {
+			respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Hinzufügen des Tags")
+			return
+		}
+	}
+	if opt, ok := opts["remove"]; ok {
+		if ticket, err = service.RemoveTag(ticket.ID, opt.StringValue()); err != nil {
+			respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Entfernen des Tags")
+			return
+		}
+	}
+	if opt, ok := opts["priority"]; ok {
+		if ticket, err = service.SetPriority(ticket.ID, ticketService.Priority(opt.StringValue())); err != nil {
+			respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Setzen der Priorität")
+			return
+		}
+	}
+
+	updateModerationPanel(bot, ticketChannelID(ticket, bot_interaction), ticket)
+	utils.SendSuccessEmbed(bot, bot_interaction, "Ticket aktualisiert", formatTicketLabels(ticket), true)
+}
+
+// formatTicketLabels zeigt Priorität und Tags eines Tickets
+func formatTicketLabels(ticket *ticketService.Ticket) string {
+	tags := "keine"
+	if len(ticket.Tags) > 0 {
+		tags = "`" + strings.Join(ticket.Tags, "` `") + "`"
+	}
+	return fmt.Sprintf("**Priorität:** %s\n**Tags:** %s", ticket.Priority.Label(), tags)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// HandlePrioritySelect setzt die Priorität über das Auswahlmenü im Moderations-Panel
+func HandlePrioritySelect(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "ticket_tags.go")
+	if !ok {
+		return
+	}
+	values := bot_interaction.MessageComponentData().Values
+	if len(values) == 0 {
+		return
+	}
+	updated, err := ticketService.NewTicketService(bot).SetPriority(ticket.ID, ticketService.Priority(values[0]))
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "medium", "Error", "ticket_tags.go", true, err, "Fehler beim Setzen der Priorität von Ticket #"+strconv.Itoa(ticket.ID))
+		utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Die Priorität konnte nicht gesetzt werden.", true)
+		return
+	}
+	respondModerationPanel(bot, bot_interaction, updated)
+}
+
+// HandleTagsSelect übernimmt die im Moderations-Panel ausgewählten Tags
+func HandleTagsSelect(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) {
+	ticket, ok := loadTicketFromInteraction(bot, bot_interaction, "ticket_tags.go")
+	if !ok {
+		return
+	}
+	updated, err := ticketService.NewTicketService(bot).SetTags(ticket.ID, bot_interaction.MessageComponentData().Values)
+	if err != nil {
+		respondTicketAdminError(bot, bot_interaction, err, "Fehler beim Speichern der Tags")
+		return
+	}
+	respondModerationPanel(bot, bot_interaction, updated)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
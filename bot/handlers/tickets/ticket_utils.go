@@ -1,11 +1,14 @@
 package tickets
 
 import (
+	"errors"
 	"strconv"
 	"strings"
 	"fmt"
 
     "bot/database"
+    "bot/services/events"
+    ticketService "bot/services/tickets"
     "bot/utils"
 
 	"github.com/bwmarrin/discordgo"
@@ -14,108 +17,84 @@ import (
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
-// Helper function to get the Role ID for a ticket based on its custom ID as the const_key in DB isnt matching the custom ID
-func getRoleIDForTicket(bot *discordgo.Session, bereich string) string {
-    roles := map[string]string{
-        "ticket_diamond_club":        utils.GetIdFromDB(bot, "ROLE_TICKET_DIAMOND_CLUB"),
-        "ticket_pro_teams":           utils.GetIdFromDB(bot, "ROLE_TICKET_PROTEAMS"),
-        "ticket_bewerbung_staff":     utils.GetIdFromDB(bot, "ROLE_TICKET_STAFFAPPLICATION"),
-        "ticket_support_kontakt":     utils.GetIdFromDB(bot, "ROLE_TICKET_SUPPORT_CONTACT"),
-        "ticket_sonstiges":           utils.GetIdFromDB(bot, "ROLE_TICKET_SONSTIGE"),
-        "ticket_content_creator":     utils.GetIdFromDB(bot, "ROLE_TICKET_CONTENT_CREATOR"),
-        "ticket_game_lol":            utils.GetIdFromDB(bot, "ROLE_TICKET_GAME_LOL"),
-        "ticket_game_r6":             utils.GetIdFromDB(bot, "ROLE_TICKET_GAME_R6"),
-        "ticket_game_cs2":            utils.GetIdFromDB(bot, "ROLE_TICKET_GAME_CS2"),
-        "ticket_game_valorant":       utils.GetIdFromDB(bot, "ROLE_TICKET_GAME_VALORANT"),
-        "ticket_game_rocket_league":  utils.GetIdFromDB(bot, "ROLE_TICKET_GAME_ROCKETLEAGUE"),
-        "ticket_game_sonstige":       utils.GetIdFromDB(bot, "ROLE_TICKET_GAME_SONSTIGE"),
-        // "game_splatoon":    os.Getenv("ROLE_TICKET_GAME_SPLATOON"),
-    }
-    if roleID, ok := roles[bereich]; ok {
-        return roleID
-    }
-    return utils.GetIdFromDB(bot, "ROLE_TICKET_STANDARD")
+// Präfixe der Moderations-Buttons, an die die Ticket-ID angehängt wird ("ticket_button_claim_<id>")
+var ticketCustomIDPrefixes = []string{
+	"ticket_button_claim",
+	"ticket_button_close",
+	"ticket_button_reopen",
+	"ticket_button_assign",
+	"ticket_button_delete",
+	"ticket_button_keep_open",
+	"ticket_button_notes",
+	"ticket_button_add_note",
+	"ticket_button_approve",
+	"ticket_button_reject",
+	"ticket_button_feedback_comment",
+	"ticket_button_move",
+	"ticket_select_assignee",
+	"ticket_select_priority",
+	"ticket_select_tags",
+	"ticket_select_team",
+	"ticket_select_rating",
+	"ticket_select_move",
+	"ticket_confirm_delete_ticket",
 }
 
-/*--------------------------------------------------------------------------------------------------------------------------*/
-
-func getTicketAreaForTicket(area string) string {
-    areas := map[string]string{
-        "ticket_diamond_club":         "Diamond Club Bewerbung",
-        "ticket_pro_teams":            "Pro Team Bewerbung",
-        "ticket_bewerbung_staff":      "Bewerbung Staff",
-        "ticket_support_kontakt":      "Kontakt/Support",
-        "ticket_sonstiges":            "Sonstiges",
-        "ticket_content_creator":      "Content Creator",
-		"ticket_game_lol":             "League of Legends",
-		"ticket_game_r6":              "Rainbow Six",
-		"ticket_game_cs2":             "Counter Strike 2",
-		"ticket_game_valorant":        "Valorant",
-		"ticket_game_rocket_league":   "Rocket League",
-        "ticket_game_sonstige":        "Spiel Sonstige",
-        //"game_splatoon":      "Splatoon",
-    }
-    if areaName, ok := areas[area]; ok {
-        return areaName
-    }
-    return "Standardbereich"
+// ticketCustomID hängt die Ticket-ID an die CustomID an
+func ticketCustomID(base string, ticketID int) string {
+	return fmt.Sprintf("%s_%d", base, ticketID)
 }
 
-/*--------------------------------------------------------------------------------------------------------------------------*/
-
-func getLabelsForTicket(bereich string) []string {
-    labels := map[string][]string{
-        "ticket_diamond_club":          {"Vorname", "Alter", "Dein Main Game", "Gib uns kurz an wann du Zeit hast"},
-        "ticket_pro_teams":             {"Vorname", "Alter", "Welches Spiel?", "Erfahrungen im Team?", "Tracker & Social Media",},
-        "ticket_bewerbung_staff":       {"Vorname", "Alter", "Für was bewirbst du dich?", "Erfahrungen in dem Bereich?", "Stelle dich kurz vor"},
-        "ticket_support_kontakt":       {"Vorname", "Was ist dein Anliegen?"},
-        "ticket_sonstiges":             {"Vorname", "Was ist dein Anliegen?"},
-        "ticket_content_creator":       {"Vorname", "Alter", "Social Links", "Weiteres"},
-        "ticket_game_lol":              {"Vorname", "Alter", "Main Rolle", "Rang", "op.gg Link"},
-        "ticket_game_r6":               {"Vorname", "Alter", "R6 Tracker Link", "Plattform", "Infos über DICH!"},
-        "ticket_game_cs2":              {"Vorname", "Alter", "Steam Tracker Link", "Rang", "Infos über DICH!"},
-        "ticket_game_valorant":         {"Vorname", "Alter", "InGame Name", "Tracker Link"},
-        "ticket_game_rocket_league":    {"Vorname", "Alter", "InGame Name", "RL Tracker Network Link", "Wunsch Elo"},
-        "ticket_game_sonstige":         {"Vorname", "Alter", "Bitte erkläre kurz für was du dich bewirbst"},
-        // "game_splatoon":         {"Vorname", "Alter", "InGame Name", "Rang"},
-    }
-    if labelList, ok := labels[bereich]; ok {
-        return labelList
-    }
-    return []string{}
+// SplitTicketCustomID trennt CustomID und Ticket-ID, ältere Buttons ohne ID liefern 0
+func SplitTicketCustomID(customID string) (string, int) {
+	for _, prefix := range ticketCustomIDPrefixes {
+		if !strings.HasPrefix(customID, prefix+"_") {
+			continue
+		}
+		if ticketID, err := strconv.Atoi(strings.TrimPrefix(customID, prefix+"_")); err == nil {
+			return prefix, ticketID
+		}
+	}
+	return customID, 0
 }
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
-// gets TicketID from channelName
-func ExtractTicketIDFromChannel(bot *discordgo.Session, channelName string) (int, error) {
-	// Channel-Name should have structure like "ticketID-status-creator"
-	parts := strings.Split(channelName, "-")
-	if len(parts) < 1 {
-		return 0, nil
+// gets TicketID from interaction
+// Buttons tragen die Ticket-ID in der CustomID, sonst wird über ticket_channel_id aufgelöst
+func GetTicketIDFromInteraction(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) (int, error) {
+	if bot_interaction.Type == discordgo.InteractionMessageComponent {
+		if _, ticketID := SplitTicketCustomID(bot_interaction.MessageComponentData().CustomID); ticketID != 0 {
+			return ticketID, nil
+		}
 	}
+	return ticketService.NewTicketService(bot).TicketIDForChannel(bot_interaction.ChannelID)
+}
 
-	// konvert the first part to an integer (ticketID)
-	ticketID, err := strconv.Atoi(parts[0])
-	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_utils.go", true, err, "Fehler beim Konvertieren der Ticket-ID aus dem Kanalnamen")
-		return 0, err
+// loadTicketFromInteraction löst das Ticket der Interaktion auf und antwortet selbst, falls das nicht klappt
+func loadTicketFromInteraction(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate, file string) (*ticketService.Ticket, bool) {
+	ticketID, err := GetTicketIDFromInteraction(bot, bot_interaction)
+	if err == nil {
+		var ticket *ticketService.Ticket
+		if ticket, err = ticketService.NewTicketService(bot).GetTicket(ticketID); err == nil {
+			return ticket, true
+		}
 	}
-
-	return ticketID, nil
+	if errors.Is(err, ticketService.ErrNotFound) {
+		utils.SendWarningEmbed(bot, bot_interaction, "Kein Ticket", "Zu diesem Channel gibt es kein Ticket. Mit `/ticket_repair` können umbenannte Ticket-Channels neu verknüpft werden.", true)
+		return nil, false
+	}
+	utils.LogAndNotifyAdmins(bot, "high", "Error", file, true, err, "Fehler beim Abrufen der Ticket-ID aus der Interaktion")
+	utils.SendErrorEmbed(bot, bot_interaction, "Fehler", "Das Ticket konnte nicht geladen werden.", true)
+	return nil, false
 }
 
-/*--------------------------------------------------------------------------------------------------------------------------*/
-
-// gets TicketID from interaction
-func GetTicketIDFromInteraction(bot *discordgo.Session, bot_interaction *discordgo.InteractionCreate) (int, error) {
-	channel, err := bot.Channel(bot_interaction.ChannelID)
-	if err != nil {
-		utils.LogAndNotifyAdmins(bot, "high", "Error", "ticket_utils.go", true, err, "Fehler beim Abrufen des Kanals aus der Interaktion")
-		return 0, err
+// ticketChannelID liefert den gespeicherten Ticket-Channel, ältere Tickets ohne Eintrag nutzen den aktuellen Channel
+func ticketChannelID(ticket *ticketService.Ticket, bot_interaction *discordgo.InteractionCreate) string {
+	if ticket.ChannelID != "" {
+		return ticket.ChannelID
 	}
-
-	return ExtractTicketIDFromChannel(bot, channel.Name)
+	return bot_interaction.ChannelID
 }
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
@@ -215,4 +194,15 @@ func removeUserChannelPermission(bot *discordgo.Session, channelID string, userI
 	return nil
 }
 
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// publishTicketStatus meldet einen Statuswechsel an den Live-Event-Stream
+func publishTicketStatus(ticketID int, status string, actorID string) {
+	events.Publish(events.TypeTicketStatus, map[string]interface{}{
+		"ticket_id": ticketID,
+		"status":    status,
+		"actor_id":  actorID,
+	})
+}
+
 /*--------------------------------------------------------------------------------------------------------------------------*/
\ No newline at end of file
@@ -0,0 +1,65 @@
+package tickets
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"bot/services/storage"
+	ticketService "bot/services/tickets"
+	"bot/services/transcripts"
+	"bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robfig/cron/v3"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// StartTranscriptRetention löscht regelmäßig Transkripte, die älter als TRANSCRIPT_RETENTION_DAYS sind (0 = nie)
+func StartTranscriptRetention(bot *discordgo.Session) {
+	c := cron.New(cron.WithLocation(time.Local))
+	_, err := c.AddFunc(utils.GetOptionalIdFromDB(bot, "TRANSCRIPT_RETENTION_CRON_SPEC", "30 4 * * *"), func() { runTranscriptRetention(bot) })
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "transcript_retention.go", true, err, "Fehler beim Einrichten der Transkript-Aufbewahrung im Cron-Scheduler")
+		return
+	}
+	c.Start()
+}
+
+func runTranscriptRetention(bot *discordgo.Session) {
+	days, err := strconv.Atoi(utils.GetOptionalIdFromDB(bot, "TRANSCRIPT_RETENTION_DAYS", "0"))
+	if err != nil || days <= 0 {
+		return
+	}
+
+	store, err := storage.Default()
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "transcript_retention.go", true, err, "Fehler beim Initialisieren des Transkript-Speichers")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	report, err := transcripts.ApplyRetention(ctx, store, time.Now().AddDate(0, 0, -days))
+	if report != nil {
+		// Auch bei einem Abbruch die bereits gelöschten Transkripte austragen
+		service := ticketService.NewTicketService(bot)
+		for _, key := range report.Transcripts {
+			if clearErr := service.ClearTranscript(key); clearErr != nil {
+				utils.LogAndNotifyAdmins(bot, "low", "Error", "transcript_retention.go", true, clearErr, "Fehler beim Austragen des Transkripts "+key)
+			}
+		}
+	}
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "transcript_retention.go", true, err, "Fehler bei der Transkript-Aufbewahrung")
+		return
+	}
+	if len(report.Transcripts) > 0 || report.Attachments > 0 {
+		utils.LogAndNotifyAdmins(bot, "info", "Info", "transcript_retention.go", false, nil,
+			fmt.Sprintf("Transkript-Aufbewahrung: %d Transkripte und %d Anhänge gelöscht (älter als %d Tage)", len(report.Transcripts), report.Attachments, days))
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
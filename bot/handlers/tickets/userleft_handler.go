@@ -1,9 +1,12 @@
 package tickets
 
 import (
-	"time"
+	"errors"
+	"strconv"
+	"sync"
 
-	"bot/database"
+	"bot/services/events"
+	ticketService "bot/services/tickets"
 	"bot/utils"
 
 	"github.com/bwmarrin/discordgo"
@@ -11,80 +14,170 @@ import (
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
 
-// CheckAndNotifyInactiveUsers checks for inactive users in tickets and notifies the server
-func CheckAndNotifyInactiveUsers(bot *discordgo.Session) {
-	ticker := time.NewTicker(5 * time.Minute)
-	go func() {
-		for range ticker.C {
-			rows, err := database.DB.Query(`
-				SELECT ticket_channel_id, ticket_ersteller_id, ticket_ersteller_name
-				FROM tickets
-				WHERE ticket_status != "Deleted" AND ticket_status != "UserLeft"
-			`)
-			if err != nil {
-				utils.LogAndNotifyAdmins(bot, "high", "Error", "userleft_handler.go", true, err, "Fehler beim Abrufen der Ticket-Daten")
-				continue
-			}
-			var updates []string
-			for rows.Next() {
-				var channelID, creatorID, creatorName string
-				err := rows.Scan(&channelID, &creatorID, &creatorName)
-				if err != nil {
-					utils.LogAndNotifyAdmins(bot, "high", "Error", "userleft_handler.go", true, err, "Fehler beim Scannen der Ticket-Daten")
-					continue
-				}
-				_, err = bot.GuildMember(utils.GetIdFromDB(bot, "GUILD_ID"), creatorID)
-				if err != nil {
-					if discordErr, ok := err.(*discordgo.RESTError); ok && discordErr.Message != nil && discordErr.Message.Code == discordgo.ErrCodeUnknownMember {
-						message := &discordgo.MessageEmbed{
-							Title:       "Benutzer nicht mehr auf dem Server",
-							Description: "Der Ersteller dieses Tickets ist nicht mehr auf dem Server.",
-							Color:       0xFF0000, // Rot
-						}
-						// Button zum löschen des Tickets
-						components := []discordgo.MessageComponent{
-							discordgo.ActionsRow{
-								Components: []discordgo.MessageComponent{
-									discordgo.Button{
-										Label:    "Delete Ticket",
-										Style:    discordgo.DangerButton,
-										CustomID: "ticket_button_delete",
-									},
-								},
-							},
-						}
-						_, sendErr := bot.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
-							Embed:      message,
-							Components: components,
-						})
-						if sendErr != nil {
-							utils.LogAndNotifyAdmins(bot, "low", "Error", "userleft_handler.go", true, sendErr, "Fehler beim Senden der Nachricht an den Ticket-Kanal")
-						}
-						updates = append(updates, channelID)
-					} else {
-						utils.LogAndNotifyAdmins(bot, "high", "Error", "userleft_handler.go", true, err, "Fehler beim Überprüfen des Benutzers im Ticket-Kanal")
-					}
-				}
-			}
-			rows.Close()
-			if len(updates) > 0 {
-				tx, txErr := database.DB.Begin()
-				if txErr != nil {
-					utils.LogAndNotifyAdmins(bot, "high", "Error", "userleft_handler.go", true, txErr, "Fehler beim Starten der Transaktion")
-					continue
-				}
-				for _, channelID := range updates {
-					_, updateErr := tx.Exec(`UPDATE tickets SET ticket_status = ? WHERE ticket_channel_id = ?`, "UserLeft", channelID)
-					if updateErr != nil {
-						utils.LogAndNotifyAdmins(bot, "high", "Error", "userleft_handler.go", true, updateErr, "Fehler beim Aktualisieren des Ticket-Status in der Datenbank")
-					}
-				}
-				if commitErr := tx.Commit(); commitErr != nil {
-					utils.LogAndNotifyAdmins(bot, "high", "Error", "userleft_handler.go", true, commitErr, "Fehler beim Commit der Transaktion")
-				}
-			}
+// Zustände, in denen der Ersteller noch als Mitglied geführt wird (Kanal existiert noch)
+var memberTicketStatuses = []ticketService.Status{ticketService.StatusOpen, ticketService.StatusClaimed, ticketService.StatusClosed}
+
+var reconcileOnce sync.Once
+
+// OnGuildMemberRemove markiert die Tickets des Users als "UserLeft" und meldet das einmalig im Ticket-Kanal
+func OnGuildMemberRemove(bot *discordgo.Session, m *discordgo.GuildMemberRemove) {
+	if m.User == nil || m.GuildID != utils.GetIdFromDB(bot, "GUILD_ID") {
+		return
+	}
+	tickets, err := ticketService.NewTicketService(bot).CreatorTickets(m.User.ID, memberTicketStatuses...)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "userleft_handler.go", true, err, "Fehler beim Abrufen der Tickets von "+m.User.ID)
+		return
+	}
+	for _, ticket := range tickets {
+		markCreatorLeft(bot, ticket.ID)
+	}
+}
+
+// OnGuildMemberAdd stellt Status und Kanal-Berechtigung der Tickets wieder her, wenn der Ersteller zurückkommt
+func OnGuildMemberAdd(bot *discordgo.Session, m *discordgo.GuildMemberAdd) {
+	if m.User == nil || m.GuildID != utils.GetIdFromDB(bot, "GUILD_ID") {
+		return
+	}
+	tickets, err := ticketService.NewTicketService(bot).CreatorTickets(m.User.ID, ticketService.StatusUserLeft)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "userleft_handler.go", true, err, "Fehler beim Abrufen der Tickets von "+m.User.ID)
+		return
+	}
+	for _, ticket := range tickets {
+		markCreatorReturned(bot, ticket.ID)
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// ReconcileTicketMembers gleicht nach dem Start einmalig die Tickets mit der Mitgliederliste ab,
+// um Austritte und Rückkehrer zu erfassen, die während der Downtime passiert sind
+func ReconcileTicketMembers(bot *discordgo.Session) {
+	reconcileOnce.Do(func() {
+		go reconcileTicketMembers(bot)
+	})
+}
+
+func reconcileTicketMembers(bot *discordgo.Session) {
+	members, err := guildMemberIDs(bot, utils.GetIdFromDB(bot, "GUILD_ID"))
+	if err != nil {
+		// Ohne vollständige Mitgliederliste würden alle Ersteller als ausgetreten gelten
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "userleft_handler.go", true, err, "Fehler beim Abrufen der Mitgliederliste für den Ticket-Abgleich")
+		return
+	}
+
+	service := ticketService.NewTicketService(bot)
+	tickets, err := service.CreatorTickets("", append(memberTicketStatuses, ticketService.StatusUserLeft)...)
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "userleft_handler.go", true, err, "Fehler beim Abrufen der Ticket-Daten")
+		return
+	}
+	for _, ticket := range tickets {
+		_, isMember := members[ticket.CreatorID]
+		switch {
+		case ticket.Status == ticketService.StatusUserLeft && isMember:
+			markCreatorReturned(bot, ticket.ID)
+		case ticket.Status != ticketService.StatusUserLeft && !isMember:
+			markCreatorLeft(bot, ticket.ID)
+		}
+	}
+}
+
+// guildMemberIDs lädt alle Mitglieder seitenweise (1000 pro Anfrage)
+func guildMemberIDs(bot *discordgo.Session, guildID string) (map[string]struct{}, error) {
+	ids := make(map[string]struct{})
+	after := ""
+	for {
+		members, err := bot.GuildMembers(guildID, after, 1000)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			ids[member.User.ID] = struct{}{}
 		}
-	}()
+		if len(members) < 1000 {
+			return ids, nil
+		}
+		after = members[len(members)-1].User.ID
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// markCreatorLeft setzt den Status und postet den Hinweis nur, wenn der Übergang tatsächlich stattgefunden hat
+func markCreatorLeft(bot *discordgo.Session, ticketID int) {
+	ticket, err := ticketService.NewTicketService(bot).MarkUserLeft(ticketID)
+	if errors.Is(err, ticketService.ErrInvalidTransition) {
+		return
+	}
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "userleft_handler.go", true, err, "Fehler beim Aktualisieren des Ticket-Status in der Datenbank")
+		return
+	}
+	publishCreatorStatus(ticket)
+	if ticket.ChannelID == "" {
+		return
+	}
+
+	_, err = bot.ChannelMessageSendComplex(ticket.ChannelID, &discordgo.MessageSend{
+		Embed: &discordgo.MessageEmbed{
+			Title:       "Benutzer nicht mehr auf dem Server",
+			Description: "Der Ersteller dieses Tickets ist nicht mehr auf dem Server.",
+			Color:       utils.ColorError,
+		},
+		// Button zum löschen des Tickets
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Delete Ticket",
+						Style:    discordgo.DangerButton,
+						CustomID: ticketCustomID("ticket_button_delete", ticketID),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "userleft_handler.go", true, err, "Fehler beim Senden der Nachricht an den Ticket-Kanal")
+	}
+}
+
+// markCreatorReturned stellt den vorherigen Status wieder her und gibt dem Ersteller bei offenen Tickets wieder Zugriff
+func markCreatorReturned(bot *discordgo.Session, ticketID int) {
+	ticket, err := ticketService.NewTicketService(bot).MarkUserReturned(ticketID)
+	if errors.Is(err, ticketService.ErrInvalidTransition) {
+		return
+	}
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "high", "Error", "userleft_handler.go", true, err, "Fehler beim Wiederherstellen des Status von Ticket "+strconv.Itoa(ticketID))
+		return
+	}
+	publishCreatorStatus(ticket)
+	if ticket.ChannelID == "" {
+		return
+	}
+
+	if ticket.Status != ticketService.StatusClosed && !ticket.Modmail {
+		addUserChannelPermission(bot, ticket.ChannelID, ticket.CreatorID)
+	}
+	_, err = bot.ChannelMessageSendEmbed(ticket.ChannelID, &discordgo.MessageEmbed{
+		Title:       "Benutzer wieder auf dem Server",
+		Description: "<@" + ticket.CreatorID + "> ist dem Server wieder beigetreten, das Ticket ist wieder **" + string(ticket.Status) + "**.",
+		Color:       utils.ColorSuccess,
+	})
+	if err != nil {
+		utils.LogAndNotifyAdmins(bot, "low", "Error", "userleft_handler.go", true, err, "Fehler beim Senden der Nachricht an den Ticket-Kanal")
+	}
+}
+
+func publishCreatorStatus(ticket *ticketService.Ticket) {
+	events.Publish(events.TypeTicketStatus, map[string]interface{}{
+		"ticket_id":  ticket.ID,
+		"channel_id": ticket.ChannelID,
+		"status":     string(ticket.Status),
+	})
 }
 
 /*--------------------------------------------------------------------------------------------------------------------------*/
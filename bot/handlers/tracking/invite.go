@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 	"bot/utils"
+	"bot/services/events"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -74,6 +75,13 @@ func (it *InviteTracker) OnGuildMemberAdd(s *discordgo.Session, m *discordgo.Gui
 		}
 	}
 
+	events.Publish(events.TypeMemberJoin, map[string]interface{}{
+		"user_id":     m.User.ID,
+		"username":    m.User.Username,
+		"inviter_id":  inviterDiscordID,
+		"invite_code": usedCode,
+	})
+
 	// Optional: wenn kein Invite erkannt, abbrechen
 	if usedCode == "" {
 		return
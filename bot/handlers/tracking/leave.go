@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 	"bot/utils"
+	"bot/services/events"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -35,4 +36,9 @@ func (lt *LeaveTracker) OnGuildMemberRemove(s *discordgo.Session, m *discordgo.G
 	if err != nil {
 		log.Printf("Fehler beim Schreiben des Leaves in die DB: %v", err)
 	}
+
+	events.Publish(events.TypeMemberLeave, map[string]interface{}{
+		"user_id":  m.User.ID,
+		"username": m.User.Username,
+	})
 }
\ No newline at end of file
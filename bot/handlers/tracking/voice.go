@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 	"bot/utils"
+	"bot/services/events"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -47,6 +48,7 @@ func (vt *VoiceTracker) OnVoiceStateUpdate(s *discordgo.Session, vs *discordgo.V
 	// 1) User joint einem Channel
 	if oldChannel == "" && newChannel != "" {
 		vt.sessions[userID] = voiceSession{channelID: newChannel, joinedAt: time.Now()}
+		publishVoiceJoin(userID, newChannel)
 		return
 	}
 
@@ -65,6 +67,7 @@ func (vt *VoiceTracker) OnVoiceStateUpdate(s *discordgo.Session, vs *discordgo.V
 		}
 
 		delete(vt.sessions, userID)
+		publishVoiceLeave(userID, sess.channelID, duration)
 		return
 	}
 
@@ -82,5 +85,24 @@ func (vt *VoiceTracker) OnVoiceStateUpdate(s *discordgo.Session, vs *discordgo.V
 			log.Printf("Fehler beim Schreiben des Voice-Log-Wechsels: %v", err)
 		}
 		vt.sessions[userID] = voiceSession{channelID: newChannel, joinedAt: time.Now()}
+		publishVoiceLeave(userID, sess.channelID, duration)
+		publishVoiceJoin(userID, newChannel)
 	}
+}
+
+// publishVoiceJoin meldet einen Voice-Join an den Live-Event-Stream
+func publishVoiceJoin(userID, channelID string) {
+	events.Publish(events.TypeVoiceJoin, map[string]interface{}{
+		"user_id":    userID,
+		"channel_id": channelID,
+	})
+}
+
+// publishVoiceLeave meldet einen Voice-Leave inkl. Dauer an den Live-Event-Stream
+func publishVoiceLeave(userID, channelID string, duration int) {
+	events.Publish(events.TypeVoiceLeave, map[string]interface{}{
+		"user_id":    userID,
+		"channel_id": channelID,
+		"duration":   duration,
+	})
 }
\ No newline at end of file
@@ -112,6 +112,14 @@ func (s *Scheduler) generateAndSendReports() error {
 		return fmt.Errorf("failed to send reports: %w", err)
 	}
 
+	// Optional ticket statistics of the last week
+	if s.config.TicketStats {
+		lastWeek := timeRanges["lastWeek"]
+		if err := s.sender.SendTicketStats(s.config.UserIDs, lastWeek.Start, lastWeek.End); err != nil {
+			return fmt.Errorf("failed to send ticket stats: %w", err)
+		}
+	}
+
 	log.Println("Weekly report generation completed successfully")
 	return nil
 }
@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
+
+	"bot/handlers/tickets"
+	ticketService "bot/services/tickets"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -85,6 +89,31 @@ func (ds *DiscordSender) sendFile(channelID, filePath, filename string) error {
 	return nil
 }
 
+// SendTicketStats sends the ticket statistics (summary and chart) for the given range to the specified users
+func (ds *DiscordSender) SendTicketStats(userIDs []string, since, before time.Time) error {
+	report, err := ticketService.NewTicketService(ds.session).StatsReport(since, before, "")
+	if err != nil {
+		return fmt.Errorf("failed to build ticket stats: %w", err)
+	}
+
+	for _, userID := range userIDs {
+		channel, err := ds.session.UserChannelCreate(userID)
+		if err != nil {
+			return fmt.Errorf("failed to create DM channel for user %s: %w", userID, err)
+		}
+		// The chart reader can only be sent once, so every user gets a fresh message
+		message, err := tickets.TicketStatsMessage(ds.session, report)
+		if err != nil {
+			return fmt.Errorf("failed to render ticket stats: %w", err)
+		}
+		if _, err := ds.session.ChannelMessageSendComplex(channel.ID, message); err != nil {
+			return fmt.Errorf("failed to send ticket stats to user %s: %w", userID, err)
+		}
+	}
+
+	return nil
+}
+
 // SendToChannel sends reports to a specific channel instead of DMs
 func (ds *DiscordSender) SendToChannel(channelID string) error {
 	reportOrder := []string{
@@ -14,6 +14,8 @@ type EnvConfig struct {
 	TableName  string
 	ReportsDir string
 	CronSpec   string
+	// TicketStats adds the ticket statistics of the last week (WEEKLY_UPDATES_TICKET_STATS, default false)
+	TicketStats bool
 }
 
 // LoadEnvConfig loads configuration from environment variables
@@ -26,11 +28,13 @@ func LoadEnvConfig(bot *discordgo.Session) (*EnvConfig, error) {
 	tableName := "survey_answers"	
 	reportsDir := "reports"	
 	cronSpec := utils.GetIdFromDB(bot, "WEEKLY_UPDATES_CRON_SPEC")
+	ticketStats := utils.GetOptionalIdFromDB(bot, "WEEKLY_UPDATES_TICKET_STATS", "false")
 	return &EnvConfig{
-		UserIDs:    userIDs,
-		TableName:  tableName,
-		ReportsDir: reportsDir,
-		CronSpec:   cronSpec,
+		UserIDs:     userIDs,
+		TableName:   tableName,
+		ReportsDir:  reportsDir,
+		CronSpec:    cronSpec,
+		TicketStats: ticketStats == "true" || ticketStats == "1",
 	}, nil
 }
 
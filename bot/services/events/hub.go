@@ -0,0 +1,121 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event-Typen, die über den Stream verteilt werden
+const (
+	TypeVoiceJoin    = "voice.join"
+	TypeVoiceLeave   = "voice.leave"
+	TypeTicketCreate = "ticket.created"
+	TypeTicketStatus = "ticket.status"
+	TypeMemberJoin   = "member.join"
+	TypeMemberLeave  = "member.leave"
+	TypeQuizAnswer   = "quiz.answer"
+)
+
+// Größe des Ringpuffers für Reconnects (Last-Event-ID)
+const bufferSize = 256
+
+// Event ist ein einzelnes Live-Ereignis
+type Event struct {
+	ID   uint64                 `json:"id"`
+	Type string                 `json:"type"`
+	Time time.Time              `json:"time"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// Subscriber empfängt Events über einen gepufferten Channel
+type Subscriber struct {
+	C      chan Event
+	filter map[string]bool
+}
+
+// Hub verteilt Events an alle Subscriber und hält die letzten Events vor
+type Hub struct {
+	mu          sync.RWMutex
+	nextID      uint64
+	buffer      []Event
+	subscribers map[*Subscriber]struct{}
+}
+
+// NewHub erstellt einen leeren Hub
+func NewHub() *Hub {
+	return &Hub{
+		buffer:      make([]Event, 0, bufferSize),
+		subscribers: make(map[*Subscriber]struct{}),
+	}
+}
+
+// Default ist der globale Hub, in den Tracking, Tickets und Quiz publizieren
+var Default = NewHub()
+
+// Publish veröffentlicht ein Event auf dem globalen Hub
+func Publish(eventType string, data map[string]interface{}) {
+	Default.Publish(eventType, data)
+}
+
+// Publish vergibt eine ID, legt das Event im Ringpuffer ab und verteilt es.
+// Langsame Subscriber blockieren den Hub nicht, ihnen gehen Events verloren.
+func (h *Hub) Publish(eventType string, data map[string]interface{}) Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	ev := Event{ID: h.nextID, Type: eventType, Time: time.Now(), Data: data}
+
+	if len(h.buffer) == bufferSize {
+		h.buffer = h.buffer[1:]
+	}
+	h.buffer = append(h.buffer, ev)
+
+	for sub := range h.subscribers {
+		if !sub.accepts(eventType) {
+			continue
+		}
+		select {
+		case sub.C <- ev:
+		default:
+		}
+	}
+	return ev
+}
+
+// Subscribe registriert einen Subscriber für die angegebenen Typen (leer = alle)
+// und liefert alle gepufferten Events nach lastID zurück.
+func (h *Hub) Subscribe(types []string, lastID uint64) (*Subscriber, []Event) {
+	sub := &Subscriber{C: make(chan Event, 64)}
+	if len(types) > 0 {
+		sub.filter = make(map[string]bool, len(types))
+		for _, t := range types {
+			sub.filter[t] = true
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var missed []Event
+	if lastID > 0 {
+		for _, ev := range h.buffer {
+			if ev.ID > lastID && sub.accepts(ev.Type) {
+				missed = append(missed, ev)
+			}
+		}
+	}
+	h.subscribers[sub] = struct{}{}
+	return sub, missed
+}
+
+// Unsubscribe entfernt den Subscriber wieder
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscribers, sub)
+}
+
+func (s *Subscriber) accepts(eventType string) bool {
+	return s.filter == nil || s.filter[eventType]
+}
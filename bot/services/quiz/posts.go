@@ -0,0 +1,119 @@
+package quiz
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Post ist ein gepostetes Tagesquiz mit Antwort-Deadline
+type Post struct {
+	ID         int
+	QuestionID int
+	ChannelID  string
+	MessageID  string
+	PostedAt   int64
+	Deadline   int64
+	Revealed   bool
+}
+
+// Result ist die Auswertung eines Posts für die Auflösung
+type Result struct {
+	Distribution []int // Anzahl Stimmen pro Antwort (Index 0 = Antwort 1)
+	Participants int
+	Correct      int
+}
+
+// CreatePost speichert einen neuen Post
+func (s *QuestionService) CreatePost(questionID int, channelID, messageID string, postedAt time.Time, window time.Duration) (*Post, error) {
+	post := &Post{
+		QuestionID: questionID,
+		ChannelID:  channelID,
+		MessageID:  messageID,
+		PostedAt:   postedAt.Unix(),
+		Deadline:   postedAt.Add(window).Unix(),
+	}
+	res, err := s.db.Exec(`
+		INSERT INTO quiz_posts (question_id, channel_id, message_id, posted_at, deadline)
+		VALUES (?, ?, ?, ?, ?)`,
+		post.QuestionID, post.ChannelID, post.MessageID, post.PostedAt, post.Deadline)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	post.ID = int(id)
+	return post, nil
+}
+
+// LatestPostForQuestion liefert den letzten Post einer Frage
+func (s *QuestionService) LatestPostForQuestion(questionID int) (*Post, error) {
+	post, err := scanPost(s.db.QueryRow(`
+		SELECT id, question_id, channel_id, message_id, posted_at, deadline, revealed
+		FROM quiz_posts WHERE question_id = ? ORDER BY id DESC LIMIT 1`, questionID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return post, err
+}
+
+// UnrevealedPosts liefert alle noch nicht aufgelösten Posts, deren Deadline vor until liegt
+func (s *QuestionService) UnrevealedPosts(until time.Time) ([]Post, error) {
+	rows, err := s.db.Query(`
+		SELECT id, question_id, channel_id, message_id, posted_at, deadline, revealed
+		FROM quiz_posts WHERE revealed = 0 AND deadline <= ? ORDER BY id`, until.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, *post)
+	}
+	return posts, rows.Err()
+}
+
+// MarkRevealed markiert einen Post als aufgelöst
+func (s *QuestionService) MarkRevealed(postID int) error {
+	_, err := s.db.Exec(`UPDATE quiz_posts SET revealed = 1 WHERE id = ?`, postID)
+	return err
+}
+
+// PostResult zählt die Antworten eines Posts
+func (s *QuestionService) PostResult(postID int, answerCount int) (*Result, error) {
+	rows, err := s.db.Query(`SELECT selected, COUNT(*), SUM(correct) FROM quiz_responses WHERE post_id = ? GROUP BY selected`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := &Result{Distribution: make([]int, answerCount)}
+	for rows.Next() {
+		var selected, count, correct int
+		if err := rows.Scan(&selected, &count, &correct); err != nil {
+			return nil, err
+		}
+		if selected >= 1 && selected <= answerCount {
+			result.Distribution[selected-1] = count
+		}
+		result.Participants += count
+		result.Correct += correct
+	}
+	return result, rows.Err()
+}
+
+func scanPost(scanner interface{ Scan(...interface{}) error }) (*Post, error) {
+	var post Post
+	var revealed int
+	if err := scanner.Scan(&post.ID, &post.QuestionID, &post.ChannelID, &post.MessageID, &post.PostedAt, &post.Deadline, &revealed); err != nil {
+		return nil, err
+	}
+	post.Revealed = revealed != 0
+	return &post, nil
+}
@@ -0,0 +1,475 @@
+package quiz
+
+import (
+	"bot/database"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const dateLayout = "2006-01-02"
+
+// Erlaubte Anzahl Antwortmöglichkeiten pro Frage
+const (
+	minAnswers = 2
+	maxAnswers = 5
+)
+
+var (
+	ErrNotFound     = errors.New("Frage nicht gefunden")
+	ErrDuplicate    = errors.New("Frage existiert bereits")
+	ErrDateTaken    = errors.New("Für dieses Datum ist bereits eine Frage geplant")
+	ErrInvalidInput = errors.New("Ungültige Frage")
+)
+
+// QuestionService kapselt die Verwaltung der Quiz-Fragen (Discord Command + API)
+type QuestionService struct {
+	bot *discordgo.Session
+	db  *sql.DB
+}
+
+// Question entspricht einer Zeile in quiz_questions
+type Question struct {
+	ID            int      `json:"id"`
+	ScheduledDate string   `json:"scheduled_date"` // YYYY-MM-DD, leer = ungeplant
+	Question      string   `json:"question"`
+	Answers       []string `json:"answers"`
+	Correct       int      `json:"correct"` // 1-basiert
+	Category      string   `json:"category"`
+	Explanation   string   `json:"explanation"`
+	ImageURL      string   `json:"image_url"`
+	AnswerWindow  int      `json:"answer_window"` // Minuten bis zur Auflösung, 0 = Standard
+	Asked         bool     `json:"asked"`
+}
+
+// ImportIssue beschreibt eine übersprungene Zeile beim Import
+type ImportIssue struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// ImportResult ist das Ergebnis eines Bulk-Imports
+type ImportResult struct {
+	Imported int           `json:"imported"`
+	Skipped  []ImportIssue `json:"skipped"`
+}
+
+// PreviewDay ist ein Tag in der Vorschau des Quiz-Plans
+type PreviewDay struct {
+	Date       string `json:"date"`
+	QuestionID int    `json:"question_id,omitempty"`
+	Question   string `json:"question,omitempty"`
+}
+
+// SchedulePreview zeigt, welche der kommenden Tage mit Fragen abgedeckt sind
+type SchedulePreview struct {
+	Days    []PreviewDay `json:"days"`
+	Covered int          `json:"covered"`
+	Missing []string     `json:"missing"`
+	Pool    int          `json:"pool"` // ungeplante, noch nicht gestellte Fragen für die automatische Planung
+}
+
+func NewQuestionService(bot *discordgo.Session) *QuestionService {
+	return &QuestionService{
+		bot: bot,
+		db:  database.DB,
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// Validate prüft Pflichtfelder, Datum und richtige Antwort
+func (q *Question) Validate() error {
+	q.Question = strings.TrimSpace(q.Question)
+	q.ScheduledDate = strings.TrimSpace(q.ScheduledDate)
+	q.Category = strings.TrimSpace(q.Category)
+	q.Explanation = strings.TrimSpace(q.Explanation)
+	q.ImageURL = strings.TrimSpace(q.ImageURL)
+
+	if q.Question == "" {
+		return fmt.Errorf("%w: Fragetext fehlt", ErrInvalidInput)
+	}
+	// Leere Antworten am Ende ignorieren (z.B. leere CSV-Spalten answer4/answer5)
+	for len(q.Answers) > 0 && strings.TrimSpace(q.Answers[len(q.Answers)-1]) == "" {
+		q.Answers = q.Answers[:len(q.Answers)-1]
+	}
+	if len(q.Answers) < minAnswers || len(q.Answers) > maxAnswers {
+		return fmt.Errorf("%w: %d bis %d Antworten erforderlich", ErrInvalidInput, minAnswers, maxAnswers)
+	}
+	for i := range q.Answers {
+		q.Answers[i] = strings.TrimSpace(q.Answers[i])
+		if q.Answers[i] == "" {
+			return fmt.Errorf("%w: Antwort %d ist leer", ErrInvalidInput, i+1)
+		}
+		// Discord Select-Optionen erlauben maximal 100 Zeichen
+		if len(q.Answers[i]) > 100 {
+			return fmt.Errorf("%w: Antwort %d ist länger als 100 Zeichen", ErrInvalidInput, i+1)
+		}
+	}
+	if q.Correct < 1 || q.Correct > len(q.Answers) {
+		return fmt.Errorf("%w: richtige Antwort muss zwischen 1 und %d liegen", ErrInvalidInput, len(q.Answers))
+	}
+	if q.ScheduledDate != "" {
+		if _, err := time.Parse(dateLayout, q.ScheduledDate); err != nil {
+			return fmt.Errorf("%w: Datum muss im Format YYYY-MM-DD sein", ErrInvalidInput)
+		}
+	}
+	// Embed-Felder erlauben maximal 1024 Zeichen
+	if len(q.Explanation) > 1024 {
+		return fmt.Errorf("%w: Erklärung ist länger als 1024 Zeichen", ErrInvalidInput)
+	}
+	if q.ImageURL != "" && !strings.HasPrefix(q.ImageURL, "https://") && !strings.HasPrefix(q.ImageURL, "http://") {
+		return fmt.Errorf("%w: Bild-URL muss mit http:// oder https:// beginnen", ErrInvalidInput)
+	}
+	if q.AnswerWindow < 0 {
+		return fmt.Errorf("%w: Antwortfenster darf nicht negativ sein", ErrInvalidInput)
+	}
+	return nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+const questionColumns = `id, COALESCE(SUBSTR(scheduled_date, 1, 10), ''), question, answer1, answer2, answer3, COALESCE(answer4, ''), COALESCE(answer5, ''),
+	correct, COALESCE(category, ''), COALESCE(explanation, ''), COALESCE(image_url, ''), COALESCE(answer_window, 0), asked`
+
+func scanQuestion(scanner interface{ Scan(...interface{}) error }) (*Question, error) {
+	var q Question
+	answers := make([]string, maxAnswers)
+	var asked int
+	if err := scanner.Scan(&q.ID, &q.ScheduledDate, &q.Question, &answers[0], &answers[1], &answers[2], &answers[3], &answers[4],
+		&q.Correct, &q.Category, &q.Explanation, &q.ImageURL, &q.AnswerWindow, &asked); err != nil {
+		return nil, err
+	}
+	// SQLite liefert DATE-Spalten je nach Insert als Datum oder Zeitstempel
+	if len(q.ScheduledDate) > len(dateLayout) {
+		q.ScheduledDate = q.ScheduledDate[:len(dateLayout)]
+	}
+	for len(answers) > 0 && answers[len(answers)-1] == "" {
+		answers = answers[:len(answers)-1]
+	}
+	q.Answers = answers
+	q.Asked = asked != 0
+	return &q, nil
+}
+
+// List gibt Fragen sortiert nach Datum zurück, ungeplante Fragen am Ende
+func (s *QuestionService) List(limit, offset int) ([]Question, error) {
+	rows, err := s.db.Query(`
+		SELECT `+questionColumns+`
+		FROM quiz_questions
+		ORDER BY scheduled_date IS NULL, scheduled_date, id
+		LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var questions []Question
+	for rows.Next() {
+		q, err := scanQuestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, *q)
+	}
+	return questions, rows.Err()
+}
+
+// Get liefert eine einzelne Frage
+func (s *QuestionService) Get(id int) (*Question, error) {
+	q, err := scanQuestion(s.db.QueryRow(`SELECT `+questionColumns+` FROM quiz_questions WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return q, err
+}
+
+// GetByDate liefert die für ein Datum geplante Frage. Wie in Preview zählen nur die ersten zehn Zeichen,
+// falls der Treiber die DATE-Spalte als Zeitstempel ablegt.
+func (s *QuestionService) GetByDate(date string) (*Question, error) {
+	q, err := scanQuestion(s.db.QueryRow(`SELECT `+questionColumns+` FROM quiz_questions WHERE SUBSTR(scheduled_date, 1, 10) = ?`, date))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return q, err
+}
+
+// Create validiert und speichert eine neue Frage
+func (s *QuestionService) Create(q *Question) (int, error) {
+	if err := q.Validate(); err != nil {
+		return 0, err
+	}
+	if err := s.checkConflicts(q); err != nil {
+		return 0, err
+	}
+
+	a := answerColumns(q.Answers)
+	res, err := s.db.Exec(`
+		INSERT INTO quiz_questions (scheduled_date, question, answer1, answer2, answer3, answer4, answer5, correct, category, explanation, image_url, answer_window)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		nullIfEmpty(q.ScheduledDate), q.Question, a[0], a[1], a[2], a[3], a[4], q.Correct,
+		nullIfEmpty(q.Category), nullIfEmpty(q.Explanation), nullIfEmpty(q.ImageURL), nullIfZero(q.AnswerWindow))
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	q.ID = int(id)
+	return q.ID, nil
+}
+
+// Update überschreibt eine bestehende Frage
+func (s *QuestionService) Update(q *Question) error {
+	if _, err := s.Get(q.ID); err != nil {
+		return err
+	}
+	if err := q.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkConflicts(q); err != nil {
+		return err
+	}
+
+	a := answerColumns(q.Answers)
+	_, err := s.db.Exec(`
+		UPDATE quiz_questions
+		SET scheduled_date = ?, question = ?, answer1 = ?, answer2 = ?, answer3 = ?, answer4 = ?, answer5 = ?, correct = ?,
+			category = ?, explanation = ?, image_url = ?, answer_window = ?
+		WHERE id = ?`,
+		nullIfEmpty(q.ScheduledDate), q.Question, a[0], a[1], a[2], a[3], a[4], q.Correct,
+		nullIfEmpty(q.Category), nullIfEmpty(q.Explanation), nullIfEmpty(q.ImageURL), nullIfZero(q.AnswerWindow), q.ID)
+	return err
+}
+
+// Delete entfernt eine Frage
+func (s *QuestionService) Delete(id int) error {
+	res, err := s.db.Exec(`DELETE FROM quiz_questions WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// checkConflicts erkennt doppelte Fragetexte und bereits belegte Tage
+func (s *QuestionService) checkConflicts(q *Question) error {
+	var id int
+	err := s.db.QueryRow(`SELECT id FROM quiz_questions WHERE LOWER(TRIM(question)) = LOWER(?) AND id != ?`, q.Question, q.ID).Scan(&id)
+	if err == nil {
+		return fmt.Errorf("%w (ID %d)", ErrDuplicate, id)
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	if q.ScheduledDate == "" {
+		return nil
+	}
+	err = s.db.QueryRow(`SELECT id FROM quiz_questions WHERE SUBSTR(scheduled_date, 1, 10) = ? AND id != ?`, q.ScheduledDate, q.ID).Scan(&id)
+	if err == nil {
+		return fmt.Errorf("%w (ID %d)", ErrDateTaken, id)
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+	return nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// Import speichert mehrere Fragen. Ungültige Zeilen und Duplikate werden übersprungen und gemeldet.
+// firstLine ist die Zeilennummer der ersten Frage (z.B. 2 bei CSV mit Header).
+func (s *QuestionService) Import(questions []Question, firstLine int) ImportResult {
+	result := ImportResult{}
+	seenText := make(map[string]int)
+	seenDate := make(map[string]int)
+
+	for i := range questions {
+		q := questions[i]
+		line := firstLine + i
+		q.ID = 0
+
+		if err := q.Validate(); err != nil {
+			result.Skipped = append(result.Skipped, ImportIssue{Line: line, Reason: err.Error()})
+			continue
+		}
+		key := strings.ToLower(q.Question)
+		if prev, ok := seenText[key]; ok {
+			result.Skipped = append(result.Skipped, ImportIssue{Line: line, Reason: fmt.Sprintf("Duplikat von Zeile %d", prev)})
+			continue
+		}
+		if prev, ok := seenDate[q.ScheduledDate]; ok && q.ScheduledDate != "" {
+			result.Skipped = append(result.Skipped, ImportIssue{Line: line, Reason: fmt.Sprintf("Datum bereits in Zeile %d verwendet", prev)})
+			continue
+		}
+
+		if _, err := s.Create(&q); err != nil {
+			result.Skipped = append(result.Skipped, ImportIssue{Line: line, Reason: err.Error()})
+			continue
+		}
+		seenText[key] = line
+		if q.ScheduledDate != "" {
+			seenDate[q.ScheduledDate] = line
+		}
+		result.Imported++
+	}
+	return result
+}
+
+// ParseCSV liest Fragen aus einer CSV mit Header.
+// Pflichtspalten: question, answer1, answer2, correct
+// Optional: scheduled_date, answer3, answer4, answer5, category, explanation, image_url, answer_window (Trennzeichen , oder ;)
+func ParseCSV(r io.Reader) ([]Question, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(raw), "\ufeff")
+
+	reader := csv.NewReader(strings.NewReader(text))
+	firstLine := strings.SplitN(text, "\n", 2)[0]
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV ist leer")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"question", "answer1", "answer2", "correct"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("Spalte %q fehlt im CSV-Header", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		idx, ok := columns[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return record[idx]
+	}
+
+	questions := make([]Question, 0, len(records)-1)
+	for _, record := range records[1:] {
+		// Ungültige Werte für correct bleiben 0 und werden beim Import gemeldet
+		correct, _ := strconv.Atoi(strings.TrimSpace(field(record, "correct")))
+		window, _ := strconv.Atoi(strings.TrimSpace(field(record, "answer_window")))
+		questions = append(questions, Question{
+			ScheduledDate: field(record, "scheduled_date"),
+			Question:      field(record, "question"),
+			Answers: []string{
+				field(record, "answer1"), field(record, "answer2"), field(record, "answer3"),
+				field(record, "answer4"), field(record, "answer5"),
+			},
+			Correct:      correct,
+			Category:     field(record, "category"),
+			Explanation:  field(record, "explanation"),
+			ImageURL:     field(record, "image_url"),
+			AnswerWindow: window,
+		})
+	}
+	return questions, nil
+}
+
+// ParseJSON liest Fragen aus einem JSON-Array im Format von Question
+func ParseJSON(r io.Reader) ([]Question, error) {
+	var questions []Question
+	if err := json.NewDecoder(r).Decode(&questions); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// Preview zeigt die kommenden Tage ab heute und welche davon noch keine Frage haben
+func (s *QuestionService) Preview(days int) (*SchedulePreview, error) {
+	start := time.Now()
+	end := start.AddDate(0, 0, days-1)
+
+	rows, err := s.db.Query(`
+		SELECT id, SUBSTR(scheduled_date, 1, 10), question
+		FROM quiz_questions
+		WHERE SUBSTR(scheduled_date, 1, 10) BETWEEN ? AND ?`,
+		start.Format(dateLayout), end.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scheduled := make(map[string]PreviewDay)
+	for rows.Next() {
+		var day PreviewDay
+		if err := rows.Scan(&day.QuestionID, &day.Date, &day.Question); err != nil {
+			return nil, err
+		}
+		scheduled[day.Date] = day
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pool, err := s.PoolSize()
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &SchedulePreview{Pool: pool}
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i).Format(dateLayout)
+		if day, ok := scheduled[date]; ok {
+			preview.Days = append(preview.Days, day)
+			preview.Covered++
+		} else {
+			preview.Days = append(preview.Days, PreviewDay{Date: date})
+			preview.Missing = append(preview.Missing, date)
+		}
+	}
+	return preview, nil
+}
+
+// answerColumns verteilt die Antworten auf answer1..answer5 (answer3 ist NOT NULL)
+func answerColumns(answers []string) []interface{} {
+	columns := []interface{}{"", "", "", nil, nil}
+	for i, answer := range answers {
+		columns[i] = answer
+	}
+	return columns
+}
+
+func nullIfZero(value int) interface{} {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
+
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
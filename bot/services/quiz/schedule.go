@@ -0,0 +1,111 @@
+package quiz
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// PickOptions steuert die automatische Auswahl aus dem Fragen-Pool
+type PickOptions struct {
+	Auto     bool     // Pool verwenden, wenn für den Tag keine Frage geplant ist
+	Rotation []string // Reihenfolge der Kategorien, leer = nur keine Kategorie zweimal hintereinander
+}
+
+// PickForDate liefert die Frage für ein Datum.
+// Geplante Fragen haben Vorrang, danach wird (falls aktiviert) aus dem Pool ungestellter Fragen gewählt
+// und als letzte Möglichkeit die am längsten nicht mehr gestellte Frage wiederverwendet.
+func (s *QuestionService) PickForDate(date string, opts PickOptions) (*Question, error) {
+	q, err := s.GetByDate(date)
+	if err != ErrNotFound || !opts.Auto {
+		return q, err
+	}
+
+	lastCategory, err := s.lastAskedCategory()
+	if err != nil {
+		return nil, err
+	}
+	categories := categoryOrder(lastCategory, opts.Rotation)
+
+	// 1) Pool: ungeplante, noch nie gestellte Fragen (älteste zuerst)
+	q, err = s.pickByCategory(`scheduled_date IS NULL AND asked = 0`, `id`, categories, lastCategory)
+	if err != ErrNotFound {
+		return q, err
+	}
+
+	// 2) Pool leer: am längsten nicht gestellte Frage wiederverwenden
+	return s.pickByCategory(`asked = 1 AND (scheduled_date IS NULL OR SUBSTR(scheduled_date, 1, 10) < ?)`, `asked_at IS NOT NULL, asked_at, id`, categories, lastCategory, date)
+}
+
+// MarkAsked markiert eine Frage als gestellt
+func (s *QuestionService) MarkAsked(id int) error {
+	_, err := s.db.Exec(`UPDATE quiz_questions SET asked = 1, asked_at = CURRENT_TIMESTAMP WHERE id = ?`, id)
+	return err
+}
+
+// PoolSize zählt die ungeplanten, noch nicht gestellten Fragen
+func (s *QuestionService) PoolSize() (int, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM quiz_questions WHERE scheduled_date IS NULL AND asked = 0`).Scan(&count)
+	return count, err
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// pickByCategory probiert die Kategorien der Reihe nach und weicht zuletzt auf
+// beliebige Kategorien außer der zuletzt gestellten und danach auf alle aus
+func (s *QuestionService) pickByCategory(where, orderBy string, categories []string, lastCategory string, args ...interface{}) (*Question, error) {
+	for _, category := range categories {
+		q, err := s.pickOne(where+` AND LOWER(COALESCE(category, '')) = LOWER(?)`, orderBy, append(args, category)...)
+		if err != ErrNotFound {
+			return q, err
+		}
+	}
+
+	q, err := s.pickOne(where+` AND LOWER(COALESCE(category, '')) != LOWER(?)`, orderBy, append(args, lastCategory)...)
+	if err != ErrNotFound {
+		return q, err
+	}
+	return s.pickOne(where, orderBy, args...)
+}
+
+func (s *QuestionService) pickOne(where, orderBy string, args ...interface{}) (*Question, error) {
+	q, err := scanQuestion(s.db.QueryRow(`SELECT `+questionColumns+` FROM quiz_questions WHERE `+where+` ORDER BY `+orderBy+` LIMIT 1`, args...))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return q, err
+}
+
+func (s *QuestionService) lastAskedCategory() (string, error) {
+	var category string
+	err := s.db.QueryRow(`SELECT COALESCE(category, '') FROM quiz_questions WHERE asked = 1 AND asked_at IS NOT NULL ORDER BY asked_at DESC LIMIT 1`).Scan(&category)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return category, err
+}
+
+// categoryOrder liefert die Kategorien der Rotation beginnend nach der zuletzt gestellten.
+// Die zuletzt gestellte Kategorie selbst wird nie vorgeschlagen.
+func categoryOrder(lastCategory string, rotation []string) []string {
+	if len(rotation) == 0 {
+		return nil
+	}
+
+	start := 0
+	for i, category := range rotation {
+		if strings.EqualFold(category, lastCategory) {
+			start = i + 1
+			break
+		}
+	}
+
+	var order []string
+	for i := 0; i < len(rotation); i++ {
+		category := rotation[(start+i)%len(rotation)]
+		if !strings.EqualFold(category, lastCategory) {
+			order = append(order, category)
+		}
+	}
+	return order
+}
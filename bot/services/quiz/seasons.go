@@ -0,0 +1,305 @@
+package quiz
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrSeasonOverlap = errors.New("Zeitraum überschneidet sich mit einer bestehenden Saison")
+
+// Season ist ein Wertungszeitraum mit eigenem Leaderboard
+type Season struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"` // YYYY-MM-DD, inklusive
+	EndDate   string `json:"end_date"`   // YYYY-MM-DD, inklusive
+	Finalized bool   `json:"finalized"`
+}
+
+// Champion ist ein Platz in der Endwertung einer Saison
+type Champion struct {
+	DiscordID string
+	Rank      int
+	Score     float64
+}
+
+// Streaks enthält aktuelle und beste Serien eines Users
+type Streaks struct {
+	CurrentParticipation int
+	BestParticipation    int
+	CurrentCorrect       int
+	BestCorrect          int
+}
+
+var germanMonths = []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"}
+
+const seasonColumns = `id, name, start_date, end_date, finalized`
+
+func scanSeason(scanner interface{ Scan(...interface{}) error }) (*Season, error) {
+	var season Season
+	var finalized int
+	if err := scanner.Scan(&season.ID, &season.Name, &season.StartDate, &season.EndDate, &finalized); err != nil {
+		return nil, err
+	}
+	season.Finalized = finalized != 0
+	return &season, nil
+}
+
+// CreateSeason legt eine Saison an, Zeiträume dürfen sich nicht überschneiden
+func (s *QuestionService) CreateSeason(name, startDate, endDate string) (*Season, error) {
+	name = strings.TrimSpace(name)
+	start, err := time.Parse(dateLayout, strings.TrimSpace(startDate))
+	if err != nil {
+		return nil, fmt.Errorf("%w: Startdatum muss im Format YYYY-MM-DD sein", ErrInvalidInput)
+	}
+	end, err := time.Parse(dateLayout, strings.TrimSpace(endDate))
+	if err != nil {
+		return nil, fmt.Errorf("%w: Enddatum muss im Format YYYY-MM-DD sein", ErrInvalidInput)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: Enddatum liegt vor dem Startdatum", ErrInvalidInput)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: Name fehlt", ErrInvalidInput)
+	}
+
+	var id int
+	err = s.db.QueryRow(`SELECT id FROM quiz_seasons WHERE start_date <= ? AND end_date >= ? LIMIT 1`,
+		end.Format(dateLayout), start.Format(dateLayout)).Scan(&id)
+	if err == nil {
+		return nil, fmt.Errorf("%w (ID %d)", ErrSeasonOverlap, id)
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	season := &Season{Name: name, StartDate: start.Format(dateLayout), EndDate: end.Format(dateLayout)}
+	res, err := s.db.Exec(`INSERT INTO quiz_seasons (name, start_date, end_date) VALUES (?, ?, ?)`, season.Name, season.StartDate, season.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	season.ID = int(lastID)
+	return season, nil
+}
+
+// EnsureMonthlySeason legt für den Monat von date eine Saison an, falls date in keiner Saison liegt
+func (s *QuestionService) EnsureMonthlySeason(date time.Time) (*Season, error) {
+	season, err := s.SeasonForDate(date)
+	if err != ErrNotFound {
+		return season, err
+	}
+
+	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	end := start.AddDate(0, 1, -1)
+
+	// Eine Custom-Saison kann später im Monat beginnen, dann endet die Monats-Saison vorher
+	var nextStart string
+	err = s.db.QueryRow(`SELECT MIN(start_date) FROM quiz_seasons WHERE start_date > ? AND start_date <= ?`,
+		date.Format(dateLayout), end.Format(dateLayout)).Scan(&nextStart)
+	if err == nil && nextStart != "" {
+		if next, parseErr := time.Parse(dateLayout, nextStart); parseErr == nil {
+			end = next.AddDate(0, 0, -1)
+		}
+	}
+	// Ebenso kann eine Custom-Saison im Monat bereits geendet haben
+	var prevEnd string
+	err = s.db.QueryRow(`SELECT MAX(end_date) FROM quiz_seasons WHERE end_date >= ? AND end_date < ?`,
+		start.Format(dateLayout), date.Format(dateLayout)).Scan(&prevEnd)
+	if err == nil && prevEnd != "" {
+		if prev, parseErr := time.Parse(dateLayout, prevEnd); parseErr == nil {
+			start = prev.AddDate(0, 0, 1)
+		}
+	}
+
+	name := fmt.Sprintf("Saison %s %d", germanMonths[date.Month()-1], date.Year())
+	return s.CreateSeason(name, start.Format(dateLayout), end.Format(dateLayout))
+}
+
+// SeasonForDate liefert die Saison, in der date liegt
+func (s *QuestionService) SeasonForDate(date time.Time) (*Season, error) {
+	day := date.Format(dateLayout)
+	season, err := scanSeason(s.db.QueryRow(`SELECT `+seasonColumns+` FROM quiz_seasons WHERE start_date <= ? AND end_date >= ? LIMIT 1`, day, day))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return season, err
+}
+
+// GetSeason liefert eine Saison per ID
+func (s *QuestionService) GetSeason(id int) (*Season, error) {
+	season, err := scanSeason(s.db.QueryRow(`SELECT `+seasonColumns+` FROM quiz_seasons WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return season, err
+}
+
+// ListSeasons liefert die Saisons, neueste zuerst
+func (s *QuestionService) ListSeasons(limit int) ([]Season, error) {
+	rows, err := s.db.Query(`SELECT `+seasonColumns+` FROM quiz_seasons ORDER BY start_date DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seasons []Season
+	for rows.Next() {
+		season, err := scanSeason(rows)
+		if err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, *season)
+	}
+	return seasons, rows.Err()
+}
+
+// EndedSeasons liefert beendete, noch nicht abgeschlossene Saisons
+func (s *QuestionService) EndedSeasons(today time.Time) ([]Season, error) {
+	rows, err := s.db.Query(`SELECT `+seasonColumns+` FROM quiz_seasons WHERE finalized = 0 AND end_date < ? ORDER BY end_date`, today.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seasons []Season
+	for rows.Next() {
+		season, err := scanSeason(rows)
+		if err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, *season)
+	}
+	return seasons, rows.Err()
+}
+
+// FinalizeSeason speichert die Champions und schließt die Saison ab
+func (s *QuestionService) FinalizeSeason(seasonID int, champions []Champion) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, champion := range champions {
+		_, err := tx.Exec(`INSERT OR REPLACE INTO quiz_season_champions (season_id, discord_id, rank, score) VALUES (?, ?, ?, ?)`,
+			seasonID, champion.DiscordID, champion.Rank, champion.Score)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE quiz_seasons SET finalized = 1 WHERE id = ?`, seasonID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PreviousChampions liefert die Champions der zuletzt abgeschlossenen Saison vor seasonID
+func (s *QuestionService) PreviousChampions(seasonID int) ([]Champion, error) {
+	rows, err := s.db.Query(`
+		SELECT discord_id, rank, score FROM quiz_season_champions
+		WHERE season_id = (
+			SELECT id FROM quiz_seasons
+			WHERE finalized = 1 AND id != ? AND end_date <= (SELECT end_date FROM quiz_seasons WHERE id = ?)
+			ORDER BY end_date DESC LIMIT 1
+		)
+		ORDER BY rank`, seasonID, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var champions []Champion
+	for rows.Next() {
+		var champion Champion
+		if err := rows.Scan(&champion.DiscordID, &champion.Rank, &champion.Score); err != nil {
+			return nil, err
+		}
+		champions = append(champions, champion)
+	}
+	return champions, rows.Err()
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// UserStreaks berechnet Teilnahme- und Richtig-Serien eines Users über alle Quiz-Tage.
+// Ein Quiz-Tag ist jeder Tag mit einem Post oder (vor Einführung der Posts) mindestens einer Antwort.
+func (s *QuestionService) UserStreaks(userID int) (*Streaks, error) {
+	rows, err := s.db.Query(`
+		WITH quiz_days AS (
+			SELECT DISTINCT date(posted_at, 'unixepoch', 'localtime') AS day FROM quiz_posts
+			UNION
+			SELECT DISTINCT date(answered_at, 'localtime') FROM quiz_responses WHERE post_id IS NULL
+		),
+		user_days AS (
+			SELECT COALESCE(date(p.posted_at, 'unixepoch', 'localtime'), date(r.answered_at, 'localtime')) AS day, MAX(r.correct) AS correct
+			FROM quiz_responses r
+			LEFT JOIN quiz_posts p ON p.id = r.post_id
+			WHERE r.user_id = ?
+			GROUP BY day
+		)
+		SELECT d.day, u.day IS NOT NULL, COALESCE(u.correct, 0)
+		FROM quiz_days d
+		LEFT JOIN user_days u ON u.day = d.day
+		WHERE d.day IS NOT NULL
+		ORDER BY d.day`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type quizDay struct {
+		day               string
+		answered, correct bool
+	}
+	var days []quizDay
+	for rows.Next() {
+		var d quizDay
+		if err := rows.Scan(&d.day, &d.answered, &d.correct); err != nil {
+			return nil, err
+		}
+		days = append(days, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	streaks := &Streaks{}
+	participation, correct := 0, 0
+	for _, d := range days {
+		if d.answered {
+			participation++
+		} else {
+			participation = 0
+		}
+		if d.answered && d.correct {
+			correct++
+		} else {
+			correct = 0
+		}
+		streaks.BestParticipation = max(streaks.BestParticipation, participation)
+		streaks.BestCorrect = max(streaks.BestCorrect, correct)
+	}
+	streaks.CurrentParticipation = participation
+	streaks.CurrentCorrect = correct
+
+	// Das heutige Quiz bricht die Serie nicht, solange der User noch nicht geantwortet hat
+	if n := len(days); n > 1 && days[n-1].day == time.Now().Format(dateLayout) && !days[n-1].answered {
+		participation, correct = 0, 0
+		for i := n - 2; i >= 0 && days[i].answered; i-- {
+			participation++
+		}
+		for i := n - 2; i >= 0 && days[i].answered && days[i].correct; i-- {
+			correct++
+		}
+		streaks.CurrentParticipation = participation
+		streaks.CurrentCorrect = correct
+	}
+	return streaks, nil
+}
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRejected wird geliefert, wenn eine Datei gegen die Richtlinie verstößt (Größe, Typ)
+var ErrRejected = errors.New("Datei abgelehnt")
+
+// Policy begrenzt Downloads für Transkripte
+type Policy struct {
+	MaxSize      int64         // Maximalgröße pro Datei in Bytes
+	AllowedTypes []string      // erlaubte MIME-Typen bzw. Präfixe wie "image/", leer = alle
+	Concurrency  int           // gleichzeitige Downloads
+	Timeout      time.Duration // pro Download
+}
+
+// DefaultPolicy wird genutzt, wenn in der Datenbank nichts konfiguriert ist
+var DefaultPolicy = Policy{
+	MaxSize:      25 * 1024 * 1024,
+	AllowedTypes: []string{"image/", "video/", "audio/", "text/plain", "application/pdf"},
+	Concurrency:  4,
+	Timeout:      60 * time.Second,
+}
+
+// FetchRequest ist eine herunterzuladende Datei, Filename dient nur der Dateiendung
+type FetchRequest struct {
+	URL      string
+	Filename string
+}
+
+// FetchResult enthält den inhaltsadressierten Schlüssel oder den Fehler
+type FetchResult struct {
+	Key         string
+	Hash        string
+	Size        int64
+	ContentType string
+	Err         error
+}
+
+var extensionPattern = regexp.MustCompile(`^\.[a-z0-9]{1,8}$`)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// Fetcher lädt Dateien herunter und legt sie unter ihrem SHA-256 ab, gleiche Dateien werden nur einmal gespeichert
+type Fetcher struct {
+	store  Storage
+	policy Policy
+	client *http.Client
+	prefix string
+}
+
+func NewFetcher(store Storage, policy Policy, prefix string) *Fetcher {
+	if policy.Concurrency < 1 {
+		policy.Concurrency = 1
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = DefaultPolicy.Timeout
+	}
+	return &Fetcher{
+		store:  store,
+		policy: policy,
+		client: &http.Client{Timeout: policy.Timeout},
+		prefix: strings.Trim(prefix, "/"),
+	}
+}
+
+// FetchAll lädt alle Dateien mit begrenzter Parallelität, die Ergebnisse haben die Reihenfolge der Anfragen
+func (f *Fetcher) FetchAll(ctx context.Context, requests []FetchRequest) []FetchResult {
+	results := make([]FetchResult, len(requests))
+	slots := make(chan struct{}, f.policy.Concurrency)
+	var wg sync.WaitGroup
+	for index, request := range requests {
+		wg.Add(1)
+		go func(index int, request FetchRequest) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				results[index] = FetchResult{Err: ctx.Err()}
+				return
+			}
+			results[index] = f.Fetch(ctx, request)
+		}(index, request)
+	}
+	wg.Wait()
+	return results
+}
+
+// Fetch lädt eine Datei in eine temporäre Datei, prüft Größe und Typ und speichert sie unter ihrem Hash
+func (f *Fetcher) Fetch(ctx context.Context, request FetchRequest) FetchResult {
+	if !strings.HasPrefix(request.URL, "https://") && !strings.HasPrefix(request.URL, "http://") {
+		return FetchResult{Err: fmt.Errorf("%w: keine http(s)-URL", ErrRejected)}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, request.URL, nil)
+	if err != nil {
+		return FetchResult{Err: err}
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return FetchResult{Err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return FetchResult{Err: fmt.Errorf("Download fehlgeschlagen: %s", resp.Status)}
+	}
+	if f.policy.MaxSize > 0 && resp.ContentLength > f.policy.MaxSize {
+		return FetchResult{Err: fmt.Errorf("%w: %d Bytes überschreiten das Limit von %d Bytes", ErrRejected, resp.ContentLength, f.policy.MaxSize)}
+	}
+
+	tmp, err := os.CreateTemp("", "transcript-download-*")
+	if err != nil {
+		return FetchResult{Err: err}
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	// Content-Length kann fehlen oder falsch sein, daher zusätzlich beim Lesen begrenzen
+	reader := io.Reader(resp.Body)
+	if f.policy.MaxSize > 0 {
+		reader = io.LimitReader(resp.Body, f.policy.MaxSize+1)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), reader)
+	if err != nil {
+		return FetchResult{Err: err}
+	}
+	if f.policy.MaxSize > 0 && size > f.policy.MaxSize {
+		return FetchResult{Err: fmt.Errorf("%w: Datei überschreitet das Limit von %d Bytes", ErrRejected, f.policy.MaxSize)}
+	}
+
+	contentType, err := sniffContentType(tmp, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return FetchResult{Err: err}
+	}
+	if !f.allowed(contentType) {
+		return FetchResult{Err: fmt.Errorf("%w: Dateityp %s ist nicht erlaubt", ErrRejected, contentType)}
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	// Auch vorhandene Dateien werden erneut geschrieben: der Inhalt ist durch den Hash gleich, aber die Änderungszeit
+	// wird aufgefrischt, damit die Aufbewahrung (ApplyRetention) sie nicht löscht, während das neue Transkript entsteht
+	result := FetchResult{Key: f.key(sum, request.Filename, contentType), Hash: sum, Size: size, ContentType: contentType}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return FetchResult{Err: err}
+	}
+	if err := f.store.Put(ctx, result.Key, tmp, contentType); err != nil {
+		return FetchResult{Err: err}
+	}
+	return result
+}
+
+func (f *Fetcher) allowed(contentType string) bool {
+	if len(f.policy.AllowedTypes) == 0 {
+		return true
+	}
+	for _, allowed := range f.policy.AllowedTypes {
+		if strings.HasSuffix(allowed, "/") && strings.HasPrefix(contentType, allowed) || contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// key bildet "<prefix>/ab/abcdef....png", die Endung kommt nur aus bereinigten Dateinamen bzw. dem Typ
+func (f *Fetcher) key(sum, filename, contentType string) string {
+	extension := strings.ToLower(path.Ext(path.Base(strings.ReplaceAll(filename, "\\", "/"))))
+	if !extensionPattern.MatchString(extension) {
+		extension = ""
+		if extensions, _ := mime.ExtensionsByType(contentType); len(extensions) > 0 {
+			extension = extensions[0]
+		}
+	}
+	key := sum[:2] + "/" + sum + extension
+	if f.prefix != "" {
+		key = f.prefix + "/" + key
+	}
+	return key
+}
+
+// sniffContentType bestimmt den Typ anhand des Inhalts, der Header dient nur als Fallback
+func sniffContentType(file *os.File, header string) (string, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	buffer := make([]byte, 512)
+	n, err := io.ReadFull(file, buffer)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	contentType := http.DetectContentType(buffer[:n])
+	if contentType == "application/octet-stream" && header != "" {
+		contentType = header
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	return contentType, nil
+}
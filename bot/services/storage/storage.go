@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound wird geliefert, wenn ein Objekt nicht existiert
+var ErrNotFound = errors.New("Objekt nicht gefunden")
+
+// ErrInvalidKey wird bei leeren oder ungültigen Schlüsseln geliefert (z.B. "../")
+var ErrInvalidKey = errors.New("ungültiger Objekt-Schlüssel")
+
+// Object beschreibt ein gespeichertes Objekt
+type Object struct {
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Storage ist ein Objektspeicher für Transkripte und Anhänge.
+// Schlüssel sind relative Pfade mit "/" als Trenner, z.B. "attachments/ab/abcdef.png".
+type Storage interface {
+	Put(ctx context.Context, key string, body io.ReadSeeker, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// Config wählt und konfiguriert das Backend (siehe ConfigFromEnv)
+type Config struct {
+	Backend   string // "local" (Standard) oder "s3"
+	LocalPath string
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool // für MinIO & Co., Bucket im Pfad statt in der Subdomain
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// ConfigFromEnv liest die Speicher-Konfiguration aus der .env, Zugangsdaten gehören nicht in die Datenbank
+func ConfigFromEnv() Config {
+	config := Config{
+		Backend:     strings.ToLower(os.Getenv("STORAGE_BACKEND")),
+		LocalPath:   os.Getenv("STORAGE_LOCAL_PATH"),
+		S3Endpoint:  os.Getenv("S3_ENDPOINT"),
+		S3Region:    os.Getenv("S3_REGION"),
+		S3Bucket:    os.Getenv("S3_BUCKET"),
+		S3AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey: os.Getenv("S3_SECRET_KEY"),
+		S3PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+	}
+	if config.Backend == "" {
+		config.Backend = "local"
+	}
+	if config.LocalPath == "" {
+		// Bisheriger Ablageort, die Web-App liest die JSON-Transkripte von dort
+		config.LocalPath = "./transcripts"
+	}
+	if config.S3Region == "" {
+		config.S3Region = "us-east-1"
+	}
+	return config
+}
+
+// New erstellt das konfigurierte Backend
+func New(config Config) (Storage, error) {
+	switch config.Backend {
+	case "local":
+		return NewLocalStorage(config.LocalPath)
+	case "s3":
+		return NewS3Storage(config)
+	default:
+		return nil, fmt.Errorf("unbekanntes Storage-Backend %q", config.Backend)
+	}
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultStorage Storage
+	defaultErr     error
+)
+
+// Default liefert das über die .env konfigurierte Backend (einmalig erstellt)
+func Default() (Storage, error) {
+	defaultOnce.Do(func() {
+		defaultStorage, defaultErr = New(ConfigFromEnv())
+	})
+	return defaultStorage, defaultErr
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// CleanKey normalisiert einen Schlüssel und verhindert Pfade außerhalb des Speichers
+func CleanKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, "\\", "/")
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	for _, r := range key {
+		if r < 0x20 || r == 0x7f {
+			return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return path.Clean(key), nil
+}
@@ -0,0 +1,722 @@
+package tickets
+
+import (
+	"bot/database"
+	"bot/utils"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Discord erlaubt maximal 5 Eingabefelder pro Modal und 25 Optionen pro Dropdown.
+// Längere Formulare werden auf mehrere Modals (Schritte) verteilt, maximal 25 Felder (Embed-Limit).
+const (
+	MaxModalFields     = 5
+	MaxFormFields      = 25
+	MaxDropdownOptions = 25
+)
+
+const defaultNamePattern = "{id}-open-{user}"
+
+var (
+	ErrNotFound     = errors.New("Eintrag nicht gefunden")
+	ErrDuplicate    = errors.New("Eintrag existiert bereits")
+	ErrInvalidInput = errors.New("Ungültige Eingabe")
+)
+
+var (
+	areaKeyPattern  = regexp.MustCompile(`^[a-z0-9_]{3,80}$`)
+	fieldKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,40}$`)
+)
+
+// AreaService kapselt die Konfiguration der Ticket-Bereiche und Panels (Discord Command + API)
+type AreaService struct {
+	bot *discordgo.Session
+	db  *sql.DB
+}
+
+// Area entspricht einer Zeile in ticket_areas inkl. Formularfeldern
+type Area struct {
+	ID          int     `json:"id"`
+	Key         string  `json:"key"`         // wird als Dropdown-Value und Modal-CustomID verwendet
+	ParentKey   string  `json:"parent_key"`  // leer = Hauptbereich
+	Label       string  `json:"label"`       // Text im Dropdown
+	Description string  `json:"description"` // Beschreibung im Dropdown
+	DisplayName string  `json:"display_name"`
+	ModalTitle  string  `json:"modal_title"`
+	SubPrompt   string  `json:"sub_prompt"`   // Text über dem Sub-Dropdown
+	SupportRole string  `json:"support_role"` // Discord-ID oder const_key
+	MentionUser bool    `json:"mention_user"` // support_role ist ein User statt einer Rolle
+	Category    string  `json:"category"`     // Discord-ID oder const_key
+	NamePattern string  `json:"name_pattern"`
+	SortOrder   int     `json:"sort_order"`
+	Enabled     bool    `json:"enabled"`
+	Fields      []Field `json:"fields"`
+
+	// SLA-Ziele, 0 = vom Oberbereich übernehmen bzw. kein Ziel
+	SLAClaimMinutes int `json:"sla_claim_minutes"` // Zeit bis zur Übernahme
+	SLACloseHours   int `json:"sla_close_hours"`   // Zeit bis zum Schließen
+	SLAReplyHours   int `json:"sla_reply_hours"`   // Erinnerung an den Bearbeiter bei unbeantworteter Nachricht
+
+	// Inaktivität, InactivityWarnDays = 0 übernimmt die Regel des Oberbereichs bzw. schaltet sie ab
+	InactivityWarnDays  int  `json:"inactivity_warn_days"`  // Warnung nach X Tagen ohne Nachricht des Erstellers
+	InactivityCloseDays int  `json:"inactivity_close_days"` // Schließen Y Tage nach der Warnung
+	InactivityDelete    bool `json:"inactivity_delete"`     // nach dem Schließen Transkript erstellen und löschen
+
+	MaxOpenTickets int `json:"max_open_tickets"` // offene Tickets pro User, 0 = Oberbereich bzw. TICKET_MAX_OPEN_PER_AREA
+
+	// Automatische Zuweisung neuer Tickets, AssignMode leer = Regel des Oberbereichs
+	AssignMode  AssignMode `json:"assign_mode"`  // off | round_robin | least_load
+	AssignRole  string     `json:"assign_role"`  // Discord-ID oder const_key, leer = Support-Rolle
+	AssignUsers []string   `json:"assign_users"` // feste Liste, hat Vorrang vor der Rolle
+}
+
+// Field ist ein Eingabefeld im Formular eines Bereichs
+type Field struct {
+	Position     int    `json:"position"`
+	Key          string `json:"key"` // Schlüssel in ticket_answers, Standard aus dem Label
+	Label        string `json:"label"`
+	Style        string `json:"style"` // short | paragraph
+	Required     bool   `json:"required"`
+	MaxLength    int    `json:"max_length"`
+	Placeholder  string `json:"placeholder"`
+	Validation   string `json:"validation"` // leer | number | url | regex
+	MinValue     *int   `json:"min_value"`  // Zahlenbereich (z.B. Alter)
+	MaxValue     *int   `json:"max_value"`
+	Pattern      string `json:"pattern"` // regulärer Ausdruck für validation = regex
+	ErrorMessage string `json:"error_message"`
+}
+
+// Panel ist eine von /ticket_view gepostete Auswahl an Bereichen
+type Panel struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	AreaKeys    []string `json:"area_keys"` // leer = alle Hauptbereiche
+}
+
+func NewAreaService(bot *discordgo.Session) *AreaService {
+	return &AreaService{
+		bot: bot,
+		db:  database.DB,
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// Validate prüft Pflichtfelder, Schlüssel, Namensmuster und Formularfelder
+func (a *Area) Validate() error {
+	a.Key = strings.TrimSpace(a.Key)
+	a.ParentKey = strings.TrimSpace(a.ParentKey)
+	a.Label = strings.TrimSpace(a.Label)
+	a.DisplayName = strings.TrimSpace(a.DisplayName)
+	a.ModalTitle = strings.TrimSpace(a.ModalTitle)
+	a.NamePattern = strings.TrimSpace(a.NamePattern)
+
+	if !areaKeyPattern.MatchString(a.Key) {
+		return fmt.Errorf("%w: Schlüssel darf nur a-z, 0-9 und _ enthalten (3-80 Zeichen)", ErrInvalidInput)
+	}
+	if a.ParentKey == a.Key {
+		return fmt.Errorf("%w: Bereich kann nicht sein eigener Oberbereich sein", ErrInvalidInput)
+	}
+	if a.Label == "" || len(a.Label) > 100 {
+		return fmt.Errorf("%w: Label muss 1-100 Zeichen lang sein", ErrInvalidInput)
+	}
+	if len(a.Description) > 100 {
+		return fmt.Errorf("%w: Beschreibung ist länger als 100 Zeichen", ErrInvalidInput)
+	}
+	if a.DisplayName == "" {
+		a.DisplayName = a.Label
+	}
+	if a.ModalTitle == "" {
+		a.ModalTitle = a.DisplayName
+	}
+	if len(a.ModalTitle) > 45 {
+		return fmt.Errorf("%w: Modal-Titel ist länger als 45 Zeichen", ErrInvalidInput)
+	}
+	if a.NamePattern == "" {
+		a.NamePattern = defaultNamePattern
+	}
+	// Die Zuordnung läuft über ticket_channel_id, die ID im Namen hält die Channels aber eindeutig
+	if !strings.Contains(a.NamePattern, "{id}") {
+		return fmt.Errorf("%w: Namensmuster muss {id} enthalten", ErrInvalidInput)
+	}
+	if a.SLAClaimMinutes < 0 || a.SLACloseHours < 0 || a.SLAReplyHours < 0 {
+		return fmt.Errorf("%w: SLA-Ziele dürfen nicht negativ sein", ErrInvalidInput)
+	}
+	if a.InactivityWarnDays < 0 || a.InactivityCloseDays < 0 {
+		return fmt.Errorf("%w: Inaktivitäts-Fristen dürfen nicht negativ sein", ErrInvalidInput)
+	}
+	if a.MaxOpenTickets < 0 {
+		return fmt.Errorf("%w: Limit offener Tickets darf nicht negativ sein", ErrInvalidInput)
+	}
+	if err := a.validateAssign(); err != nil {
+		return err
+	}
+	if a.InactivityWarnDays > 0 && a.InactivityCloseDays == 0 {
+		return fmt.Errorf("%w: Zur Inaktivitäts-Warnung gehört eine Frist bis zum Schließen", ErrInvalidInput)
+	}
+	if len(a.Fields) > MaxFormFields {
+		return fmt.Errorf("%w: maximal %d Felder pro Formular", ErrInvalidInput, MaxFormFields)
+	}
+	keys := make(map[string]bool)
+	for i := range a.Fields {
+		a.Fields[i].Position = i + 1
+		if err := a.Fields[i].Validate(); err != nil {
+			return err
+		}
+		if keys[a.Fields[i].Key] {
+			return fmt.Errorf("%w: Feld-Schlüssel %s ist doppelt", ErrInvalidInput, a.Fields[i].Key)
+		}
+		keys[a.Fields[i].Key] = true
+	}
+	return nil
+}
+
+// Validate prüft ein einzelnes Formularfeld
+func (f *Field) Validate() error {
+	f.Label = strings.TrimSpace(f.Label)
+	f.Style = strings.ToLower(strings.TrimSpace(f.Style))
+	if f.Label == "" || len(f.Label) > 45 {
+		return fmt.Errorf("%w: Feld-Label muss 1-45 Zeichen lang sein", ErrInvalidInput)
+	}
+	if f.Style == "" {
+		f.Style = "short"
+	}
+	if f.Style != "short" && f.Style != "paragraph" {
+		return fmt.Errorf("%w: Feld-Stil muss short oder paragraph sein", ErrInvalidInput)
+	}
+	if f.MaxLength < 0 || f.MaxLength > 4000 {
+		return fmt.Errorf("%w: Maximallänge muss zwischen 0 und 4000 liegen", ErrInvalidInput)
+	}
+	if len(f.Placeholder) > 100 {
+		return fmt.Errorf("%w: Platzhalter ist länger als 100 Zeichen", ErrInvalidInput)
+	}
+	f.Key = strings.TrimSpace(f.Key)
+	if f.Key == "" {
+		f.Key = fieldKeyFromLabel(f.Label, f.Position)
+	}
+	if !fieldKeyPattern.MatchString(f.Key) {
+		return fmt.Errorf("%w: Feld-Schlüssel darf nur a-z, 0-9 und _ enthalten (1-40 Zeichen)", ErrInvalidInput)
+	}
+	return f.validateRule()
+}
+
+// Validate prüft Name und Titel eines Panels
+func (p *Panel) Validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Title = strings.TrimSpace(p.Title)
+	if p.Name == "" || p.Title == "" {
+		return fmt.Errorf("%w: Name und Titel sind Pflicht", ErrInvalidInput)
+	}
+	if len(p.AreaKeys) > MaxDropdownOptions {
+		return fmt.Errorf("%w: maximal %d Bereiche pro Panel", ErrInvalidInput, MaxDropdownOptions)
+	}
+	return nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+const areaColumns = `id, area_key, COALESCE(parent_key, ''), label, COALESCE(description, ''), display_name, modal_title,
+	COALESCE(sub_prompt, ''), COALESCE(support_role, ''), mention_user, COALESCE(category, ''), name_pattern, sort_order, enabled,
+	COALESCE(sla_claim_minutes, 0), COALESCE(sla_close_hours, 0), COALESCE(sla_reply_hours, 0),
+	COALESCE(inactivity_warn_days, 0), COALESCE(inactivity_close_days, 0), COALESCE(inactivity_delete, 0),
+	COALESCE(max_open_tickets, 0), COALESCE(assign_mode, ''), COALESCE(assign_role, ''), COALESCE(assign_users, '')`
+
+func scanArea(scanner interface{ Scan(...interface{}) error }) (*Area, error) {
+	var a Area
+	var mentionUser, enabled, inactivityDelete int
+	var assignUsers string
+	if err := scanner.Scan(&a.ID, &a.Key, &a.ParentKey, &a.Label, &a.Description, &a.DisplayName, &a.ModalTitle,
+		&a.SubPrompt, &a.SupportRole, &mentionUser, &a.Category, &a.NamePattern, &a.SortOrder, &enabled,
+		&a.SLAClaimMinutes, &a.SLACloseHours, &a.SLAReplyHours,
+		&a.InactivityWarnDays, &a.InactivityCloseDays, &inactivityDelete, &a.MaxOpenTickets,
+		&a.AssignMode, &a.AssignRole, &assignUsers); err != nil {
+		return nil, err
+	}
+	a.AssignUsers = SplitAreaKeys(assignUsers)
+	a.InactivityDelete = inactivityDelete != 0
+	a.MentionUser = mentionUser != 0
+	a.Enabled = enabled != 0
+	return &a, nil
+}
+
+func (s *AreaService) queryAreas(where string, args ...interface{}) ([]Area, error) {
+	rows, err := s.db.Query(`SELECT `+areaColumns+` FROM ticket_areas `+where+` ORDER BY sort_order, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var areas []Area
+	for rows.Next() {
+		a, err := scanArea(rows)
+		if err != nil {
+			return nil, err
+		}
+		areas = append(areas, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range areas {
+		if areas[i].Fields, err = s.fields(areas[i].Key); err != nil {
+			return nil, err
+		}
+	}
+	return areas, nil
+}
+
+func (s *AreaService) fields(areaKey string) ([]Field, error) {
+	rows, err := s.db.Query(`
+		SELECT position, COALESCE(field_key, ''), label, style, required, max_length, COALESCE(placeholder, ''),
+			COALESCE(validation, ''), min_value, max_value, COALESCE(pattern, ''), COALESCE(error_message, '')
+		FROM ticket_area_fields WHERE area_key = ? ORDER BY position`, areaKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := []Field{}
+	for rows.Next() {
+		var f Field
+		var required int
+		var minValue, maxValue sql.NullInt64
+		if err := rows.Scan(&f.Position, &f.Key, &f.Label, &f.Style, &required, &f.MaxLength, &f.Placeholder,
+			&f.Validation, &minValue, &maxValue, &f.Pattern, &f.ErrorMessage); err != nil {
+			return nil, err
+		}
+		f.Required = required != 0
+		f.MinValue = nullableInt(minValue)
+		f.MaxValue = nullableInt(maxValue)
+		// Felder aus der Zeit vor ticket_answers haben noch keinen Schlüssel
+		if f.Key == "" {
+			f.Key = fieldKeyFromLabel(f.Label, f.Position)
+		}
+		fields = append(fields, f)
+	}
+	return fields, rows.Err()
+}
+
+// ListAreas liefert alle Bereiche inkl. deaktivierter
+func (s *AreaService) ListAreas() ([]Area, error) {
+	return s.queryAreas("")
+}
+
+// GetArea liefert einen Bereich per Schlüssel
+func (s *AreaService) GetArea(key string) (*Area, error) {
+	areas, err := s.queryAreas("WHERE area_key = ?", key)
+	if err != nil {
+		return nil, err
+	}
+	if len(areas) == 0 {
+		return nil, ErrNotFound
+	}
+	return &areas[0], nil
+}
+
+// Children liefert die aktiven Unterbereiche, parentKey leer = Hauptbereiche
+func (s *AreaService) Children(parentKey string) ([]Area, error) {
+	if parentKey == "" {
+		return s.queryAreas("WHERE enabled = 1 AND (parent_key IS NULL OR parent_key = '')")
+	}
+	return s.queryAreas("WHERE enabled = 1 AND parent_key = ?", parentKey)
+}
+
+// AreasForPanel liefert die aktiven Bereiche eines Panels in der Reihenfolge des Panels
+func (s *AreaService) AreasForPanel(panel *Panel) ([]Area, error) {
+	if panel == nil || len(panel.AreaKeys) == 0 {
+		return s.Children("")
+	}
+	var areas []Area
+	for _, key := range panel.AreaKeys {
+		area, err := s.GetArea(key)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if area.Enabled {
+			areas = append(areas, *area)
+		}
+	}
+	return areas, nil
+}
+
+// checkParent stellt sicher, dass der Oberbereich existiert und keine Schleife entsteht
+func (s *AreaService) checkParent(key, parentKey string) error {
+	for current := parentKey; current != ""; {
+		if current == key {
+			return fmt.Errorf("%w: Verschachtelung würde eine Schleife erzeugen", ErrInvalidInput)
+		}
+		parent, err := s.GetArea(current)
+		if err == ErrNotFound {
+			return fmt.Errorf("%w: Oberbereich %s existiert nicht", ErrInvalidInput, current)
+		}
+		if err != nil {
+			return err
+		}
+		current = parent.ParentKey
+	}
+	return nil
+}
+
+// CreateArea legt einen neuen Bereich inkl. Formularfeldern an
+func (s *AreaService) CreateArea(a *Area) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	if _, err := s.GetArea(a.Key); err == nil {
+		return fmt.Errorf("%w: Bereich %s", ErrDuplicate, a.Key)
+	} else if err != ErrNotFound {
+		return err
+	}
+	if err := s.checkParent(a.Key, a.ParentKey); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		INSERT INTO ticket_areas (area_key, parent_key, label, description, display_name, modal_title, sub_prompt, support_role, mention_user, category, name_pattern, sort_order, enabled,
+			sla_claim_minutes, sla_close_hours, sla_reply_hours, inactivity_warn_days, inactivity_close_days, inactivity_delete, max_open_tickets,
+			assign_mode, assign_role, assign_users)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.Key, nullIfEmpty(a.ParentKey), a.Label, nullIfEmpty(a.Description), a.DisplayName, a.ModalTitle, nullIfEmpty(a.SubPrompt),
+		nullIfEmpty(a.SupportRole), a.MentionUser, nullIfEmpty(a.Category), a.NamePattern, a.SortOrder, a.Enabled,
+		a.SLAClaimMinutes, a.SLACloseHours, a.SLAReplyHours, a.InactivityWarnDays, a.InactivityCloseDays, a.InactivityDelete, a.MaxOpenTickets,
+		nullIfEmpty(string(a.AssignMode)), nullIfEmpty(a.AssignRole), nullIfEmpty(strings.Join(a.AssignUsers, ",")))
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err := replaceFields(tx, a.Key, a.Fields); err != nil {
+		return err
+	}
+	a.ID = int(id)
+	return tx.Commit()
+}
+
+// UpdateArea überschreibt einen Bereich, Fields == nil lässt die Formularfelder unverändert
+func (s *AreaService) UpdateArea(key string, a *Area) error {
+	existing, err := s.GetArea(key)
+	if err != nil {
+		return err
+	}
+	// Der Schlüssel steckt in bestehenden Tickets und Modals und bleibt daher fest
+	a.Key = existing.Key
+	keepFields := a.Fields == nil
+	if keepFields {
+		a.Fields = existing.Fields
+	}
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkParent(a.Key, a.ParentKey); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE ticket_areas SET parent_key = ?, label = ?, description = ?, display_name = ?, modal_title = ?, sub_prompt = ?,
+			support_role = ?, mention_user = ?, category = ?, name_pattern = ?, sort_order = ?, enabled = ?,
+			sla_claim_minutes = ?, sla_close_hours = ?, sla_reply_hours = ?,
+			inactivity_warn_days = ?, inactivity_close_days = ?, inactivity_delete = ?, max_open_tickets = ?,
+			assign_mode = ?, assign_role = ?, assign_users = ?
+		WHERE area_key = ?`,
+		nullIfEmpty(a.ParentKey), a.Label, nullIfEmpty(a.Description), a.DisplayName, a.ModalTitle, nullIfEmpty(a.SubPrompt),
+		nullIfEmpty(a.SupportRole), a.MentionUser, nullIfEmpty(a.Category), a.NamePattern, a.SortOrder, a.Enabled,
+		a.SLAClaimMinutes, a.SLACloseHours, a.SLAReplyHours,
+		a.InactivityWarnDays, a.InactivityCloseDays, a.InactivityDelete, a.MaxOpenTickets,
+		nullIfEmpty(string(a.AssignMode)), nullIfEmpty(a.AssignRole), nullIfEmpty(strings.Join(a.AssignUsers, ",")), a.Key)
+	if err != nil {
+		return err
+	}
+	if !keepFields {
+		if err := replaceFields(tx, a.Key, a.Fields); err != nil {
+			return err
+		}
+	}
+	a.ID = existing.ID
+	return tx.Commit()
+}
+
+// DeleteArea löscht einen Bereich ohne Unterbereiche inkl. Formularfeldern
+func (s *AreaService) DeleteArea(key string) error {
+	if _, err := s.GetArea(key); err != nil {
+		return err
+	}
+	var children int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM ticket_areas WHERE parent_key = ?`, key).Scan(&children); err != nil {
+		return err
+	}
+	if children > 0 {
+		return fmt.Errorf("%w: Bereich hat noch %d Unterbereiche", ErrInvalidInput, children)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM ticket_area_fields WHERE area_key = ?`, key); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM ticket_areas WHERE area_key = ?`, key); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddField hängt ein Feld an das Formular an oder fügt es an position ein (0 = am Ende)
+func (s *AreaService) AddField(key string, field Field, position int) (*Area, error) {
+	area, err := s.GetArea(key)
+	if err != nil {
+		return nil, err
+	}
+	if position <= 0 || position > len(area.Fields) {
+		area.Fields = append(area.Fields, field)
+	} else {
+		area.Fields = append(area.Fields[:position-1], append([]Field{field}, area.Fields[position-1:]...)...)
+	}
+	if err := s.UpdateArea(key, area); err != nil {
+		return nil, err
+	}
+	return area, nil
+}
+
+// RemoveField entfernt das Feld an position (1-basiert)
+func (s *AreaService) RemoveField(key string, position int) (*Area, error) {
+	area, err := s.GetArea(key)
+	if err != nil {
+		return nil, err
+	}
+	if position < 1 || position > len(area.Fields) {
+		return nil, fmt.Errorf("%w: Feld %d existiert nicht", ErrNotFound, position)
+	}
+	area.Fields = append(area.Fields[:position-1], area.Fields[position:]...)
+	if err := s.UpdateArea(key, area); err != nil {
+		return nil, err
+	}
+	return area, nil
+}
+
+func replaceFields(tx *sql.Tx, key string, fields []Field) error {
+	if _, err := tx.Exec(`DELETE FROM ticket_area_fields WHERE area_key = ?`, key); err != nil {
+		return err
+	}
+	for _, f := range fields {
+		var minValue, maxValue interface{}
+		if f.MinValue != nil {
+			minValue = *f.MinValue
+		}
+		if f.MaxValue != nil {
+			maxValue = *f.MaxValue
+		}
+		_, err := tx.Exec(`
+			INSERT INTO ticket_area_fields (area_key, position, field_key, label, style, required, max_length, placeholder, validation, min_value, max_value, pattern, error_message)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			key, f.Position, f.Key, f.Label, f.Style, f.Required, f.MaxLength, nullIfEmpty(f.Placeholder),
+			nullIfEmpty(f.Validation), minValue, maxValue, nullIfEmpty(f.Pattern), nullIfEmpty(f.ErrorMessage))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// SupportRoleID löst die Support-Rolle (bzw. den User) auf, Standard ist ROLE_TICKET_STANDARD
+func (s *AreaService) SupportRoleID(a *Area) string {
+	if a.SupportRole == "" {
+		return utils.GetIdFromDB(s.bot, "ROLE_TICKET_STANDARD")
+	}
+	return s.resolveID(a.SupportRole)
+}
+
+// CategoryID löst die Channel-Kategorie auf, leer = keine Kategorie
+func (s *AreaService) CategoryID(a *Area) string {
+	if a.Category == "" {
+		return ""
+	}
+	return s.resolveID(a.Category)
+}
+
+// resolveID gibt Discord-IDs direkt zurück und liest const_keys aus bot_const_ids
+func (s *AreaService) resolveID(value string) string {
+	if _, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return value
+	}
+	return utils.GetIdFromDB(s.bot, value)
+}
+
+// ChannelName setzt Ticket-ID, Username und Bereich in das Namensmuster ein
+func (a *Area) ChannelName(ticketID int64, username string) string {
+	pattern := a.NamePattern
+	if pattern == "" {
+		pattern = defaultNamePattern
+	}
+	return strings.NewReplacer(
+		"{id}", strconv.FormatInt(ticketID, 10),
+		"{user}", username,
+		"{area}", strings.TrimPrefix(a.Key, "ticket_"),
+	).Replace(pattern)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+func scanPanel(scanner interface{ Scan(...interface{}) error }) (*Panel, error) {
+	var p Panel
+	var keys string
+	if err := scanner.Scan(&p.ID, &p.Name, &p.Title, &p.Description, &keys); err != nil {
+		return nil, err
+	}
+	p.AreaKeys = SplitAreaKeys(keys)
+	return &p, nil
+}
+
+// ListPanels liefert alle Panels
+func (s *AreaService) ListPanels() ([]Panel, error) {
+	rows, err := s.db.Query(`SELECT id, name, title, COALESCE(description, ''), area_keys FROM ticket_panels ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	panels := []Panel{}
+	for rows.Next() {
+		p, err := scanPanel(rows)
+		if err != nil {
+			return nil, err
+		}
+		panels = append(panels, *p)
+	}
+	return panels, rows.Err()
+}
+
+// GetPanel liefert ein Panel per ID
+func (s *AreaService) GetPanel(id int) (*Panel, error) {
+	p, err := scanPanel(s.db.QueryRow(`SELECT id, name, title, COALESCE(description, ''), area_keys FROM ticket_panels WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return p, err
+}
+
+// checkPanelAreas stellt sicher, dass alle Bereiche des Panels existieren
+func (s *AreaService) checkPanelAreas(p *Panel) error {
+	for _, key := range p.AreaKeys {
+		if _, err := s.GetArea(key); err == ErrNotFound {
+			return fmt.Errorf("%w: Bereich %s existiert nicht", ErrInvalidInput, key)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreatePanel legt ein neues Panel an
+func (s *AreaService) CreatePanel(p *Panel) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkPanelAreas(p); err != nil {
+		return err
+	}
+	res, err := s.db.Exec(`INSERT INTO ticket_panels (name, title, description, area_keys) VALUES (?, ?, ?, ?)`,
+		p.Name, p.Title, nullIfEmpty(p.Description), strings.Join(p.AreaKeys, ","))
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return fmt.Errorf("%w: Panel %s", ErrDuplicate, p.Name)
+		}
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	p.ID = int(id)
+	return nil
+}
+
+// UpdatePanel überschreibt ein Panel
+func (s *AreaService) UpdatePanel(id int, p *Panel) error {
+	if _, err := s.GetPanel(id); err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkPanelAreas(p); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`UPDATE ticket_panels SET name = ?, title = ?, description = ?, area_keys = ? WHERE id = ?`,
+		p.Name, p.Title, nullIfEmpty(p.Description), strings.Join(p.AreaKeys, ","), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return fmt.Errorf("%w: Panel %s", ErrDuplicate, p.Name)
+		}
+		return err
+	}
+	p.ID = id
+	return nil
+}
+
+// DeletePanel löscht ein Panel, bereits gepostete Panels zeigen danach alle Hauptbereiche
+func (s *AreaService) DeletePanel(id int) error {
+	res, err := s.db.Exec(`DELETE FROM ticket_panels WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SplitAreaKeys zerlegt eine kommagetrennte Liste von Bereichsschlüsseln
+func SplitAreaKeys(value string) []string {
+	keys := []string{}
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func nullableInt(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	v := int(value.Int64)
+	return &v
+}
+
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
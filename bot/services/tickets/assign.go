@@ -0,0 +1,376 @@
+package tickets
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"bot/utils"
+)
+
+// AssignMode legt fest, wie neue Tickets eines Bereichs verteilt werden
+type AssignMode string
+
+const (
+	AssignOff        AssignMode = "off"
+	AssignRoundRobin AssignMode = "round_robin"
+	AssignLeastLoad  AssignMode = "least_load"
+	AssignManual     AssignMode = "manual" // nur im Verlauf, manuelle Zuweisung über das Panel
+)
+
+// ErrNoAssignee wird geliefert, wenn im Pool niemand verfügbar ist (alle abwesend oder Pool leer)
+var ErrNoAssignee = errors.New("Kein verfügbares Teammitglied")
+
+// Assignment ist ein Eintrag aus ticket_assignments
+type Assignment struct {
+	TicketID     int        `json:"ticket_id"`
+	AssigneeID   string     `json:"assignee_id"`
+	AssigneeName string     `json:"assignee_name"`
+	Mode         AssignMode `json:"mode"`
+	Reason       string     `json:"reason"`
+	ActorID      string     `json:"actor_id"`
+	CreatedAt    int64      `json:"created_at"`
+}
+
+// Absence ist eine Abwesenheit aus ticket_staff_absence, Until 0 = bis auf Weiteres
+type Absence struct {
+	UserID    string `json:"user_id"`
+	UserName  string `json:"user_name"`
+	Reason    string `json:"reason"`
+	Until     int64  `json:"until"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// Label liefert die Bezeichnung im Discord
+func (m AssignMode) Label() string {
+	switch m {
+	case AssignRoundRobin:
+		return "Reihum"
+	case AssignLeastLoad:
+		return "Wenigste offene Tickets"
+	case AssignManual:
+		return "Manuell"
+	case AssignOff:
+		return "Aus"
+	}
+	return "Oberbereich"
+}
+
+// validateAssign prüft Modus und User-Liste der automatischen Zuweisung
+func (a *Area) validateAssign() error {
+	switch a.AssignMode {
+	case "", AssignOff, AssignRoundRobin, AssignLeastLoad:
+	default:
+		return fmt.Errorf("%w: Zuweisung muss off, round_robin oder least_load sein", ErrInvalidInput)
+	}
+	if len(a.AssignUsers) > MaxDropdownOptions {
+		return fmt.Errorf("%w: maximal %d Teammitglieder im Zuweisungs-Pool", ErrInvalidInput, MaxDropdownOptions)
+	}
+	seen := make(map[string]bool)
+	users := []string{}
+	for _, id := range a.AssignUsers {
+		if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+			return fmt.Errorf("%w: %s ist keine Discord-ID", ErrInvalidInput, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			users = append(users, id)
+		}
+	}
+	a.AssignUsers = users
+	return nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// AssignWithReason weist das Ticket zu und speichert Modus und Begründung in ticket_assignments
+func (s *TicketService) AssignWithReason(ticketID int, actor, assignee Actor, mode AssignMode, reason string) (*Ticket, error) {
+	ticket, err := s.Assign(ticketID, actor, assignee)
+	if err != nil {
+		return ticket, err
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO ticket_assignments (ticket_id, assignee_id, assignee_name, mode, reason, actor_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		ticketID, assignee.ID, nullIfEmpty(assignee.Name), string(mode), nullIfEmpty(reason), nullIfEmpty(actor.ID), time.Now().Unix())
+	return ticket, err
+}
+
+// Assignments liefert alle Zuweisungen eines Tickets, älteste zuerst
+func (s *TicketService) Assignments(ticketID int) ([]Assignment, error) {
+	rows, err := s.db.Query(`
+		SELECT ticket_id, assignee_id, COALESCE(assignee_name, ''), mode, COALESCE(reason, ''), COALESCE(actor_id, ''), created_at
+		FROM ticket_assignments WHERE ticket_id = ? ORDER BY created_at, id`, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []Assignment{}
+	for rows.Next() {
+		var a Assignment
+		var mode string
+		if err := rows.Scan(&a.TicketID, &a.AssigneeID, &a.AssigneeName, &mode, &a.Reason, &a.ActorID, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.Mode = AssignMode(mode)
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// SetAbsent markiert ein Teammitglied als abwesend, until 0 = bis zur Rückmeldung
+func (s *TicketService) SetAbsent(user Actor, until time.Time, reason string) (*Absence, error) {
+	absence := &Absence{UserID: user.ID, UserName: user.Name, Reason: reason, CreatedAt: time.Now().Unix()}
+	if !until.IsZero() {
+		absence.Until = until.Unix()
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO ticket_staff_absence (user_id, user_name, reason, until, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET user_name = excluded.user_name, reason = excluded.reason, until = excluded.until, created_at = excluded.created_at`,
+		absence.UserID, nullIfEmpty(absence.UserName), nullIfEmpty(absence.Reason), absence.Until, absence.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return absence, nil
+}
+
+// ClearAbsent beendet die Abwesenheit, ErrNotFound wenn keine eingetragen ist
+func (s *TicketService) ClearAbsent(userID string) error {
+	res, err := s.db.Exec(`DELETE FROM ticket_staff_absence WHERE user_id = ?`, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: keine Abwesenheit eingetragen", ErrNotFound)
+	}
+	return nil
+}
+
+// Absences liefert alle laufenden Abwesenheiten, abgelaufene werden dabei entfernt
+func (s *TicketService) Absences() ([]Absence, error) {
+	now := time.Now().Unix()
+	if _, err := s.db.Exec(`DELETE FROM ticket_staff_absence WHERE until > 0 AND until <= ?`, now); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(`
+		SELECT user_id, COALESCE(user_name, ''), COALESCE(reason, ''), COALESCE(until, 0), created_at
+		FROM ticket_staff_absence ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	absences := []Absence{}
+	for rows.Next() {
+		var a Absence
+		if err := rows.Scan(&a.UserID, &a.UserName, &a.Reason, &a.Until, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		absences = append(absences, a)
+	}
+	return absences, rows.Err()
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// assignRule ist die wirksame Zuweisungsregel eines Bereichs (ggf. vom Oberbereich übernommen)
+type assignRule struct {
+	AreaKey string // Bereich, in dem die Regel eingetragen ist (gemeinsame Rotation für alle Unterbereiche)
+	Mode    AssignMode
+	RoleID  string
+	Users   []string
+}
+
+// assignRuleFor löst die Regel auf, leerer Modus übernimmt den Oberbereich
+func (s *TicketService) assignRuleFor(areaKey string) (*assignRule, error) {
+	areas := NewAreaService(s.bot)
+	for key, depth := areaKey, 0; key != "" && depth < 10; depth++ {
+		area, err := areas.GetArea(key)
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if area.AssignMode == "" {
+			key = area.ParentKey
+			continue
+		}
+		if area.AssignMode == AssignOff {
+			return nil, nil
+		}
+		rule := &assignRule{AreaKey: area.Key, Mode: area.AssignMode, Users: area.AssignUsers}
+		if len(rule.Users) == 0 {
+			if area.AssignRole != "" {
+				rule.RoleID = areas.resolveID(area.AssignRole)
+			} else if !area.MentionUser {
+				rule.RoleID = areas.SupportRoleID(area)
+			} else if area.SupportRole != "" {
+				rule.Users = []string{areas.resolveID(area.SupportRole)}
+			}
+		}
+		return rule, nil
+	}
+	return nil, nil
+}
+
+// assignPool liefert die Teammitglieder der Regel, die noch auf dem Server sind (ohne Bots), sortiert nach ID
+func (s *TicketService) assignPool(rule *assignRule) ([]Actor, error) {
+	guildID := utils.GetIdFromDB(s.bot, "GUILD_ID")
+	var pool []Actor
+	if len(rule.Users) > 0 {
+		for _, id := range rule.Users {
+			member, err := s.bot.GuildMember(guildID, id)
+			if err != nil || member.User == nil || member.User.Bot {
+				continue
+			}
+			pool = append(pool, Actor{ID: member.User.ID, Name: member.User.Username})
+		}
+	} else if rule.RoleID != "" {
+		for after := ""; ; {
+			members, err := s.bot.GuildMembers(guildID, after, 1000)
+			if err != nil {
+				return nil, err
+			}
+			for _, member := range members {
+				if member.User == nil || member.User.Bot {
+					continue
+				}
+				for _, role := range member.Roles {
+					if role == rule.RoleID {
+						pool = append(pool, Actor{ID: member.User.ID, Name: member.User.Username})
+						break
+					}
+				}
+			}
+			if len(members) < 1000 {
+				break
+			}
+			after = members[len(members)-1].User.ID
+		}
+	}
+	sort.Slice(pool, func(i, j int) bool { return pool[i].ID < pool[j].ID })
+	return pool, nil
+}
+
+// claimedLoad zählt die bearbeiteten (Claimed) Tickets je Teammitglied
+func (s *TicketService) claimedLoad() (map[string]int, error) {
+	rows, err := s.db.Query(`
+		SELECT ticket_bearbeiter_id, COUNT(*) FROM tickets
+		WHERE ticket_status = ? AND COALESCE(ticket_bearbeiter_id, '') != ''
+		GROUP BY ticket_bearbeiter_id`, string(StatusClaimed))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	load := make(map[string]int)
+	for rows.Next() {
+		var userID string
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, err
+		}
+		load[userID] = count
+	}
+	return load, rows.Err()
+}
+
+// pickAssignee wählt aus dem verfügbaren Pool: reihum nach lastID bzw. die geringste Last,
+// bei Gleichstand gewinnt, wer in der Rotation als Nächstes dran wäre
+func pickAssignee(pool []Actor, mode AssignMode, lastID string, load map[string]int) (Actor, bool) {
+	if len(pool) == 0 {
+		return Actor{}, false
+	}
+	start := sort.Search(len(pool), func(i int) bool { return pool[i].ID > lastID })
+	if start == len(pool) {
+		start = 0
+	}
+	if mode != AssignLeastLoad {
+		return pool[start], true
+	}
+	best := pool[start]
+	for i := 1; i < len(pool); i++ {
+		actor := pool[(start+i)%len(pool)]
+		if load[actor.ID] < load[best.ID] {
+			best = actor
+		}
+	}
+	return best, true
+}
+
+// AutoAssign weist ein neues Ticket nach der Regel seines Bereichs zu. Ohne Regel liefert es (nil, nil, nil),
+// ist niemand verfügbar ErrNoAssignee. Abwesende Teammitglieder werden übersprungen.
+func (s *TicketService) AutoAssign(ticketID int) (*Ticket, *Assignment, error) {
+	ticket, err := s.GetTicket(ticketID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ticket.ClaimerID != "" || !ticket.Status.Can(ActionAssign) {
+		return nil, nil, nil
+	}
+	rule, err := s.assignRuleFor(ticket.Area)
+	if err != nil || rule == nil {
+		return nil, nil, err
+	}
+
+	pool, err := s.assignPool(rule)
+	if err != nil {
+		return nil, nil, err
+	}
+	absences, err := s.Absences()
+	if err != nil {
+		return nil, nil, err
+	}
+	absent := make(map[string]bool, len(absences))
+	for _, absence := range absences {
+		absent[absence.UserID] = true
+	}
+	var available []Actor
+	for _, actor := range pool {
+		if !absent[actor.ID] {
+			available = append(available, actor)
+		}
+	}
+	if len(available) == 0 {
+		return nil, nil, fmt.Errorf("%w: Pool %d, davon %d abwesend", ErrNoAssignee, len(pool), len(pool)-len(available))
+	}
+
+	var lastID string
+	err = s.db.QueryRow(`SELECT last_user_id FROM ticket_assign_rotation WHERE area_key = ?`, rule.AreaKey).Scan(&lastID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, nil, err
+	}
+	load := map[string]int{}
+	if rule.Mode == AssignLeastLoad {
+		if load, err = s.claimedLoad(); err != nil {
+			return nil, nil, err
+		}
+	}
+	assignee, _ := pickAssignee(available, rule.Mode, lastID, load)
+
+	reason := fmt.Sprintf("Reihum im Bereich %s", rule.AreaKey)
+	if rule.Mode == AssignLeastLoad {
+		reason = fmt.Sprintf("Wenigste bearbeitete Tickets (%d) im Bereich %s", load[assignee.ID], rule.AreaKey)
+	}
+	reason += fmt.Sprintf(", %d von %d Teammitgliedern verfügbar", len(available), len(pool))
+
+	ticket, err = s.AssignWithReason(ticketID, Actor{}, assignee, rule.Mode, reason)
+	if err != nil {
+		return ticket, nil, err
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO ticket_assign_rotation (area_key, last_user_id, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(area_key) DO UPDATE SET last_user_id = excluded.last_user_id, updated_at = excluded.updated_at`,
+		rule.AreaKey, assignee.ID, time.Now().Unix())
+	if err != nil {
+		return ticket, nil, err
+	}
+	return ticket, &Assignment{TicketID: ticketID, AssigneeID: assignee.ID, AssigneeName: assignee.Name, Mode: rule.Mode, Reason: reason, CreatedAt: time.Now().Unix()}, nil
+}
@@ -0,0 +1,227 @@
+package tickets
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"bot/utils"
+)
+
+var (
+	ErrBlacklisted = errors.New("Vom Ticket-System ausgeschlossen")
+	ErrTicketLimit = errors.New("Zu viele offene Tickets")
+	ErrCooldown    = errors.New("Ticket-Erstellung vorübergehend gesperrt")
+)
+
+// BlacklistEntry ist ein Ausschluss vom Ticket-System, AreaKeys leer = alle Bereiche, ExpiresAt 0 = dauerhaft
+type BlacklistEntry struct {
+	ID           int      `json:"id"`
+	UserID       string   `json:"user_id"`
+	Reason       string   `json:"reason"`
+	IssuedByID   string   `json:"issued_by_id"`
+	IssuedByName string   `json:"issued_by_name"`
+	AreaKeys     []string `json:"area_keys"`
+	ExpiresAt    int64    `json:"expires_at"`
+	CreatedAt    int64    `json:"created_at"`
+}
+
+// CreateDenial erklärt, warum ein User gerade kein Ticket erstellen darf (Kind: ErrBlacklisted, ErrTicketLimit, ErrCooldown)
+type CreateDenial struct {
+	Kind  error
+	Entry *BlacklistEntry // bei ErrBlacklisted
+	Limit int             // bei ErrTicketLimit
+	Open  []Ticket        // bei ErrTicketLimit
+	Until int64           // bei ErrCooldown, Unix-Zeit
+}
+
+func (d *CreateDenial) Error() string { return d.Kind.Error() }
+func (d *CreateDenial) Unwrap() error { return d.Kind }
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// Covers prüft, ob der Ausschluss für den Bereich gilt ("" = Prüfung vor der Bereichsauswahl, nur globale Ausschlüsse)
+func (e *BlacklistEntry) Covers(areaKey string) bool {
+	if len(e.AreaKeys) == 0 {
+		return true
+	}
+	for _, key := range e.AreaKeys {
+		if key == areaKey {
+			return true
+		}
+	}
+	return false
+}
+
+const blacklistColumns = `id, user_id, reason, issued_by_id, COALESCE(issued_by_name, ''), area_keys, COALESCE(expires_at, 0), created_at`
+
+func scanBlacklistEntry(scanner interface{ Scan(...interface{}) error }) (*BlacklistEntry, error) {
+	var e BlacklistEntry
+	var areaKeys string
+	if err := scanner.Scan(&e.ID, &e.UserID, &e.Reason, &e.IssuedByID, &e.IssuedByName, &areaKeys, &e.ExpiresAt, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	e.AreaKeys = []string{}
+	if areaKeys != "" {
+		e.AreaKeys = strings.Split(areaKeys, ",")
+	}
+	return &e, nil
+}
+
+// Blacklist schließt einen User aus, ein bestehender Eintrag wird ersetzt
+func (s *TicketService) Blacklist(entry *BlacklistEntry) error {
+	entry.Reason = strings.TrimSpace(entry.Reason)
+	if entry.UserID == "" || entry.Reason == "" {
+		return fmt.Errorf("%w: User und Grund sind Pflicht", ErrInvalidInput)
+	}
+	if len(entry.Reason) > 500 {
+		return fmt.Errorf("%w: Grund ist länger als 500 Zeichen", ErrInvalidInput)
+	}
+	if entry.AreaKeys == nil {
+		entry.AreaKeys = []string{}
+	}
+	for _, key := range entry.AreaKeys {
+		var exists int
+		if err := s.db.QueryRow(`SELECT COUNT(*) FROM ticket_areas WHERE area_key = ?`, key).Scan(&exists); err != nil {
+			return err
+		}
+		if exists == 0 {
+			return fmt.Errorf("%w: Bereich %s", ErrNotFound, key)
+		}
+	}
+	entry.CreatedAt = time.Now().Unix()
+
+	_, err := s.db.Exec(`
+		INSERT INTO ticket_blacklist (user_id, reason, issued_by_id, issued_by_name, area_keys, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET reason = excluded.reason, issued_by_id = excluded.issued_by_id,
+			issued_by_name = excluded.issued_by_name, area_keys = excluded.area_keys, expires_at = excluded.expires_at, created_at = excluded.created_at`,
+		entry.UserID, entry.Reason, entry.IssuedByID, nullIfEmpty(entry.IssuedByName), strings.Join(entry.AreaKeys, ","), entry.ExpiresAt, entry.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return s.db.QueryRow(`SELECT id FROM ticket_blacklist WHERE user_id = ?`, entry.UserID).Scan(&entry.ID)
+}
+
+// Unblacklist hebt den Ausschluss eines Users auf
+func (s *TicketService) Unblacklist(userID string) error {
+	res, err := s.db.Exec(`DELETE FROM ticket_blacklist WHERE user_id = ?`, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListBlacklist liefert alle noch gültigen Ausschlüsse, neueste zuerst
+func (s *TicketService) ListBlacklist(now time.Time) ([]BlacklistEntry, error) {
+	rows, err := s.db.Query(`SELECT `+blacklistColumns+` FROM ticket_blacklist
+		WHERE expires_at = 0 OR expires_at > ? ORDER BY created_at DESC`, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []BlacklistEntry{}
+	for rows.Next() {
+		entry, err := scanBlacklistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+// ActiveBlacklistEntry liefert den gültigen Ausschluss eines Users oder nil
+func (s *TicketService) ActiveBlacklistEntry(userID string, now time.Time) (*BlacklistEntry, error) {
+	entry, err := scanBlacklistEntry(s.db.QueryRow(`SELECT `+blacklistColumns+` FROM ticket_blacklist
+		WHERE user_id = ? AND (expires_at = 0 OR expires_at > ?)`, userID, now.Unix()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return entry, err
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// CheckCreate prüft Ausschluss, Limit offener Tickets und Cooldown, bevor Dropdown bzw. Formular angezeigt werden.
+// Ohne area (vor der Bereichsauswahl) werden nur globale Ausschlüsse und der Cooldown geprüft.
+// Bei einer Ablehnung wird ein *CreateDenial geliefert.
+func (s *TicketService) CheckCreate(userID string, area *Area, now time.Time) error {
+	areaKey := ""
+	if area != nil {
+		areaKey = area.Key
+	}
+	entry, err := s.ActiveBlacklistEntry(userID, now)
+	if err != nil {
+		return err
+	}
+	if entry != nil && entry.Covers(areaKey) {
+		return &CreateDenial{Kind: ErrBlacklisted, Entry: entry}
+	}
+
+	if cooldown, _ := strconv.Atoi(utils.GetOptionalIdFromDB(s.bot, "TICKET_CREATE_COOLDOWN_MINUTES", "5")); cooldown > 0 {
+		var last int64
+		if err := s.db.QueryRow(`SELECT COALESCE(MAX(ticket_erstellungszeit), 0) FROM tickets WHERE ticket_ersteller_id = ?`, userID).Scan(&last); err != nil {
+			return err
+		}
+		if until := last + int64(cooldown)*60; until > now.Unix() {
+			return &CreateDenial{Kind: ErrCooldown, Until: until}
+		}
+	}
+
+	if area == nil {
+		return nil
+	}
+	limit, err := s.openTicketLimit(area)
+	if err != nil || limit <= 0 {
+		return err
+	}
+	open, err := s.openTicketsOf(userID, area.Key)
+	if err != nil {
+		return err
+	}
+	if len(open) >= limit {
+		return &CreateDenial{Kind: ErrTicketLimit, Limit: limit, Open: open}
+	}
+	return nil
+}
+
+// openTicketLimit liefert das Limit des Bereichs, sonst des Oberbereichs, sonst TICKET_MAX_OPEN_PER_AREA (0 = unbegrenzt)
+func (s *TicketService) openTicketLimit(area *Area) (int, error) {
+	if area.MaxOpenTickets > 0 {
+		return area.MaxOpenTickets, nil
+	}
+	if area.ParentKey != "" {
+		var parentLimit int
+		err := s.db.QueryRow(`SELECT COALESCE(max_open_tickets, 0) FROM ticket_areas WHERE area_key = ?`, area.ParentKey).Scan(&parentLimit)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, err
+		}
+		if parentLimit > 0 {
+			return parentLimit, nil
+		}
+	}
+	limit, err := strconv.Atoi(utils.GetOptionalIdFromDB(s.bot, "TICKET_MAX_OPEN_PER_AREA", "1"))
+	if err != nil {
+		return 0, nil
+	}
+	return limit, nil
+}
+
+// openTicketsOf liefert die offenen bzw. bearbeiteten Tickets eines Users in einem Bereich
+func (s *TicketService) openTicketsOf(userID, areaKey string) ([]Ticket, error) {
+	rows, err := s.db.Query(`SELECT `+ticketColumns+` FROM tickets
+		WHERE ticket_ersteller_id = ? AND ticket_bereich = ? AND ticket_status IN (?, ?) ORDER BY ticket_id`,
+		userID, areaKey, string(StatusOpen), string(StatusClaimed))
+	if err != nil {
+		return nil, err
+	}
+	return scanTickets(rows)
+}
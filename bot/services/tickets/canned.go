@@ -0,0 +1,252 @@
+package tickets
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Formate eines Textbausteins: Markdown wird als Nachricht gesendet, embed enthält ein Discord-Embed als JSON
+const (
+	CannedMarkdown = "markdown"
+	CannedEmbed    = "embed"
+)
+
+// maxCannedBody entspricht dem Limit einer Embed-Beschreibung, Markdown wird beim Senden auf 2000 Zeichen geprüft
+const maxCannedBody = 4000
+
+// CannedResponse ist ein Textbaustein aus canned_responses
+type CannedResponse struct {
+	ID         int    `json:"id"`
+	Title      string `json:"title"`
+	AreaKey    string `json:"area_key"` // leer = alle Bereiche, sonst auch für die Unterbereiche
+	Format     string `json:"format"`   // markdown | embed
+	Body       string `json:"body"`
+	UsageCount int    `json:"usage_count"`
+	LastUsedAt int64  `json:"last_used_at"`
+	CreatedBy  string `json:"created_by"`
+	CreatedAt  int64  `json:"created_at"`
+	UpdatedAt  int64  `json:"updated_at"`
+}
+
+// Validate prüft Titel, Format und Inhalt, Embed-JSON muss sich als Discord-Embed lesen lassen
+func (c *CannedResponse) Validate() error {
+	c.Title = strings.TrimSpace(c.Title)
+	c.AreaKey = strings.TrimSpace(c.AreaKey)
+	c.Format = strings.ToLower(strings.TrimSpace(c.Format))
+	c.Body = strings.TrimSpace(c.Body)
+	if c.Title == "" || len([]rune(c.Title)) > 80 {
+		return fmt.Errorf("%w: Titel muss 1-80 Zeichen lang sein", ErrInvalidInput)
+	}
+	if c.Format == "" {
+		c.Format = CannedMarkdown
+	}
+	if c.Body == "" || len([]rune(c.Body)) > maxCannedBody {
+		return fmt.Errorf("%w: Text muss 1-%d Zeichen lang sein", ErrInvalidInput, maxCannedBody)
+	}
+	switch c.Format {
+	case CannedMarkdown:
+	case CannedEmbed:
+		var embed discordgo.MessageEmbed
+		if err := json.Unmarshal([]byte(c.Body), &embed); err != nil {
+			return fmt.Errorf("%w: Embed-JSON ist ungültig (%v)", ErrInvalidInput, err)
+		}
+		if embed.Title == "" && embed.Description == "" {
+			return fmt.Errorf("%w: Embed braucht einen title oder eine description", ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: Format muss markdown oder embed sein", ErrInvalidInput)
+	}
+	return nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+const cannedColumns = `id, title, COALESCE(area_key, ''), format, body, COALESCE(usage_count, 0), COALESCE(last_used_at, 0),
+	COALESCE(created_by, ''), created_at, updated_at`
+
+func scanCanned(scanner interface{ Scan(...interface{}) error }) (*CannedResponse, error) {
+	var c CannedResponse
+	err := scanner.Scan(&c.ID, &c.Title, &c.AreaKey, &c.Format, &c.Body, &c.UsageCount, &c.LastUsedAt, &c.CreatedBy, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CannedResponses liefert die Textbausteine für einen Bereich (inkl. Oberbereich und bereichsübergreifende),
+// areaKey leer = alle. query filtert den Titel, sortiert wird nach Nutzung.
+func (s *TicketService) CannedResponses(areaKey, query string) ([]CannedResponse, error) {
+	where := `WHERE LOWER(title) LIKE ?`
+	args := []interface{}{"%" + strings.ToLower(strings.TrimSpace(query)) + "%"}
+	if areaKey != "" {
+		where += ` AND (COALESCE(area_key, '') = '' OR area_key = ? OR area_key = (SELECT parent_key FROM ticket_areas WHERE area_key = ?))`
+		args = append(args, areaKey, areaKey)
+	}
+	rows, err := s.db.Query(`SELECT `+cannedColumns+` FROM canned_responses `+where+` ORDER BY usage_count DESC, title`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	responses := []CannedResponse{}
+	for rows.Next() {
+		c, err := scanCanned(rows)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *c)
+	}
+	return responses, rows.Err()
+}
+
+// CannedResponse liefert einen Textbaustein per ID
+func (s *TicketService) CannedResponse(id int) (*CannedResponse, error) {
+	c, err := scanCanned(s.db.QueryRow(`SELECT `+cannedColumns+` FROM canned_responses WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: Textbaustein %d", ErrNotFound, id)
+	}
+	return c, err
+}
+
+// checkAreaKey stellt sicher, dass der Bereich existiert (leer = alle Bereiche)
+func (s *TicketService) checkAreaKey(areaKey string) error {
+	if areaKey == "" {
+		return nil
+	}
+	if _, err := NewAreaService(s.bot).GetArea(areaKey); errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: Bereich %s existiert nicht", ErrInvalidInput, areaKey)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateCannedResponse legt einen Textbaustein an
+func (s *TicketService) CreateCannedResponse(c *CannedResponse, author Actor) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkAreaKey(c.AreaKey); err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	res, err := s.db.Exec(`INSERT INTO canned_responses (title, area_key, format, body, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		c.Title, nullIfEmpty(c.AreaKey), c.Format, c.Body, nullIfEmpty(author.ID), now, now)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return fmt.Errorf("%w: Textbaustein %s", ErrDuplicate, c.Title)
+		}
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	c.ID, c.CreatedBy, c.CreatedAt, c.UpdatedAt = int(id), author.ID, now, now
+	return nil
+}
+
+// UpdateCannedResponse überschreibt Titel, Bereich, Format und Text, die Nutzung bleibt erhalten
+func (s *TicketService) UpdateCannedResponse(c *CannedResponse) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkAreaKey(c.AreaKey); err != nil {
+		return err
+	}
+	c.UpdatedAt = time.Now().Unix()
+	res, err := s.db.Exec(`UPDATE canned_responses SET title = ?, area_key = ?, format = ?, body = ?, updated_at = ? WHERE id = ?`,
+		c.Title, nullIfEmpty(c.AreaKey), c.Format, c.Body, c.UpdatedAt, c.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return fmt.Errorf("%w: Textbaustein %s", ErrDuplicate, c.Title)
+		}
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: Textbaustein %d", ErrNotFound, c.ID)
+	}
+	return nil
+}
+
+// DeleteCannedResponse löscht einen Textbaustein
+func (s *TicketService) DeleteCannedResponse(id int) error {
+	res, err := s.db.Exec(`DELETE FROM canned_responses WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: Textbaustein %d", ErrNotFound, id)
+	}
+	return nil
+}
+
+// RecordCannedUse zählt die Verwendung eines Textbausteins
+func (s *TicketService) RecordCannedUse(id int) error {
+	_, err := s.db.Exec(`UPDATE canned_responses SET usage_count = COALESCE(usage_count, 0) + 1, last_used_at = ? WHERE id = ?`, time.Now().Unix(), id)
+	return err
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// cannedReplacer setzt {creator}, {creator_name}, {claimer}, {claimer_name}, {area} und {ticket_id} sowie die
+// zusätzlichen Platzhalter (z.B. {reason}) ein. Ohne Ticket bleiben die Platzhalter stehen, damit sie von Hand ersetzt werden können.
+func (s *TicketService) cannedReplacer(ticket *Ticket, extra map[string]string) *strings.Replacer {
+	var pairs []string
+	for key, value := range extra {
+		pairs = append(pairs, "{"+key+"}", value)
+	}
+	if ticket == nil {
+		return strings.NewReplacer(pairs...)
+	}
+	area := ticket.Area
+	if a, err := NewAreaService(s.bot).GetArea(ticket.Area); err == nil {
+		area = a.DisplayName
+	}
+	claimer, claimerName := "", ""
+	if ticket.ClaimerID != "" {
+		claimer, claimerName = "<@"+ticket.ClaimerID+">", ticket.ClaimerName
+	}
+	return strings.NewReplacer(append(pairs,
+		"{creator}", "<@"+ticket.CreatorID+">",
+		"{creator_name}", ticket.CreatorName,
+		"{claimer}", claimer,
+		"{claimer_name}", claimerName,
+		"{area}", area,
+		"{ticket_id}", strconv.Itoa(ticket.ID),
+	)...)
+}
+
+// RenderCannedResponse liefert den Textbaustein als Nachricht mit eingesetzten Platzhaltern (ticket und extra dürfen nil sein)
+func (s *TicketService) RenderCannedResponse(c *CannedResponse, ticket *Ticket, extra map[string]string) (*discordgo.MessageSend, error) {
+	replacer := s.cannedReplacer(ticket, extra)
+	if c.Format != CannedEmbed {
+		content := replacer.Replace(c.Body)
+		if len([]rune(content)) > 2000 {
+			return nil, fmt.Errorf("%w: Nachricht ist länger als 2000 Zeichen, als Embed speichern", ErrInvalidInput)
+		}
+		return &discordgo.MessageSend{Content: content}, nil
+	}
+
+	var embed discordgo.MessageEmbed
+	if err := json.Unmarshal([]byte(c.Body), &embed); err != nil {
+		return nil, fmt.Errorf("%w: Embed-JSON ist ungültig (%v)", ErrInvalidInput, err)
+	}
+	embed.Title = replacer.Replace(embed.Title)
+	embed.Description = replacer.Replace(embed.Description)
+	for _, field := range embed.Fields {
+		field.Name = replacer.Replace(field.Name)
+		field.Value = replacer.Replace(field.Value)
+	}
+	if embed.Footer != nil {
+		embed.Footer.Text = replacer.Replace(embed.Footer.Text)
+	}
+	return &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{&embed}}, nil
+}
@@ -0,0 +1,146 @@
+package tickets
+
+import (
+	"database/sql"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// channelCache merkt sich Channel-ID -> Ticket-ID, da jede Moderationsaktion darüber auflöst.
+// 0 steht für Channels ohne Ticket, damit Nachrichten in normalen Channels keine Abfrage auslösen.
+var channelCache = struct {
+	sync.RWMutex
+	tickets map[string]int
+}{tickets: make(map[string]int)}
+
+// RepairReport fasst das Ergebnis von Repair zusammen
+type RepairReport struct {
+	Relinked []Ticket `json:"relinked"` // Channels, die wieder mit ihrem Ticket verknüpft wurden
+	Missing  []Ticket `json:"missing"`  // offene Tickets, deren Channel nicht mehr existiert
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// TicketIDForChannel liefert das Ticket zu einem Channel über ticket_channel_id
+func (s *TicketService) TicketIDForChannel(channelID string) (int, error) {
+	channelCache.RLock()
+	ticketID, ok := channelCache.tickets[channelID]
+	channelCache.RUnlock()
+	if ok {
+		if ticketID == 0 {
+			return 0, ErrNotFound
+		}
+		return ticketID, nil
+	}
+
+	// Gelöschte Tickets zuletzt, falls ein Channel versehentlich doppelt verknüpft ist
+	err := s.db.QueryRow(`
+		SELECT ticket_id FROM tickets WHERE ticket_channel_id = ?
+		ORDER BY ticket_status = ?, ticket_id DESC LIMIT 1`, channelID, string(StatusDeleted)).Scan(&ticketID)
+	if err == sql.ErrNoRows {
+		channelCache.Lock()
+		channelCache.tickets[channelID] = 0
+		channelCache.Unlock()
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	channelCache.Lock()
+	channelCache.tickets[channelID] = ticketID
+	channelCache.Unlock()
+	return ticketID, nil
+}
+
+// LinkChannel speichert den Channel eines Tickets und aktualisiert den Cache
+func (s *TicketService) LinkChannel(ticketID int, channelID string) error {
+	if _, err := s.db.Exec(`UPDATE tickets SET ticket_channel_id = ? WHERE ticket_id = ?`, channelID, ticketID); err != nil {
+		return err
+	}
+
+	channelCache.Lock()
+	for cached, id := range channelCache.tickets {
+		if id == ticketID {
+			delete(channelCache.tickets, cached)
+		}
+	}
+	channelCache.tickets[channelID] = ticketID
+	channelCache.Unlock()
+	return nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// Repair gleicht die Tickets mit den Channels der Guild ab.
+// Channels ohne Ticket-Verknüpfung, deren Name mit "<ticketID>-" beginnt, werden dem Ticket wieder zugeordnet,
+// sofern dessen gespeicherter Channel nicht mehr existiert. Offene Tickets ohne Channel werden gemeldet.
+func (s *TicketService) Repair(channels []*discordgo.Channel) (*RepairReport, error) {
+	existing := make(map[string]*discordgo.Channel)
+	for _, channel := range channels {
+		if channel.Type == discordgo.ChannelTypeGuildText {
+			existing[channel.ID] = channel
+		}
+	}
+
+	rows, err := s.db.Query(`SELECT `+ticketColumns+` FROM tickets WHERE ticket_status != ? ORDER BY ticket_id`, string(StatusDeleted))
+	if err != nil {
+		return nil, err
+	}
+	tickets := make(map[int]*Ticket)
+	linked := make(map[string]bool)
+	var order []int
+	for rows.Next() {
+		t, err := scanTicket(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tickets[t.ID] = t
+		order = append(order, t.ID)
+		if existing[t.ChannelID] != nil {
+			linked[t.ChannelID] = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &RepairReport{Relinked: []Ticket{}, Missing: []Ticket{}}
+	for channelID, channel := range existing {
+		if linked[channelID] {
+			continue
+		}
+		prefix, _, found := strings.Cut(channel.Name, "-")
+		if !found {
+			continue
+		}
+		ticketID, err := strconv.Atoi(prefix)
+		if err != nil {
+			continue
+		}
+		ticket := tickets[ticketID]
+		if ticket == nil || existing[ticket.ChannelID] != nil {
+			continue
+		}
+		if err := s.LinkChannel(ticketID, channelID); err != nil {
+			return nil, err
+		}
+		ticket.ChannelID = channelID
+		linked[channelID] = true
+		report.Relinked = append(report.Relinked, *ticket)
+	}
+
+	sort.Slice(report.Relinked, func(i, j int) bool { return report.Relinked[i].ID < report.Relinked[j].ID })
+	for _, id := range order {
+		if existing[tickets[id].ChannelID] == nil {
+			report.Missing = append(report.Missing, *tickets[id])
+		}
+	}
+	return report, nil
+}
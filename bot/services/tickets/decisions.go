@@ -0,0 +1,501 @@
+package tickets
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bot/utils"
+)
+
+// Decision ist das Ergebnis einer Bewerbung, gespeichert in tickets.ticket_decision bzw. als Stimme in ticket_decision_votes
+type Decision string
+
+const (
+	DecisionApproved Decision = "approved"
+	DecisionRejected Decision = "rejected"
+)
+
+// TeamSelect als Team einer Regel: das Management wählt das Team nach der Annahme im Ticket aus
+const TeamSelect = "select"
+
+var (
+	ErrAlreadyDecided = errors.New("Über die Bewerbung wurde bereits entschieden")
+	ErrNoDecisionRule = errors.New("Für diesen Bereich sind keine Bewerbungs-Entscheidungen eingerichtet")
+)
+
+// Label liefert die Anzeige im Moderations-Panel
+func (d Decision) Label() string {
+	switch d {
+	case DecisionApproved:
+		return "Angenommen"
+	case DecisionRejected:
+		return "Abgelehnt"
+	}
+	return "Offen"
+}
+
+// DecisionRule legt fest, wie über Bewerbungen eines Bereichs (inkl. Unterbereichen ohne eigene Regel) entschieden wird
+type DecisionRule struct {
+	AreaKey         string   `json:"area_key"`
+	Quorum          int      `json:"quorum"`           // Stimmen für Annahme bzw. Ablehnung, 1 = Einzelentscheidung
+	ApproveRoles    []string `json:"approve_roles"`    // Discord-IDs oder const_keys, z.B. ROLE_DIAMOND_CLUB
+	Team            string   `json:"team"`             // leer, team_areas.id oder "select"
+	WelcomeTemplate int      `json:"welcome_template"` // canned_responses.id für die DM bei Annahme, 0 = Standardtext
+	WelcomeChannel  string   `json:"welcome_channel"`  // Discord-ID oder const_key, leer = kein Post
+	RejectTemplate  int      `json:"reject_template"`  // canned_responses.id für die DM bei Ablehnung ({reason}), 0 = Standardtext
+	UpdatedAt       int64    `json:"updated_at"`
+}
+
+// Vote ist die Stimme eines Teammitglieds, eine neue Stimme ersetzt die bisherige
+type Vote struct {
+	UserID    string   `json:"user_id"`
+	UserName  string   `json:"user_name"`
+	Vote      Decision `json:"vote"`
+	Reason    string   `json:"reason"`
+	CreatedAt int64    `json:"created_at"`
+}
+
+// DecisionState ist der Stand der Abstimmung über ein Ticket
+type DecisionState struct {
+	Rule       *DecisionRule `json:"rule"` // nil = kein Bewerbungs-Bereich
+	Votes      []Vote        `json:"votes"`
+	Approvals  int           `json:"approvals"`
+	Rejections int           `json:"rejections"`
+	Decision   Decision      `json:"decision"`
+	DecidedAt  int64         `json:"decided_at"`
+	Reason     string        `json:"reason"`
+}
+
+// Validate prüft Quorum, Team und Vorlagen-IDs
+func (r *DecisionRule) Validate() error {
+	r.AreaKey = strings.TrimSpace(r.AreaKey)
+	r.Team = strings.ToLower(strings.TrimSpace(r.Team))
+	r.WelcomeChannel = strings.TrimSpace(r.WelcomeChannel)
+	if r.AreaKey == "" {
+		return fmt.Errorf("%w: Bereich fehlt", ErrInvalidInput)
+	}
+	if r.Quorum < 1 || r.Quorum > 10 {
+		return fmt.Errorf("%w: Quorum muss zwischen 1 und 10 liegen", ErrInvalidInput)
+	}
+	if r.Team != "" && r.Team != TeamSelect {
+		if id, err := strconv.Atoi(r.Team); err != nil || id <= 0 {
+			return fmt.Errorf("%w: Team muss eine Team-ID oder select sein", ErrInvalidInput)
+		}
+	}
+	if r.WelcomeTemplate < 0 || r.RejectTemplate < 0 {
+		return fmt.Errorf("%w: ungültige Vorlage", ErrInvalidInput)
+	}
+	return nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+const decisionRuleColumns = `area_key, quorum, COALESCE(approve_roles, ''), COALESCE(team, ''), COALESCE(welcome_template, 0),
+	COALESCE(welcome_channel, ''), COALESCE(reject_template, 0), updated_at`
+
+func scanDecisionRule(scanner interface{ Scan(...interface{}) error }) (*DecisionRule, error) {
+	var r DecisionRule
+	var roles string
+	if err := scanner.Scan(&r.AreaKey, &r.Quorum, &roles, &r.Team, &r.WelcomeTemplate, &r.WelcomeChannel, &r.RejectTemplate, &r.UpdatedAt); err != nil {
+		return nil, err
+	}
+	r.ApproveRoles = SplitAreaKeys(roles)
+	return &r, nil
+}
+
+// DecisionRules liefert alle eingetragenen Regeln
+func (s *TicketService) DecisionRules() ([]DecisionRule, error) {
+	rows, err := s.db.Query(`SELECT ` + decisionRuleColumns + ` FROM ticket_decision_rules ORDER BY area_key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []DecisionRule{}
+	for rows.Next() {
+		r, err := scanDecisionRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *r)
+	}
+	return rules, rows.Err()
+}
+
+// SetDecisionRule legt die Regel eines Bereichs an oder überschreibt sie
+func (s *TicketService) SetDecisionRule(r *DecisionRule) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkAreaKey(r.AreaKey); err != nil {
+		return err
+	}
+	if r.Team != "" && r.Team != TeamSelect {
+		var exists int
+		if err := s.db.QueryRow(`SELECT COUNT(*) FROM team_areas WHERE id = ?`, r.Team).Scan(&exists); err != nil {
+			return err
+		}
+		if exists == 0 {
+			return fmt.Errorf("%w: Team %s existiert nicht", ErrInvalidInput, r.Team)
+		}
+	}
+	for _, id := range []int{r.WelcomeTemplate, r.RejectTemplate} {
+		if id == 0 {
+			continue
+		}
+		if _, err := s.CannedResponse(id); errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%w: Textbaustein %d existiert nicht", ErrInvalidInput, id)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	r.UpdatedAt = time.Now().Unix()
+	_, err := s.db.Exec(`
+		INSERT INTO ticket_decision_rules (area_key, quorum, approve_roles, team, welcome_template, welcome_channel, reject_template, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(area_key) DO UPDATE SET quorum = excluded.quorum, approve_roles = excluded.approve_roles, team = excluded.team,
+			welcome_template = excluded.welcome_template, welcome_channel = excluded.welcome_channel,
+			reject_template = excluded.reject_template, updated_at = excluded.updated_at`,
+		r.AreaKey, r.Quorum, nullIfEmpty(strings.Join(r.ApproveRoles, ",")), nullIfEmpty(r.Team), r.WelcomeTemplate,
+		nullIfEmpty(r.WelcomeChannel), r.RejectTemplate, r.UpdatedAt)
+	return err
+}
+
+// DeleteDecisionRule entfernt die Regel eines Bereichs, bereits gespeicherte Entscheidungen bleiben erhalten
+func (s *TicketService) DeleteDecisionRule(areaKey string) error {
+	res, err := s.db.Exec(`DELETE FROM ticket_decision_rules WHERE area_key = ?`, areaKey)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: keine Regel für %s", ErrNotFound, areaKey)
+	}
+	return nil
+}
+
+// DecisionRuleFor liefert die wirksame Regel, Unterbereiche ohne eigene Regel übernehmen die des Oberbereichs (nil = keine)
+func (s *TicketService) DecisionRuleFor(areaKey string) (*DecisionRule, error) {
+	areas := NewAreaService(s.bot)
+	for key, depth := areaKey, 0; key != "" && depth < 10; depth++ {
+		rule, err := scanDecisionRule(s.db.QueryRow(`SELECT `+decisionRuleColumns+` FROM ticket_decision_rules WHERE area_key = ?`, key))
+		if err == nil {
+			return rule, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+		area, err := areas.GetArea(key)
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		key = area.ParentKey
+	}
+	return nil, nil
+}
+
+// ApproveRoleIDs löst die Rollen der Regel auf (const_keys über bot_const_ids)
+func (s *TicketService) ApproveRoleIDs(rule *DecisionRule) []string {
+	areas := NewAreaService(s.bot)
+	ids := make([]string, 0, len(rule.ApproveRoles))
+	for _, role := range rule.ApproveRoles {
+		if id := areas.resolveID(role); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// WelcomeChannelID löst den Willkommens-Channel auf, leer = kein Post
+func (s *TicketService) WelcomeChannelID(rule *DecisionRule) string {
+	if rule.WelcomeChannel == "" {
+		return ""
+	}
+	return NewAreaService(s.bot).resolveID(rule.WelcomeChannel)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// DecisionState liefert Regel, Stimmen und Ergebnis eines Tickets
+func (s *TicketService) DecisionState(ticketID int) (*DecisionState, error) {
+	ticket, err := s.GetTicket(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	state := &DecisionState{Votes: []Vote{}, Decision: ticket.Decision}
+	if state.Rule, err = s.DecisionRuleFor(ticket.Area); err != nil {
+		return nil, err
+	}
+	if err := s.db.QueryRow(`SELECT COALESCE(ticket_decision_at, 0), COALESCE(ticket_decision_reason, '') FROM tickets WHERE ticket_id = ?`,
+		ticketID).Scan(&state.DecidedAt, &state.Reason); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT user_id, COALESCE(user_name, ''), vote, COALESCE(reason, ''), created_at
+		FROM ticket_decision_votes WHERE ticket_id = ? ORDER BY created_at`, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v Vote
+		var vote string
+		if err := rows.Scan(&v.UserID, &v.UserName, &vote, &v.Reason, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		v.Vote = Decision(vote)
+		state.Votes = append(state.Votes, v)
+		if v.Vote == DecisionApproved {
+			state.Approvals++
+		} else {
+			state.Rejections++
+		}
+	}
+	return state, rows.Err()
+}
+
+// CastVote speichert die Stimme und entscheidet, sobald Annahme oder Ablehnung das Quorum erreicht.
+// decided ist nur bei der Stimme true, die die Entscheidung ausgelöst hat.
+func (s *TicketService) CastVote(ticketID int, voter Actor, vote Decision, reason string) (ticket *Ticket, state *DecisionState, decided bool, err error) {
+	if vote != DecisionApproved && vote != DecisionRejected {
+		return nil, nil, false, fmt.Errorf("%w: unbekannte Stimme %s", ErrInvalidInput, vote)
+	}
+	if ticket, err = s.GetTicket(ticketID); err != nil {
+		return nil, nil, false, err
+	}
+	if ticket.Decision != "" {
+		return ticket, nil, false, ErrAlreadyDecided
+	}
+	if ticket.Status != StatusOpen && ticket.Status != StatusClaimed {
+		return ticket, nil, false, fmt.Errorf("%w: Entscheidung bei Status %s", ErrInvalidTransition, ticket.Status)
+	}
+	rule, err := s.DecisionRuleFor(ticket.Area)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if rule == nil {
+		return ticket, nil, false, ErrNoDecisionRule
+	}
+
+	now := time.Now().Unix()
+	_, err = s.db.Exec(`
+		INSERT INTO ticket_decision_votes (ticket_id, user_id, user_name, vote, reason, created_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(ticket_id, user_id) DO UPDATE SET user_name = excluded.user_name, vote = excluded.vote, reason = excluded.reason, created_at = excluded.created_at`,
+		ticketID, voter.ID, nullIfEmpty(voter.Name), string(vote), nullIfEmpty(strings.TrimSpace(reason)), now)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if state, err = s.DecisionState(ticketID); err != nil {
+		return nil, nil, false, err
+	}
+
+	var outcome Decision
+	switch {
+	case state.Approvals >= rule.Quorum:
+		outcome = DecisionApproved
+	case state.Rejections >= rule.Quorum:
+		outcome = DecisionRejected
+	default:
+		return ticket, state, false, nil
+	}
+
+	// Begründungen der ausschlaggebenden Stimmen, bei der Ablehnung werden sie dem Bewerber geschickt
+	var reasons []string
+	for _, v := range state.Votes {
+		if v.Vote == outcome && v.Reason != "" {
+			reasons = append(reasons, v.Reason)
+		}
+	}
+	state.Reason = strings.Join(reasons, "\n")
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	defer tx.Rollback()
+	// Die Bedingung auf ticket_decision verhindert, dass zwei gleichzeitige Stimmen beide entscheiden
+	res, err := tx.Exec(`UPDATE tickets SET ticket_decision = ?, ticket_decision_at = ?, ticket_decision_reason = ?
+		WHERE ticket_id = ? AND ticket_decision IS NULL`, string(outcome), now, nullIfEmpty(state.Reason), ticketID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ticket, state, false, ErrAlreadyDecided
+	}
+	details := fmt.Sprintf("%s (%d:%d)", outcome, state.Approvals, state.Rejections)
+	if err := insertEvent(tx, ticketID, ActionDecide, ticket.Status, ticket.Status, voter, details); err != nil {
+		return nil, nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, false, err
+	}
+	ticket.Decision, state.Decision, state.DecidedAt = outcome, outcome, now
+	return ticket, state, true, nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// Onboard vergibt nach der Annahme die Rollen (siehe ApproveRoleIDs) und trägt den Bewerber ins Team ein (teamID 0 = kein Team).
+// Es werden alle Rollen versucht, der erste Fehler wird zurückgegeben.
+func (s *TicketService) Onboard(ticket *Ticket, roles []string, teamID int) ([]string, error) {
+	guildID := utils.GetIdFromDB(s.bot, "GUILD_ID")
+	var firstErr error
+	if teamID > 0 {
+		var teamRole string
+		if err := s.db.QueryRow(`SELECT role_id FROM team_areas WHERE id = ?`, teamID).Scan(&teamRole); err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: Team %d", ErrNotFound, teamID)
+		} else if err != nil {
+			return nil, err
+		}
+		roles = append(roles, teamRole)
+
+		userID, err := utils.EnsureUser(s.bot, ticket.CreatorID)
+		if err == nil {
+			_, err = s.db.Exec(`INSERT OR IGNORE INTO team_members (team_id, user_id, joined_at) VALUES (?, ?, CURRENT_TIMESTAMP)`, teamID, userID)
+		}
+		firstErr = err
+	}
+
+	granted := []string{}
+	for _, roleID := range roles {
+		if err := s.bot.GuildMemberRoleAdd(guildID, ticket.CreatorID, roleID); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("Rolle %s: %w", roleID, err)
+			}
+			continue
+		}
+		granted = append(granted, roleID)
+	}
+	return granted, firstErr
+}
+
+// Team ist ein aktives Team aus team_areas, zur Auswahl nach der Annahme
+type Team struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Game   string `json:"game"`
+	RoleID string `json:"role_id"`
+}
+
+// GetTeam liefert ein Team aus team_areas, auch wenn es inzwischen inaktiv ist
+func (s *TicketService) GetTeam(teamID int) (*Team, error) {
+	var t Team
+	err := s.db.QueryRow(`SELECT id, team_name, game, role_id FROM team_areas WHERE id = ?`, teamID).Scan(&t.ID, &t.Name, &t.Game, &t.RoleID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: Team %d", ErrNotFound, teamID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ActiveTeams liefert die aktiven Teams sortiert nach Spiel und Name
+func (s *TicketService) ActiveTeams() ([]Team, error) {
+	rows, err := s.db.Query(`SELECT id, team_name, game, role_id FROM team_areas WHERE is_active IN ('1', 'true') ORDER BY game, team_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []Team{}
+	for rows.Next() {
+		var t Team
+		if err := rows.Scan(&t.ID, &t.Name, &t.Game, &t.RoleID); err != nil {
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// FunnelReport fasst die Bewerbungen je Bereich im Zeitraum zusammen
+type FunnelReport struct {
+	Since int64        `json:"since"`
+	Until int64        `json:"until"`
+	Areas []AreaFunnel `json:"areas"`
+}
+
+// AreaFunnel sind die Kennzahlen eines Bewerbungs-Bereichs, Undecided enthält auch ohne Entscheidung geschlossene Tickets
+type AreaFunnel struct {
+	Area               string `json:"area"`
+	Label              string `json:"label"`
+	Applied            int    `json:"applied"`
+	Approved           int    `json:"approved"`
+	Rejected           int    `json:"rejected"`
+	Undecided          int    `json:"undecided"`
+	AvgDecisionSeconds int64  `json:"avg_decision_seconds"`
+}
+
+// FunnelReport zählt Bewerbungen in Bereichen mit Entscheidungs-Regel (auch über den Oberbereich) bzw. mit Entscheidung
+func (s *TicketService) FunnelReport(since, now time.Time) (*FunnelReport, error) {
+	rows, err := s.db.Query(`
+		SELECT COALESCE(t.ticket_bereich, ''), COALESCE(a.label, t.ticket_bereich, ''), COALESCE(t.ticket_erstellungszeit, 0),
+			COALESCE(t.ticket_decision, ''), COALESCE(t.ticket_decision_at, 0)
+		FROM tickets t `+slaAreaJoin+`
+		LEFT JOIN ticket_decision_rules r ON r.area_key = t.ticket_bereich
+		LEFT JOIN ticket_decision_rules pr ON pr.area_key = a.parent_key
+		WHERE t.ticket_erstellungszeit >= ? AND (r.area_key IS NOT NULL OR pr.area_key IS NOT NULL OR t.ticket_decision IS NOT NULL)`,
+		since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &FunnelReport{Since: since.Unix(), Until: now.Unix(), Areas: []AreaFunnel{}}
+	areas := make(map[string]*AreaFunnel)
+	sums := make(map[string]int64)
+	for rows.Next() {
+		var key, label, decision string
+		var created, decidedAt int64
+		if err := rows.Scan(&key, &label, &created, &decision, &decidedAt); err != nil {
+			return nil, err
+		}
+		area := areas[key]
+		if area == nil {
+			area = &AreaFunnel{Area: key, Label: label}
+			areas[key] = area
+		}
+		area.Applied++
+		switch Decision(decision) {
+		case DecisionApproved:
+			area.Approved++
+		case DecisionRejected:
+			area.Rejected++
+		default:
+			area.Undecided++
+			continue
+		}
+		if created > 0 && decidedAt >= created {
+			sums[key] += decidedAt - created
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for key, area := range areas {
+		if decided := area.Approved + area.Rejected; decided > 0 {
+			area.AvgDecisionSeconds = sums[key] / int64(decided)
+		}
+		report.Areas = append(report.Areas, *area)
+	}
+	sort.Slice(report.Areas, func(i, j int) bool {
+		if report.Areas[i].Applied != report.Areas[j].Applied {
+			return report.Areas[i].Applied > report.Areas[j].Applied
+		}
+		return report.Areas[i].Area < report.Areas[j].Area
+	})
+	return report, nil
+}
@@ -0,0 +1,217 @@
+package tickets
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Bewertungen der Zufriedenheits-Umfrage, ab SatisfiedRating zählt eine Antwort als zufrieden (CSAT)
+const (
+	MinRating       = 1
+	MaxRating       = 5
+	SatisfiedRating = 4
+)
+
+// ErrNotCreator wird geliefert, wenn jemand anderes als der Ersteller das Ticket bewerten will
+var ErrNotCreator = errors.New("Nur der Ersteller kann das Ticket bewerten")
+
+// Feedback ist die Bewertung eines Tickets aus ticket_feedback, Rating 0 = noch nicht beantwortet
+type Feedback struct {
+	TicketID    int    `json:"ticket_id"`
+	UserID      string `json:"user_id"`
+	Area        string `json:"area"`
+	ClaimerID   string `json:"claimer_id"`
+	ClaimerName string `json:"claimer_name"`
+	Rating      int    `json:"rating"`
+	Comment     string `json:"comment"`
+	RequestedAt int64  `json:"requested_at"`
+	AnsweredAt  int64  `json:"answered_at"`
+}
+
+// FeedbackReport fasst die Bewertungen der im Zeitraum verschickten Umfragen zusammen
+type FeedbackReport struct {
+	Since      int64           `json:"since"`
+	Until      int64           `json:"until"`
+	Requested  int             `json:"requested"`
+	Total      FeedbackGroup   `json:"total"`
+	Areas      []FeedbackGroup `json:"areas"`
+	Moderators []FeedbackGroup `json:"moderators"`
+	Comments   []Feedback      `json:"comments"` // die letzten Kommentare, neueste zuerst
+}
+
+// FeedbackGroup sind die Kennzahlen eines Bereichs bzw. Bearbeiters, CSAT in Prozent
+type FeedbackGroup struct {
+	Key       string  `json:"key"`
+	Label     string  `json:"label"`
+	Responses int     `json:"responses"`
+	Average   float64 `json:"average"`
+	Satisfied int     `json:"satisfied"`
+	CSAT      float64 `json:"csat"`
+	Ratings   [5]int  `json:"ratings"` // Anzahl je Bewertung 1-5
+}
+
+const maxReportComments = 10
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// RequestFeedback merkt sich den Versand der Umfrage für ein Ticket mit dem aktuellen Bearbeiter und Bereich.
+// false = für das Ticket wurde bereits gefragt (z.B. nach erneutem Öffnen und Schließen)
+func (s *TicketService) RequestFeedback(ticket *Ticket) (bool, error) {
+	res, err := s.db.Exec(`
+		INSERT INTO ticket_feedback (ticket_id, user_id, area_key, claimer_id, claimer_name, requested_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(ticket_id) DO NOTHING`,
+		ticket.ID, ticket.CreatorID, nullIfEmpty(ticket.Area), nullIfEmpty(ticket.ClaimerID), nullIfEmpty(ticket.ClaimerName), time.Now().Unix())
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Feedback liefert die Bewertung eines Tickets
+func (s *TicketService) Feedback(ticketID int) (*Feedback, error) {
+	var f Feedback
+	err := s.db.QueryRow(`
+		SELECT ticket_id, user_id, COALESCE(area_key, ''), COALESCE(claimer_id, ''), COALESCE(claimer_name, ''),
+			COALESCE(rating, 0), COALESCE(comment, ''), requested_at, COALESCE(answered_at, 0)
+		FROM ticket_feedback WHERE ticket_id = ?`, ticketID).Scan(&f.TicketID, &f.UserID, &f.Area, &f.ClaimerID, &f.ClaimerName,
+		&f.Rating, &f.Comment, &f.RequestedAt, &f.AnsweredAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: Bewertung für Ticket %d", ErrNotFound, ticketID)
+	}
+	return &f, err
+}
+
+// RateTicket speichert die Bewertung des Erstellers, eine erneute Antwort überschreibt die vorherige
+func (s *TicketService) RateTicket(ticketID int, userID string, rating int) (*Feedback, error) {
+	if rating < MinRating || rating > MaxRating {
+		return nil, fmt.Errorf("%w: Bewertung muss zwischen %d und %d liegen", ErrInvalidInput, MinRating, MaxRating)
+	}
+	feedback, err := s.Feedback(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if feedback.UserID != userID {
+		return nil, ErrNotCreator
+	}
+	feedback.Rating, feedback.AnsweredAt = rating, time.Now().Unix()
+	_, err = s.db.Exec(`UPDATE ticket_feedback SET rating = ?, answered_at = ? WHERE ticket_id = ?`, feedback.Rating, feedback.AnsweredAt, ticketID)
+	return feedback, err
+}
+
+// CommentTicket speichert den optionalen Kommentar zur Bewertung
+func (s *TicketService) CommentTicket(ticketID int, userID, comment string) (*Feedback, error) {
+	feedback, err := s.Feedback(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if feedback.UserID != userID {
+		return nil, ErrNotCreator
+	}
+	feedback.Comment = strings.TrimSpace(comment)
+	_, err = s.db.Exec(`UPDATE ticket_feedback SET comment = ? WHERE ticket_id = ?`, nullIfEmpty(feedback.Comment), ticketID)
+	return feedback, err
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// FeedbackReport liefert Durchschnitt und CSAT je Bereich und Bearbeiter für die seit since verschickten Umfragen
+func (s *TicketService) FeedbackReport(since, now time.Time) (*FeedbackReport, error) {
+	rows, err := s.db.Query(`
+		SELECT f.ticket_id, f.user_id, COALESCE(f.area_key, ''), COALESCE(a.label, f.area_key, ''), COALESCE(f.claimer_id, ''),
+			COALESCE(f.claimer_name, ''), COALESCE(f.rating, 0), COALESCE(f.comment, ''), f.requested_at, COALESCE(f.answered_at, 0)
+		FROM ticket_feedback f
+		LEFT JOIN ticket_areas a ON a.area_key = f.area_key
+		WHERE f.requested_at >= ?
+		ORDER BY f.answered_at DESC`, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &FeedbackReport{Since: since.Unix(), Until: now.Unix(), Areas: []FeedbackGroup{}, Moderators: []FeedbackGroup{}, Comments: []Feedback{}}
+	areas := make(map[string]*FeedbackGroup)
+	moderators := make(map[string]*FeedbackGroup)
+	for rows.Next() {
+		var f Feedback
+		var label string
+		if err := rows.Scan(&f.TicketID, &f.UserID, &f.Area, &label, &f.ClaimerID, &f.ClaimerName, &f.Rating, &f.Comment,
+			&f.RequestedAt, &f.AnsweredAt); err != nil {
+			return nil, err
+		}
+		report.Requested++
+		if f.Rating < MinRating || f.Rating > MaxRating {
+			continue
+		}
+		report.Total.add(f.Rating)
+
+		area := areas[f.Area]
+		if area == nil {
+			area = &FeedbackGroup{Key: f.Area, Label: label}
+			areas[f.Area] = area
+		}
+		area.add(f.Rating)
+
+		// Ohne Bearbeiter geschlossene Tickets fließen nur in Bereich und Gesamtwert ein
+		if f.ClaimerID != "" {
+			moderator := moderators[f.ClaimerID]
+			if moderator == nil {
+				moderator = &FeedbackGroup{Key: f.ClaimerID, Label: f.ClaimerName}
+				moderators[f.ClaimerID] = moderator
+			}
+			moderator.add(f.Rating)
+		}
+		if f.Comment != "" && len(report.Comments) < maxReportComments {
+			report.Comments = append(report.Comments, f)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report.Total.finish()
+	report.Areas = sortedFeedbackGroups(areas)
+	report.Moderators = sortedFeedbackGroups(moderators)
+	return report, nil
+}
+
+// add zählt eine Bewertung, Durchschnitt und CSAT werden in finish berechnet
+func (g *FeedbackGroup) add(rating int) {
+	g.Responses++
+	g.Ratings[rating-1]++
+	if rating >= SatisfiedRating {
+		g.Satisfied++
+	}
+}
+
+func (g *FeedbackGroup) finish() {
+	if g.Responses == 0 {
+		return
+	}
+	sum := 0
+	for i, count := range g.Ratings {
+		sum += (i + 1) * count
+	}
+	g.Average = float64(sum) / float64(g.Responses)
+	g.CSAT = float64(g.Satisfied) * 100 / float64(g.Responses)
+}
+
+// sortedFeedbackGroups sortiert nach Anzahl der Antworten, dann nach Schlüssel
+func sortedFeedbackGroups(groups map[string]*FeedbackGroup) []FeedbackGroup {
+	out := make([]FeedbackGroup, 0, len(groups))
+	for _, g := range groups {
+		g.finish()
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Responses != out[j].Responses {
+			return out[i].Responses > out[j].Responses
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
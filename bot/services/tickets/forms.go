@@ -0,0 +1,271 @@
+package tickets
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Zwischenstände mehrstufiger Formulare verfallen nach 24 Stunden
+const draftTTL = 24 * time.Hour
+
+// Unterstützte Prüfungen für Formularfelder
+const (
+	ValidationNone   = ""
+	ValidationNumber = "number"
+	ValidationURL    = "url"
+	ValidationRegex  = "regex"
+)
+
+// Answer ist eine gespeicherte Antwort aus dem Ticket-Formular (ticket_answers)
+type Answer struct {
+	Position int    `json:"position"`
+	Key      string `json:"key"`
+	Label    string `json:"label"`
+	Value    string `json:"value"`
+}
+
+var umlautReplacer = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss")
+var nonKeyChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// fieldKeyFromLabel bildet einen Feld-Schlüssel aus dem Label, z.B. "Dein Main Game" -> dein_main_game
+func fieldKeyFromLabel(label string, position int) string {
+	key := umlautReplacer.Replace(strings.ToLower(label))
+	key = strings.Trim(nonKeyChars.ReplaceAllString(key, "_"), "_")
+	if len(key) > 40 {
+		key = strings.TrimRight(key[:40], "_")
+	}
+	if key == "" {
+		key = "feld_" + strconv.Itoa(position)
+	}
+	return key
+}
+
+// validateRule prüft die Konfiguration der Eingabeprüfung eines Feldes
+func (f *Field) validateRule() error {
+	f.Validation = strings.ToLower(strings.TrimSpace(f.Validation))
+	switch f.Validation {
+	case ValidationNone, ValidationNumber, ValidationURL:
+	case ValidationRegex:
+		if f.Pattern == "" {
+			return fmt.Errorf("%w: Feld %s benötigt ein Muster für regex", ErrInvalidInput, f.Label)
+		}
+	default:
+		return fmt.Errorf("%w: Prüfung muss number, url oder regex sein", ErrInvalidInput)
+	}
+	if f.Pattern != "" {
+		if _, err := regexp.Compile(f.Pattern); err != nil {
+			return fmt.Errorf("%w: ungültiges Muster für %s: %v", ErrInvalidInput, f.Label, err)
+		}
+	}
+	if f.MinValue != nil && f.MaxValue != nil && *f.MinValue > *f.MaxValue {
+		return fmt.Errorf("%w: Mindestwert von %s ist größer als der Höchstwert", ErrInvalidInput, f.Label)
+	}
+	return nil
+}
+
+// ValidateAnswer prüft eine Eingabe gegen die Regeln des Feldes und liefert eine Meldung für den User
+func (f *Field) ValidateAnswer(value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		if f.Required {
+			return f.answerError(fmt.Sprintf("**%s** ist ein Pflichtfeld.", f.Label))
+		}
+		return nil
+	}
+
+	// Min-/Maxwerte gelten auch ohne explizite number-Prüfung (z.B. Alter bei Pro Teams)
+	if f.Validation == ValidationNumber || f.MinValue != nil || f.MaxValue != nil {
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return f.answerError(fmt.Sprintf("**%s** muss eine Zahl sein.", f.Label))
+		}
+		if f.MinValue != nil && number < *f.MinValue {
+			return f.answerError(fmt.Sprintf("**%s** muss mindestens %d sein.", f.Label, *f.MinValue))
+		}
+		if f.MaxValue != nil && number > *f.MaxValue {
+			return f.answerError(fmt.Sprintf("**%s** darf höchstens %d sein.", f.Label, *f.MaxValue))
+		}
+	}
+
+	switch f.Validation {
+	case ValidationURL:
+		parsed, err := url.ParseRequestURI(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return f.answerError(fmt.Sprintf("**%s** muss ein Link (http/https) sein.", f.Label))
+		}
+	case ValidationRegex:
+		if matched, _ := regexp.MatchString(f.Pattern, value); !matched {
+			return f.answerError(fmt.Sprintf("**%s** hat nicht das erwartete Format.", f.Label))
+		}
+	}
+	return nil
+}
+
+// answerError bevorzugt die im Feld hinterlegte Fehlermeldung
+func (f *Field) answerError(fallback string) error {
+	if f.ErrorMessage != "" {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, f.ErrorMessage)
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidInput, fallback)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// StepCount liefert die Anzahl der Modals, auf die das Formular verteilt wird
+func (a *Area) StepCount() int {
+	if len(a.Fields) == 0 {
+		return 1
+	}
+	return (len(a.Fields) + MaxModalFields - 1) / MaxModalFields
+}
+
+// StepFields liefert die Felder des Schritts (1-basiert)
+func (a *Area) StepFields(step int) []Field {
+	start := (step - 1) * MaxModalFields
+	if step < 1 || start >= len(a.Fields) {
+		return nil
+	}
+	end := start + MaxModalFields
+	if end > len(a.Fields) {
+		end = len(a.Fields)
+	}
+	return a.Fields[start:end]
+}
+
+// Answers ordnet die Eingaben (Feld-Schlüssel -> Wert) den Feldern des Formulars zu
+func (a *Area) Answers(values map[string]string) []Answer {
+	answers := make([]Answer, 0, len(a.Fields))
+	for _, f := range a.Fields {
+		answers = append(answers, Answer{Position: f.Position, Key: f.Key, Label: f.Label, Value: strings.TrimSpace(values[f.Key])})
+	}
+	return answers
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// GetDraft liefert die bisherigen Eingaben eines Users für ein mehrstufiges Formular
+func (s *AreaService) GetDraft(userID, areaKey string) (map[string]string, error) {
+	values := make(map[string]string)
+	var raw string
+	err := s.db.QueryRow(`SELECT answers FROM ticket_drafts WHERE user_id = ? AND area_key = ? AND updated_at >= ?`,
+		userID, areaKey, time.Now().Add(-draftTTL).Unix()).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// SaveDraft speichert den Zwischenstand und räumt abgelaufene Entwürfe auf
+func (s *AreaService) SaveDraft(userID, areaKey string, values map[string]string) error {
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if _, err := s.db.Exec(`DELETE FROM ticket_drafts WHERE updated_at < ?`, now.Add(-draftTTL).Unix()); err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO ticket_drafts (user_id, area_key, answers, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id, area_key) DO UPDATE SET answers = excluded.answers, updated_at = excluded.updated_at`,
+		userID, areaKey, string(raw), now.Unix())
+	return err
+}
+
+// DeleteDraft entfernt den Zwischenstand nach dem Absenden
+func (s *AreaService) DeleteDraft(userID, areaKey string) error {
+	_, err := s.db.Exec(`DELETE FROM ticket_drafts WHERE user_id = ? AND area_key = ?`, userID, areaKey)
+	return err
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// SaveAnswers speichert alle Formular-Antworten eines Tickets
+func (s *AreaService) SaveAnswers(ticketID int64, answers []Answer) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM ticket_answers WHERE ticket_id = ?`, ticketID); err != nil {
+		return err
+	}
+	for _, answer := range answers {
+		_, err := tx.Exec(`INSERT INTO ticket_answers (ticket_id, position, field_key, label, value) VALUES (?, ?, ?, ?, ?)`,
+			ticketID, answer.Position, answer.Key, answer.Label, answer.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// TicketAnswers liefert die gespeicherten Formular-Antworten eines Tickets
+func (s *AreaService) TicketAnswers(ticketID int64) ([]Answer, error) {
+	return ticketAnswers(s.db, ticketID)
+}
+
+// legacyFieldKeys sind die festen Spalten aus der Zeit vor ticket_answers, neue Tickets schreiben sie nur noch als Kopie fürs Webinterface
+var legacyFieldKeys = []string{"field_one", "field_two", "field_three", "field_four", "field_five"}
+
+// ticketAnswers liest ticket_answers, ältere Tickets ohne Einträge aus den festen Spalten ticket_modal_field_*.
+// Deren Beschriftung kommt aus den Feldern des Bereichs, sofern es ihn noch gibt, sonst "Feld N".
+func ticketAnswers(db *sql.DB, ticketID int64) ([]Answer, error) {
+	rows, err := db.Query(`SELECT position, field_key, label, value FROM ticket_answers WHERE ticket_id = ? ORDER BY position`, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	answers := []Answer{}
+	for rows.Next() {
+		var answer Answer
+		if err := rows.Scan(&answer.Position, &answer.Key, &answer.Label, &answer.Value); err != nil {
+			return nil, err
+		}
+		answers = append(answers, answer)
+	}
+	if err := rows.Err(); err != nil || len(answers) > 0 {
+		return answers, err
+	}
+
+	var areaKey sql.NullString
+	var fields [5]sql.NullString
+	err = db.QueryRow(`SELECT ticket_bereich, ticket_modal_field_one, ticket_modal_field_two, ticket_modal_field_three, ticket_modal_field_four, ticket_modal_field_five
+		FROM tickets WHERE ticket_id = ?`, ticketID).Scan(&areaKey, &fields[0], &fields[1], &fields[2], &fields[3], &fields[4])
+	if err == sql.ErrNoRows {
+		return answers, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Die festen Spalten wurden in der Reihenfolge der Formularfelder befüllt
+	areaFields, err := (&AreaService{db: db}).fields(areaKey.String)
+	if err != nil {
+		return nil, err
+	}
+	for i, field := range fields {
+		if field.String == "" {
+			continue
+		}
+		answer := Answer{Position: i + 1, Key: legacyFieldKeys[i], Label: fmt.Sprintf("Feld %d", i+1), Value: field.String}
+		if i < len(areaFields) {
+			answer.Key, answer.Label = areaFields[i].Key, areaFields[i].Label
+		}
+		answers = append(answers, answer)
+	}
+	return answers, nil
+}
@@ -0,0 +1,93 @@
+package tickets
+
+import "time"
+
+// InactivityPolicy ist die Inaktivitätsregel eines Bereichs (bzw. seines Oberbereichs)
+type InactivityPolicy struct {
+	WarnDays  int  `json:"warn_days"`
+	CloseDays int  `json:"close_days"`
+	Delete    bool `json:"delete"`
+}
+
+// InactiveTicket ist ein offenes Ticket, bei dem die nächste Inaktivitätsstufe fällig ist
+type InactiveTicket struct {
+	Ticket       Ticket           `json:"ticket"`
+	Policy       InactivityPolicy `json:"policy"`
+	LastActivity int64            `json:"last_activity"` // letzte Nachricht des Erstellers, Erstellung oder "Offen halten"
+	WarnedAt     int64            `json:"warned_at"`
+}
+
+// Unterbereiche ohne eigene Regel übernehmen die Regel des Oberbereichs als Ganzes
+const inactivityPolicyColumns = `
+	CASE WHEN COALESCE(a.inactivity_warn_days, 0) > 0 THEN a.inactivity_warn_days ELSE COALESCE(p.inactivity_warn_days, 0) END,
+	CASE WHEN COALESCE(a.inactivity_warn_days, 0) > 0 THEN COALESCE(a.inactivity_close_days, 0) ELSE COALESCE(p.inactivity_close_days, 0) END,
+	CASE WHEN COALESCE(a.inactivity_warn_days, 0) > 0 THEN COALESCE(a.inactivity_delete, 0) ELSE COALESCE(p.inactivity_delete, 0) END`
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// DueInactivity liefert offene Tickets, die gewarnt (X Tage ohne Nachricht des Erstellers) bzw.
+// geschlossen werden müssen (Y Tage nach der Warnung ohne neue Nachricht oder "Offen halten")
+func (s *TicketService) DueInactivity(now time.Time) (warnings, closings []InactiveTicket, err error) {
+	rows, err := s.db.Query(`
+		SELECT `+slaTicketColumns+`, MAX(COALESCE(t.ticket_erstellungszeit, 0), COALESCE(sla.reopened_at, 0)), `+inactivityPolicyColumns+`,
+			COALESCE(sla.last_creator_message, 0), COALESCE(sla.inactivity_warned_at, 0), COALESCE(sla.inactivity_kept_at, 0),
+			COALESCE(t.ticket_modmail, 0)
+		FROM tickets t `+slaAreaJoin+`
+		LEFT JOIN ticket_sla sla ON sla.ticket_id = t.ticket_id
+		WHERE t.ticket_status IN (?, ?)`, string(StatusOpen), string(StatusClaimed))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t Ticket
+		var status string
+		var policy InactivityPolicy
+		var deleteFlag int
+		var created, lastMessage, warnedAt, keptAt int64
+		if err := rows.Scan(&t.ID, &status, &t.Area, &t.ChannelID, &t.CreatorID, &t.CreatorName, &t.ClaimerID, &t.ClaimerName,
+			&t.CloserID, &t.CloserName, &created, &policy.WarnDays, &policy.CloseDays, &deleteFlag, &lastMessage, &warnedAt, &keptAt,
+			&t.Modmail); err != nil {
+			return nil, nil, err
+		}
+		if policy.WarnDays <= 0 || policy.CloseDays <= 0 {
+			continue
+		}
+		policy.Delete = deleteFlag != 0
+		t.Status, t.rawStatus = ParseStatus(status), status
+
+		lastActivity := max(created, lastMessage, keptAt)
+		if lastActivity <= 0 {
+			continue
+		}
+		entry := InactiveTicket{Ticket: t, Policy: policy, LastActivity: lastActivity, WarnedAt: warnedAt}
+		if warnedAt < lastActivity {
+			// Noch nicht gewarnt oder seit der Warnung wieder aktiv
+			if now.Sub(time.Unix(lastActivity, 0)) >= time.Duration(policy.WarnDays)*24*time.Hour {
+				warnings = append(warnings, entry)
+			}
+		} else if now.Sub(time.Unix(warnedAt, 0)) >= time.Duration(policy.CloseDays)*24*time.Hour {
+			closings = append(closings, entry)
+		}
+	}
+	return warnings, closings, rows.Err()
+}
+
+// MarkInactivityWarned speichert den Zeitpunkt der Inaktivitäts-Warnung
+func (s *TicketService) MarkInactivityWarned(ticketID int, at time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT INTO ticket_sla (ticket_id, inactivity_warned_at) VALUES (?, ?)
+		ON CONFLICT(ticket_id) DO UPDATE SET inactivity_warned_at = excluded.inactivity_warned_at`,
+		ticketID, at.Unix())
+	return err
+}
+
+// KeepOpen setzt die Inaktivitätsfrist zurück ("Offen halten"-Button)
+func (s *TicketService) KeepOpen(ticketID int, at time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT INTO ticket_sla (ticket_id, inactivity_kept_at) VALUES (?, ?)
+		ON CONFLICT(ticket_id) DO UPDATE SET inactivity_kept_at = excluded.inactivity_kept_at, inactivity_warned_at = 0`,
+		ticketID, at.Unix())
+	return err
+}
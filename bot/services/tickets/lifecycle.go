@@ -0,0 +1,346 @@
+package tickets
+
+import (
+	"bot/database"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Status ist der Zustand eines Tickets, die Werte entsprechen ticket_status in der Datenbank
+type Status string
+
+const (
+	StatusOpen     Status = "Open"
+	StatusClaimed  Status = "Claimed"
+	StatusClosed   Status = "Closed"
+	StatusUserLeft Status = "UserLeft"
+	StatusDeleted  Status = "Deleted"
+)
+
+// Action ist ein Übergang zwischen zwei Zuständen, wird in ticket_events gespeichert
+type Action string
+
+const (
+	ActionCreate       Action = "create"
+	ActionClaim        Action = "claim"
+	ActionAssign       Action = "assign"
+	ActionClose        Action = "close"
+	ActionReopen       Action = "reopen"
+	ActionDelete       Action = "delete"
+	ActionUserLeft     Action = "user_left"
+	ActionUserReturned Action = "user_returned"
+	ActionDecide       Action = "decide" // Entscheidung über eine Bewerbung, ändert den Status nicht
+	ActionMove         Action = "move"   // Wechsel des Bereichs, ändert den Status nicht
+
+	// Zusätzliche Teilnehmer, ändern den Status nicht
+	ActionParticipantAdd    Action = "participant_add"
+	ActionParticipantRemove Action = "participant_remove"
+)
+
+// ErrInvalidTransition wird geliefert, wenn die Aktion im aktuellen Zustand nicht erlaubt ist
+var ErrInvalidTransition = errors.New("Aktion im aktuellen Ticket-Status nicht erlaubt")
+
+// Erlaubte Übergänge je Zustand. Reopen führt zu Claimed, wenn das Ticket bereits bearbeitet wurde, sonst zu Open.
+// UserReturned stellt den Zustand vor dem Verlassen des Servers wieder her.
+var transitions = map[Status]map[Action]Status{
+	StatusOpen: {
+		ActionClaim:    StatusClaimed,
+		ActionAssign:   StatusClaimed,
+		ActionClose:    StatusClosed,
+		ActionDelete:   StatusDeleted,
+		ActionUserLeft: StatusUserLeft,
+	},
+	StatusClaimed: {
+		ActionAssign:   StatusClaimed,
+		ActionClose:    StatusClosed,
+		ActionDelete:   StatusDeleted,
+		ActionUserLeft: StatusUserLeft,
+	},
+	StatusClosed: {
+		ActionReopen:   StatusOpen,
+		ActionDelete:   StatusDeleted,
+		ActionUserLeft: StatusUserLeft,
+	},
+	StatusUserLeft: {
+		ActionClose:        StatusClosed,
+		ActionDelete:       StatusDeleted,
+		ActionUserReturned: StatusOpen,
+	},
+	StatusDeleted: {},
+}
+
+// TicketService kapselt Zustand und Verlauf der Tickets
+type TicketService struct {
+	bot *discordgo.Session
+	db  *sql.DB
+}
+
+// Actor ist der Auslöser eines Übergangs, leer = System
+type Actor struct {
+	ID   string
+	Name string
+}
+
+// Ticket enthält die für Zustandswechsel relevanten Spalten aus tickets
+type Ticket struct {
+	ID          int      `json:"id"`
+	Status      Status   `json:"status"`
+	Area        string   `json:"area"`
+	ChannelID   string   `json:"channel_id"`
+	CreatorID   string   `json:"creator_id"`
+	CreatorName string   `json:"creator_name"`
+	ClaimerID   string   `json:"claimer_id"`
+	ClaimerName string   `json:"claimer_name"`
+	CloserID    string   `json:"closer_id"`
+	CloserName  string   `json:"closer_name"`
+	Priority    Priority `json:"priority"`
+	Tags        []string `json:"tags"`
+	Decision    Decision `json:"decision"` // leer = keine Entscheidung (bzw. kein Bewerbungs-Bereich)
+	Modmail     bool     `json:"modmail"`  // per DM eröffnet, der Ersteller hat keinen Zugriff auf den Channel
+
+	rawStatus string // ticket_status wie in der Datenbank, für die Prüfung beim Update
+}
+
+// Event ist ein Eintrag aus ticket_events
+type Event struct {
+	ID         int    `json:"id"`
+	TicketID   int    `json:"ticket_id"`
+	Action     Action `json:"action"`
+	FromStatus Status `json:"from_status"`
+	ToStatus   Status `json:"to_status"`
+	ActorID    string `json:"actor_id"`
+	ActorName  string `json:"actor_name"`
+	Details    string `json:"details"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+func NewTicketService(bot *discordgo.Session) *TicketService {
+	return &TicketService{
+		bot: bot,
+		db:  database.DB,
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// ParseStatus liest ticket_status tolerant ein (ältere Tickets stehen z.B. auf "open")
+func ParseStatus(value string) Status {
+	for status := range transitions {
+		if strings.EqualFold(value, string(status)) {
+			return status
+		}
+	}
+	return StatusOpen
+}
+
+// Can prüft, ob die Aktion im Zustand erlaubt ist
+func (s Status) Can(action Action) bool {
+	_, ok := transitions[s][action]
+	return ok
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+const ticketColumns = `ticket_id, COALESCE(ticket_status, ''), COALESCE(ticket_bereich, ''), COALESCE(ticket_channel_id, ''),
+	COALESCE(ticket_ersteller_id, ''), COALESCE(ticket_ersteller_name, ''), COALESCE(ticket_bearbeiter_id, ''),
+	COALESCE(ticket_bearbeiter_name, ''), COALESCE(ticket_schliesser_id, ''), COALESCE(ticket_schliesser_name, ''),
+	COALESCE(ticket_priority, ''), COALESCE((SELECT GROUP_CONCAT(tag) FROM ticket_tags tt WHERE tt.ticket_id = tickets.ticket_id), ''),
+	COALESCE(ticket_decision, ''), COALESCE(ticket_modmail, 0)`
+
+// scanTicket liest eine Zeile aus ticketColumns
+func scanTicket(scanner interface{ Scan(...interface{}) error }) (*Ticket, error) {
+	var t Ticket
+	var status, priority, tags, decision string
+	err := scanner.Scan(&t.ID, &status, &t.Area, &t.ChannelID, &t.CreatorID, &t.CreatorName, &t.ClaimerID, &t.ClaimerName,
+		&t.CloserID, &t.CloserName, &priority, &tags, &decision, &t.Modmail)
+	if err != nil {
+		return nil, err
+	}
+	t.Status, t.rawStatus = ParseStatus(status), status
+	t.Priority = ParsePriority(priority)
+	t.Tags = splitTags(tags)
+	t.Decision = Decision(decision)
+	return &t, nil
+}
+
+type rowQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func getTicket(q rowQuerier, ticketID int) (*Ticket, error) {
+	t, err := scanTicket(q.QueryRow(`SELECT `+ticketColumns+` FROM tickets WHERE ticket_id = ?`, ticketID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return t, err
+}
+
+// GetTicket liefert ein Ticket per ID
+func (s *TicketService) GetTicket(ticketID int) (*Ticket, error) {
+	return getTicket(s.db, ticketID)
+}
+
+// TranscriptKey liefert den Storage-Schlüssel des JSON-Transkripts, leer solange das Ticket nicht gelöscht wurde.
+// Ältere Tickets speichern noch den Pfad ("./transcripts/<id>_<name>.json"), der Dateiname ist dort der Schlüssel.
+func (s *TicketService) TranscriptKey(ticketID int) (string, error) {
+	var stored sql.NullString
+	err := s.db.QueryRow(`SELECT ticket_transcript FROM tickets WHERE ticket_id = ?`, ticketID).Scan(&stored)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil || stored.String == "" {
+		return "", err
+	}
+	return path.Base(strings.ReplaceAll(stored.String, "\\", "/")), nil
+}
+
+// ClearTranscript entfernt den Verweis auf ein gelöschtes Transkript (Aufbewahrungsfrist abgelaufen)
+func (s *TicketService) ClearTranscript(key string) error {
+	_, err := s.db.Exec(`UPDATE tickets SET ticket_transcript = NULL WHERE ticket_transcript = ? OR ticket_transcript = ?`, key, "./transcripts/"+key)
+	return err
+}
+
+// RecordCreated schreibt das Anlegen eines Tickets in den Verlauf
+func (s *TicketService) RecordCreated(ticketID int, actor Actor) error {
+	return insertEvent(s.db, ticketID, ActionCreate, "", StatusOpen, actor, "")
+}
+
+// Claim übernimmt das Ticket für den Auslöser
+func (s *TicketService) Claim(ticketID int, actor Actor) (*Ticket, error) {
+	return s.transition(ticketID, ActionClaim, actor, &actor)
+}
+
+// Assign weist das Ticket einem Teammitglied zu (auch erneut bei bereits bearbeiteten Tickets)
+func (s *TicketService) Assign(ticketID int, actor, assignee Actor) (*Ticket, error) {
+	return s.transition(ticketID, ActionAssign, actor, &assignee)
+}
+
+// Close schließt das Ticket
+func (s *TicketService) Close(ticketID int, actor Actor) (*Ticket, error) {
+	return s.transition(ticketID, ActionClose, actor, nil)
+}
+
+// Reopen öffnet ein geschlossenes Ticket wieder, der bisherige Schließer bleibt erhalten
+func (s *TicketService) Reopen(ticketID int, actor Actor) (*Ticket, error) {
+	return s.transition(ticketID, ActionReopen, actor, nil)
+}
+
+// Delete markiert das Ticket als gelöscht
+func (s *TicketService) Delete(ticketID int, actor Actor) (*Ticket, error) {
+	return s.transition(ticketID, ActionDelete, actor, nil)
+}
+
+// MarkUserLeft markiert das Ticket, weil der Ersteller den Server verlassen hat
+func (s *TicketService) MarkUserLeft(ticketID int) (*Ticket, error) {
+	return s.transition(ticketID, ActionUserLeft, Actor{}, nil)
+}
+
+// transition prüft den Übergang, aktualisiert tickets und schreibt ticket_events in einer Transaktion
+func (s *TicketService) transition(ticketID int, action Action, actor Actor, assignee *Actor) (*Ticket, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ticket, err := getTicket(tx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	from := ticket.Status
+	to, ok := transitions[from][action]
+	if !ok {
+		return ticket, fmt.Errorf("%w: %s bei Status %s", ErrInvalidTransition, action, from)
+	}
+	if action == ActionReopen && ticket.ClaimerID != "" {
+		to = StatusClaimed
+	}
+	if action == ActionUserReturned {
+		to = statusBeforeUserLeft(tx, ticket)
+	}
+
+	now := time.Now().Unix()
+	query := `UPDATE tickets SET ticket_status = ?`
+	args := []interface{}{string(to)}
+	details := ""
+	switch action {
+	case ActionClaim, ActionAssign:
+		query += `, ticket_bearbeiter_id = ?, ticket_bearbeiter_name = ?, ticket_bearbeitungszeit = ?`
+		args = append(args, assignee.ID, assignee.Name, now)
+		ticket.ClaimerID, ticket.ClaimerName = assignee.ID, assignee.Name
+		details = assignee.ID
+	case ActionClose:
+		query += `, ticket_schliesser_id = ?, ticket_schliesser_name = ?, ticket_schliesszeit = ?`
+		args = append(args, actor.ID, actor.Name, now)
+		ticket.CloserID, ticket.CloserName = actor.ID, actor.Name
+	case ActionDelete:
+		query += `, ticket_loescher_id = ?, ticket_loescher_name = ?, ticket_loeschzeit = ?`
+		args = append(args, actor.ID, actor.Name, now)
+	}
+	// Der Status in der WHERE-Bedingung verhindert, dass parallele Klicks beide durchgehen
+	query += ` WHERE ticket_id = ? AND COALESCE(ticket_status, '') = ?`
+	args = append(args, ticketID, ticket.rawStatus)
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ticket, fmt.Errorf("%w: Ticket wurde zwischenzeitlich geändert", ErrInvalidTransition)
+	}
+
+	if action == ActionReopen {
+		if err := resetSLA(tx, ticketID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := insertEvent(tx, ticketID, action, from, to, actor, details); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	ticket.Status = to
+	return ticket, nil
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertEvent(db execer, ticketID int, action Action, from, to Status, actor Actor, details string) error {
+	_, err := db.Exec(`
+		INSERT INTO ticket_events (ticket_id, action, from_status, to_status, actor_id, actor_name, details, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		ticketID, string(action), nullIfEmpty(string(from)), string(to), nullIfEmpty(actor.ID), nullIfEmpty(actor.Name), nullIfEmpty(details), time.Now().Unix())
+	return err
+}
+
+// Events liefert den Verlauf eines Tickets, älteste zuerst
+func (s *TicketService) Events(ticketID int) ([]Event, error) {
+	rows, err := s.db.Query(`
+		SELECT id, ticket_id, action, COALESCE(from_status, ''), to_status, COALESCE(actor_id, ''), COALESCE(actor_name, ''), COALESCE(details, ''), created_at
+		FROM ticket_events WHERE ticket_id = ? ORDER BY created_at, id`, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var e Event
+		var action, from, to string
+		if err := rows.Scan(&e.ID, &e.TicketID, &action, &from, &to, &e.ActorID, &e.ActorName, &e.Details, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Action, e.FromStatus, e.ToStatus = Action(action), Status(from), Status(to)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,60 @@
+package tickets
+
+import (
+	"database/sql"
+	"strings"
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// CreatorTickets liefert die Tickets eines Erstellers in den angegebenen Zuständen, ohne creatorID die aller Ersteller
+func (s *TicketService) CreatorTickets(creatorID string, statuses ...Status) ([]Ticket, error) {
+	query := `SELECT ` + ticketColumns + ` FROM tickets WHERE ticket_status IN (?` + strings.Repeat(", ?", len(statuses)-1) + `)`
+	args := make([]interface{}, 0, len(statuses)+1)
+	for _, status := range statuses {
+		args = append(args, string(status))
+	}
+	if creatorID != "" {
+		query += ` AND ticket_ersteller_id = ?`
+		args = append(args, creatorID)
+	}
+	rows, err := s.db.Query(query+` ORDER BY ticket_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanTickets(rows)
+}
+
+// MarkUserReturned stellt den Zustand vor dem Verlassen des Servers wieder her, wenn der Ersteller zurückkommt
+func (s *TicketService) MarkUserReturned(ticketID int) (*Ticket, error) {
+	return s.transition(ticketID, ActionUserReturned, Actor{}, nil)
+}
+
+// statusBeforeUserLeft liefert den Zustand vor dem letzten user_left-Übergang, ältere Tickets ohne Verlauf gelten als offen
+func statusBeforeUserLeft(q rowQuerier, ticket *Ticket) Status {
+	var from sql.NullString
+	q.QueryRow(`SELECT from_status FROM ticket_events WHERE ticket_id = ? AND action = ? ORDER BY created_at DESC, id DESC LIMIT 1`,
+		ticket.ID, string(ActionUserLeft)).Scan(&from)
+	switch status := Status(from.String); status {
+	case StatusOpen, StatusClaimed, StatusClosed:
+		return status
+	}
+	if ticket.ClaimerID != "" {
+		return StatusClaimed
+	}
+	return StatusOpen
+}
+
+func scanTickets(rows *sql.Rows) ([]Ticket, error) {
+	defer rows.Close()
+
+	var tickets []Ticket
+	for rows.Next() {
+		t, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, *t)
+	}
+	return tickets, rows.Err()
+}
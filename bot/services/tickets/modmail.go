@@ -0,0 +1,71 @@
+package tickets
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"bot/utils"
+)
+
+// DefaultModmailPrefix leitet Nachrichten im Team-Channel ohne /reply an den Ersteller weiter ("!r Hallo")
+const DefaultModmailPrefix = "!r"
+
+// ModmailAreaKey liefert den Bereich für per DM eröffnete Tickets, leer = Modmail deaktiviert
+func (s *TicketService) ModmailAreaKey() string {
+	return strings.TrimSpace(utils.GetOptionalIdFromDB(s.bot, "TICKET_MODMAIL_AREA", ""))
+}
+
+// ModmailPrefix liefert das Präfix für Antworten im Team-Channel, leer = nur /reply
+func (s *TicketService) ModmailPrefix() string {
+	return strings.TrimSpace(utils.GetOptionalIdFromDB(s.bot, "TICKET_MODMAIL_PREFIX", DefaultModmailPrefix))
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// ModmailTicket liefert das neueste noch offene Modmail-Ticket des Users, geschlossene Tickets werden nicht fortgesetzt
+func (s *TicketService) ModmailTicket(userID string) (*Ticket, error) {
+	t, err := scanTicket(s.db.QueryRow(`
+		SELECT `+ticketColumns+` FROM tickets
+		WHERE ticket_ersteller_id = ? AND ticket_modmail = 1 AND ticket_status IN (?, ?, ?)
+		ORDER BY ticket_id DESC LIMIT 1`,
+		userID, string(StatusOpen), string(StatusClaimed), string(StatusUserLeft)))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return t, err
+}
+
+// CreateModmailTicket legt ein Modmail-Ticket im Bereich an und schreibt es in den Verlauf, der Channel folgt per LinkChannel
+func (s *TicketService) CreateModmailTicket(areaKey string, creator Actor) (*Ticket, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		INSERT INTO tickets (ticket_status, ticket_bereich, ticket_ersteller_id, ticket_ersteller_name, ticket_erstellungszeit, ticket_modmail)
+		VALUES (?, ?, ?, ?, ?, 1)`,
+		string(StatusOpen), areaKey, creator.ID, creator.Name, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	ticketID, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	if err := insertEvent(tx, int(ticketID), ActionCreate, "", StatusOpen, creator, "modmail"); err != nil {
+		return nil, err
+	}
+	ticket, err := getTicket(tx, int(ticketID))
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
@@ -0,0 +1,58 @@
+package tickets
+
+import "fmt"
+
+// Move verschiebt ein offenes oder bearbeitetes Ticket in einen anderen Bereich und schreibt den Wechsel in den Verlauf.
+// Die SLA-Eskalation beginnt neu, damit die Support-Rolle des neuen Bereichs benachrichtigt wird.
+// Geliefert werden das aktualisierte Ticket sowie alter und neuer Bereich.
+func (s *TicketService) Move(ticketID int, areaKey string, actor Actor) (*Ticket, *Area, *Area, error) {
+	areas := NewAreaService(s.bot)
+	to, err := areas.GetArea(areaKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: Bereich %s", ErrNotFound, areaKey)
+	}
+
+	// Tickets liegen immer im untersten Bereich, wie bei der Erstellung über das Sub-Dropdown
+	if children, err := areas.Children(to.Key); err != nil {
+		return nil, nil, nil, err
+	} else if len(children) > 0 {
+		return nil, nil, nil, fmt.Errorf("%w: %s hat Unterbereiche, bitte einen Unterbereich wählen", ErrInvalidInput, to.DisplayName)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer tx.Rollback()
+
+	ticket, err := getTicket(tx, ticketID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if ticket.Status != StatusOpen && ticket.Status != StatusClaimed {
+		return ticket, nil, nil, fmt.Errorf("%w: Verschieben bei Status %s", ErrInvalidTransition, ticket.Status)
+	}
+	if ticket.Area == to.Key {
+		return ticket, nil, nil, fmt.Errorf("%w: Ticket ist bereits im Bereich %s", ErrInvalidInput, to.DisplayName)
+	}
+	// Der alte Bereich kann inzwischen gelöscht sein, dann bleibt nur der Schlüssel
+	from, err := areas.GetArea(ticket.Area)
+	if err != nil {
+		from = &Area{Key: ticket.Area, DisplayName: ticket.Area}
+	}
+
+	if _, err := tx.Exec(`UPDATE tickets SET ticket_bereich = ? WHERE ticket_id = ?`, to.Key, ticketID); err != nil {
+		return nil, nil, nil, err
+	}
+	if _, err := tx.Exec(`UPDATE ticket_sla SET claim_escalation = 0 WHERE ticket_id = ?`, ticketID); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := insertEvent(tx, ticketID, ActionMove, ticket.Status, ticket.Status, actor, from.Key+" -> "+to.Key); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, nil, err
+	}
+	ticket.Area = to.Key
+	return ticket, from, to, nil
+}
@@ -0,0 +1,70 @@
+package tickets
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxNoteLength entspricht dem Limit einer Discord-Nachricht
+const maxNoteLength = 2000
+
+// Note ist eine interne Notiz des Teams, die der Ersteller nicht sieht
+type Note struct {
+	ID         int    `json:"id"`
+	TicketID   int    `json:"ticket_id"`
+	AuthorID   string `json:"author_id"`
+	AuthorName string `json:"author_name"`
+	Content    string `json:"content"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// AddNote speichert eine interne Notiz zum Ticket
+func (s *TicketService) AddNote(ticketID int, author Actor, content string) (*Note, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, fmt.Errorf("%w: Notiz ist leer", ErrInvalidInput)
+	}
+	if len([]rune(content)) > maxNoteLength {
+		return nil, fmt.Errorf("%w: Notiz ist länger als %d Zeichen", ErrInvalidInput, maxNoteLength)
+	}
+	if _, err := s.GetTicket(ticketID); err != nil {
+		return nil, err
+	}
+
+	note := &Note{TicketID: ticketID, AuthorID: author.ID, AuthorName: author.Name, Content: content, CreatedAt: time.Now().Unix()}
+	res, err := s.db.Exec(`INSERT INTO ticket_notes (ticket_id, author_id, author_name, content, created_at) VALUES (?, ?, ?, ?, ?)`,
+		note.TicketID, note.AuthorID, nullIfEmpty(note.AuthorName), note.Content, note.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	note.ID = int(id)
+	return note, nil
+}
+
+// Notes liefert die Notizen eines Tickets, älteste zuerst
+func (s *TicketService) Notes(ticketID int) ([]Note, error) {
+	rows, err := s.db.Query(`
+		SELECT id, ticket_id, author_id, COALESCE(author_name, ''), content, created_at
+		FROM ticket_notes WHERE ticket_id = ? ORDER BY created_at, id`, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []Note{}
+	for rows.Next() {
+		var n Note
+		if err := rows.Scan(&n.ID, &n.TicketID, &n.AuthorID, &n.AuthorName, &n.Content, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
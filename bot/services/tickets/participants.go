@@ -0,0 +1,131 @@
+package tickets
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Participant ist ein zusätzlicher Teilnehmer eines Tickets, der wie der Ersteller Zugriff auf den Channel hat
+type Participant struct {
+	TicketID    int    `json:"ticket_id"`
+	UserID      string `json:"user_id"`
+	UserName    string `json:"user_name"`
+	AddedByID   string `json:"added_by_id"`
+	AddedByName string `json:"added_by_name"`
+	AddedAt     int64  `json:"added_at"`
+}
+
+// HasAccess gibt an, ob Ersteller und Teilnehmer im aktuellen Status Zugriff auf den Channel haben
+func (s Status) HasAccess() bool {
+	return s == StatusOpen || s == StatusClaimed || s == StatusUserLeft
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// AddParticipant trägt einen zusätzlichen Teilnehmer ein und schreibt ihn in den Verlauf.
+// Bei geschlossenen Tickets bleibt er gespeichert und erhält den Zugriff beim erneuten Öffnen.
+func (s *TicketService) AddParticipant(ticketID int, user Actor, actor Actor) (*Ticket, *Participant, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	ticket, err := getTicket(tx, ticketID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ticket.Status == StatusDeleted {
+		return ticket, nil, fmt.Errorf("%w: Teilnehmer hinzufügen bei Status %s", ErrInvalidTransition, ticket.Status)
+	}
+	if user.ID == ticket.CreatorID {
+		return ticket, nil, fmt.Errorf("%w: <@%s> hat das Ticket erstellt", ErrInvalidInput, user.ID)
+	}
+
+	participant := &Participant{TicketID: ticketID, UserID: user.ID, UserName: user.Name, AddedByID: actor.ID, AddedByName: actor.Name, AddedAt: time.Now().Unix()}
+	res, err := tx.Exec(`
+		INSERT INTO ticket_participants (ticket_id, user_id, user_name, added_by_id, added_by_name, added_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(ticket_id, user_id) DO NOTHING`,
+		ticketID, participant.UserID, nullIfEmpty(participant.UserName), nullIfEmpty(participant.AddedByID), nullIfEmpty(participant.AddedByName), participant.AddedAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ticket, nil, fmt.Errorf("%w: <@%s> ist bereits Teilnehmer", ErrDuplicate, user.ID)
+	}
+	if err := insertEvent(tx, ticketID, ActionParticipantAdd, ticket.Status, ticket.Status, actor, participantDetails(user)); err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return ticket, participant, nil
+}
+
+// RemoveParticipant entfernt einen zusätzlichen Teilnehmer und schreibt das in den Verlauf
+func (s *TicketService) RemoveParticipant(ticketID int, userID string, actor Actor) (*Ticket, *Participant, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	ticket, err := getTicket(tx, ticketID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ticket.Status == StatusDeleted {
+		return ticket, nil, fmt.Errorf("%w: Teilnehmer entfernen bei Status %s", ErrInvalidTransition, ticket.Status)
+	}
+
+	var p Participant
+	err = tx.QueryRow(`
+		SELECT ticket_id, user_id, COALESCE(user_name, ''), COALESCE(added_by_id, ''), COALESCE(added_by_name, ''), added_at
+		FROM ticket_participants WHERE ticket_id = ? AND user_id = ?`, ticketID, userID).Scan(&p.TicketID, &p.UserID, &p.UserName, &p.AddedByID, &p.AddedByName, &p.AddedAt)
+	if err == sql.ErrNoRows {
+		return ticket, nil, fmt.Errorf("%w: <@%s> ist kein Teilnehmer", ErrNotFound, userID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM ticket_participants WHERE ticket_id = ? AND user_id = ?`, ticketID, userID); err != nil {
+		return nil, nil, err
+	}
+	if err := insertEvent(tx, ticketID, ActionParticipantRemove, ticket.Status, ticket.Status, actor, participantDetails(Actor{ID: p.UserID, Name: p.UserName})); err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return ticket, &p, nil
+}
+
+// Participants liefert die zusätzlichen Teilnehmer eines Tickets, zuerst hinzugefügte zuerst
+func (s *TicketService) Participants(ticketID int) ([]Participant, error) {
+	rows, err := s.db.Query(`
+		SELECT ticket_id, user_id, COALESCE(user_name, ''), COALESCE(added_by_id, ''), COALESCE(added_by_name, ''), added_at
+		FROM ticket_participants WHERE ticket_id = ? ORDER BY added_at, user_id`, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	participants := []Participant{}
+	for rows.Next() {
+		var p Participant
+		if err := rows.Scan(&p.TicketID, &p.UserID, &p.UserName, &p.AddedByID, &p.AddedByName, &p.AddedAt); err != nil {
+			return nil, err
+		}
+		participants = append(participants, p)
+	}
+	return participants, rows.Err()
+}
+
+// participantDetails beschreibt den Teilnehmer im Verlauf, der Name kann sich später ändern
+func participantDetails(user Actor) string {
+	if user.Name == "" {
+		return user.ID
+	}
+	return user.Name + " (" + user.ID + ")"
+}
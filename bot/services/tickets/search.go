@@ -0,0 +1,288 @@
+package tickets
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"bot/database"
+)
+
+// SearchResult ist ein Treffer der Ticket-Suche, Snippet hebt den Suchbegriff mit ** hervor
+type SearchResult struct {
+	Ticket  Ticket `json:"ticket"`
+	Snippet string `json:"snippet"`
+}
+
+// Tabelle und ID-Spalte des Suchindex, abhängig vom Build (siehe database/search_fts5.go)
+var searchTable, searchIDColumn = func() (string, string) {
+	if database.SearchFTS5 {
+		return "ticket_search_fts", "rowid"
+	}
+	return "ticket_search_plain", "ticket_id"
+}()
+
+const maxSearchResults = 25
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// IndexTicket aktualisiert Bereich, Ersteller, Tags und Formular-Antworten im Suchindex, ein bereits indiziertes Transkript bleibt erhalten
+func (s *TicketService) IndexTicket(ticketID int) error {
+	var transcript string
+	err := s.db.QueryRow(`SELECT COALESCE(transcript, '') FROM `+searchTable+` WHERE `+searchIDColumn+` = ?`, ticketID).Scan(&transcript)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	return s.IndexTranscript(ticketID, transcript)
+}
+
+// IndexTranscript schreibt den Suchindex eines Tickets inklusive Transkript-Text neu
+func (s *TicketService) IndexTranscript(ticketID int, transcript string) error {
+	ticket, err := s.GetTicket(ticketID)
+	if err != nil {
+		return err
+	}
+	answers, err := s.searchAnswers(ticketID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM `+searchTable+` WHERE `+searchIDColumn+` = ?`, ticketID); err != nil {
+		return err
+	}
+	tags := strings.Join(ticket.Tags, " ")
+	if database.SearchFTS5 {
+		_, err = tx.Exec(`INSERT INTO ticket_search_fts (rowid, area, creator, tags, answers, transcript) VALUES (?, ?, ?, ?, ?, ?)`,
+			ticketID, ticket.Area, ticket.CreatorName, tags, answers, transcript)
+	} else {
+		// LOWER() in SQLite wandelt nur ASCII um, der Suchtext wird daher hier kleingeschrieben gespeichert
+		content := strings.ToLower(strings.Join([]string{ticket.CreatorName, tags, answers, transcript}, " "))
+		_, err = tx.Exec(`INSERT INTO ticket_search_plain (ticket_id, area, creator, tags, answers, transcript, content) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			ticketID, ticket.Area, ticket.CreatorName, tags, answers, transcript, content)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// searchAnswers fasst die Formular-Antworten zusammen, ältere Tickets haben nur die festen Spalten
+func (s *TicketService) searchAnswers(ticketID int) (string, error) {
+	answers, err := ticketAnswers(s.db, int64(ticketID))
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, answer := range answers {
+		lines = append(lines, answer.Label+": "+answer.Value)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// RebuildSearchIndex indiziert alle Tickets ohne Transkript-Text, solange der Index leer ist (z.B. nach dem Umstieg auf FTS5).
+// Außerdem werden ältere Tickets neu geschrieben, die noch mit "Feld N" statt der Feld-Beschriftungen des Bereichs indiziert sind,
+// und ohne FTS5 Einträge ohne kleingeschriebenen Suchtext (aus der Zeit vor der Spalte content).
+func (s *TicketService) RebuildSearchIndex() (int, error) {
+	var indexed int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM ` + searchTable).Scan(&indexed); err != nil {
+		return 0, err
+	}
+	query := `SELECT ticket_id FROM tickets ORDER BY ticket_id`
+	if indexed > 0 {
+		query = `
+			SELECT s.` + searchIDColumn + ` FROM ` + searchTable + ` s
+			JOIN tickets t ON t.ticket_id = s.` + searchIDColumn + `
+			WHERE s.answers LIKE 'Feld _:%'
+				AND NOT EXISTS (SELECT 1 FROM ticket_answers a WHERE a.ticket_id = t.ticket_id)
+				AND EXISTS (SELECT 1 FROM ticket_area_fields f WHERE f.area_key = t.ticket_bereich)`
+		if !database.SearchFTS5 {
+			query += ` UNION SELECT ticket_id FROM ticket_search_plain WHERE content IS NULL`
+		}
+	}
+	indexed = 0
+
+	var ids []int
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := s.IndexTicket(id); err != nil {
+			return indexed, err
+		}
+		indexed++
+	}
+	return indexed, nil
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// Search durchsucht Formular-Antworten, Tags, Ersteller und Transkripte. Alle Begriffe müssen vorkommen (Präfixsuche),
+// "#<id>" liefert direkt das Ticket. area schränkt optional auf einen Bereich ein.
+func (s *TicketService) Search(query, area string, limit int) ([]SearchResult, error) {
+	if limit <= 0 || limit > maxSearchResults {
+		limit = maxSearchResults
+	}
+	query = strings.TrimSpace(query)
+	if id, err := strconv.Atoi(strings.TrimPrefix(query, "#")); err == nil && strings.HasPrefix(query, "#") {
+		ticket, err := s.GetTicket(id)
+		if errors.Is(err, ErrNotFound) {
+			return []SearchResult{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []SearchResult{{Ticket: *ticket}}, nil
+	}
+
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("%w: Suchbegriff fehlt", ErrInvalidInput)
+	}
+
+	var hits []SearchResult
+	var err error
+	if database.SearchFTS5 {
+		hits, err = s.searchFTS5(terms, area, limit)
+	} else {
+		hits, err = s.searchPlain(terms, area, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := []SearchResult{}
+	for _, hit := range hits {
+		ticket, err := s.GetTicket(hit.Ticket.ID)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, SearchResult{Ticket: *ticket, Snippet: hit.Snippet})
+	}
+	return results, nil
+}
+
+// searchTerms zerlegt die Eingabe in Wörter, Sonderzeichen der FTS5-Syntax werden entfernt.
+// Begriffe ohne Buchstaben oder Ziffern (z.B. ein einzelnes "-") fallen weg, sie würden in FTS5 zu `""*`.
+func searchTerms(query string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+	terms := fields[:0]
+	for _, field := range fields {
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			terms = append(terms, field)
+		}
+	}
+	return terms
+}
+
+func (s *TicketService) searchFTS5(terms []string, area string, limit int) ([]SearchResult, error) {
+	match := make([]string, len(terms))
+	for i, term := range terms {
+		match[i] = `"` + term + `"*`
+	}
+	query := `SELECT rowid, snippet(ticket_search_fts, -1, '**', '**', '…', 12) FROM ticket_search_fts WHERE ticket_search_fts MATCH ?`
+	args := []interface{}{strings.Join(match, " ")}
+	if area != "" {
+		query += ` AND area = ?`
+		args = append(args, area)
+	}
+	rows, err := s.db.Query(query+` ORDER BY rank LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []SearchResult
+	for rows.Next() {
+		var hit SearchResult
+		if err := rows.Scan(&hit.Ticket.ID, &hit.Snippet); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+// searchPlain ist die LIKE-Suche für Builds ohne FTS5 auf dem kleingeschriebenen Suchtext, neueste Tickets zuerst
+func (s *TicketService) searchPlain(terms []string, area string, limit int) ([]SearchResult, error) {
+	const text = `(COALESCE(creator, '') || ' ' || COALESCE(tags, '') || ' ' || COALESCE(answers, '') || ' ' || COALESCE(transcript, ''))`
+	query := `SELECT ticket_id, ` + text + ` FROM ticket_search_plain WHERE 1 = 1`
+	var args []interface{}
+	for _, term := range terms {
+		query += ` AND content LIKE ?`
+		args = append(args, "%"+term+"%")
+	}
+	if area != "" {
+		query += ` AND area = ?`
+		args = append(args, area)
+	}
+	rows, err := s.db.Query(query+` ORDER BY ticket_id DESC LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []SearchResult
+	for rows.Next() {
+		var hit SearchResult
+		var content string
+		if err := rows.Scan(&hit.Ticket.ID, &content); err != nil {
+			return nil, err
+		}
+		hit.Snippet = plainSnippet(content, terms[0])
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+// plainSnippet schneidet den Text um den ersten Treffer aus, ähnlich snippet() in FTS5
+func plainSnippet(content, term string) string {
+	runes := []rune(strings.Join(strings.Fields(content), " "))
+	lower := []rune(strings.ToLower(string(runes)))
+	if len(lower) != len(runes) {
+		runes = lower
+	}
+	termRunes := []rune(term)
+	index := strings.Index(string(lower), term)
+	if index < 0 {
+		return ""
+	}
+	start := len([]rune(string(lower)[:index]))
+	end := start + len(termRunes)
+	from, to := max(start-40, 0), min(end+40, len(runes))
+
+	snippet := string(runes[from:start]) + "**" + string(runes[start:end]) + "**" + string(runes[end:to])
+	if from > 0 {
+		snippet = "…" + snippet
+	}
+	if to < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
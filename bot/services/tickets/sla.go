@@ -0,0 +1,291 @@
+package tickets
+
+import (
+	"sort"
+	"time"
+)
+
+// Escalation ist ein offenes Ticket, das länger als das Übernahme-Ziel wartet.
+// Stufe 1 pingt die Support-Rolle des Bereichs, Stufe 2 das Head Management.
+type Escalation struct {
+	Ticket  Ticket        `json:"ticket"`
+	Level   int           `json:"level"`
+	Waiting time.Duration `json:"waiting"`
+	Target  time.Duration `json:"target"`
+}
+
+// Reminder ist ein bearbeitetes Ticket, in dem die letzte Nachricht des Erstellers unbeantwortet ist
+type Reminder struct {
+	Ticket  Ticket        `json:"ticket"`
+	Since   int64         `json:"since"` // letzte Nachricht des Erstellers
+	Waiting time.Duration `json:"waiting"`
+}
+
+// SLAReport fasst die Einhaltung der SLA-Ziele im Zeitraum zusammen
+type SLAReport struct {
+	Since    int64       `json:"since"`
+	Until    int64       `json:"until"`
+	Areas    []AreaSLA   `json:"areas"`
+	Breaches []SLABreach `json:"breaches"` // noch offene Tickets, die ein Ziel bereits überschritten haben
+}
+
+// AreaSLA sind die Kennzahlen eines Bereichs, Durchschnitte in Sekunden
+type AreaSLA struct {
+	Area            string `json:"area"`
+	Label           string `json:"label"`
+	ClaimMinutes    int    `json:"claim_minutes"`
+	CloseHours      int    `json:"close_hours"`
+	Tickets         int    `json:"tickets"`
+	Claimed         int    `json:"claimed"`
+	Closed          int    `json:"closed"`
+	ClaimBreaches   int    `json:"claim_breaches"`
+	CloseBreaches   int    `json:"close_breaches"`
+	AvgClaimSeconds int64  `json:"avg_claim_seconds"`
+	AvgCloseSeconds int64  `json:"avg_close_seconds"`
+}
+
+// SLABreach ist ein offenes Ticket über einem Ziel, Kind ist "claim" oder "close"
+type SLABreach struct {
+	Ticket         Ticket `json:"ticket"`
+	Kind           string `json:"kind"`
+	OverdueSeconds int64  `json:"overdue_seconds"`
+}
+
+// Effektive SLA-Ziele: Unterbereiche ohne eigenes Ziel übernehmen das des Oberbereichs
+const slaTargetColumns = `
+	COALESCE(NULLIF(a.sla_claim_minutes, 0), p.sla_claim_minutes, 0),
+	COALESCE(NULLIF(a.sla_close_hours, 0), p.sla_close_hours, 0),
+	COALESCE(NULLIF(a.sla_reply_hours, 0), p.sla_reply_hours, 0)`
+
+const slaAreaJoin = `
+	LEFT JOIN ticket_areas a ON a.area_key = t.ticket_bereich
+	LEFT JOIN ticket_areas p ON p.area_key = a.parent_key`
+
+// Übernahmezeit: erster Claim bzw. erste Zuweisung (Platzhalter für ActionClaim und ActionAssign).
+// Automatisch zugewiesene Tickets gelten ab der Erstellung als übernommen und zählen daher mit 0 nicht in die Übernahme-Kennzahlen.
+const slaClaimedColumn = `
+	CASE WHEN EXISTS (SELECT 1 FROM ticket_assignments x WHERE x.ticket_id = t.ticket_id AND x.mode <> '` + string(AssignManual) + `') THEN 0
+	ELSE COALESCE((SELECT MIN(e.created_at) FROM ticket_events e WHERE e.ticket_id = t.ticket_id AND e.action IN (?, ?)),
+		NULLIF(t.ticket_bearbeitungszeit, 0), 0) END`
+
+// Die ersten zehn Spalten aus ticketColumns (ohne Priorität und Tags) mit Tabellen-Alias t
+const slaTicketColumns = `t.ticket_id, COALESCE(t.ticket_status, ''), COALESCE(t.ticket_bereich, ''), COALESCE(t.ticket_channel_id, ''),
+	COALESCE(t.ticket_ersteller_id, ''), COALESCE(t.ticket_ersteller_name, ''), COALESCE(t.ticket_bearbeiter_id, ''),
+	COALESCE(t.ticket_bearbeiter_name, ''), COALESCE(t.ticket_schliesser_id, ''), COALESCE(t.ticket_schliesser_name, '')`
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// RecordMessage merkt sich die letzte Nachricht des Erstellers bzw. des Teams in einem Ticket
+func (s *TicketService) RecordMessage(ticketID int, fromCreator bool, at time.Time) error {
+	column := "last_team_message"
+	if fromCreator {
+		column = "last_creator_message"
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO ticket_sla (ticket_id, `+column+`) VALUES (?, ?)
+		ON CONFLICT(ticket_id) DO UPDATE SET `+column+` = MAX(`+column+`, excluded.`+column+`)`,
+		ticketID, at.Unix())
+	return err
+}
+
+// resetSLA startet Eskalation, Antwort-Erinnerung und Inaktivität beim Wiedereröffnen neu. Die Wartezeit zählt ab
+// reopened_at statt ab der Erstellung, und eine vor dem Schließen unbeantwortete Nachricht löst keine Erinnerung mehr aus.
+func resetSLA(db execer, ticketID int, now int64) error {
+	_, err := db.Exec(`
+		INSERT INTO ticket_sla (ticket_id, reopened_at, reply_reminded_at) VALUES (?, ?, ?)
+		ON CONFLICT(ticket_id) DO UPDATE SET claim_escalation = 0, reply_reminded_at = excluded.reply_reminded_at,
+			inactivity_warned_at = 0, inactivity_kept_at = 0, reopened_at = excluded.reopened_at`,
+		ticketID, now, now)
+	return err
+}
+
+// DueEscalations liefert offene Tickets, deren nächste Eskalationsstufe fällig ist.
+// Stufe 2 wird nach factor-mal dem Übernahme-Ziel erreicht, übersprungene Stufen werden nicht nachgeholt.
+// Wiedereröffnete Tickets warten ab dem letzten Wiedereröffnen.
+func (s *TicketService) DueEscalations(now time.Time, factor int) ([]Escalation, error) {
+	if factor < 1 {
+		factor = 1
+	}
+	rows, err := s.db.Query(`
+		SELECT `+slaTicketColumns+`, MAX(COALESCE(t.ticket_erstellungszeit, 0), COALESCE(sla.reopened_at, 0)), `+slaTargetColumns+`,
+			COALESCE(sla.claim_escalation, 0)
+		FROM tickets t `+slaAreaJoin+`
+		LEFT JOIN ticket_sla sla ON sla.ticket_id = t.ticket_id
+		WHERE t.ticket_status = ?`, string(StatusOpen))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var escalations []Escalation
+	for rows.Next() {
+		var t Ticket
+		var status string
+		var start int64
+		var claimMinutes, closeHours, replyHours, level int
+		if err := rows.Scan(&t.ID, &status, &t.Area, &t.ChannelID, &t.CreatorID, &t.CreatorName, &t.ClaimerID, &t.ClaimerName,
+			&t.CloserID, &t.CloserName, &start, &claimMinutes, &closeHours, &replyHours, &level); err != nil {
+			return nil, err
+		}
+		if claimMinutes <= 0 || start <= 0 {
+			continue
+		}
+		t.Status, t.rawStatus = ParseStatus(status), status
+
+		target := time.Duration(claimMinutes) * time.Minute
+		waiting := now.Sub(time.Unix(start, 0))
+		due := 0
+		switch {
+		case waiting >= target*time.Duration(factor) && factor > 1:
+			due = 2
+		case waiting >= target:
+			due = 1
+		}
+		if due > level {
+			escalations = append(escalations, Escalation{Ticket: t, Level: due, Waiting: waiting, Target: target})
+		}
+	}
+	return escalations, rows.Err()
+}
+
+// MarkEscalated speichert die erreichte Eskalationsstufe
+func (s *TicketService) MarkEscalated(ticketID, level int) error {
+	_, err := s.db.Exec(`
+		INSERT INTO ticket_sla (ticket_id, claim_escalation) VALUES (?, ?)
+		ON CONFLICT(ticket_id) DO UPDATE SET claim_escalation = MAX(claim_escalation, excluded.claim_escalation)`,
+		ticketID, level)
+	return err
+}
+
+// DueReminders liefert bearbeitete Tickets, in denen die letzte Nachricht des Erstellers länger als das
+// Antwort-Ziel unbeantwortet ist. Pro unbeantworteter Nachricht wird nur einmal erinnert.
+func (s *TicketService) DueReminders(now time.Time) ([]Reminder, error) {
+	rows, err := s.db.Query(`
+		SELECT `+slaTicketColumns+`, `+slaTargetColumns+`, sla.last_creator_message
+		FROM tickets t `+slaAreaJoin+`
+		JOIN ticket_sla sla ON sla.ticket_id = t.ticket_id
+		WHERE t.ticket_status = ? AND COALESCE(t.ticket_bearbeiter_id, '') != ''
+			AND sla.last_creator_message > sla.last_team_message
+			AND sla.reply_reminded_at < sla.last_creator_message`, string(StatusClaimed))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reminders []Reminder
+	for rows.Next() {
+		var t Ticket
+		var status string
+		var claimMinutes, closeHours, replyHours int
+		var since int64
+		if err := rows.Scan(&t.ID, &status, &t.Area, &t.ChannelID, &t.CreatorID, &t.CreatorName, &t.ClaimerID, &t.ClaimerName,
+			&t.CloserID, &t.CloserName, &claimMinutes, &closeHours, &replyHours, &since); err != nil {
+			return nil, err
+		}
+		if replyHours <= 0 {
+			continue
+		}
+		t.Status, t.rawStatus = ParseStatus(status), status
+		waiting := now.Sub(time.Unix(since, 0))
+		if waiting >= time.Duration(replyHours)*time.Hour {
+			reminders = append(reminders, Reminder{Ticket: t, Since: since, Waiting: waiting})
+		}
+	}
+	return reminders, rows.Err()
+}
+
+// MarkReminded merkt sich die Erinnerung, bis der Ersteller erneut schreibt
+func (s *TicketService) MarkReminded(ticketID int, at time.Time) error {
+	_, err := s.db.Exec(`UPDATE ticket_sla SET reply_reminded_at = ? WHERE ticket_id = ?`, at.Unix(), ticketID)
+	return err
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// SLAReport wertet alle seit since erstellten Tickets aus.
+// Als Übernahmezeit zählt der erste Claim bzw. die erste manuelle Zuweisung (slaClaimedColumn), als Schließzeit das letzte Schließen.
+func (s *TicketService) SLAReport(since, now time.Time) (*SLAReport, error) {
+	rows, err := s.db.Query(`
+		SELECT `+slaTicketColumns+`, COALESCE(a.label, t.ticket_bereich, ''), COALESCE(t.ticket_erstellungszeit, 0), `+slaClaimedColumn+`,
+			COALESCE(t.ticket_schliesszeit, 0), `+slaTargetColumns+`
+		FROM tickets t `+slaAreaJoin+`
+		WHERE t.ticket_erstellungszeit >= ?
+		ORDER BY t.ticket_id`, string(ActionClaim), string(ActionAssign), since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &SLAReport{Since: since.Unix(), Until: now.Unix(), Areas: []AreaSLA{}, Breaches: []SLABreach{}}
+	areas := make(map[string]*AreaSLA)
+	claimSums := make(map[string]int64)
+	closeSums := make(map[string]int64)
+	for rows.Next() {
+		var t Ticket
+		var status, label string
+		var created, claimed, closed int64
+		var claimMinutes, closeHours, replyHours int
+		if err := rows.Scan(&t.ID, &status, &t.Area, &t.ChannelID, &t.CreatorID, &t.CreatorName, &t.ClaimerID, &t.ClaimerName,
+			&t.CloserID, &t.CloserName, &label, &created, &claimed, &closed, &claimMinutes, &closeHours, &replyHours); err != nil {
+			return nil, err
+		}
+		if created <= 0 {
+			continue
+		}
+		t.Status, t.rawStatus = ParseStatus(status), status
+		open := t.Status == StatusOpen || t.Status == StatusClaimed
+
+		area := areas[t.Area]
+		if area == nil {
+			area = &AreaSLA{Area: t.Area, Label: label, ClaimMinutes: claimMinutes, CloseHours: closeHours}
+			areas[t.Area] = area
+		}
+		area.Tickets++
+
+		// Übernahme: bereits geschehen oder noch ausstehend (dann zählt die bisherige Wartezeit)
+		claimTarget := int64(claimMinutes) * 60
+		if claimed > 0 {
+			area.Claimed++
+			claimSums[t.Area] += claimed - created
+			if claimTarget > 0 && claimed-created > claimTarget {
+				area.ClaimBreaches++
+			}
+		} else if claimTarget > 0 && t.Status == StatusOpen && now.Unix()-created > claimTarget {
+			area.ClaimBreaches++
+			report.Breaches = append(report.Breaches, SLABreach{Ticket: t, Kind: "claim", OverdueSeconds: now.Unix() - created - claimTarget})
+		}
+
+		closeTarget := int64(closeHours) * 3600
+		if closed > 0 && !open {
+			area.Closed++
+			closeSums[t.Area] += closed - created
+			if closeTarget > 0 && closed-created > closeTarget {
+				area.CloseBreaches++
+			}
+		} else if closeTarget > 0 && open && now.Unix()-created > closeTarget {
+			area.CloseBreaches++
+			report.Breaches = append(report.Breaches, SLABreach{Ticket: t, Kind: "close", OverdueSeconds: now.Unix() - created - closeTarget})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for key, area := range areas {
+		if area.Claimed > 0 {
+			area.AvgClaimSeconds = claimSums[key] / int64(area.Claimed)
+		}
+		if area.Closed > 0 {
+			area.AvgCloseSeconds = closeSums[key] / int64(area.Closed)
+		}
+		report.Areas = append(report.Areas, *area)
+	}
+	sort.Slice(report.Areas, func(i, j int) bool {
+		if report.Areas[i].Tickets != report.Areas[j].Tickets {
+			return report.Areas[i].Tickets > report.Areas[j].Tickets
+		}
+		return report.Areas[i].Area < report.Areas[j].Area
+	})
+	sort.Slice(report.Breaches, func(i, j int) bool { return report.Breaches[i].OverdueSeconds > report.Breaches[j].OverdueSeconds })
+	return report, nil
+}
@@ -0,0 +1,304 @@
+package tickets
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Einteilung des Ticket-Volumens im Statistik-Bericht, abhängig von der Länge des Zeitraums
+const (
+	StatsIntervalDay   = "day"
+	StatsIntervalWeek  = "week"
+	StatsIntervalMonth = "month"
+)
+
+// DefaultStatsDays ist der Zeitraum von /ticket_stats und /api/tickets/stats ohne Angabe von from
+const DefaultStatsDays = 30
+
+// StatsReport ist die Auswertung aller im Zeitraum erstellten Tickets
+type StatsReport struct {
+	From       int64            `json:"from"`
+	To         int64            `json:"to"`
+	Area       string           `json:"area,omitempty"` // Filter inkl. Unterbereiche, leer = alle Bereiche
+	Interval   string           `json:"interval"`       // Einteilung von Volume
+	Total      TicketStats      `json:"total"`
+	Areas      []TicketStats    `json:"areas"`
+	Moderators []ModeratorStats `json:"moderators"`
+	Volume     []VolumeBucket   `json:"volume"`
+}
+
+// TicketStats sind die Kennzahlen eines Bereichs bzw. aller Tickets, Zeiten in Sekunden, Quoten in Prozent aller Tickets.
+// Closed zählt wie im SLA-Bericht nur Tickets, die aktuell geschlossen sind.
+type TicketStats struct {
+	Key          string  `json:"key"`
+	Label        string  `json:"label"`
+	Tickets      int     `json:"tickets"`
+	Claimed      int     `json:"claimed"`
+	Closed       int     `json:"closed"`
+	Reopened     int     `json:"reopened"`
+	UserLeft     int     `json:"user_left"`
+	ClaimMedian  int64   `json:"claim_median_seconds"`
+	ClaimP90     int64   `json:"claim_p90_seconds"`
+	CloseMedian  int64   `json:"close_median_seconds"`
+	CloseP90     int64   `json:"close_p90_seconds"`
+	ReopenRate   float64 `json:"reopen_rate"`
+	UserLeftRate float64 `json:"user_left_rate"`
+
+	claimTimes []int64
+	closeTimes []int64
+}
+
+// ModeratorStats zählt die bearbeiteten und geschlossenen Tickets eines Teammitglieds
+type ModeratorStats struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Handled int    `json:"handled"` // als Bearbeiter eingetragen
+	Closed  int    `json:"closed"`
+}
+
+// VolumeBucket ist die Anzahl neuer Tickets je Tag, Woche oder Monat, aufgeteilt nach Bereich
+type VolumeBucket struct {
+	Start int64          `json:"start"`
+	Label string         `json:"label"`
+	Total int            `json:"total"`
+	Areas map[string]int `json:"areas"`
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// ParseStatsRange liest from und to als 2006-01-02 oder 02.01.2006 in der Zeitzone von now, to zählt bis Tagesende.
+// Ohne to endet der Zeitraum jetzt, ohne from beginnt er DefaultStatsDays Tage vor dem Ende.
+func ParseStatsRange(fromStr, toStr string, now time.Time) (time.Time, time.Time, error) {
+	to := now
+	if strings.TrimSpace(toStr) != "" {
+		day, err := parseStatsDate(toStr, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = day.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -DefaultStatsDays)
+	if strings.TrimSpace(fromStr) != "" {
+		day, err := parseStatsDate(fromStr, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = day
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from muss vor to liegen", ErrInvalidInput)
+	}
+	return from, to, nil
+}
+
+func parseStatsDate(value string, location *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", "02.01.2006", "2.1.2006"} {
+		if day, err := time.ParseInLocation(layout, value, location); err == nil {
+			return day, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: Datum %q, erwartet JJJJ-MM-TT oder TT.MM.JJJJ", ErrInvalidInput, value)
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// StatsReport wertet alle zwischen from und to erstellten Tickets aus, optional nur einen Bereich inkl. Unterbereiche.
+// Übernahme- und Schließzeit werden wie im SLA-Bericht bestimmt (erster Claim bzw. manuelle Zuweisung, letztes Schließen),
+// das Volumen wird in der Zeitzone von from eingeteilt.
+func (s *TicketService) StatsReport(from, to time.Time, areaKey string) (*StatsReport, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from muss vor to liegen", ErrInvalidInput)
+	}
+	if areaKey != "" {
+		if _, err := NewAreaService(s.bot).GetArea(areaKey); err != nil {
+			return nil, fmt.Errorf("%w: Bereich %s", ErrNotFound, areaKey)
+		}
+	}
+
+	rows, err := s.db.Query(`
+		SELECT t.ticket_id, COALESCE(t.ticket_status, ''), COALESCE(t.ticket_bereich, ''), COALESCE(a.label, t.ticket_bereich, ''),
+			COALESCE(t.ticket_bearbeiter_id, ''), COALESCE(t.ticket_bearbeiter_name, ''),
+			COALESCE(t.ticket_schliesser_id, ''), COALESCE(t.ticket_schliesser_name, ''), COALESCE(t.ticket_erstellungszeit, 0),
+			`+slaClaimedColumn+`,
+			COALESCE(t.ticket_schliesszeit, 0),
+			EXISTS (SELECT 1 FROM ticket_events e WHERE e.ticket_id = t.ticket_id AND e.action = ?),
+			EXISTS (SELECT 1 FROM ticket_events e WHERE e.ticket_id = t.ticket_id AND e.action = ?)
+		FROM tickets t
+		LEFT JOIN ticket_areas a ON a.area_key = t.ticket_bereich
+		WHERE t.ticket_erstellungszeit >= ? AND t.ticket_erstellungszeit < ?
+			AND (? = '' OR t.ticket_bereich = ? OR a.parent_key = ?)
+		ORDER BY t.ticket_erstellungszeit`,
+		string(ActionClaim), string(ActionAssign), string(ActionReopen), string(ActionUserLeft),
+		from.Unix(), to.Unix(), areaKey, areaKey, areaKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &StatsReport{From: from.Unix(), To: to.Unix(), Area: areaKey, Interval: statsInterval(from, to), Areas: []TicketStats{}, Moderators: []ModeratorStats{}}
+	report.Volume = statsBuckets(from, to, report.Interval)
+	areas := make(map[string]*TicketStats)
+	moderators := make(map[string]*ModeratorStats)
+	moderator := func(id, name string) *ModeratorStats {
+		m := moderators[id]
+		if m == nil {
+			m = &ModeratorStats{ID: id, Name: name}
+			moderators[id] = m
+		}
+		return m
+	}
+
+	for rows.Next() {
+		var id int
+		var status, key, label, claimerID, claimerName, closerID, closerName string
+		var created, claimed, closed int64
+		var reopened, userLeft bool
+		if err := rows.Scan(&id, &status, &key, &label, &claimerID, &claimerName, &closerID, &closerName, &created, &claimed, &closed,
+			&reopened, &userLeft); err != nil {
+			return nil, err
+		}
+		current := ParseStatus(status)
+
+		area := areas[key]
+		if area == nil {
+			area = &TicketStats{Key: key, Label: label}
+			areas[key] = area
+		}
+		for _, stats := range []*TicketStats{&report.Total, area} {
+			stats.add(current, created, claimed, closed, reopened, userLeft)
+		}
+
+		if claimerID != "" {
+			moderator(claimerID, claimerName).Handled++
+		}
+		if closerID != "" && closed > 0 {
+			moderator(closerID, closerName).Closed++
+		}
+
+		// Buckets sind aufsteigend und lückenlos, das Ticket gehört in den letzten, der davor beginnt
+		i := sort.Search(len(report.Volume), func(i int) bool { return report.Volume[i].Start > created }) - 1
+		if i >= 0 {
+			report.Volume[i].Total++
+			report.Volume[i].Areas[key]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report.Total.finish()
+	for _, area := range areas {
+		area.finish()
+		report.Areas = append(report.Areas, *area)
+	}
+	sort.Slice(report.Areas, func(i, j int) bool {
+		if report.Areas[i].Tickets != report.Areas[j].Tickets {
+			return report.Areas[i].Tickets > report.Areas[j].Tickets
+		}
+		return report.Areas[i].Key < report.Areas[j].Key
+	})
+	for _, m := range moderators {
+		report.Moderators = append(report.Moderators, *m)
+	}
+	sort.Slice(report.Moderators, func(i, j int) bool {
+		if report.Moderators[i].Handled != report.Moderators[j].Handled {
+			return report.Moderators[i].Handled > report.Moderators[j].Handled
+		}
+		if report.Moderators[i].Closed != report.Moderators[j].Closed {
+			return report.Moderators[i].Closed > report.Moderators[j].Closed
+		}
+		return report.Moderators[i].Name < report.Moderators[j].Name
+	})
+	return report, nil
+}
+
+// add zählt ein Ticket, Median, P90 und Quoten werden in finish berechnet
+func (t *TicketStats) add(status Status, created, claimed, closed int64, reopened, userLeft bool) {
+	t.Tickets++
+	if claimed > 0 && claimed >= created {
+		t.Claimed++
+		t.claimTimes = append(t.claimTimes, claimed-created)
+	}
+	// Erneut geöffnete Tickets behalten die Schließzeit, zählen aber erst nach dem nächsten Schließen
+	if closed > 0 && closed >= created && status != StatusOpen && status != StatusClaimed && status != StatusUserLeft {
+		t.Closed++
+		t.closeTimes = append(t.closeTimes, closed-created)
+	}
+	if reopened {
+		t.Reopened++
+	}
+	if userLeft || status == StatusUserLeft {
+		t.UserLeft++
+	}
+}
+
+func (t *TicketStats) finish() {
+	t.ClaimMedian, t.ClaimP90 = percentile(t.claimTimes, 50), percentile(t.claimTimes, 90)
+	t.CloseMedian, t.CloseP90 = percentile(t.closeTimes, 50), percentile(t.closeTimes, 90)
+	if t.Tickets > 0 {
+		t.ReopenRate = float64(t.Reopened) * 100 / float64(t.Tickets)
+		t.UserLeftRate = float64(t.UserLeft) * 100 / float64(t.Tickets)
+	}
+}
+
+// percentile liefert das p-te Perzentil nach dem Nearest-Rank-Verfahren, 0 ohne Werte
+func percentile(values []int64, p int) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// statsInterval wählt die Einteilung so, dass der Zeitraum in höchstens etwa 30 Balken passt
+func statsInterval(from, to time.Time) string {
+	switch days := to.Sub(from).Hours() / 24; {
+	case days <= 31:
+		return StatsIntervalDay
+	case days <= 26*7:
+		return StatsIntervalWeek
+	default:
+		return StatsIntervalMonth
+	}
+}
+
+// statsBuckets liefert die lückenlosen Zeitabschnitte von from bis to, Wochen beginnen am Montag
+func statsBuckets(from, to time.Time, interval string) []VolumeBucket {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	switch interval {
+	case StatsIntervalWeek:
+		start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+	case StatsIntervalMonth:
+		start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+	}
+
+	buckets := []VolumeBucket{}
+	for current := start; current.Before(to); {
+		bucket := VolumeBucket{Start: current.Unix(), Areas: make(map[string]int)}
+		switch interval {
+		case StatsIntervalWeek:
+			_, week := current.ISOWeek()
+			bucket.Label = fmt.Sprintf("KW %d", week)
+			current = current.AddDate(0, 0, 7)
+		case StatsIntervalMonth:
+			bucket.Label = current.Format("01/2006")
+			current = current.AddDate(0, 1, 0)
+		default:
+			bucket.Label = current.Format("02.01.")
+			current = current.AddDate(0, 0, 1)
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
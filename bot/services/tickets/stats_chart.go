@@ -0,0 +1,158 @@
+package tickets
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// maxChartAreas ist die Anzahl einzeln gefärbter Bereiche im Diagramm, alle weiteren werden als "Sonstige" gestapelt
+const maxChartAreas = 5
+
+var (
+	chartBackground = color.RGBA{255, 255, 255, 255}
+	chartText       = color.RGBA{30, 31, 34, 255}
+	chartGrid       = color.RGBA{225, 227, 230, 255}
+	chartOther      = color.RGBA{150, 150, 150, 255}
+	chartPalette    = []color.RGBA{
+		{88, 101, 242, 255}, // Blurple
+		{237, 66, 69, 255},  // Rot
+		{87, 242, 135, 255}, // Grün
+		{254, 231, 92, 255}, // Gelb
+		{235, 69, 158, 255}, // Pink
+	}
+)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// RenderStatsChart zeichnet das Ticket-Volumen des Berichts als gestapeltes Balkendiagramm je Bereich (PNG)
+func RenderStatsChart(report *StatsReport, title string) ([]byte, error) {
+	const width, height = 900, 480
+	const left, right, top, bottom = 60, 20, 80, 50
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{chartBackground}, image.Point{}, draw.Src)
+	chartWidth, chartHeight := width-left-right, height-top-bottom
+
+	drawChartText(img, left, 24, title, chartText)
+
+	// Legende: die größten Bereiche einzeln, der Rest als "Sonstige"
+	colors := make(map[string]color.RGBA)
+	x := left
+	for i, area := range report.Areas {
+		if i > maxChartAreas {
+			break
+		}
+		col, label := chartOther, "Sonstige"
+		if i < maxChartAreas {
+			col, label = chartPalette[i], area.Label
+			colors[area.Key] = col
+		}
+		if label == "" {
+			label = "Ohne Bereich"
+		}
+		fillRect(img, x, 40, 12, 12, col)
+		drawChartText(img, x+16, 51, label, chartText)
+		x += 16 + textWidth(label) + 16
+	}
+
+	maxValue := 0
+	for _, bucket := range report.Volume {
+		if bucket.Total > maxValue {
+			maxValue = bucket.Total
+		}
+	}
+	if maxValue == 0 {
+		drawChartText(img, left+(chartWidth-textWidth("Keine Tickets im Zeitraum"))/2, top+chartHeight/2, "Keine Tickets im Zeitraum", chartText)
+		return encodeChart(img)
+	}
+
+	// Hilfslinien in Vierteln mit Beschriftung an der Y-Achse
+	for i := 0; i <= 4; i++ {
+		y := top + chartHeight - chartHeight*i/4
+		fillRect(img, left, y, chartWidth, 1, chartGrid)
+		label := fmt.Sprintf("%.4g", float64(maxValue)*float64(i)/4)
+		drawChartText(img, left-8-textWidth(label), y+4, label, chartText)
+	}
+
+	n := len(report.Volume)
+	slot := float64(chartWidth) / float64(n)
+	barWidth := int(slot * 0.7)
+	if barWidth < 1 {
+		barWidth = 1
+	}
+	// Nur so viele X-Beschriftungen, wie nebeneinander passen
+	labelStep := 1
+	if n > 0 {
+		labelWidth := textWidth(report.Volume[0].Label) + 10
+		for float64(labelWidth) > slot*float64(labelStep) {
+			labelStep++
+		}
+	}
+
+	for i, bucket := range report.Volume {
+		barX := left + int(slot*float64(i)+(slot-float64(barWidth))/2)
+		y := top + chartHeight
+		// Stapel in der Reihenfolge der Legende, Sonstige oben
+		for _, area := range report.Areas {
+			count := bucket.Areas[area.Key]
+			if count == 0 {
+				continue
+			}
+			col, ok := colors[area.Key]
+			if !ok {
+				col = chartOther
+			}
+			h := count * chartHeight / maxValue
+			fillRect(img, barX, y-h, barWidth, h, col)
+			y -= h
+		}
+		if bucket.Total > 0 && barWidth >= 14 {
+			label := fmt.Sprint(bucket.Total)
+			drawChartText(img, barX+(barWidth-textWidth(label))/2, y-4, label, chartText)
+		}
+		if i%labelStep == 0 {
+			drawChartText(img, barX+(barWidth-textWidth(bucket.Label))/2, top+chartHeight+18, bucket.Label, chartText)
+		}
+	}
+	fillRect(img, left, top, 1, chartHeight+1, chartText)
+	fillRect(img, left, top+chartHeight, chartWidth, 1, chartText)
+	return encodeChart(img)
+}
+
+func fillRect(img *image.RGBA, x, y, width, height int, col color.RGBA) {
+	draw.Draw(img, image.Rect(x, y, x+width, y+height), &image.Uniform{col}, image.Point{}, draw.Src)
+}
+
+// Die eingebaute Schrift kennt nur ASCII, Umlaute werden umschrieben
+var chartReplacer = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "Ä", "Ae", "Ö", "Oe", "Ü", "Ue", "ß", "ss", "›", ">", "·", "-", "Ø", "O")
+
+func drawChartText(img *image.RGBA, x, y int, text string, col color.RGBA) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  &image.Uniform{col},
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(chartReplacer.Replace(text))
+}
+
+// textWidth ist die Breite des Textes in Pixeln (7 px je Zeichen)
+func textWidth(text string) int {
+	return len([]rune(chartReplacer.Replace(text))) * 7
+}
+
+func encodeChart(img *image.RGBA) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
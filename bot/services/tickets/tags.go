@@ -0,0 +1,200 @@
+package tickets
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"bot/utils"
+)
+
+// Priority ist die Dringlichkeit eines Tickets (tickets.ticket_priority)
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// Priorities in aufsteigender Dringlichkeit, z.B. für Auswahlmenüs
+var Priorities = []Priority{PriorityLow, PriorityNormal, PriorityHigh, PriorityUrgent}
+
+// maxTagsPerTicket begrenzt die Tags, damit Panel und Auswahlmenü übersichtlich bleiben
+const maxTagsPerTicket = 10
+
+var invalidTagChars = regexp.MustCompile(`[^a-z0-9äöüß-]+`)
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// ParsePriority liefert die Priorität, unbekannte und leere Werte gelten als normal
+func ParsePriority(value string) Priority {
+	for _, priority := range Priorities {
+		if strings.EqualFold(value, string(priority)) {
+			return priority
+		}
+	}
+	return PriorityNormal
+}
+
+// Label liefert die deutsche Bezeichnung der Priorität
+func (p Priority) Label() string {
+	switch p {
+	case PriorityLow:
+		return "Niedrig"
+	case PriorityHigh:
+		return "Hoch"
+	case PriorityUrgent:
+		return "Dringend"
+	}
+	return "Normal"
+}
+
+// NormalizeTag bildet einen Tag aus der Eingabe, z.B. "Pro Candidate" -> pro-candidate
+func NormalizeTag(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	tag = strings.Trim(invalidTagChars.ReplaceAllString(tag, "-"), "-")
+	if runes := []rune(tag); len(runes) > 32 {
+		tag = strings.TrimRight(string(runes[:32]), "-")
+	}
+	return tag
+}
+
+func splitTags(value string) []string {
+	tags := []string{}
+	if value != "" {
+		tags = strings.Split(value, ",")
+		sort.Strings(tags)
+	}
+	return tags
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// PredefinedTags liefert die vorgegebenen Tags (TICKET_TAGS, kommagetrennt)
+func (s *TicketService) PredefinedTags() []string {
+	var tags []string
+	for _, tag := range strings.Split(utils.GetOptionalIdFromDB(s.bot, "TICKET_TAGS", "minor,urgent,pro-candidate"), ",") {
+		if tag = NormalizeTag(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// KnownTags liefert vorgegebene und bereits genutzte Tags, die mit prefix beginnen (für Autocomplete)
+func (s *TicketService) KnownTags(prefix string) ([]string, error) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	seen := map[string]bool{}
+	var tags []string
+	add := func(tag string) {
+		if !seen[tag] && strings.HasPrefix(tag, prefix) {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range s.PredefinedTags() {
+		add(tag)
+	}
+
+	rows, err := s.db.Query(`SELECT tag FROM ticket_tags GROUP BY tag ORDER BY COUNT(*) DESC LIMIT 100`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		add(tag)
+	}
+	return tags, rows.Err()
+}
+
+// SetTags ersetzt die Tags eines Tickets und aktualisiert den Suchindex
+func (s *TicketService) SetTags(ticketID int, tags []string) (*Ticket, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		if tag = NormalizeTag(tag); tag != "" && !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > maxTagsPerTicket {
+		return nil, fmt.Errorf("%w: maximal %d Tags pro Ticket", ErrInvalidInput, maxTagsPerTicket)
+	}
+	if _, err := s.GetTicket(ticketID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM ticket_tags WHERE ticket_id = ?`, ticketID); err != nil {
+		return nil, err
+	}
+	for _, tag := range normalized {
+		if _, err := tx.Exec(`INSERT INTO ticket_tags (ticket_id, tag) VALUES (?, ?)`, ticketID, tag); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	if err := s.IndexTicket(ticketID); err != nil {
+		return nil, err
+	}
+	return s.GetTicket(ticketID)
+}
+
+// AddTag fügt einem Ticket einen Tag hinzu
+func (s *TicketService) AddTag(ticketID int, tag string) (*Ticket, error) {
+	if NormalizeTag(tag) == "" {
+		return nil, fmt.Errorf("%w: Tag ist leer", ErrInvalidInput)
+	}
+	ticket, err := s.GetTicket(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	return s.SetTags(ticketID, append(ticket.Tags, tag))
+}
+
+// RemoveTag entfernt einen Tag von einem Ticket
+func (s *TicketService) RemoveTag(ticketID int, tag string) (*Ticket, error) {
+	ticket, err := s.GetTicket(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	tag = NormalizeTag(tag)
+	remaining := []string{}
+	for _, existing := range ticket.Tags {
+		if existing != tag {
+			remaining = append(remaining, existing)
+		}
+	}
+	if len(remaining) == len(ticket.Tags) {
+		return nil, fmt.Errorf("%w: Tag %s", ErrNotFound, tag)
+	}
+	return s.SetTags(ticketID, remaining)
+}
+
+// SetPriority setzt die Priorität eines Tickets
+func (s *TicketService) SetPriority(ticketID int, priority Priority) (*Ticket, error) {
+	if ParsePriority(string(priority)) != priority {
+		return nil, fmt.Errorf("%w: Priorität muss low, normal, high oder urgent sein", ErrInvalidInput)
+	}
+	res, err := s.db.Exec(`UPDATE tickets SET ticket_priority = ? WHERE ticket_id = ?`, string(priority), ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNotFound
+	}
+	return s.GetTicket(ticketID)
+}
@@ -0,0 +1,415 @@
+package transcripts
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bilder bis zu dieser Größe werden als data-URI eingebettet, größere bleiben Links auf Discord
+const maxInlineImageSize = 8 * 1024 * 1024
+
+// Folgenachrichten desselben Autors innerhalb dieses Abstands werden ohne Kopfzeile angezeigt
+const groupWindow = 7 * time.Minute
+
+//go:embed transcript.html
+var transcriptTemplate string
+
+var pageTemplate = template.Must(template.New("transcript").Parse(transcriptTemplate))
+
+type pageView struct {
+	Title        string
+	GuildName    string
+	ChannelName  string
+	GeneratedAt  string
+	Count        int
+	Participants string
+	Messages     []messageView
+	Notes        []noteView
+}
+
+type messageView struct {
+	ID         string
+	AuthorName string
+	Bot        bool
+	Avatar     template.URL
+	Initial    string
+	Time       string
+	Edited     string
+	Pinned     bool
+	Continued  bool
+	Reply      *replyView
+	Content    template.HTML
+	Images     []imageView
+	Files      []fileView
+	Embeds     []embedView
+	Reactions  []Reaction
+}
+
+type noteView struct {
+	AuthorName string
+	Time       string
+	Content    template.HTML
+}
+
+type replyView struct {
+	ID         string
+	AuthorName string
+	Snippet    string
+}
+
+type imageView struct {
+	Src  template.URL
+	Name string
+}
+
+type fileView struct {
+	Name string
+	URL  string
+	Note string
+}
+
+type embedView struct {
+	Color       string
+	Author      string
+	Title       string
+	URL         string
+	Description template.HTML
+	Fields      []embedFieldView
+	Image       template.URL
+	Thumbnail   template.URL
+	Footer      string
+}
+
+type embedFieldView struct {
+	Name   template.HTML
+	Value  template.HTML
+	Inline bool
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+// RenderHTML erzeugt ein eigenständiges HTML-Dokument im Discord-Stil.
+// Bilder werden eingebettet, Erwähnungen über die Maps im Transcript in Namen aufgelöst.
+func RenderHTML(t *Transcript) ([]byte, error) {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		location = time.Local
+	}
+	r := &renderer{t: t, location: location, avatars: map[string]template.URL{}}
+
+	// Discord liefert die neuesten Nachrichten zuerst, das Transkript liest sich von oben nach unten
+	messages := append([]Message(nil), t.Messages...)
+	sort.SliceStable(messages, func(i, j int) bool { return messages[i].Timestamp < messages[j].Timestamp })
+	byID := make(map[string]*Message, len(messages))
+	for i := range messages {
+		if messages[i].ID != "" {
+			byID[messages[i].ID] = &messages[i]
+		}
+	}
+
+	page := pageView{
+		Title:        fmt.Sprintf("Ticket #%d", t.TicketID),
+		GuildName:    t.GuildName,
+		ChannelName:  t.ChannelName,
+		GeneratedAt:  t.GeneratedAt.In(location).Format("02.01.2006 15:04"),
+		Count:        len(messages),
+		Participants: strings.Join(t.Participants, ", "),
+	}
+	var previous *Message
+	var previousTime time.Time
+	for i := range messages {
+		msg := &messages[i]
+		sent, _ := time.Parse(time.RFC3339, msg.Timestamp)
+		view := r.message(msg, sent, byID)
+		view.Continued = previous != nil && previous.UserID == msg.UserID && msg.ReplyTo == "" && sent.Sub(previousTime) < groupWindow
+		page.Messages = append(page.Messages, view)
+		previous, previousTime = msg, sent
+	}
+	for _, note := range t.Notes {
+		page.Notes = append(page.Notes, noteView{
+			AuthorName: note.AuthorName,
+			Time:       note.CreatedAt.In(location).Format("02.01.2006 15:04"),
+			Content:    r.markdown(note.Content),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, page); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type renderer struct {
+	t        *Transcript
+	location *time.Location
+	avatars  map[string]template.URL
+}
+
+func (r *renderer) message(msg *Message, sent time.Time, byID map[string]*Message) messageView {
+	view := messageView{
+		ID:         msg.ID,
+		AuthorName: r.authorName(msg),
+		Bot:        msg.Bot,
+		Avatar:     r.avatar(msg.UserID),
+		Time:       sent.In(r.location).Format("02.01.2006 15:04"),
+		Pinned:     msg.Pinned,
+		Content:    r.markdown(msg.Message),
+		Reactions:  msg.Reactions,
+	}
+	view.Initial = strings.ToUpper(string([]rune(view.AuthorName + "?")[:1]))
+	if edited, err := time.Parse(time.RFC3339, msg.EditedTimestamp); err == nil {
+		view.Edited = edited.In(r.location).Format("02.01.2006 15:04")
+	}
+
+	if msg.ReplyTo != "" {
+		reply := &replyView{ID: msg.ReplyTo, Snippet: "Ursprüngliche Nachricht wurde gelöscht"}
+		if original := byID[msg.ReplyTo]; original != nil {
+			reply.AuthorName = r.authorName(original)
+			reply.Snippet = snippet(original)
+		}
+		view.Reply = reply
+	}
+
+	for _, att := range msg.Attachments {
+		if isImage(att) {
+			if src := r.imageSource(att.Key, att.URL); src != "" {
+				view.Images = append(view.Images, imageView{Src: src, Name: att.Filename})
+				continue
+			}
+		}
+		view.Files = append(view.Files, fileView{Name: att.Filename, URL: att.URL, Note: att.Skipped})
+	}
+
+	for _, embed := range msg.Embeds {
+		view.Embeds = append(view.Embeds, r.embed(embed))
+	}
+	return view
+}
+
+func (r *renderer) embed(embed Embed) embedView {
+	view := embedView{
+		Color:       "#202225",
+		Author:      embed.Author,
+		Title:       embed.Title,
+		URL:         embed.URL,
+		Description: r.markdown(embed.Description),
+		Footer:      embed.Footer,
+	}
+	if embed.Color != 0 {
+		view.Color = fmt.Sprintf("#%06x", embed.Color)
+	}
+	for _, field := range embed.Fields {
+		view.Fields = append(view.Fields, embedFieldView{Name: r.markdown(field.Name), Value: r.markdown(field.Value), Inline: field.Inline})
+	}
+	if embed.Image != nil {
+		view.Image = r.imageSource(embed.Image.Key, embed.Image.URL)
+	}
+	if embed.Thumbnail != nil {
+		view.Thumbnail = r.imageSource(embed.Thumbnail.Key, embed.Thumbnail.URL)
+	}
+	return view
+}
+
+func (r *renderer) authorName(msg *Message) string {
+	if user, ok := r.t.Users[msg.UserID]; ok && user.Name != "" {
+		return user.Name
+	}
+	if msg.DisplayName != "" {
+		return msg.DisplayName
+	}
+	return msg.Username
+}
+
+// avatar bettet den lokal gespeicherten Avatar einmal pro User ein
+func (r *renderer) avatar(userID string) template.URL {
+	if src, ok := r.avatars[userID]; ok {
+		return src
+	}
+	src := r.imageSource(r.t.Users[userID].AvatarKey, "")
+	r.avatars[userID] = src
+	return src
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+func isImage(att Attachment) bool {
+	if att.ContentType != "" {
+		return strings.HasPrefix(att.ContentType, "image/")
+	}
+	switch strings.ToLower(att.Filename[strings.LastIndex(att.Filename, ".")+1:]) {
+	case "png", "jpg", "jpeg", "gif", "webp":
+		return true
+	}
+	return false
+}
+
+// imageSource liefert das gespeicherte Bild als data-URI, sonst den Discord-Link
+func (r *renderer) imageSource(key, remoteURL string) template.URL {
+	if key != "" && r.t.Store != nil {
+		if data, err := r.load(key); err == nil {
+			if contentType := http.DetectContentType(data); strings.HasPrefix(contentType, "image/") {
+				return template.URL("data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data))
+			}
+		}
+	}
+	if strings.HasPrefix(remoteURL, "https://") || strings.HasPrefix(remoteURL, "http://") {
+		return template.URL(remoteURL)
+	}
+	return ""
+}
+
+func (r *renderer) load(key string) ([]byte, error) {
+	reader, err := r.t.Store.Get(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, maxInlineImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxInlineImageSize {
+		return nil, fmt.Errorf("Bild %s ist zu groß zum Einbetten", key)
+	}
+	return data, nil
+}
+
+// snippet kürzt eine Nachricht für die Antwort-Vorschau
+func snippet(msg *Message) string {
+	text := strings.Join(strings.Fields(msg.Message), " ")
+	if text == "" {
+		switch {
+		case len(msg.Attachments) > 0:
+			return "Anhang"
+		case len(msg.Embeds) > 0:
+			return "Embed"
+		}
+	}
+	if runes := []rune(text); len(runes) > 100 {
+		return string(runes[:97]) + "..."
+	}
+	return text
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+var (
+	codeBlockPattern  = regexp.MustCompile("(?s)```(?:[a-zA-Z0-9_+-]*\n)?(.*?)```")
+	inlineCodePattern = regexp.MustCompile("`([^`\n]+)`")
+	boldPattern       = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	underlinePattern  = regexp.MustCompile(`__(.+?)__`)
+	italicPattern     = regexp.MustCompile(`\*(.+?)\*|\b_(.+?)_\b`)
+	strikePattern     = regexp.MustCompile(`~~(.+?)~~`)
+	spoilerPattern    = regexp.MustCompile(`\|\|(.+?)\|\|`)
+	linkPattern       = regexp.MustCompile(`https?://(?:[^\s<&]|&amp;)*[^\s<&.,:;"')\]]`)
+	// Erwähnungen nach dem Escapen ("<" wird zu "&lt;")
+	escapedUserPattern      = regexp.MustCompile(`&lt;@!?(\d+)&gt;`)
+	escapedRolePattern      = regexp.MustCompile(`&lt;@&amp;(\d+)&gt;`)
+	escapedChannelPattern   = regexp.MustCompile(`&lt;#(\d+)&gt;`)
+	escapedTimestampPattern = regexp.MustCompile(`&lt;t:(-?\d+)(?::[tTdDfFR])?&gt;`)
+	escapedEmojiPattern     = regexp.MustCompile(`&lt;a?:(\w+):\d+&gt;`)
+)
+
+// markdown setzt die gängige Discord-Formatierung in HTML um
+func (r *renderer) markdown(text string) template.HTML {
+	var b strings.Builder
+	last := 0
+	for _, loc := range codeBlockPattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(r.inline(text[last:loc[0]]))
+		b.WriteString("<pre><code>" + html.EscapeString(text[loc[2]:loc[3]]) + "</code></pre>")
+		last = loc[1]
+	}
+	b.WriteString(r.inline(text[last:]))
+	return template.HTML(b.String())
+}
+
+// inline formatiert Text außerhalb von Codeblöcken, Inline-Code bleibt unformatiert
+func (r *renderer) inline(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range inlineCodePattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(r.format(text[last:loc[0]]))
+		b.WriteString("<code>" + html.EscapeString(text[loc[2]:loc[3]]) + "</code>")
+		last = loc[1]
+	}
+	b.WriteString(r.format(text[last:]))
+	return b.String()
+}
+
+func (r *renderer) format(text string) string {
+	var lines []string
+	for _, line := range strings.Split(html.EscapeString(text), "\n") {
+		if strings.HasPrefix(line, "&gt; ") {
+			line = `<span class="quote">` + strings.TrimPrefix(line, "&gt; ") + `</span>`
+		}
+		lines = append(lines, line)
+	}
+	text = strings.Join(lines, "<br>")
+
+	// Links werden durch Platzhalter ersetzt und erst am Ende eingesetzt, damit Formatierung
+	// (z.B. "_" oder "*" in der URL) nicht in das href-Attribut gerät. Formatierungszeichen am Ende
+	// (z.B. "**https://…**") gehören zur Formatierung und nicht zum Link.
+	var links []string
+	text = linkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		trimmed := strings.TrimRight(link, "*_~|")
+		links = append(links, trimmed)
+		return linkPlaceholder(len(links)-1) + link[len(trimmed):]
+	})
+	text = boldPattern.ReplaceAllString(text, `<strong>$1</strong>`)
+	text = underlinePattern.ReplaceAllString(text, `<u>$1</u>`)
+	text = italicPattern.ReplaceAllString(text, `<em>$1$2</em>`)
+	text = strikePattern.ReplaceAllString(text, `<s>$1</s>`)
+	text = spoilerPattern.ReplaceAllString(text, `<span class="spoiler">$1</span>`)
+
+	text = escapedRolePattern.ReplaceAllStringFunc(text, func(match string) string {
+		id := escapedRolePattern.FindStringSubmatch(match)[1]
+		return mention("@" + nameOr(r.t.Roles[id], "unbekannte Rolle"))
+	})
+	text = escapedUserPattern.ReplaceAllStringFunc(text, func(match string) string {
+		id := escapedUserPattern.FindStringSubmatch(match)[1]
+		return mention("@" + nameOr(r.t.Users[id].Name, "unbekannt"))
+	})
+	text = escapedChannelPattern.ReplaceAllStringFunc(text, func(match string) string {
+		id := escapedChannelPattern.FindStringSubmatch(match)[1]
+		return mention("#" + nameOr(r.t.Channels[id], "unbekannter-channel"))
+	})
+	text = escapedTimestampPattern.ReplaceAllStringFunc(text, func(match string) string {
+		unix, _ := strconv.ParseInt(escapedTimestampPattern.FindStringSubmatch(match)[1], 10, 64)
+		return `<span class="timestamp">` + time.Unix(unix, 0).In(r.location).Format("02.01.2006 15:04") + `</span>`
+	})
+	text = escapedEmojiPattern.ReplaceAllString(text, `:$1:`)
+
+	for i, link := range links {
+		text = strings.Replace(text, linkPlaceholder(i), `<a href="`+link+`" target="_blank" rel="noopener">`+link+`</a>`, 1)
+	}
+	return text
+}
+
+// linkPlaceholder enthält nur Steuerzeichen und Ziffern, die kein Formatierungsmuster trifft
+func linkPlaceholder(i int) string {
+	return "\x00" + strconv.Itoa(i) + "\x00"
+}
+
+func mention(label string) string {
+	return `<span class="mention">` + html.EscapeString(label) + `</span>`
+}
+
+func nameOr(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
@@ -0,0 +1,130 @@
+package transcripts
+
+import (
+	"strings"
+	"testing"
+)
+
+// renderMessage rendert ein Transkript mit einer einzelnen Nachricht
+func renderMessage(t *testing.T, text string, roles map[string]string) string {
+	t.Helper()
+	page, err := RenderHTML(&Transcript{
+		TicketID: 1,
+		Messages: []Message{{ID: "1", UserID: "10", Username: "user", Message: text, Timestamp: "2024-05-01T12:00:00Z"}},
+		Roles:    roles,
+	})
+	if err != nil {
+		t.Fatalf("RenderHTML: %v", err)
+	}
+	return string(page)
+}
+
+func TestRenderHTMLEscapesMarkup(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		roles   map[string]string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "script",
+			text:    `<script>alert(1)</script>`,
+			want:    []string{`&lt;script&gt;alert(1)&lt;/script&gt;`},
+			notWant: []string{`<script>alert`},
+		},
+		{
+			name:    "script in bold",
+			text:    `**<script>alert(1)</script>**`,
+			want:    []string{`<strong>&lt;script&gt;alert(1)&lt;/script&gt;</strong>`},
+			notWant: []string{`<script>alert`},
+		},
+		{
+			name:    "attribute in italics",
+			text:    `*<img src=x onerror=alert(1)>*`,
+			want:    []string{`&lt;img src=x onerror=alert(1)&gt;`},
+			notWant: []string{`<img src=x`},
+		},
+		{
+			name:    "script in code block",
+			text:    "```\n<script>alert(1)</script>\n```",
+			want:    []string{`&lt;script&gt;alert(1)&lt;/script&gt;`},
+			notWant: []string{`<script>alert`},
+		},
+		{
+			name:    "quote ends link",
+			text:    `https://example.com/a"onmouseover="alert(1)`,
+			want:    []string{`href="https://example.com/a"`},
+			notWant: []string{`"onmouseover="`, `" onmouseover=`},
+		},
+		{
+			name:    "tag after link",
+			text:    `https://example.com/<img src=x onerror=alert(1)>`,
+			want:    []string{`href="https://example.com/"`, `&lt;img src=x onerror=alert(1)&gt;`},
+			notWant: []string{`<img src=x`},
+		},
+		{
+			name: "markdown inside link",
+			text: `https://example.com/__init__/*a*?q=1&x=2`,
+			want: []string{`href="https://example.com/__init__/*a*?q=1&amp;x=2"`},
+		},
+		{
+			name:    "link in bold",
+			text:    `**https://example.com/a_b_c**`,
+			want:    []string{`<strong><a href="https://example.com/a_b_c" target="_blank" rel="noopener">https://example.com/a_b_c</a></strong>`},
+			notWant: []string{`<em>`},
+		},
+		{
+			name:    "javascript url",
+			text:    `javascript:alert(document.cookie)`,
+			want:    []string{`javascript:alert(document.cookie)`},
+			notWant: []string{`href="javascript:`},
+		},
+		{
+			name:    "javascript url in bold",
+			text:    `**javascript:alert(1)**`,
+			notWant: []string{`href="javascript:`},
+		},
+		{
+			name:    "role name",
+			text:    `<@&5>`,
+			roles:   map[string]string{"5": `<img src=x onerror=alert(1)>`},
+			want:    []string{`@&lt;img src=x onerror=alert(1)&gt;`},
+			notWant: []string{`<img src=x`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := renderMessage(t, tt.text, tt.roles)
+			for _, want := range tt.want {
+				if !strings.Contains(page, want) {
+					t.Errorf("%q fehlt in der Ausgabe für %q", want, tt.text)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(page, notWant) {
+					t.Errorf("%q darf nicht in der Ausgabe für %q vorkommen", notWant, tt.text)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderHTMLEmbedURLs(t *testing.T) {
+	page, err := RenderHTML(&Transcript{
+		TicketID: 1,
+		Messages: []Message{{
+			ID: "1", UserID: "10", Username: "user", Timestamp: "2024-05-01T12:00:00Z",
+			Embeds: []Embed{{Title: `<b>Titel</b>`, URL: `javascript:alert(1)`, Description: `<script>alert(1)</script>`}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("RenderHTML: %v", err)
+	}
+	for _, notWant := range []string{`href="javascript:`, `<b>Titel</b>`, `<script>alert`} {
+		if strings.Contains(string(page), notWant) {
+			t.Errorf("%q darf nicht in der Ausgabe vorkommen", notWant)
+		}
+	}
+}
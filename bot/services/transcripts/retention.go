@@ -0,0 +1,94 @@
+package transcripts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"bot/services/storage"
+)
+
+// AttachmentPrefix ist der Bereich im Storage für heruntergeladene Anhänge, Avatare und Embed-Bilder
+const AttachmentPrefix = "attachments"
+
+// RetentionReport fasst einen Lauf der Aufbewahrungsregel zusammen
+type RetentionReport struct {
+	Transcripts []string // gelöschte JSON-Transkripte
+	Files       int      // gelöschte HTML-Transkripte
+	Attachments int      // gelöschte Anhänge ohne Verweis
+}
+
+// ApplyRetention löscht Transkripte, die älter als cutoff sind, und danach alle älteren Anhänge,
+// auf die kein verbleibendes Transkript mehr verweist (Anhänge werden per Hash geteilt).
+func ApplyRetention(ctx context.Context, store storage.Storage, cutoff time.Time) (*RetentionReport, error) {
+	objects, err := store.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RetentionReport{}
+	var remaining []string
+	var attachments []storage.Object
+	for _, object := range objects {
+		switch {
+		case strings.HasPrefix(object.Key, AttachmentPrefix+"/"):
+			attachments = append(attachments, object)
+		case strings.Contains(object.Key, "/"):
+			// Ordner anderer Herkunft (z.B. alte "attachements/<id>") bleiben unangetastet
+		case strings.HasSuffix(object.Key, ".json"), strings.HasSuffix(object.Key, ".html"):
+			if !object.ModTime.Before(cutoff) {
+				if strings.HasSuffix(object.Key, ".json") {
+					remaining = append(remaining, object.Key)
+				}
+				continue
+			}
+			if err := store.Delete(ctx, object.Key); err != nil {
+				return report, err
+			}
+			if strings.HasSuffix(object.Key, ".json") {
+				report.Transcripts = append(report.Transcripts, object.Key)
+			} else {
+				report.Files++
+			}
+		}
+	}
+
+	// Verweise aller verbleibenden Transkripte sammeln, bei Lesefehlern wird nichts gelöscht
+	referenced := make(map[string]bool)
+	for _, key := range remaining {
+		messages, err := loadMessages(ctx, store, key)
+		if err != nil {
+			return report, fmt.Errorf("Transkript %s konnte nicht gelesen werden: %w", key, err)
+		}
+		for _, stored := range StoredKeys(messages) {
+			referenced[stored] = true
+		}
+	}
+
+	// Jüngere Anhänge bleiben, sie können zu einem Transkript gehören, das gerade erstellt wird
+	for _, object := range attachments {
+		if referenced[object.Key] || !object.ModTime.Before(cutoff) {
+			continue
+		}
+		if err := store.Delete(ctx, object.Key); err != nil {
+			return report, err
+		}
+		report.Attachments++
+	}
+	return report, nil
+}
+
+func loadMessages(ctx context.Context, store storage.Storage, key string) ([]Message, error) {
+	reader, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	var messages []Message
+	if err := json.NewDecoder(reader).Decode(&messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
@@ -0,0 +1,209 @@
+package transcripts
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"bot/services/storage"
+)
+
+// Message ist eine Nachricht im Transkript. Die ersten Felder entsprechen dem bisherigen JSON-Format,
+// alles Weitere ist optional, damit ältere Transkripte und die Web-App weiter funktionieren.
+type Message struct {
+	UserID      string       `json:"userID"`
+	Username    string       `json:"username"`
+	Message     string       `json:"message"`
+	Timestamp   string       `json:"timestamp"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+
+	ID              string     `json:"id,omitempty"`
+	DisplayName     string     `json:"displayName,omitempty"`
+	AvatarURL       string     `json:"avatarURL,omitempty"`
+	AvatarKey       string     `json:"avatarKey,omitempty"`
+	Bot             bool       `json:"bot,omitempty"`
+	EditedTimestamp string     `json:"editedTimestamp,omitempty"`
+	Pinned          bool       `json:"pinned,omitempty"`
+	ReplyTo         string     `json:"replyTo,omitempty"` // ID der beantworteten Nachricht
+	Embeds          []Embed    `json:"embeds,omitempty"`
+	Reactions       []Reaction `json:"reactions,omitempty"`
+}
+
+// Attachment enthält Metadaten zu einem Anhang
+type Attachment struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	URL         string `json:"url"`
+	Key         string `json:"key,omitempty"` // Schlüssel im Storage (inhaltsadressiert)
+	Size        int64  `json:"size,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Skipped     string `json:"skipped,omitempty"` // Grund, falls nicht gespeichert (Größe, Typ, Fehler)
+}
+
+// Embed ist ein vereinfachtes Discord-Embed
+type Embed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	Color       int          `json:"color,omitempty"`
+	Author      string       `json:"author,omitempty"`
+	Footer      string       `json:"footer,omitempty"`
+	Fields      []EmbedField `json:"fields,omitempty"`
+	Image       *Image       `json:"image,omitempty"`
+	Thumbnail   *Image       `json:"thumbnail,omitempty"`
+}
+
+// EmbedField ist ein Feld eines Embeds
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// Image ist ein Bild eines Embeds, Key ist gesetzt, wenn es gespeichert wurde
+type Image struct {
+	URL string `json:"url"`
+	Key string `json:"key,omitempty"`
+}
+
+// Reaction ist eine Reaktion mit Anzahl
+type Reaction struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
+
+// User ist ein Teilnehmer bzw. erwähnter User
+type User struct {
+	Name      string
+	AvatarKey string // gespeicherter Avatar, wird eingebettet
+}
+
+// Note ist eine interne Notiz des Teams, sie erscheint im HTML-Transkript in einem eigenen Abschnitt
+type Note struct {
+	AuthorName string
+	Content    string
+	CreatedAt  time.Time
+}
+
+// Transcript enthält alles, was für die HTML-Ausgabe benötigt wird
+type Transcript struct {
+	TicketID     int
+	ChannelName  string
+	GuildName    string
+	Messages     []Message
+	Participants []string          // Namen der zusätzlichen Teilnehmer, erscheinen in der Kopfzeile
+	Notes        []Note            // nur für das Team, siehe StripStaffSection
+	Users        map[string]User   // User-ID -> User
+	Roles        map[string]string // Rollen-ID -> Name
+	Channels     map[string]string // Channel-ID -> Name
+	GeneratedAt  time.Time
+	Store        storage.Storage // Quelle für eingebettete Bilder
+}
+
+/*--------------------------------------------------------------------------------------------------------------------------*/
+
+var (
+	userMentionPattern    = regexp.MustCompile(`<@!?(\d+)>`)
+	roleMentionPattern    = regexp.MustCompile(`<@&(\d+)>`)
+	channelMentionPattern = regexp.MustCompile(`<#(\d+)>`)
+)
+
+// MentionedIDs sammelt alle in Texten und Embeds erwähnten User, Rollen und Channels
+func MentionedIDs(messages []Message) (users, roles, channels []string) {
+	seen := map[string]bool{}
+	collect := func(pattern *regexp.Regexp, text string, target *[]string) {
+		for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+			key := pattern.String() + match[1]
+			if !seen[key] {
+				seen[key] = true
+				*target = append(*target, match[1])
+			}
+		}
+	}
+	for _, msg := range messages {
+		texts := []string{msg.Message}
+		for _, embed := range msg.Embeds {
+			texts = append(texts, embed.Title, embed.Description)
+			for _, field := range embed.Fields {
+				texts = append(texts, field.Name, field.Value)
+			}
+		}
+		text := strings.Join(texts, "\n")
+		collect(userMentionPattern, text, &users)
+		collect(roleMentionPattern, text, &roles)
+		collect(channelMentionPattern, text, &channels)
+	}
+	sort.Strings(users)
+	sort.Strings(roles)
+	sort.Strings(channels)
+	return users, roles, channels
+}
+
+// Beginn und Ende des Team-Abschnitts im HTML-Transkript (Notizen werden escaped, "</section>" kann darin nicht vorkommen)
+const (
+	staffSectionStart = `<section class="staff-notes">`
+	staffSectionEnd   = `</section>`
+)
+
+// StripStaffSection entfernt die internen Notizen aus einem HTML-Transkript (für Abrufe ohne Team-Berechtigung)
+func StripStaffSection(page []byte) []byte {
+	start := bytes.Index(page, []byte(staffSectionStart))
+	if start < 0 {
+		return page
+	}
+	end := bytes.Index(page[start:], []byte(staffSectionEnd))
+	if end < 0 {
+		return page[:start]
+	}
+	return append(page[:start:start], page[start+end+len(staffSectionEnd):]...)
+}
+
+// PlainText fasst Nachrichten und Embeds als Text zusammen (z.B. für den Ticket-Suchindex)
+func PlainText(messages []Message) string {
+	var b strings.Builder
+	for _, msg := range messages {
+		if msg.Message != "" {
+			b.WriteString(nameOr(msg.DisplayName, msg.Username) + ": " + msg.Message + "\n")
+		}
+		for _, embed := range msg.Embeds {
+			texts := []string{embed.Title, embed.Description}
+			for _, field := range embed.Fields {
+				texts = append(texts, field.Name, field.Value)
+			}
+			if text := strings.TrimSpace(strings.Join(texts, " ")); text != "" {
+				b.WriteString(text + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// HTMLKey liefert den Schlüssel des HTML-Transkripts, das neben dem JSON-Transkript liegt
+func HTMLKey(jsonKey string) string {
+	return strings.TrimSuffix(jsonKey, ".json") + ".html"
+}
+
+// StoredKeys liefert alle Storage-Schlüssel, auf die die Nachrichten verweisen
+func StoredKeys(messages []Message) []string {
+	var keys []string
+	for _, msg := range messages {
+		if msg.AvatarKey != "" {
+			keys = append(keys, msg.AvatarKey)
+		}
+		for _, att := range msg.Attachments {
+			if att.Key != "" {
+				keys = append(keys, att.Key)
+			}
+		}
+		for _, embed := range msg.Embeds {
+			for _, image := range []*Image{embed.Image, embed.Thumbnail} {
+				if image != nil && image.Key != "" {
+					keys = append(keys, image.Key)
+				}
+			}
+		}
+	}
+	return keys
+}
@@ -0,0 +1,60 @@
+package transcripts
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStripStaffSection(t *testing.T) {
+	messages := []Message{{ID: "1", UserID: "10", Username: "user", Message: "Hallo Team", Timestamp: "2024-05-01T12:00:00Z"}}
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		notes []Note
+	}{
+		{name: "ohne Notizen"},
+		{name: "eine Notiz", notes: []Note{{AuthorName: "mod", Content: "Geheime Notiz", CreatedAt: created}}},
+		{name: "mehrere Notizen", notes: []Note{
+			{AuthorName: "mod", Content: "Geheime Notiz", CreatedAt: created},
+			{AuthorName: "admin", Content: "**Geheime** Notiz 2", CreatedAt: created},
+		}},
+		{name: "Abschnittsende in der Notiz", notes: []Note{{AuthorName: "mod", Content: `</section><p>Geheime Notiz</p><section class="staff-notes">`, CreatedAt: created}}},
+		{name: "Abschnittsende im Autor", notes: []Note{{AuthorName: `</section>Geheime Notiz`, Content: "Geheime Notiz", CreatedAt: created}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := RenderHTML(&Transcript{TicketID: 1, Messages: messages, Notes: tt.notes})
+			if err != nil {
+				t.Fatalf("RenderHTML: %v", err)
+			}
+			if len(tt.notes) > 0 && !strings.Contains(string(page), "Geheime") {
+				t.Fatalf("Notizen fehlen im Transkript für das Team")
+			}
+
+			stripped := string(StripStaffSection(append([]byte(nil), page...)))
+			for _, notWant := range []string{"Geheime", "staff-notes\">", "Interne Notizen"} {
+				if strings.Contains(stripped, notWant) {
+					t.Errorf("%q ist nach dem Entfernen noch enthalten", notWant)
+				}
+			}
+			for _, want := range []string{"Hallo Team", "</main>", "</body>", "</html>"} {
+				if !strings.Contains(stripped, want) {
+					t.Errorf("%q fehlt nach dem Entfernen", want)
+				}
+			}
+			if len(tt.notes) == 0 && stripped != string(page) {
+				t.Errorf("Transkript ohne Notizen wurde verändert")
+			}
+		})
+	}
+}
+
+func TestStripStaffSectionWithoutEnd(t *testing.T) {
+	page := []byte(`<main>Nachrichten</main><section class="staff-notes"><div>Geheime Notiz`)
+	if got := string(StripStaffSection(page)); got != "<main>Nachrichten</main>" {
+		t.Errorf("StripStaffSection = %q", got)
+	}
+}
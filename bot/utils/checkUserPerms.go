@@ -34,6 +34,12 @@ func CheckUserPermissions(bot *discordgo.Session, bot_interaction *discordgo.Int
 	return true
 }
 
+// HasRequiredRole prüft die Berechtigung ohne Interaktion (z.B. für Nachrichten), sendet also keine Antwort
+func HasRequiredRole(bot *discordgo.Session, member *discordgo.Member, requiredRole RequiredRole) bool {
+	userRoles := CheckUserRoles(bot, member)
+	return checkPermissionHierarchy(&userRoles, requiredRole)
+}
+
 // checkPermissionHierarchy implementiert die Berechtigungshierarchie
 func checkPermissionHierarchy(userRoles *shared.UserRoles, requiredRole RequiredRole) bool {
 	if userRoles.Developer || userRoles.HeadManagement || userRoles.Projektleitung {
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bot/database"
+	"database/sql"
 	"fmt"
 	"os"
 
@@ -32,6 +33,24 @@ func GetIdFromDB(bot *discordgo.Session, constKey string) (string) {
 	return value
 }
 
+// Gives the value of an optional constant (e.g. feature settings)
+// If the constant does not exist, the fallback is returned without notifying the admins
+// Other database errors are still reported
+func GetOptionalIdFromDB(bot *discordgo.Session, constKey string, fallback string) string {
+	constant, err := GetConstantEntryFromDB(bot, constKey)
+	if err == sql.ErrNoRows {
+		return fallback
+	}
+	if err != nil {
+		LogAndNotifyAdmins(bot, "medium", "Database Error", "Func:GetOptionalIdFromDB", true, err, fmt.Sprintf("Failed to get constant with key: %s", constKey))
+		return fallback
+	}
+	if value := selectValue(constant); value != "" {
+		return value
+	}
+	return fallback
+}
+
 // gets const Entry from db table bot_const_ids (ID, const_key, prod_value, test_value, description, category, is_active)
 func GetConstantEntryFromDB(bot *discordgo.Session, constKey string) (*BotConstant, error) {
 	query := `
@@ -59,8 +78,7 @@ func GetConstantEntryFromDB(bot *discordgo.Session, constKey string) (*BotConsta
 		&constant.IsActive,
 	)
 	if err != nil {
-		LogAndNotifyAdmins(bot, "critical", "Database Error", "Func:GetConstantEntryFromDB", true, err, fmt.Sprintf("Failed to get constant with key: %s", constKey))
-		return nil, nil
+		return nil, err
 	}
 	return &constant, nil
 }